@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/litmuschaos/chaos-exporter/pkg/sla"
+)
+
+// writeJSON serves v as an indented JSON document
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		log.Error("Unable to write response: ", err)
+	}
+}
+
+// slaHandler serves the resilience SLA report of every application under test, restricted to the
+// applications all the chaosengines of which are in the scope of the token
+func slaHandler(w http.ResponseWriter, r *http.Request) {
+	scope := requestScope(r)
+	reports := []sla.Report{}
+	for _, report := range slaTracker.Reports() {
+		if enginesAllowed(scope, report.Engines) {
+			reports = append(reports, report)
+		}
+	}
+	writeJSON(w, map[string]interface{}{"applications": reports})
+}
+
+// enginesAllowed reports whether the data of each of the engines, namespace/name keys, may be read
+func enginesAllowed(scope *tokenScope, engines []string) bool {
+	for _, engine := range engines {
+		parts := strings.SplitN(engine, "/", 2)
+		if len(parts) != 2 || !scope.allows(parts[0], parts[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// heatmapHandler serves the daily pass & fail counts of every experiment, over the number of days given by
+// the days query parameter (all the retained days by default)
+func heatmapHandler(w http.ResponseWriter, r *http.Request) {
+	days := 0
+	if value := r.URL.Query().Get("days"); value != "" {
+		var err error
+		if days, err = strconv.Atoi(value); err != nil || days < 1 {
+			http.Error(w, "days must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+	matrix := heatmap.Matrix(days)
+	scope := requestScope(r)
+	rows := matrix.Experiments[:0]
+	for _, row := range matrix.Experiments {
+		if scope.allows(row.Namespace, "") {
+			rows = append(rows, row)
+		}
+	}
+	matrix.Experiments = rows
+	writeJSON(w, matrix)
+}
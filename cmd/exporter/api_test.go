@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/sla"
+)
+
+// TestSLAHandlerScope verifies that a scoped token reads the SLA of the applications targeted from its namespaces
+func TestSLAHandlerScope(t *testing.T) {
+	slaTracker.Observe("shop", "app=checkout", "payments/engine-checkout", sla.EngineState{Available: true})
+	slaTracker.Observe("shop", "app=cart", "orders/engine-cart", sla.EngineState{Available: true})
+	defer func() {
+		deleteApplicationSLA(slaTracker.Forget("payments/engine-checkout"))
+		deleteApplicationSLA(slaTracker.Forget("orders/engine-cart"))
+	}()
+
+	scope := &tokenScope{namespaces: map[string]bool{"payments": true}}
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest("GET", "/api/v1/sla", nil)
+	slaHandler(recorder, request.WithContext(context.WithValue(request.Context(), scopeKey{}, scope)))
+	var body struct {
+		Applications []sla.Report `json:"applications"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Applications) != 1 || body.Applications[0].AppLabel != "app=checkout" {
+		t.Errorf("expected the application targeted from payments alone, got %v", body.Applications)
+	}
+}
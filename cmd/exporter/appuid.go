@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/chaosmetrics"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Time after which the UID resolved for an application is looked up again, to follow re-created workloads
+const appUUIDTTL = 10 * time.Minute
+
+// Resolve the UID of the application under test from the appinfo of the chaosengines when APP_UUID is not set
+var autodetectAppUUID bool
+
+// workloadGetter looks up the pods of an application and the controllers owning them
+type workloadGetter interface {
+	pods(ns string, selector string) ([]metav1.ObjectMeta, error)
+	controller(ns string, kind string, name string) (metav1.ObjectMeta, error)
+}
+
+// kubeWorkloads looks up the workloads in the apiserver
+type kubeWorkloads struct {
+	client kubernetes.Interface
+}
+
+func (k kubeWorkloads) pods(ns string, selector string) ([]metav1.ObjectMeta, error) {
+	list, err := k.client.CoreV1().Pods(ns).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]metav1.ObjectMeta, 0, len(list.Items))
+	for _, pod := range list.Items {
+		pods = append(pods, pod.ObjectMeta)
+	}
+	return pods, nil
+}
+
+func (k kubeWorkloads) controller(ns string, kind string, name string) (metav1.ObjectMeta, error) {
+	switch kind {
+	case "ReplicaSet":
+		replicaSet, err := k.client.AppsV1().ReplicaSets(ns).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return metav1.ObjectMeta{}, err
+		}
+		return replicaSet.ObjectMeta, nil
+	case "Job":
+		job, err := k.client.BatchV1().Jobs(ns).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return metav1.ObjectMeta{}, err
+		}
+		return job.ObjectMeta, nil
+	}
+	return metav1.ObjectMeta{}, fmt.Errorf("unsupported controller kind %s", kind)
+}
+
+// Controllers that are themselves owned by the workload, e.g. the ReplicaSets of a Deployment
+var intermediateControllers = map[string]bool{"ReplicaSet": true, "Job": true}
+
+// resolveAppUUID returns the UID of the workload (e.g. Deployment, StatefulSet or DaemonSet) running the pods
+// labelled with selector in ns, found by following their owner references. All the pods must belong to the
+// same workload
+func resolveAppUUID(workloads workloadGetter, ns string, selector string) (string, error) {
+	pods, err := workloads.pods(ns, selector)
+	if err != nil {
+		return "", err
+	}
+	if len(pods) == 0 {
+		return "", fmt.Errorf("no pod labelled %s in namespace %s", selector, ns)
+	}
+	resolved := make(map[types.UID]bool)
+	for _, pod := range pods {
+		object := pod
+		for {
+			owner := metav1.GetControllerOf(&object)
+			if owner == nil {
+				// Not owned by a controller, the object itself is the workload
+				resolved[object.UID] = true
+				break
+			}
+			if !intermediateControllers[owner.Kind] {
+				resolved[owner.UID] = true
+				break
+			}
+			if object, err = workloads.controller(ns, owner.Kind, owner.Name); err != nil {
+				return "", err
+			}
+		}
+	}
+	if len(resolved) > 1 {
+		return "", fmt.Errorf("pods labelled %s in namespace %s belong to %d workloads, set APP_UUID", selector, ns, len(resolved))
+	}
+	for uid := range resolved {
+		return string(uid), nil
+	}
+	return "", nil
+}
+
+// resolvedUUID is an application UID, and the time it was resolved at
+type resolvedUUID struct {
+	uid      string
+	resolved time.Time
+}
+
+// Holds the resolved application UIDs, keyed by <namespace>/<selector>
+var (
+	appUUIDsMutex sync.Mutex
+	appUUIDs      = make(map[string]resolvedUUID)
+)
+
+// lookupAppUUID returns the UID of the application labelled with selector in ns, resolved at most once per
+// appUUIDTTL
+func lookupAppUUID(cfg *rest.Config, ns string, selector string) (string, error) {
+	key := ns + "/" + selector
+	now := exporterClock.Now()
+	appUUIDsMutex.Lock()
+	cached, ok := appUUIDs[key]
+	appUUIDsMutex.Unlock()
+	if ok && now.Sub(cached.resolved) < appUUIDTTL {
+		return cached.uid, nil
+	}
+
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return "", err
+	}
+	uid, err := resolveAppUUID(kubeWorkloads{client: client}, ns, selector)
+	if err != nil {
+		return "", err
+	}
+	appUUIDsMutex.Lock()
+	appUUIDs[key] = resolvedUUID{uid: uid, resolved: now}
+	appUUIDsMutex.Unlock()
+	return uid, nil
+}
+
+// engineAppUUID returns the UID of the application under test of a chaosengine, empty if it cannot be resolved
+func engineAppUUID(cfg *rest.Config, appNS string, chaosEngine string, engineMetrics *chaosmetrics.EngineMetrics) string {
+	ns := engineMetrics.AppNamespace
+	if ns == "" {
+		ns = appNS
+	}
+	uid, err := lookupAppUUID(cfg, ns, engineMetrics.AppLabel)
+	if err != nil {
+		if logger := logSampling.sample(engineLogger(appNS, chaosEngine), "appuid/"+appNS+"/"+chaosEngine); logger != nil {
+			logger.Warn("Unable to resolve the UID of the application under test, set APP_UUID: ", err)
+		}
+		return ""
+	}
+	return uid
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// fakeWorkloads serves the pods & controllers of an application
+type fakeWorkloads struct {
+	podList     []metav1.ObjectMeta
+	controllers map[string]metav1.ObjectMeta
+}
+
+func (f fakeWorkloads) pods(ns string, selector string) ([]metav1.ObjectMeta, error) {
+	return f.podList, nil
+}
+
+func (f fakeWorkloads) controller(ns string, kind string, name string) (metav1.ObjectMeta, error) {
+	meta, ok := f.controllers[kind+"/"+name]
+	if !ok {
+		return meta, fmt.Errorf("%s %s not found", kind, name)
+	}
+	return meta, nil
+}
+
+func TestResolveAppUUID(t *testing.T) {
+	controlled := func(kind string, name string, uid string) []metav1.OwnerReference {
+		isController := true
+		return []metav1.OwnerReference{{Kind: kind, Name: name, UID: types.UID(uid), Controller: &isController}}
+	}
+	workloads := fakeWorkloads{
+		podList: []metav1.ObjectMeta{
+			{Name: "nginx-7c9f-abcde", OwnerReferences: controlled("ReplicaSet", "nginx-7c9f", "rs-1")},
+			{Name: "nginx-6b8d-fghij", OwnerReferences: controlled("ReplicaSet", "nginx-6b8d", "rs-2")},
+		},
+		controllers: map[string]metav1.ObjectMeta{
+			"ReplicaSet/nginx-7c9f": {Name: "nginx-7c9f", UID: "rs-1", OwnerReferences: controlled("Deployment", "nginx", "deploy-uid")},
+			"ReplicaSet/nginx-6b8d": {Name: "nginx-6b8d", UID: "rs-2", OwnerReferences: controlled("Deployment", "nginx", "deploy-uid")},
+		},
+	}
+	if uid, err := resolveAppUUID(workloads, "default", "app=nginx"); err != nil || uid != "deploy-uid" {
+		t.Errorf("expected the UID of the deployment, got %q, %v", uid, err)
+	}
+
+	workloads.podList = append(workloads.podList, metav1.ObjectMeta{Name: "redis-0", OwnerReferences: controlled("StatefulSet", "redis", "sts-uid")})
+	if _, err := resolveAppUUID(workloads, "default", "app=nginx"); err == nil {
+		t.Error("expected an error for pods of several workloads")
+	}
+	workloads.podList = nil
+	if _, err := resolveAppUUID(workloads, "default", "app=nginx"); err == nil {
+		t.Error("expected an error when no pod matches")
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/sinks"
+	dto "github.com/prometheus/client_model/go"
+	"golang.org/x/oauth2"
+)
+
+// Maximum number of dimensions of an Azure Monitor custom metric
+const azureMonitorMaxDimensions = 10
+
+// Holds the settings of the Azure Monitor publishing, an empty resource ID disables it
+var (
+	azureMonitorResourceID string
+	azureMonitorRegion     string
+	azureMonitorNamespace  string
+	azureMonitorDimensions string
+	azureMonitorTimeout    time.Duration
+)
+
+// Holds the Azure Monitor sink, nil unless the publishing is enabled
+var azureMonitorSink *azureMonitorPublisher
+
+// azureMonitorPublisher publishes the series of the chaosengines as custom metrics of an Azure resource
+type azureMonitorPublisher struct {
+	client *sinks.AzureMonitor
+	// Labels of the engine series kept as dimensions, the others are dropped
+	dimensions map[string]bool
+}
+
+// newAzureMonitorPublisher returns the publisher of the configured resource, nil if no resource ID is configured.
+// The requests are authenticated with the workload identity of the serviceaccount, or the managed identity of the
+// node (see sinks.NewAzureTokenSource)
+func newAzureMonitorPublisher(resourceID string, region string, namespace string, dimensions string, timeout time.Duration) (*azureMonitorPublisher, error) {
+	if resourceID == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(resourceID, "/subscriptions/") {
+		return nil, fmt.Errorf("expected a resource ID starting with /subscriptions/, got %q", resourceID)
+	}
+	if region == "" {
+		return nil, fmt.Errorf("the region of the resource is required along with its ID")
+	}
+	publisher := &azureMonitorPublisher{dimensions: make(map[string]bool)}
+	for _, name := range splitList(dimensions) {
+		publisher.dimensions[name] = true
+	}
+	if len(publisher.dimensions) > azureMonitorMaxDimensions {
+		return nil, fmt.Errorf("expected at most %d dimensions, got %d", azureMonitorMaxDimensions, len(publisher.dimensions))
+	}
+	client := &http.Client{
+		Transport: &oauth2.Transport{Source: sinks.NewAzureTokenSource(&http.Client{Timeout: timeout}), Base: http.DefaultTransport},
+		Timeout:   timeout,
+	}
+	publisher.client = &sinks.AzureMonitor{Region: region, ResourceID: resourceID, Namespace: namespace, Client: client}
+	return publisher, nil
+}
+
+// publish publishes the gauges of the last collection of every chaosengine. Called after each collection pass, it
+// does nothing unless the publishing is enabled
+func (p *azureMonitorPublisher) publish(ctx context.Context) {
+	if p == nil {
+		return
+	}
+	_, engines, err := gatherEngineFamilies()
+	if err != nil {
+		recordAzureMonitorPublish(err)
+		return
+	}
+	var metrics []sinks.AzureMetric
+	for _, families := range engines {
+		for _, family := range families {
+			if family.GetType() != dto.MetricType_GAUGE {
+				continue
+			}
+			for _, metric := range family.Metric {
+				azureMetric := sinks.AzureMetric{Name: family.GetName(), Value: metric.GetGauge().GetValue()}
+				for _, label := range metric.Label {
+					if p.dimensions[label.GetName()] {
+						azureMetric.Dimensions = append(azureMetric.Dimensions, sinks.Tag{Name: label.GetName(), Value: label.GetValue()})
+					}
+				}
+				metrics = append(metrics, azureMetric)
+			}
+		}
+	}
+	if len(metrics) == 0 {
+		return
+	}
+	recordAzureMonitorPublish(p.client.Publish(ctx, metrics, exporterClock.Now()))
+}
+
+// recordAzureMonitorPublish counts the outcome of a publishing, logging failures
+func recordAzureMonitorPublish(err error) {
+	if err == nil {
+		azureMonitorPublishes.WithLabelValues("success").Inc()
+		return
+	}
+	azureMonitorPublishes.WithLabelValues("failure").Inc()
+	if logger := logSampling.sample(exporterLog, "azuremonitor"); logger != nil {
+		logger.Warn("Unable to publish the metrics to Azure Monitor: ", err)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"runtime"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Holds the summary of the settings the exporter started with, logged once & served by /debug/status
+var startupSummary = log.Fields{}
+
+// newStartupSummary summarizes the collection settings the exporter starts with, for support tickets to carry
+// a complete picture from a single log line. Serving settings are added by the caller
+func newStartupSummary(mode string, settings runtimeSettings, kubernetesVersion string, openebsVersion string) log.Fields {
+	providers := []string{}
+	for _, provider := range chaosProviders {
+		providers = append(providers, provider.Name())
+	}
+	sinkNames := []string{}
+	for _, endpoint := range sinkEndpoints {
+		sinkNames = append(sinkNames, endpoint.Sink)
+	}
+	return log.Fields{
+		"mode":               mode,
+		"chaosEngine":        settings.defaults.chaosEngine,
+		"namespaces":         splitNamespaces(settings.defaults.appNamespace),
+		"engineSelector":     settings.defaults.engineSelector,
+		"providers":          providers,
+		"sinks":              sinkNames,
+		"resyncPeriod":       settings.resync.String(),
+		"chaosResultResync":  chaosResultResync.String(),
+		"collectionWorkers":  collectionWorkers,
+		"engineTimeout":      engineTimeout.String(),
+		"collectionDeadline": collectionDeadline.String(),
+		"stateRetention":     stateRetention.String(),
+		"leaderElect":        leaderElect,
+		"timeSource":         timeSource,
+		"kubernetesVersion":  kubernetesVersion,
+		"openebsVersion":     openebsVersion,
+		"version":            exporterVersion,
+		"commit":             gitCommit,
+		"goVersion":          runtime.Version(),
+	}
+}
+
+// debugStatusHandler serves the startup summary, which spans every namespace, to cluster-wide tokens only
+func debugStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if requestScope(r) != nil {
+		http.Error(w, "the startup summary requires a token without namespaces", http.StatusForbidden)
+		return
+	}
+	writeJSON(w, startupSummary)
+}
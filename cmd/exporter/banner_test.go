@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+func TestStartupSummary(t *testing.T) {
+	defer func() { startupSummary = log.Fields{} }()
+	startupSummary = newStartupSummary(modeStandalone, runtimeSettings{
+		defaults: exporterSettings{appNamespace: "litmus,payments"},
+		resync:   time.Minute,
+	}, "1.13", "1.0")
+
+	w := httptest.NewRecorder()
+	debugStatusHandler(w, httptest.NewRequest("GET", "/debug/status", nil))
+	var summary map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary["mode"] != modeStandalone || summary["resyncPeriod"] != "1m0s" || summary["kubernetesVersion"] != "1.13" {
+		t.Errorf("unexpected summary %v", summary)
+	}
+	if namespaces, ok := summary["namespaces"].([]interface{}); !ok || len(namespaces) != 2 {
+		t.Errorf("expected the monitored namespaces, got %v", summary["namespaces"])
+	}
+
+	// Tokens scoped to namespaces are refused the summary of them all
+	scope := &tokenScope{namespaces: map[string]bool{"payments": true}}
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/debug/status", nil)
+	debugStatusHandler(w, r.WithContext(context.WithValue(r.Context(), scopeKey{}, scope)))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected a scoped token to be refused, got %d %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Annotation of a chaosengine setting its failure budget, overriding FAILURE_BUDGET
+const failureBudgetAnnotation = "litmuschaos.io/failure-budget"
+
+// failureBudget is the number of experiment failures an engine is expected to have within a rolling window
+type failureBudget struct {
+	allowed int
+	window  time.Duration
+}
+
+// Holds the failure budget of the engines without a failure budget annotation, nil for none
+var defaultFailureBudget *failureBudget
+
+// Holds the time of the recent experiment failures of each engine, keyed by <namespace>/<engine>
+var engineFailures = make(map[string][]time.Time)
+
+// parseFailureBudget parses a budget of the form <failures>/<window>, for e.g. 1/168h for one failure a week.
+// An empty value yields no budget
+func parseFailureBudget(value string) (*failureBudget, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("%q is not of the form <failures>/<window>", value)
+	}
+	allowed, err := strconv.Atoi(parts[0])
+	if err != nil || allowed < 0 {
+		return nil, fmt.Errorf("invalid number of failures %q", parts[0])
+	}
+	window, err := time.ParseDuration(parts[1])
+	if err != nil || window <= 0 {
+		return nil, fmt.Errorf("invalid window %q", parts[1])
+	}
+	return &failureBudget{allowed: allowed, window: window}, nil
+}
+
+// engineFailureBudget returns the budget of an engine, from its annotation or the default
+func engineFailureBudget(annotations map[string]string) (*failureBudget, error) {
+	if value, ok := annotations[failureBudgetAnnotation]; ok {
+		return parseFailureBudget(value)
+	}
+	return defaultFailureBudget, nil
+}
+
+// budgetRemaining returns the failures an engine may still have within the window of its budget, negative once
+// the budget is overrun, dropping the failures past the window. It must be called with stateMutex held
+func budgetRemaining(key string, budget *failureBudget, now time.Time) int {
+	recent := engineFailures[key][:0]
+	for _, failure := range engineFailures[key] {
+		if now.Sub(failure) < budget.window {
+			recent = append(recent, failure)
+		}
+	}
+	if len(recent) == 0 {
+		delete(engineFailures, key)
+	} else {
+		engineFailures[key] = recent
+	}
+	return budget.allowed - len(recent)
+}
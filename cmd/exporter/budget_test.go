@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestFailureBudget(t *testing.T) {
+	for _, value := range []string{"1", "x/168h", "-1/168h", "1/week", "1/0s"} {
+		if _, err := parseFailureBudget(value); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+	budget, err := engineFailureBudget(map[string]string{failureBudgetAnnotation: "2/1h"})
+	if err != nil || *budget != (failureBudget{allowed: 2, window: time.Hour}) {
+		t.Fatalf("unexpected budget %v, %v", budget, err)
+	}
+
+	fakeClock := clock.NewFakeClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	defer func(c clock.Clock) { exporterClock = c }(exporterClock)
+	exporterClock = fakeClock
+	defer delete(engineFailures, "litmus/engine-budget")
+
+	// Two failures of pod-delete, the first observation is not a transition
+	for _, verdict := range []float64{3, 2, 3, 2} {
+		recordTransitions("litmus", "engine-budget", map[string]float64{"pod-delete": verdict}, nil)
+		fakeClock.Step(10 * time.Minute)
+	}
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+	if remaining := budgetRemaining("litmus/engine-budget", budget, fakeClock.Now()); remaining != 0 {
+		t.Errorf("expected the budget to be exhausted, got %d remaining", remaining)
+	}
+	// The first failure leaves the window
+	fakeClock.Step(30 * time.Minute)
+	if remaining := budgetRemaining("litmus/engine-budget", budget, fakeClock.Now()); remaining != 1 {
+		t.Errorf("expected 1 failure remaining, got %d", remaining)
+	}
+}
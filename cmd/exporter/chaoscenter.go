@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/credentials"
+	"github.com/litmuschaos/chaos-exporter/pkg/sinks"
+)
+
+// Holds the settings of the ChaosCenter feed, an empty URL disables it
+var (
+	chaosCenterURL       string
+	chaosCenterInfraID   string
+	chaosCenterAccessKey string
+	chaosCenterTimeout   time.Duration
+)
+
+// Holds the ChaosCenter client, nil unless the feed is enabled
+var chaosCenterSink *sinks.ChaosCenter
+
+// Phases of the runs & experiments reported to the ChaosCenter, by their verdict
+var chaosCenterPhases = map[string]string{
+	"":     "Running",
+	"pass": "Succeeded",
+	"fail": "Failed",
+}
+
+// newChaosCenterSink returns the client of the configured ChaosCenter, nil if no URL is configured. The access
+// key of the chaos infrastructure is a credential reference
+func newChaosCenterSink(address string, infraID string, accessKeyRef string, timeout time.Duration) (*sinks.ChaosCenter, error) {
+	if address == "" {
+		return nil, nil
+	}
+	if err := validatePushURL(address); err != nil {
+		return nil, err
+	}
+	if infraID == "" || accessKeyRef == "" {
+		return nil, fmt.Errorf("the infrastructure ID & access key are required along with the URL")
+	}
+	accessKey, err := credentials.New(accessKeyRef)
+	if err != nil {
+		return nil, err
+	}
+	return &sinks.ChaosCenter{
+		URL:       address,
+		InfraID:   infraID,
+		AccessKey: accessKey,
+		Version:   exporterVersion,
+		Client:    &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// chaosCenterNode is the execution data of an experiment of a run
+type chaosCenterNode struct {
+	Name       string              `json:"name"`
+	Phase      string              `json:"phase"`
+	Type       string              `json:"type"`
+	StartedAt  string              `json:"startedAt"`
+	FinishedAt string              `json:"finishedAt,omitempty"`
+	ChaosData  chaosCenterNodeData `json:"chaosData"`
+}
+
+type chaosCenterNodeData struct {
+	EngineName        string `json:"engineName"`
+	Namespace         string `json:"namespace"`
+	ExperimentName    string `json:"experimentName"`
+	ExperimentVerdict string `json:"experimentVerdict"`
+	FailStep          string `json:"failStep,omitempty"`
+}
+
+// chaosCenterExecution is the execution data of a run, the experiments of a chaosengine
+type chaosCenterExecution struct {
+	Name       string                     `json:"name"`
+	Namespace  string                     `json:"namespace"`
+	Phase      string                     `json:"phase"`
+	StartedAt  string                     `json:"startedAt"`
+	FinishedAt string                     `json:"finishedAt,omitempty"`
+	Nodes      map[string]chaosCenterNode `json:"nodes"`
+}
+
+// chaosCenterRuns tracks the run of every chaosengine, keyed by <namespace>/<engine>, from the first verdict
+// transition of its experiments to the one completing them
+type chaosCenterRuns struct {
+	runs map[string]*chaosCenterExecution
+}
+
+func newChaosCenterRuns() *chaosCenterRuns {
+	return &chaosCenterRuns{runs: make(map[string]*chaosCenterExecution)}
+}
+
+// observe updates the run of the engine of event, returning it to report. The run is forgotten once completed,
+// the next transition starting another
+func (c *chaosCenterRuns) observe(event verdictEvent) sinks.ChaosCenterRun {
+	seconds := func(t time.Time) string {
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+	key := event.Namespace + "/" + event.Engine
+	run, ok := c.runs[key]
+	if !ok {
+		started := event.Since
+		if started.IsZero() {
+			started = event.Time
+		}
+		run = &chaosCenterExecution{Name: event.Engine, Namespace: event.Namespace, StartedAt: seconds(started), Nodes: make(map[string]chaosCenterNode)}
+		c.runs[key] = run
+	}
+	node, ok := run.Nodes[event.Experiment]
+	if !ok {
+		node = chaosCenterNode{Name: event.Experiment, Type: "ChaosEngine", StartedAt: seconds(event.Time)}
+	}
+	node.Phase = "Running"
+	node.ChaosData = chaosCenterNodeData{
+		EngineName:        event.Engine,
+		Namespace:         event.Namespace,
+		ExperimentName:    event.Experiment,
+		ExperimentVerdict: event.To,
+		FailStep:          event.FailStep,
+	}
+	if event.To == "pass" || event.To == "fail" {
+		node.Phase, node.FinishedAt = chaosCenterPhases[event.To], seconds(event.Time)
+	}
+	run.Nodes[event.Experiment] = node
+	run.Phase = chaosCenterPhases[event.EngineVerdict]
+	if event.EngineVerdict != "" {
+		run.FinishedAt = seconds(event.Time)
+		delete(c.runs, key)
+	}
+	return sinks.ChaosCenterRun{
+		ExperimentID:   key,
+		ExperimentName: key,
+		RunID:          event.Namespace + "-" + event.Engine + "-" + run.StartedAt,
+		Completed:      event.EngineVerdict != "",
+		ExecutionData:  *run,
+	}
+}
+
+// runChaosCenterFeed reports the run of a chaosengine to the ChaosCenter on every verdict transition of its
+// experiments, until ctx is done, so that the runs collected by the exporter show in the Portal
+func runChaosCenterFeed(ctx context.Context, sink *sinks.ChaosCenter) {
+	events, unsubscribe := verdictEvents.subscribe()
+	defer unsubscribe()
+	runs := newChaosCenterRuns()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			recordChaosCenterReport(sink.Report(ctx, runs.observe(event)))
+		}
+	}
+}
+
+// recordChaosCenterReport counts the outcome of a report to the ChaosCenter, logging failures
+func recordChaosCenterReport(err error) {
+	if err == nil {
+		chaosCenterReports.WithLabelValues("success").Inc()
+		return
+	}
+	chaosCenterReports.WithLabelValues("failure").Inc()
+	if logger := logSampling.sample(exporterLog, "chaoscenter"); logger != nil {
+		logger.Warn("Unable to report the run to the ChaosCenter: ", err)
+	}
+}
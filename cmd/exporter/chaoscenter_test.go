@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChaosCenterRuns(t *testing.T) {
+	runs := newChaosCenterRuns()
+	start := time.Unix(60, 0)
+	run := runs.observe(verdictEvent{Namespace: "litmus", Engine: "engine-nginx", Experiment: "pod-delete", From: "awaited", To: "running", Time: start.Add(time.Minute), Since: start})
+	execution := run.ExecutionData.(chaosCenterExecution)
+	if run.Completed || run.RunID != "litmus-engine-nginx-60" || execution.Phase != "Running" || execution.Nodes["pod-delete"].Phase != "Running" {
+		t.Errorf("unexpected running run %+v", run)
+	}
+	run = runs.observe(verdictEvent{Namespace: "litmus", Engine: "engine-nginx", Experiment: "pod-delete", From: "running", To: "fail", Time: start.Add(2 * time.Minute), FailStep: "ChaosInject", EngineVerdict: "fail"})
+	execution = run.ExecutionData.(chaosCenterExecution)
+	node := execution.Nodes["pod-delete"]
+	if !run.Completed || run.RunID != "litmus-engine-nginx-60" || execution.Phase != "Failed" || execution.FinishedAt != "180" {
+		t.Errorf("unexpected completed run %+v", run)
+	}
+	if node.Phase != "Failed" || node.StartedAt != "120" || node.ChaosData.FailStep != "ChaosInject" {
+		t.Errorf("unexpected node %+v", node)
+	}
+	// The next transition starts another run
+	run = runs.observe(verdictEvent{Namespace: "litmus", Engine: "engine-nginx", Experiment: "pod-delete", From: "fail", To: "running", Time: start.Add(time.Hour), Since: start.Add(time.Hour)})
+	if run.Completed || run.RunID != "litmus-engine-nginx-3660" {
+		t.Errorf("expected another run, got %+v", run)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// Version, commit & date of the exporter build, set with -ldflags "-X main.exporterVersion=..." and so on
+var (
+	exporterVersion = "dev"
+	gitCommit       = "unknown"
+	buildDate       = "unknown"
+)
+
+const usage = `Usage: exporter [command] [flags]
+
+Commands:
+  serve          collect & serve the chaos metrics (default)
+  validate       check the settings, ENVs & files, without connecting to the cluster
+  gen-dashboard  collect the chaosengines once & print a Grafana dashboard of them
+  gen-rules      print the Prometheus alerting rules on the chaos metrics, without connecting to the cluster
+  version        print the version of the exporter
+
+Run 'exporter serve -h' for the flags, which all commands accept.
+`
+
+// buildInfo describes the exporter build
+type buildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+}
+
+func currentBuild() buildInfo {
+	return buildInfo{
+		Version:   exporterVersion,
+		Commit:    gitCommit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+}
+
+// printVersion writes the version of the exporter build to w
+func printVersion(w io.Writer) {
+	build := currentBuild()
+	fmt.Fprintf(w, "chaos-exporter %s (commit %s, built %s, %s %s)\n", build.Version, build.Commit, build.BuildDate, build.GoVersion, build.Platform)
+}
+
+// versionHandler serves the version of the exporter build, to confirm what is deployed
+func versionHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, currentBuild())
+}
+
+// parseCommand splits the command off the args, serve if the args start with a flag
+func parseCommand(args []string) (string, []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return "serve", args
+	}
+	return args[0], args[1:]
+}
+
+func main() {
+	command, args := parseCommand(os.Args[1:])
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage+"\nFlags:\n")
+		flag.PrintDefaults()
+	}
+	switch command {
+	case "serve":
+		serve(args, false)
+	case "validate":
+		serve(args, true)
+	case "gen-rules":
+		generateRules = true
+		serve(args, true)
+	case "gen-dashboard":
+		generateDashboard = true
+		serve(args, false)
+	case "version":
+		printVersion(os.Stdout)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	for args, expected := range map[string]string{
+		"":                              "serve ",
+		"--mode sidecar":                "serve --mode sidecar",
+		"validate --config config.yaml": "validate --config config.yaml",
+		"version":                       "version ",
+	} {
+		command, rest := parseCommand(strings.Fields(args))
+		if got := command + " " + strings.Join(rest, " "); got != expected {
+			t.Errorf("expected %q from %q, got %q", expected, args, got)
+		}
+	}
+
+	var out bytes.Buffer
+	printVersion(&out)
+	if !strings.HasPrefix(out.String(), "chaos-exporter dev (commit unknown, built unknown, go") {
+		t.Errorf("unexpected version %q", out.String())
+	}
+}
+
+func TestVersionHandler(t *testing.T) {
+	exporterVersion, gitCommit = "1.2.0", "abc1234"
+	defer func() { exporterVersion, gitCommit = "dev", "unknown" }()
+
+	w := httptest.NewRecorder()
+	versionHandler(w, httptest.NewRequest("GET", "/version", nil))
+	var build buildInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &build); err != nil {
+		t.Fatal(err)
+	}
+	if build.Version != "1.2.0" || build.Commit != "abc1234" || build.BuildDate != "unknown" || build.GoVersion == "" {
+		t.Errorf("unexpected build info %+v", build)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/sinks"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Holds the settings of the CloudWatch publishing, an empty namespace disables it
+var (
+	cloudWatchNamespace        string
+	cloudWatchRegion           string
+	cloudWatchDimensions       string
+	cloudWatchStaticDimensions string
+	cloudWatchTimeout          time.Duration
+)
+
+// Holds the CloudWatch sink, nil unless the publishing is enabled
+var cloudWatchSink *cloudWatchPublisher
+
+// Families of the application chaos windows, published as durations along with the engine series to CloudWatch
+// & New Relic
+var applicationDurations = map[string]bool{
+	"litmuschaos_application_chaos_seconds":     true,
+	"litmuschaos_application_available_seconds": true,
+}
+
+// cloudWatchPublisher publishes the experiment counts, verdicts & chaos durations to CloudWatch
+type cloudWatchPublisher struct {
+	client *sinks.CloudWatch
+	// Labels of the engine series kept as dimensions, the others are dropped
+	dimensions map[string]bool
+	// Dimensions added to every metric, e.g. the cluster name
+	static []sinks.Tag
+}
+
+// newCloudWatchPublisher returns the publisher of the configured namespace, nil if no namespace is configured.
+// The credentials are those of IRSA (AWS_ROLE_ARN & AWS_WEB_IDENTITY_TOKEN_FILE) or of the AWS_* ENVs
+func newCloudWatchPublisher(namespace string, region string, dimensions string, static string, timeout time.Duration) (*cloudWatchPublisher, error) {
+	if namespace == "" {
+		return nil, nil
+	}
+	if region == "" {
+		return nil, fmt.Errorf("the AWS region is required, set --cloudwatch.region or AWS_REGION")
+	}
+	publisher := &cloudWatchPublisher{dimensions: make(map[string]bool)}
+	for _, name := range strings.Split(dimensions, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			publisher.dimensions[name] = true
+		}
+	}
+	pairs, err := parseKeyValues(static)
+	if err != nil {
+		return nil, fmt.Errorf("invalid static dimensions: %v", err)
+	}
+	for name, value := range pairs {
+		publisher.static = append(publisher.static, sinks.Tag{Name: name, Value: value})
+	}
+	sort.Slice(publisher.static, func(i, j int) bool { return publisher.static[i].Name < publisher.static[j].Name })
+	client := &http.Client{Timeout: timeout}
+	publisher.client = &sinks.CloudWatch{
+		Namespace:   namespace,
+		Region:      region,
+		Credentials: sinks.NewAWSCredentials(region, client),
+		Client:      client,
+	}
+	return publisher, nil
+}
+
+// publish publishes the series of the last collection of every chaosengine, and the chaos durations of the
+// applications under test. Called after each collection pass, it does nothing unless the publishing is enabled
+func (p *cloudWatchPublisher) publish(ctx context.Context, gatherer prometheus.Gatherer) {
+	if p == nil {
+		return
+	}
+	_, engines, err := gatherEngineFamilies()
+	if err != nil {
+		recordCloudWatchPublish(err)
+		return
+	}
+	var datums []sinks.CloudWatchDatum
+	for _, families := range engines {
+		datums = append(datums, p.datums(families, p.dimensions)...)
+	}
+	families, err := gatherer.Gather()
+	if err != nil {
+		recordCloudWatchPublish(err)
+		return
+	}
+	for _, family := range families {
+		if applicationDurations[family.GetName()] {
+			datums = append(datums, p.datums([]*dto.MetricFamily{family}, map[string]bool{"app_namespace": true, "app_label": true})...)
+		}
+	}
+	if len(datums) == 0 {
+		return
+	}
+	recordCloudWatchPublish(p.client.PutMetricData(ctx, datums, exporterClock.Now()))
+}
+
+// datums converts the gauges of families to CloudWatch datums, dimensioned by their labels listed in dimensions
+func (p *cloudWatchPublisher) datums(families []*dto.MetricFamily, dimensions map[string]bool) []sinks.CloudWatchDatum {
+	var datums []sinks.CloudWatchDatum
+	for _, family := range families {
+		if family.GetType() != dto.MetricType_GAUGE {
+			continue
+		}
+		for _, metric := range family.Metric {
+			datum := sinks.CloudWatchDatum{MetricName: family.GetName(), Value: metric.GetGauge().GetValue(), Unit: cloudWatchUnit(family.GetName())}
+			for _, label := range metric.Label {
+				if dimensions[label.GetName()] && label.GetValue() != "" {
+					datum.Dimensions = append(datum.Dimensions, sinks.Tag{Name: label.GetName(), Value: label.GetValue()})
+				}
+			}
+			datum.Dimensions = append(datum.Dimensions, p.static...)
+			datums = append(datums, datum)
+		}
+	}
+	return datums
+}
+
+// cloudWatchUnit returns the CloudWatch unit of a family, from the suffix of its name
+func cloudWatchUnit(name string) string {
+	switch {
+	case strings.HasSuffix(name, "_seconds"):
+		return "Seconds"
+	case strings.HasSuffix(name, "_count"), strings.HasSuffix(name, "_experiments"):
+		return "Count"
+	case strings.HasSuffix(name, "_percent"):
+		return "Percent"
+	}
+	return "None"
+}
+
+// recordCloudWatchPublish counts the outcome of a publishing, logging failures
+func recordCloudWatchPublish(err error) {
+	if err == nil {
+		cloudWatchPublishes.WithLabelValues("success").Inc()
+		return
+	}
+	cloudWatchPublishes.WithLabelValues("failure").Inc()
+	if logger := logSampling.sample(exporterLog, "cloudwatch"); logger != nil {
+		logger.Warn("Unable to publish the metrics to CloudWatch: ", err)
+	}
+}
@@ -0,0 +1,697 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/litmuschaos/chaos-exporter/pkg/chaosmetrics"
+	clientV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/clientset/v1alpha1"
+	"github.com/litmuschaos/chaos-exporter/pkg/history"
+	"github.com/litmuschaos/chaos-exporter/pkg/informers"
+	"github.com/litmuschaos/chaos-exporter/pkg/sla"
+	"github.com/litmuschaos/chaos-exporter/pkg/version"
+	"github.com/prometheus/client_golang/prometheus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/rest"
+)
+
+// Collects on the replica elected by a Lease only, the others standing by
+var leaderElect bool
+
+// Number of consecutive failed collections after which the exporter exits, 0 retries forever
+var maxConsecutiveFailures int
+
+// Number of chaosengines collected in parallel, and the time after which the collection of an engine is abandoned
+var (
+	collectionWorkers int
+	engineTimeout     time.Duration
+)
+
+// Time after which a collection pass returns the engines collected so far, 0 disables the deadline
+var collectionDeadline time.Duration
+
+// Holds the engines left uncollected by the last pass, mapped to the monitored namespace (empty for the
+// cluster) they were listed in. They are collected first by the next pass
+var staleEngines = make(map[types.NamespacedName]string)
+
+// Period after which the chaosresult informer cache is relisted, as a safety net against missed events
+var chaosResultResync time.Duration
+
+// Interval at which the kubernetes & openebs versions are looked up again
+var versionRefreshInterval time.Duration
+
+// Holds the versions the metrics are currently labelled with
+var currentVersions [2]string
+
+// Source of the time used to account chaos windows, local or apiserver (local time corrected by the clock skew)
+var timeSource string
+
+// Drives the resync & retry intervals of the collection loop, replaced by a fake clock in tests
+var exporterClock clock.Clock = clock.RealClock{}
+
+// randSource is the source of the retry jitter
+type randSource interface {
+	Int63n(n int64) int64
+}
+
+// Holds the source of the retry jitter, only used by the collection loop. Replaced by a fixed source in tests
+var jitter randSource = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// Bounds of the wait period between retries of a failed collection
+var (
+	minRetryBackoff = 1 * time.Second
+	maxRetryBackoff = 2 * time.Minute
+)
+
+// Guards the state below, which is shared by the collection of all the monitored namespaces
+var stateMutex sync.Mutex
+
+// Holds the reasons each chaosengine was last reported invalid for, keyed by <namespace>/<engine>
+var invalidEngines = make(map[string][]string)
+
+// Holds the dynamic (experiment state) gauges, keyed by the sanitized experiment name.
+// These are shared by all the monitored chaosengines
+var experimentGauges = make(map[string]*engineGauge)
+
+// Holds the providers of the collected chaos engines, litmus unless CHAOS_PROVIDERS lists others
+var chaosProviders = []chaosmetrics.ChaosProvider{chaosmetrics.LitmusProvider{}}
+
+// relevantEvent checks whether a change to a chaos resource affects the metrics of chaosEngine.
+// All changes are relevant when every chaosengine in the namespace is monitored
+func relevantEvent(event chaosmetrics.ChaosEvent, chaosEngine string) bool {
+	if chaosEngine == "" {
+		return true
+	}
+	if event.Resource == "chaosengines" {
+		return event.Name == chaosEngine
+	}
+	return strings.HasPrefix(event.Name, chaosEngine+"-")
+}
+
+// waitForChange blocks until a change relevant to chaosEngine is observed, the resync period has elapsed,
+// settings are reloaded, the chaosexporterconfig is changed or ctx is done
+func waitForChange(ctx context.Context, events <-chan chaosmetrics.ChaosEvent, chaosEngine string, resync time.Duration, reloaded <-chan struct{}, configChanged <-chan struct{}) {
+	timeout := exporterClock.After(resync)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timeout:
+			return
+		case <-reloaded:
+			return
+		case <-configChanged:
+			log.Info("chaosexporterconfig changed, reconfiguring")
+			return
+		case event := <-events:
+			if !relevantEvent(event, chaosEngine) {
+				continue
+			}
+			exporterLog.WithFields(log.Fields{"namespace": event.Namespace, "resource": event.Resource, "name": event.Name}).Debugf("%s, updating metrics", event.Type)
+			// Coalesce the changes delivered in a burst into a single collection
+			for {
+				select {
+				case <-events:
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// setInvalidReasons reports the reasons a chaosengine is invalid for, clearing those that no longer apply
+func setInvalidReasons(appNS string, chaosEngine string, reasons []string) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+
+	key := appNS + "/" + chaosEngine
+	for _, reason := range invalidEngines[key] {
+		engineInvalid.DeleteLabelValues(labelValues(appNS, chaosEngine, reason)...)
+	}
+	for _, reason := range reasons {
+		if logger := logSampling.sample(engineLogger(appNS, chaosEngine), "invalid/"+key+"/"+reason); logger != nil {
+			logger.Warn("chaosengine is invalid: ", reason)
+		}
+		engineInvalid.WithLabelValues(labelValues(appNS, chaosEngine, reason)...).Set(1)
+	}
+	if len(reasons) == 0 {
+		delete(invalidEngines, key)
+		return
+	}
+	invalidEngines[key] = reasons
+}
+
+// experimentGauge returns the dynamic gauge of an experiment, defining it on first use.
+// Experiments common to several engines and namespaces share a single gauge
+func experimentGauge(sanitizedExpName string) *engineGauge {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+
+	tmpExp, ok := experimentGauges[sanitizedExpName]
+	if !ok {
+		tmpExp = newExperimentGauge(sanitizedExpName)
+		experimentGauges[sanitizedExpName] = tmpExp
+	}
+	return tmpExp
+}
+
+// splitNamespaces returns the namespaces listed in a comma separated APP_NAMESPACE.
+// An empty value yields a single, cluster-wide, namespace
+func splitNamespaces(appNS string) []string {
+	var namespaces []string
+	for _, ns := range strings.Split(appNS, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	if len(namespaces) == 0 {
+		return []string{""}
+	}
+	return namespaces
+}
+
+// setVersionInfo updates the version info metric, returning whether the versions changed (i.e. an upgrade)
+func setVersionInfo(kubernetesVersion string, openebsVersion string) bool {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+
+	versions := [2]string{kubernetesVersion, openebsVersion}
+	if versions == currentVersions {
+		return false
+	}
+	if currentVersions != [2]string{} {
+		log.Infof("versions changed to kubernetes: %s, openebs: %s", kubernetesVersion, openebsVersion)
+		versionInfo.DeleteLabelValues(currentVersions[0], currentVersions[1])
+	}
+	versionInfo.WithLabelValues(kubernetesVersion, openebsVersion).Set(1)
+	currentVersions = versions
+	return true
+}
+
+// engineJob is a chaosengine (or the engine of another chaos provider) to collect
+type engineJob struct {
+	provider chaosmetrics.ChaosProvider
+	engine   types.NamespacedName
+}
+
+// collectNamespace collects the chaos metrics of the monitored chaosengines of a namespace, in parallel
+// on up to collectionWorkers workers. A failure to collect an engine (including a timeout) does not
+// prevent the collection of the others, the last error is returned. Once ctx is done, the engines not
+// collected yet are marked stale & their count returned, they keep the series of their last collection
+func collectNamespace(ctx context.Context, cfg *rest.Config, settings exporterSettings, appNS string, kubernetesVersion string, openebsVersion string) (int, error) {
+	// Monitor the specified chaosengine, or all the engines of every provider in the namespace if none is specified
+	engines := []engineJob{{provider: chaosmetrics.LitmusProvider{}, engine: types.NamespacedName{Namespace: appNS, Name: settings.chaosEngine}}}
+	if settings.chaosEngine == "" {
+		engines = nil
+		for _, provider := range chaosProviders {
+			listed, err := provider.ListEngines(ctx, cfg, appNS, settings.engineSelector)
+			if err != nil {
+				exporterLog.WithFields(log.Fields{"namespace": appNS, "provider": provider.Name()}).Error("Unable to list the engines: ", err.Error())
+				return 0, err
+			}
+			for _, engine := range listed {
+				if isSelfTestEngine(engine) {
+					continue
+				}
+				engines = append(engines, engineJob{provider: provider, engine: engine})
+			}
+		}
+	}
+
+	// Continue with the engines the previous pass left stale
+	stateMutex.Lock()
+	sort.SliceStable(engines, func(i, j int) bool {
+		_, iStale := staleEngines[engines[i].engine]
+		_, jStale := staleEngines[engines[j].engine]
+		return iStale && !jStale
+	})
+	stateMutex.Unlock()
+
+	workers := collectionWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(engines) {
+		workers = len(engines)
+	}
+
+	jobs := make(chan engineJob)
+	var errMutex sync.Mutex
+	var lastErr error
+	var pending []types.NamespacedName
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				err := collectEngineWithTimeout(ctx, cfg, job.provider, job.engine, settings.appUUID, kubernetesVersion, openebsVersion)
+				errMutex.Lock()
+				if err != nil && ctx.Err() != nil {
+					// Interrupted by the end of the pass rather than failed
+					pending = append(pending, job.engine)
+				} else if err != nil {
+					logger := engineLogger(job.engine.Namespace, job.engine.Name).WithField("provider", job.provider.Name())
+					if logger := logSampling.sample(logger, "collect/"+job.engine.String()); logger != nil {
+						logger.Error("Unable to get metrics: ", err.Error())
+					}
+					lastErr = err
+				}
+				errMutex.Unlock()
+			}
+		}()
+	}
+dispatch:
+	for i, engine := range engines {
+		select {
+		case <-ctx.Done():
+			errMutex.Lock()
+			for _, job := range engines[i:] {
+				pending = append(pending, job.engine)
+			}
+			errMutex.Unlock()
+			break dispatch
+		case jobs <- engine:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	setStaleEngines(appNS, pending)
+	return len(pending), lastErr
+}
+
+// setStaleEngines replaces the stale engines listed in a monitored namespace
+func setStaleEngines(appNS string, pending []types.NamespacedName) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+
+	for engine, listedIn := range staleEngines {
+		if listedIn == appNS {
+			delete(staleEngines, engine)
+			engineStale.DeleteLabelValues(labelValues(engine.Namespace, engine.Name)...)
+		}
+	}
+	for _, engine := range pending {
+		staleEngines[engine] = appNS
+		engineStale.WithLabelValues(labelValues(engine.Namespace, engine.Name)...).Set(1)
+	}
+}
+
+// collectEngineWithTimeout collects a chaosengine, abandoning its collection after engineTimeout
+// (if set) so that a slow engine does not hold up a worker indefinitely
+func collectEngineWithTimeout(ctx context.Context, cfg *rest.Config, provider chaosmetrics.ChaosProvider, engine types.NamespacedName, appUUID string, kubernetesVersion string, openebsVersion string) error {
+	if engineTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, engineTimeout)
+		defer cancel()
+	}
+	return collectEngine(ctx, cfg, provider, engine.Name, appUUID, engine.Namespace, kubernetesVersion, openebsVersion)
+}
+
+// retryBackoff returns the wait period after the given number of consecutive failed collections.
+// The period doubles with every failure up to maxRetryBackoff, half of it is randomized (jitter)
+// so that replicas and exporters do not retry against the apiserver in lockstep
+func retryBackoff(failures int) time.Duration {
+	backoff := maxRetryBackoff
+	if failures < 16 {
+		if exp := minRetryBackoff << uint(failures-1); exp < maxRetryBackoff {
+			backoff = exp
+		}
+	}
+	return backoff/2 + time.Duration(jitter.Int63n(int64(backoff/2)+1))
+}
+
+// collectEngine gets the chaos metrics of a chaosengine and sets the corresponding series
+func collectEngine(ctx context.Context, cfg *rest.Config, provider chaosmetrics.ChaosProvider, chaosEngine string, appUUID string, appNS string, kubernetesVersion string, openebsVersion string) error {
+
+	// Get the chaos metrics for the specified chaosengine
+	engineMetrics, err := provider.GetEngineMetrics(ctx, cfg, chaosEngine, appNS)
+	if k8serrors.IsNotFound(err) {
+		// Report the missing engine rather than failing the collection of the others
+		setInvalidReasons(appNS, chaosEngine, []string{chaosmetrics.ReasonEngineNotFound})
+		replaceEngineSeries(appNS+"/"+chaosEngine, nil)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	markCollected(appNS, chaosEngine)
+	setInvalidReasons(appNS, chaosEngine, engineMetrics.InvalidReasons)
+	for _, warning := range engineMetrics.Warnings {
+		if logger := logSampling.sample(engineLogger(appNS, chaosEngine), "warning/"+appNS+"/"+chaosEngine+"/"+warning); logger != nil {
+			logger.Warn(warning)
+		}
+	}
+	if appUUID == "" && autodetectAppUUID && engineMetrics.AppLabel != "" {
+		appUUID = engineAppUUID(cfg, appNS, chaosEngine, engineMetrics)
+	}
+	expMap := engineMetrics.ExperimentStatus
+	recordTransitions(appNS, chaosEngine, expMap, engineMetrics.FailSteps)
+
+	// Holds the series set by this collection, those of the previous one that are not set again are deleted
+	series := make(seriesSet)
+	defer replaceEngineSeries(appNS+"/"+chaosEngine, series)
+
+	// Set the fixed chaos metrics & the experiment states
+	setExperimentSeries(series, engineMetrics, appNS, appUUID, chaosEngine, kubernetesVersion, openebsVersion)
+
+	// Set the labels of the engine listed in the chaosexporterconfig, to enrich its series by joining them
+	setEngineLabelSeries(series, appNS, chaosEngine, engineMetrics.Labels)
+
+	// Set the owners of the engine, so that engines can be grouped by their parent automation
+	for _, owner := range engineMetrics.Owners {
+		series.set(engineOwner, 1, labelValues(appNS, chaosEngine, owner.Kind, owner.Name)...)
+	}
+
+	// Set the outcome of the individual probes
+	for _, probe := range engineMetrics.Probes {
+		passed := 0.0
+		if probe.Passed {
+			passed = 1
+		}
+		series.set(probeStatus, passed, labelValues(appNS, chaosEngine, probe.Name, probe.Type, probe.Experiment)...)
+	}
+
+	// Set the failure budget left to the engine, the failures of engines without a budget are not retained
+	budget, err := engineFailureBudget(engineMetrics.Annotations)
+	if err != nil {
+		if logger := logSampling.sample(engineLogger(appNS, chaosEngine), "budget/"+appNS+"/"+chaosEngine); logger != nil {
+			logger.Warn("Ignoring the failure budget: ", err)
+		}
+	}
+	stateMutex.Lock()
+	if budget != nil {
+		series.set(failureBudgetRemaining, float64(budgetRemaining(appNS+"/"+chaosEngine, budget, exporterClock.Now())), labelValues(appNS, chaosEngine)...)
+	} else {
+		delete(engineFailures, appNS+"/"+chaosEngine)
+	}
+	stateMutex.Unlock()
+
+	// Set the chaos interval adherence of iterative experiments
+	for _, iteration := range engineMetrics.Iterations {
+		series.set(expectedIterations, iteration.Expected, labelValues(appNS, chaosEngine, iteration.Experiment)...)
+		series.set(actualIterations, iteration.Actual, labelValues(appNS, chaosEngine, iteration.Experiment)...)
+	}
+
+	// Account the chaos window of the application under test
+	state := sla.EngineState{Available: true}
+	for _, verdict := range expMap {
+		switch chaosmetrics.StatusName(verdict) {
+		case "running":
+			state.UnderChaos = true
+		case "pass":
+			state.Passed++
+		case "fail":
+			state.Failed++
+		}
+	}
+	for _, probe := range engineMetrics.Probes {
+		state.Available = state.Available && probe.Passed
+	}
+	report, dropped := slaTracker.Observe(engineMetrics.AppNamespace, engineMetrics.AppLabel, appNS+"/"+chaosEngine, state)
+	deleteApplicationSLA(dropped)
+	appSLA.WithLabelValues(report.AppNamespace, report.AppLabel).Set(report.SLAPercent)
+	appChaosSeconds.WithLabelValues(report.AppNamespace, report.AppLabel).Set(report.ChaosSeconds)
+	appAvailableSecs.WithLabelValues(report.AppNamespace, report.AppLabel).Set(report.AvailableSeconds)
+	return nil
+}
+
+// deleteApplicationSLA deletes the SLA series of the applications no longer targeted by any chaosengine
+func deleteApplicationSLA(reports []sla.Report) {
+	for _, report := range reports {
+		appSLA.DeleteLabelValues(report.AppNamespace, report.AppLabel)
+		appChaosSeconds.DeleteLabelValues(report.AppNamespace, report.AppLabel)
+		appAvailableSecs.DeleteLabelValues(report.AppNamespace, report.AppLabel)
+	}
+}
+
+// setExperimentSeries sets the experiment counts of a chaosengine, and the dynamically obtained state of each
+// of its experiments, in series
+func setExperimentSeries(series seriesSet, engineMetrics *chaosmetrics.EngineMetrics, appNS string, appUUID string, chaosEngine string, kubernetesVersion string, openebsVersion string) {
+	labels := labelValues(appNS, appUUID, chaosEngine, kubernetesVersion, openebsVersion)
+	series.setVersioned(experimentsTotal, engineMetrics.TotalExperiments, labels...)
+	series.setVersioned(passedExperiments, engineMetrics.PassedExperiments, labels...)
+	series.setVersioned(failedExperiments, engineMetrics.FailedExperiments, labels...)
+
+	// Define & set the dynamically obtained chaos metrics (experiment state)
+	for index, verdict := range engineMetrics.ExperimentStatus {
+		sanitizedExpName := strings.Replace(index, "-", "_", -1)
+		series.setVersioned(experimentGauge(sanitizedExpName), verdict, labels...)
+	}
+}
+
+// exporter collects the chaos metrics for a given chaosengine (or all chaosengines in the namespace)
+// whenever the engines or their results change, and at least once every resync period. It returns once
+// ctx is done, after the collection in progress (if any) has been abandoned
+func exporter(ctx context.Context, cfg *rest.Config, runtime runtimeSettings, configName string, configNamespace string, versions *version.Provider, reloader *settingsReloader) {
+
+	events := make(chan chaosmetrics.ChaosEvent)
+	var stopWatch chan struct{}
+	watchedNamespace := ""
+
+	defer func() {
+		if stopWatch != nil {
+			close(stopWatch)
+		}
+	}()
+
+	// Reconfigure on the changes of the ChaosExporterConfig CR, if one is in use
+	var configWatch *exporterConfigWatch
+	if configName != "" {
+		configWatch = watchExporterConfig(ctx, cfg, configName, configNamespace)
+	}
+
+	// Push the collected series if the Pushgateway push mode is enabled
+	pusher := newEnginePusher()
+
+	settings := runtime.defaults
+	consecutiveFailures := 0
+	for ctx.Err() == nil {
+		// Apply the settings reloaded from the config file, now that no collection is in progress
+		if reloaded, ok := reloader.take(); ok {
+			log.Infof("Reloaded settings, resync period: %s", reloaded.resync)
+			runtime = reloaded
+			labelNormalization = runtime.normalizer
+		}
+
+		// Pick up changes to the ChaosExporterConfig CR, if one is in use
+		current, err := configWatch.settings(runtime.defaults)
+		if err != nil {
+			log.Error("Unable to read chaosexporterconfig, retaining previous settings: ", err.Error())
+			current = settings
+		}
+		if current != settings {
+			log.Infof("Exporter settings changed to engine: %s, namespace: %s, app_uid: %s, engine selector: %s", current.chaosEngine, current.appNamespace, current.appUUID, current.engineSelector)
+			settings = current
+		}
+		chaosEngine, appNS := settings.chaosEngine, settings.appNamespace
+
+		// (Re)start the watches when the monitored namespace changes. An empty namespace watches the whole cluster
+		if stopWatch == nil || appNS != watchedNamespace {
+			if stopWatch != nil {
+				close(stopWatch)
+			}
+			stopWatch = make(chan struct{})
+			// The informer watches are long-lived, they are not cut by the request timeout
+			informerConfig := rest.CopyConfig(cfg)
+			informerConfig.Timeout = 0
+			clientSet, err := clientV1alpha1.NewForConfig(informerConfig)
+			if err != nil {
+				log.Fatal("Unable to create the chaos clientset: ", err.Error())
+			}
+			resultCaches := make(map[string]chaosmetrics.ChaosResultCache)
+			for _, ns := range splitNamespaces(appNS) {
+				if err := chaosmetrics.WatchChaosResources(cfg, ns, events, stopWatch); err != nil {
+					log.Fatal("Unable to watch chaos resources: ", err.Error())
+				}
+				// Serve the verdict lookups from an informer cache rather than a request per chaosresult
+				informer := informers.NewChaosResultInformer(clientSet, ns, chaosResultResync)
+				go informer.Run(stopWatch)
+				resultCaches[ns] = informer
+			}
+			chaosmetrics.SetChaosResultCaches(resultCaches)
+			watchedNamespace = appNS
+		}
+
+		updateClockSkew(ctx, cfg)
+		kubernetesVersion, openebsVersion := versions.Versions()
+		// The series labelled with the previous versions are replaced by the collection pass
+		versionsChanged := setVersionInfo(kubernetesVersion, openebsVersion)
+		if versionsChanged && seriesReplacement == replaceReset {
+			pruneVersionedSeries(kubernetesVersion, openebsVersion)
+		}
+
+		// Collect the listed namespaces concurrently, until the collection deadline if any
+		passCtx, cancelPass := ctx, context.CancelFunc(func() {})
+		if collectionDeadline > 0 {
+			passCtx, cancelPass = context.WithTimeout(ctx, collectionDeadline)
+		}
+		var wg sync.WaitGroup
+		namespaces := splitNamespaces(appNS)
+		errs := make([]error, len(namespaces))
+		stale := make([]int, len(namespaces))
+		for i, ns := range namespaces {
+			wg.Add(1)
+			go func(i int, ns string) {
+				defer wg.Done()
+				stale[i], errs[i] = collectNamespace(passCtx, cfg, settings, ns, kubernetesVersion, openebsVersion)
+			}(i, ns)
+		}
+		wg.Wait()
+		cancelPass()
+		if ctx.Err() != nil {
+			return
+		}
+		if versionsChanged && seriesReplacement == replaceSwap {
+			pruneVersionedSeries(kubernetesVersion, openebsVersion)
+		}
+
+		// Retry failed collections with backoff, only giving up once the failure budget is exhausted
+		failed := false
+		for _, err := range errs {
+			failed = failed || err != nil
+		}
+		if reloaded := reloadRejectedConfig(errs); reloaded != nil {
+			// Restart the watches with the new credentials
+			cfg = reloaded
+			versions.SetConfig(reloaded)
+			close(stopWatch)
+			stopWatch = nil
+		}
+		if failed {
+			collectionErrors.Inc()
+			consecutiveFailures++
+			if maxConsecutiveFailures > 0 && consecutiveFailures >= maxConsecutiveFailures {
+				log.Fatalf("Unable to get metrics, %d consecutive collections failed", consecutiveFailures)
+			}
+			backoff := retryBackoff(consecutiveFailures)
+			log.Warnf("Collection failed (%d consecutive), retrying in %s", consecutiveFailures, backoff)
+			select {
+			case <-ctx.Done():
+			case <-exporterClock.After(backoff):
+			}
+			continue
+		}
+		consecutiveFailures = 0
+		atomic.StoreInt32(&collectionSucceeded, 1)
+
+		// Continue the engines left stale right away, rather than after the next change
+		staleCount := 0
+		for _, count := range stale {
+			staleCount += count
+		}
+		if staleCount > 0 {
+			log.Warnf("Collection deadline of %s reached, continuing the %d stale engines", collectionDeadline, staleCount)
+			continue
+		}
+		// Every monitored engine has been collected, the restored series left over belong to none
+		dropRestoredSeries()
+		pusher.push(ctx)
+		emitStatsDGauges(statsdSink)
+		submitDatadogSeries(ctx, datadogSink)
+		cloudWatchSink.publish(ctx, prometheus.DefaultGatherer)
+		cloudMonitoringSink.publish(ctx)
+		azureMonitorSink.publish(ctx)
+		newRelicSink.publish(ctx, prometheus.DefaultGatherer)
+		writeInfluxPoints(ctx, influxSink)
+		writeRemoteSamples(ctx, remoteWriteSink, prometheus.DefaultGatherer)
+
+		waitForChange(ctx, events, chaosEngine, runtime.resync, reloader.reloaded(), configWatch.changes())
+	}
+}
+
+// setupProviders sets the chaos providers collected out of the comma separated list of their names, the litmus one
+// only collecting the chaosengines annotated for chaos with the annotation discovery
+func setupProviders(providers string, discovery string, problems *configProblems) {
+	if discovery != "" && discovery != "annotation" {
+		problems.add("--discovery (CHAOS_EXPORTER_DISCOVERY)", "expected annotation or empty, got %q", discovery)
+	}
+	chaosProviders = nil
+	for _, name := range strings.Split(providers, ",") {
+		provider, err := chaosmetrics.NewChaosProvider(strings.TrimSpace(name))
+		problems.addErr("--chaos-providers (CHAOS_PROVIDERS)", err)
+		if _, litmus := provider.(chaosmetrics.LitmusProvider); litmus && discovery == "annotation" {
+			provider = chaosmetrics.AnnotatedLitmusProvider{}
+		}
+		if err == nil {
+			chaosProviders = append(chaosProviders, provider)
+		}
+	}
+}
+
+// validateCollectionSettings checks the settings of the collection loop, and sets up the heatmap & the clock of
+// the chaos windows out of them
+func validateCollectionSettings(problems *configProblems) {
+	if seriesReplacement != replaceSwap && seriesReplacement != replaceReset {
+		problems.add("--series-replacement (SERIES_REPLACEMENT)", "expected %s or %s, got %q", replaceSwap, replaceReset, seriesReplacement)
+	}
+	if timeSource != "local" && timeSource != "apiserver" {
+		problems.add("--time-source (TIME_SOURCE)", "expected local or apiserver, got %q", timeSource)
+	}
+	if heatmapDays < 1 {
+		problems.add("--heatmap-days (HEATMAP_DAYS)", "expected a positive number of days, got %d", heatmapDays)
+		heatmapDays = 1
+	}
+	if selfTestNamespace != "" && selfTestInterval <= 0 {
+		problems.add("--selftest-interval (SELFTEST_INTERVAL)", "expected a positive duration, got %s", selfTestInterval)
+	}
+	for setting, value := range map[string]time.Duration{
+		"--collection-deadline (COLLECTION_DEADLINE)":           collectionDeadline,
+		"--engine-timeout (ENGINE_TIMEOUT)":                     engineTimeout,
+		"--state-gc-interval (STATE_GC_INTERVAL)":               stateGCInterval,
+		"--version-refresh-interval (VERSION_REFRESH_INTERVAL)": versionRefreshInterval,
+		"--chaosresult-resync (CHAOSRESULT_RESYNC_PERIOD)":      chaosResultResync,
+	} {
+		if value < 0 {
+			problems.add(setting, "expected a duration of 0 or more, got %s", value)
+		}
+	}
+	heatmap = history.NewHeatmap(heatmapDays)
+	if timeSource == "apiserver" {
+		slaTracker.SetClock(apiserverClock{})
+		heatmap.SetClock(apiserverClock{})
+	}
+}
+
+// startCollection triggers the chaos metrics collection, on the elected replica only when leader election is
+// enabled. The returned mutex is held for the duration of the collection, so that shutdown can wait for it to drain
+func startCollection(ctx context.Context, runtime runtimeSettings, configName string, configNamespace string, versions *version.Provider, reloader *settingsReloader) *sync.Mutex {
+	var collecting sync.Mutex
+	collect := func() {
+		collecting.Lock()
+		defer collecting.Unlock()
+		if ctx.Err() != nil {
+			return
+		}
+		exporterLeader.Set(1)
+		atomic.StoreInt32(&collectingReplica, 1)
+		if selfTestNamespace != "" {
+			go runSelfTests(ctx, config)
+		}
+		exporter(ctx, config, runtime, configName, configNamespace, versions, reloader)
+	}
+	if leaderElect {
+		elector, err := newLeaderElector(config, configNamespace)
+		if err != nil {
+			log.Fatal("Unable to set up leader election: ", err)
+		}
+		log.Infof("waiting to acquire lease %s as %s", elector.Name, elector.Identity)
+		go elector.Run(collect, func() {
+			// Exit so the replica restarts as a standby, rather than collecting alongside the new leader
+			log.Fatal("lost leadership, exiting")
+		})
+	} else {
+		go collect()
+	}
+	return &collecting
+}
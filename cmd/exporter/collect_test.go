@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/chaosmetrics"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/rest"
+)
+
+// TestRelevantEvent verifies that only changes to the monitored engine & its results trigger a collection
+func TestRelevantEvent(t *testing.T) {
+	tests := []struct {
+		event    chaosmetrics.ChaosEvent
+		relevant bool
+	}{
+		{chaosmetrics.ChaosEvent{Resource: "chaosengines", Name: "engine-nginx"}, true},
+		{chaosmetrics.ChaosEvent{Resource: "chaosengines", Name: "engine-redis"}, false},
+		{chaosmetrics.ChaosEvent{Resource: "chaosresults", Name: "engine-nginx-pod-delete"}, true},
+		{chaosmetrics.ChaosEvent{Resource: "chaosresults", Name: "engine-redis-pod-delete"}, false},
+	}
+	for _, test := range tests {
+		if got := relevantEvent(test.event, "engine-nginx"); got != test.relevant {
+			t.Errorf("relevantEvent(%+v) = %v, expected %v", test.event, got, test.relevant)
+		}
+		if !relevantEvent(test.event, "") {
+			t.Errorf("relevantEvent(%+v) should be relevant when monitoring all engines", test.event)
+		}
+	}
+}
+
+// TestSetInvalidReasons verifies that reasons which no longer apply are cleared
+func TestSetInvalidReasons(t *testing.T) {
+	setInvalidReasons("litmus", "engine-test", []string{"unknown_experiment", "invalid_selector"})
+	setInvalidReasons("litmus", "engine-test", []string{"invalid_selector"})
+
+	metrics := make(chan prometheus.Metric, 10)
+	engineInvalid.Collect(metrics)
+	close(metrics)
+	if len(metrics) != 1 {
+		t.Errorf("expected a single invalid reason, got %d", len(metrics))
+	}
+
+	setInvalidReasons("litmus", "engine-test", nil)
+	if _, ok := invalidEngines["litmus/engine-test"]; ok {
+		t.Error("expected the engine to no longer be tracked as invalid")
+	}
+}
+
+// TestSplitNamespaces verifies the parsing of a comma separated APP_NAMESPACE
+func TestSplitNamespaces(t *testing.T) {
+	tests := map[string][]string{
+		"litmus":       {"litmus"},
+		"ns1, ns2,ns3": {"ns1", "ns2", "ns3"},
+		"":             {""},
+		"ns1,,":        {"ns1"},
+	}
+	for appNS, expected := range tests {
+		got := splitNamespaces(appNS)
+		if strings.Join(got, "|") != strings.Join(expected, "|") {
+			t.Errorf("splitNamespaces(%q) = %q, expected %q", appNS, got, expected)
+		}
+	}
+}
+
+// maxJitter always draws the largest jitter
+type maxJitter struct{}
+
+func (maxJitter) Int63n(n int64) int64 { return n - 1 }
+
+// TestRetryBackoff verifies that the backoff grows exponentially up to its bound
+func TestRetryBackoff(t *testing.T) {
+	defer func(source randSource) { jitter = source }(jitter)
+	jitter = maxJitter{}
+
+	for failures := 1; failures <= 20; failures++ {
+		expected := maxRetryBackoff
+		if failures < 16 && minRetryBackoff<<uint(failures-1) < maxRetryBackoff {
+			expected = minRetryBackoff << uint(failures-1)
+		}
+		if got := retryBackoff(failures); got != expected {
+			t.Errorf("retryBackoff(%d) = %s, expected %s", failures, got, expected)
+		}
+	}
+}
+
+// TestWaitForChangeResync verifies that a collection is triggered once the resync period has elapsed
+func TestWaitForChangeResync(t *testing.T) {
+	defer func(c clock.Clock) { exporterClock = c }(exporterClock)
+	fakeClock := clock.NewFakeClock(time.Now())
+	exporterClock = fakeClock
+
+	done := make(chan struct{})
+	go func() {
+		waitForChange(context.Background(), make(chan chaosmetrics.ChaosEvent), "", time.Minute, nil, nil)
+		close(done)
+	}()
+	for !fakeClock.HasWaiters() {
+		time.Sleep(time.Millisecond)
+	}
+
+	fakeClock.Step(59 * time.Second)
+	select {
+	case <-done:
+		t.Fatal("expected waitForChange to block until the resync period has elapsed")
+	default:
+	}
+	fakeClock.Step(time.Second)
+	<-done
+}
+
+// fakeProvider lists the given engines, whose collection blocks until ctx is done for those set in blocking
+type fakeProvider struct {
+	engines  []string
+	blocking map[string]bool
+	mu       sync.Mutex
+	order    []string
+	// Number of engines being collected, & the most collected at once
+	active, peak int
+}
+
+func (p *fakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *fakeProvider) ListEngines(ctx context.Context, cfg *rest.Config, ns string, selector string) ([]types.NamespacedName, error) {
+	var engines []types.NamespacedName
+	for _, name := range p.engines {
+		engines = append(engines, types.NamespacedName{Namespace: ns, Name: name})
+	}
+	return engines, nil
+}
+
+func (p *fakeProvider) GetEngineMetrics(ctx context.Context, cfg *rest.Config, name string, ns string) (*chaosmetrics.EngineMetrics, error) {
+	p.mu.Lock()
+	if p.active++; p.active > p.peak {
+		p.peak = p.active
+	}
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.active--
+		p.mu.Unlock()
+	}()
+	if p.blocking[name] {
+		<-ctx.Done()
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	p.mu.Lock()
+	p.order = append(p.order, name)
+	p.mu.Unlock()
+	return &chaosmetrics.EngineMetrics{TotalExperiments: 1}, nil
+}
+
+func TestCollectNamespaceWorkers(t *testing.T) {
+	provider := &fakeProvider{engines: []string{"engine-a", "engine-b", "engine-c", "engine-d"}, blocking: map[string]bool{"engine-b": true, "engine-c": true}}
+	chaosProviders = []chaosmetrics.ChaosProvider{provider}
+	collectionWorkers, engineTimeout = 2, 50*time.Millisecond
+	defer func() {
+		chaosProviders = []chaosmetrics.ChaosProvider{chaosmetrics.LitmusProvider{}}
+		collectionWorkers, engineTimeout = 0, 0
+		for _, engine := range provider.engines {
+			replaceEngineSeries("litmus/"+engine, nil)
+		}
+	}()
+
+	// The engines timing out fail on their own, without holding up the others or the pass
+	stale, err := collectNamespace(context.Background(), nil, exporterSettings{}, "litmus", "1.13", "1.0")
+	if stale != 0 || err != context.DeadlineExceeded {
+		t.Fatalf("expected the timeout of an engine, got %d stale, %v", stale, err)
+	}
+	sort.Strings(provider.order)
+	if strings.Join(provider.order, ",") != "engine-a,engine-d" {
+		t.Errorf("expected engine-a & engine-d to be collected, got %v", provider.order)
+	}
+	if provider.peak != 2 {
+		t.Errorf("expected the engines to be collected on 2 workers, got %d at once", provider.peak)
+	}
+}
+
+func TestCollectNamespaceDeadline(t *testing.T) {
+	provider := &fakeProvider{engines: []string{"engine-a", "engine-b", "engine-c"}, blocking: map[string]bool{"engine-b": true}}
+	chaosProviders = []chaosmetrics.ChaosProvider{provider}
+	collectionWorkers = 1
+	defer func() {
+		chaosProviders = []chaosmetrics.ChaosProvider{chaosmetrics.LitmusProvider{}}
+		collectionWorkers = 0
+	}()
+
+	// engine-b holds the only worker past the deadline, engine-c is never started
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	stale, err := collectNamespace(ctx, nil, exporterSettings{}, "litmus", "1.13", "1.0")
+	if stale != 2 || err != nil {
+		t.Fatalf("expected 2 stale engines, got %d, %v", stale, err)
+	}
+	if value, ok := collectedValue(experimentsTotal, "litmus", "", "engine-a", "1.13", "1.0"); !ok || value != 1 {
+		t.Errorf("expected the series of engine-a to be exposed, got %v", value)
+	}
+	metric := &dto.Metric{}
+	if err := engineStale.WithLabelValues("litmus", "engine-c").Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	if value := metric.GetGauge().GetValue(); value != 1 {
+		t.Errorf("expected engine-c to be marked stale, got %v", value)
+	}
+
+	// The next pass starts with the stale engines, and clears them
+	provider.blocking, provider.order = nil, nil
+	if stale, err := collectNamespace(context.Background(), nil, exporterSettings{}, "litmus", "1.13", "1.0"); stale != 0 || err != nil {
+		t.Fatalf("expected no stale engines, got %d, %v", stale, err)
+	}
+	if strings.Join(provider.order, ",") != "engine-b,engine-c,engine-a" {
+		t.Errorf("expected the stale engines to be collected first, got %v", provider.order)
+	}
+	if len(staleEngines) != 0 {
+		t.Errorf("expected the stale engines to be cleared, got %v", staleEngines)
+	}
+	for _, engine := range provider.engines {
+		replaceEngineSeries("litmus/"+engine, nil)
+	}
+}
+
+func TestCollectEngineOwners(t *testing.T) {
+	server := newChaosAPIServer()
+	defer server.Close()
+	defer replaceEngineSeries("litmus/engine-nginx", nil)
+	defer func() { deleteApplicationSLA(slaTracker.Forget("litmus/engine-nginx")) }()
+
+	err := collectEngine(context.Background(), &rest.Config{Host: server.URL}, chaosmetrics.LitmusProvider{}, "engine-nginx", "", "litmus", "1.13", "1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := collectedValue(engineOwner, "litmus", "engine-nginx", "Workflow", "wf-nginx"); !ok || value != 1 {
+		t.Errorf("expected the workflow owning engine-nginx to be exposed, got %v", value)
+	}
+}
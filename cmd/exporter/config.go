@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/litmuschaos/chaos-exporter/pkg/apis/litmuschaos/v1alpha1"
+	clientV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/clientset/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+)
+
+// exporterSettings holds the settings used by a single collection pass
+type exporterSettings struct {
+	chaosEngine  string
+	appUUID      string
+	appNamespace string
+	// Label selector of the chaosengines to monitor, when no single chaosengine is specified
+	engineSelector string
+}
+
+// getConfigSettings overlays the spec of the ChaosExporterConfig CR on the ENV derived settings, read once at
+// startup, the collection passes read it from the exporterConfigWatch. An empty configName disables the CR based
+// configuration and returns the defaults as is
+func getConfigSettings(cfg *rest.Config, defaults exporterSettings, configName string, configNamespace string) (exporterSettings, error) {
+	if configName == "" {
+		return defaults, nil
+	}
+
+	clientSet, err := clientV1alpha1.NewForConfig(cfg)
+	if err != nil {
+		return defaults, err
+	}
+
+	exporterConfig, err := clientSet.ChaosExporterConfigs(configNamespace).Get(configName, metav1.GetOptions{})
+	if err != nil {
+		return defaults, err
+	}
+
+	settings, err := configSettings(defaults, exporterConfig.Spec)
+	if err != nil {
+		return defaults, err
+	}
+	if err := setConfigExtensions(exporterConfig.Spec.EngineLabels, exporterConfig.Spec.NotificationTargets); err != nil {
+		return defaults, err
+	}
+	return settings, nil
+}
+
+// configSettings overlays spec on the ENV derived settings
+func configSettings(defaults exporterSettings, spec v1alpha1.ChaosExporterConfigSpec) (exporterSettings, error) {
+	settings := defaults
+	if spec.ChaosEngine != "" {
+		settings.chaosEngine = spec.ChaosEngine
+	}
+	if spec.AppNamespace != "" {
+		settings.appNamespace = spec.AppNamespace
+	}
+	if spec.AppUUID != "" {
+		settings.appUUID = spec.AppUUID
+	}
+	if spec.EngineSelector != "" {
+		if _, err := labels.Parse(spec.EngineSelector); err != nil {
+			return defaults, fmt.Errorf("invalid engineSelector: %v", err)
+		}
+		settings.engineSelector = spec.EngineSelector
+	}
+	return settings, nil
+}
+
+// setConfigExtensions applies the label enrichment & notification targets of the ChaosExporterConfig CR, once
+// both are found valid. These are kept out of exporterSettings, which only holds what selects the engines
+func setConfigExtensions(engineLabels []string, targets []v1alpha1.NotificationTarget) error {
+	if err := validateEngineLabels(engineLabels); err != nil {
+		return err
+	}
+	if err := validateNotificationTargets(targets); err != nil {
+		return err
+	}
+	setEngineLabelKeys(engineLabels)
+	setNotificationTargets(targets)
+	return nil
+}
+
+// Time after which a closed or failed watch on the ChaosExporterConfig CR is re-established
+const configWatchRetryInterval = 5 * time.Second
+
+// exporterConfigWatch holds the spec of the ChaosExporterConfig CR as last seen by its watch, so that the
+// collection passes read it without a request of their own
+type exporterConfigWatch struct {
+	mu   sync.Mutex
+	spec *v1alpha1.ChaosExporterConfigSpec
+	// Reported in place of the spec, e.g. once the CR is deleted or found invalid
+	err error
+	// Notified on every change, buffered so that notifications coalesce
+	changed chan struct{}
+}
+
+// watchExporterConfig lists the ChaosExporterConfig CR, then watches it until ctx is done, re-establishing the
+// watch once closed or failed. Its changes are signalled, so that the exporter reconfigures itself without
+// waiting for the resync period
+func watchExporterConfig(ctx context.Context, cfg *rest.Config, configName string, configNamespace string) *exporterConfigWatch {
+	w := &exporterConfigWatch{changed: make(chan struct{}, 1)}
+
+	// Watches are long running requests, which the request timeout would otherwise cut short
+	watchConfig := rest.CopyConfig(cfg)
+	watchConfig.Timeout = 0
+	clientSet, err := clientV1alpha1.NewForConfig(watchConfig)
+	if err != nil {
+		w.store(nil, err)
+		return w
+	}
+	configs := clientSet.ChaosExporterConfigs(configNamespace)
+	selector := fields.OneTermEqualSelector("metadata.name", configName).String()
+	list := func() string {
+		list, err := configs.List(metav1.ListOptions{FieldSelector: selector})
+		switch {
+		case err != nil:
+			w.store(nil, err)
+			return ""
+		case len(list.Items) == 0:
+			w.store(nil, fmt.Errorf("chaosexporterconfig %s/%s not found", configNamespace, configName))
+		default:
+			w.store(&list.Items[0], nil)
+		}
+		return list.ResourceVersion
+	}
+
+	resourceVersion := list()
+	go func() {
+		for ctx.Err() == nil {
+			if resourceVersion != "" {
+				if err := w.forwardChanges(ctx, configs, selector, resourceVersion); err != nil {
+					log.Warn("Unable to watch chaosexporterconfig: ", err)
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-exporterClock.After(configWatchRetryInterval):
+			}
+			resourceVersion = list()
+		}
+	}()
+	return w
+}
+
+// forwardChanges stores the changes of the ChaosExporterConfig CR since resourceVersion until the watch is closed
+func (w *exporterConfigWatch) forwardChanges(ctx context.Context, configs clientV1alpha1.ChaosExporterConfigInterface, selector string, resourceVersion string) error {
+	watcher, err := configs.Watch(metav1.ListOptions{FieldSelector: selector, ResourceVersion: resourceVersion})
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil
+			}
+			switch event.Type {
+			case watch.Error:
+				return fmt.Errorf("watch failed: %v", event.Object)
+			case watch.Deleted:
+				w.store(nil, fmt.Errorf("chaosexporterconfig deleted"))
+			default:
+				config, ok := event.Object.(*v1alpha1.ChaosExporterConfig)
+				if !ok {
+					return fmt.Errorf("unexpected %T in the watch", event.Object)
+				}
+				w.store(config, nil)
+			}
+		}
+	}
+}
+
+// store replaces the spec of the CR, applying its label enrichment & notification targets along, or records
+// err. A CR found invalid is recorded as an error
+func (w *exporterConfigWatch) store(config *v1alpha1.ChaosExporterConfig, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err == nil {
+		if _, err = configSettings(exporterSettings{}, config.Spec); err == nil {
+			err = setConfigExtensions(config.Spec.EngineLabels, config.Spec.NotificationTargets)
+		}
+	}
+	w.spec, w.err = nil, err
+	if err == nil {
+		w.spec = &config.Spec
+	}
+	// A change is already pending if the channel is full
+	select {
+	case w.changed <- struct{}{}:
+	default:
+	}
+}
+
+// settings overlays the spec of the CR last seen on defaults. A nil watch returns the defaults as is
+func (w *exporterConfigWatch) settings(defaults exporterSettings) (exporterSettings, error) {
+	if w == nil {
+		return defaults, nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return defaults, w.err
+	}
+	return configSettings(defaults, *w.spec)
+}
+
+// changes is notified on every change of the CR. It is nil, and so never ready, for a nil watch
+func (w *exporterConfigWatch) changes() <-chan struct{} {
+	if w == nil {
+		return nil
+	}
+	return w.changed
+}
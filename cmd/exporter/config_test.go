@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/apis/litmuschaos/v1alpha1"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+func TestGetConfigSettings(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+	specs := map[string]string{
+		"exporter-config": `{"chaosEngine":"engine-nginx","appUUID":"uid-1"}`,
+		"invalid-config":  `{"engineSelector":"team in ("}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		name := strings.TrimPrefix(r.URL.Path, "/apis/litmuschaos.io/v1alpha1/namespaces/litmus/chaosexporterconfigs/")
+		spec, ok := specs[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"apiVersion":"v1","kind":"Status","status":"Failure","reason":"NotFound","code":404}`)
+			return
+		}
+		fmt.Fprintf(w, `{"apiVersion":"litmuschaos.io/v1alpha1","kind":"ChaosExporterConfig","metadata":{"name":%q,"namespace":"litmus"},"spec":%s}`, name, spec)
+	}))
+	defer server.Close()
+	cfg := &rest.Config{Host: server.URL}
+
+	defaults := exporterSettings{appNamespace: "litmus", appUUID: "uid-0", engineSelector: "team=payments"}
+	if settings, err := getConfigSettings(cfg, defaults, "", "litmus"); err != nil || settings != defaults {
+		t.Errorf("expected the defaults without a CR, got %+v, %v", settings, err)
+	}
+	settings, err := getConfigSettings(cfg, defaults, "exporter-config", "litmus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := exporterSettings{chaosEngine: "engine-nginx", appUUID: "uid-1", appNamespace: "litmus", engineSelector: "team=payments"}
+	if settings != expected {
+		t.Errorf("expected the spec overlaid on the defaults %+v, got %+v", expected, settings)
+	}
+	if settings, err := getConfigSettings(cfg, defaults, "invalid-config", "litmus"); err == nil || settings != defaults {
+		t.Errorf("expected the invalid selector to be rejected, got %+v, %v", settings, err)
+	}
+	if _, err := getConfigSettings(cfg, defaults, "missing-config", "litmus"); err == nil {
+		t.Error("expected an error for a missing CR")
+	}
+}
+
+func TestExporterConfigWatch(t *testing.T) {
+	v1alpha1.AddToScheme(scheme.Scheme)
+	defer setConfigExtensions(nil, nil)
+	events := make(chan string)
+	var lists int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("watch") != "true" {
+			atomic.AddInt32(&lists, 1)
+			fmt.Fprint(w, `{"apiVersion":"litmuschaos.io/v1alpha1","kind":"ChaosExporterConfigList","metadata":{"resourceVersion":"1"},`+
+				`"items":[{"metadata":{"name":"exporter-config","namespace":"litmus"},"spec":{"appUUID":"uid-1"}}]}`)
+			return
+		}
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case event := <-events:
+				fmt.Fprintln(w, event)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}))
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	configWatch := watchExporterConfig(ctx, &rest.Config{Host: server.URL}, "exporter-config", "litmus")
+	defaults := exporterSettings{appNamespace: "litmus", appUUID: "uid-0"}
+	if settings, err := configWatch.settings(defaults); err != nil || settings.appUUID != "uid-1" {
+		t.Errorf("expected the listed spec, got %+v, %v", settings, err)
+	}
+	<-configWatch.changes()
+
+	changed := func() {
+		select {
+		case <-configWatch.changes():
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected the change to be signalled")
+		}
+	}
+	events <- `{"type":"MODIFIED","object":{"apiVersion":"litmuschaos.io/v1alpha1","kind":"ChaosExporterConfig",` +
+		`"metadata":{"name":"exporter-config","namespace":"litmus"},"spec":{"appUUID":"uid-2","engineLabels":["team"]}}}`
+	changed()
+	if settings, err := configWatch.settings(defaults); err != nil || settings.appUUID != "uid-2" {
+		t.Errorf("expected the watched spec, got %+v, %v", settings, err)
+	}
+	if _, keys := engineLabelsGauge(); len(keys) != 1 || keys[0] != "team" {
+		t.Errorf("expected the engine labels of the watched spec, got %v", keys)
+	}
+
+	// An invalid or deleted CR is reported, the collection retaining its previous settings
+	events <- `{"type":"MODIFIED","object":{"apiVersion":"litmuschaos.io/v1alpha1","kind":"ChaosExporterConfig",` +
+		`"metadata":{"name":"exporter-config","namespace":"litmus"},"spec":{"engineSelector":"team in ("}}}`
+	changed()
+	if _, err := configWatch.settings(defaults); err == nil {
+		t.Error("expected the invalid selector to be rejected")
+	}
+	events <- `{"type":"DELETED","object":{"apiVersion":"litmuschaos.io/v1alpha1","kind":"ChaosExporterConfig",` +
+		`"metadata":{"name":"exporter-config","namespace":"litmus"}}}`
+	changed()
+	if _, err := configWatch.settings(defaults); err == nil {
+		t.Error("expected an error once the CR is deleted")
+	}
+	if value := atomic.LoadInt32(&lists); value != 1 {
+		t.Errorf("expected the CR to be listed once, got %d lists", value)
+	}
+
+	var none *exporterConfigWatch
+	if settings, err := none.settings(defaults); err != nil || settings != defaults || none.changes() != nil {
+		t.Errorf("expected the defaults without a CR, got %+v, %v", settings, err)
+	}
+}
+
+func TestConfigExtensions(t *testing.T) {
+	defer setConfigExtensions(nil, nil)
+
+	if err := setConfigExtensions([]string{"team", "app.kubernetes.io/team"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := setConfigExtensions([]string{"team", "invalid key"}, nil); err == nil {
+		t.Error("expected an error for an invalid label key")
+	}
+	if err := setConfigExtensions(nil, []v1alpha1.NotificationTarget{{Name: "hook", URL: "ftp://example.com"}}); err == nil {
+		t.Error("expected an error for a non http(s) target")
+	}
+
+	// The invalid updates leave the previous label keys in place
+	series := make(seriesSet)
+	setEngineLabelSeries(series, "default", "engine-nginx", map[string]string{"team": "sre", "app.kubernetes.io/team": "payments"})
+	gauge, _ := engineLabelsGauge()
+	for _, value := range series[gauge] {
+		if expected := []string{"default", "engine-nginx", "sre", "payments"}; !reflect.DeepEqual(value.labels, expected) {
+			t.Errorf("expected labels %v, got %v", expected, value.labels)
+		}
+	}
+	if len(series[gauge]) != 1 {
+		t.Errorf("expected a single engine labels series, got %d", len(series[gauge]))
+	}
+	if name := engineLabelName("app.kubernetes.io/team"); name != "label_app_kubernetes_io_team" {
+		t.Errorf("unexpected label name %s", name)
+	}
+
+	received := make(chan verdictEvent, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event verdictEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Error(err)
+		}
+		received <- event
+	}))
+	defer server.Close()
+	target := notificationTarget{NotificationTarget: v1alpha1.NotificationTarget{Name: "test-hook", URL: server.URL}}
+	notify(context.Background(), server.Client(), target, verdictEvent{Namespace: "default", Engine: "engine-nginx", To: "fail"})
+	if event := <-received; event.Engine != "engine-nginx" || event.To != "fail" {
+		t.Errorf("unexpected event %+v", event)
+	}
+	metric := &dto.Metric{}
+	if err := notificationsSent.WithLabelValues("test-hook", "success").Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	if got := metric.GetCounter().GetValue(); got != 1 {
+		t.Errorf("expected 1 notification sent, got %v", got)
+	}
+
+	// The URL of a urlFrom is read from its credential reference on every notification
+	if err := setConfigExtensions(nil, []v1alpha1.NotificationTarget{{Name: "secret-hook", URL: server.URL, URLFrom: "env:TEST_WEBHOOK_URL"}}); err == nil {
+		t.Error("expected an error for a target with both a url & a urlFrom")
+	}
+	if err := setConfigExtensions(nil, []v1alpha1.NotificationTarget{{Name: "secret-hook", URLFrom: "env:TEST_WEBHOOK_URL"}}); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("TEST_WEBHOOK_URL", server.URL)
+	defer os.Unsetenv("TEST_WEBHOOK_URL")
+	notify(context.Background(), server.Client(), notificationTargets[0], verdictEvent{Namespace: "default", Engine: "engine-redis", To: "fail"})
+	if event := <-received; event.Engine != "engine-redis" {
+		t.Errorf("unexpected event %+v", event)
+	}
+	os.Setenv("TEST_WEBHOOK_URL", "not a url")
+	if _, err := notificationTargets[0].address(); err == nil || strings.Contains(err.Error(), "not a url") {
+		t.Errorf("expected an error without the value of the urlFrom, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+// corsPolicy holds the origins allowed to call the JSON API from a browser
+type corsPolicy struct {
+	origins map[string]bool
+	// Set if any origin is allowed
+	anyOrigin bool
+	headers   string
+}
+
+// Holds the CORS policy of the JSON API, nil to serve no CORS headers
+var apiCORS *corsPolicy
+
+// newCORSPolicy parses comma separated lists of the allowed origins, * for any, and request headers.
+// It returns nil if no origin is allowed
+func newCORSPolicy(origins string, headers string) *corsPolicy {
+	policy := &corsPolicy{origins: make(map[string]bool)}
+	for _, origin := range strings.Split(origins, ",") {
+		origin = strings.TrimSpace(origin)
+		if origin == "*" {
+			policy.anyOrigin = true
+		} else if origin != "" {
+			policy.origins[strings.TrimSuffix(origin, "/")] = true
+		}
+	}
+	if !policy.anyOrigin && len(policy.origins) == 0 {
+		return nil
+	}
+	var allowed []string
+	for _, header := range strings.Split(headers, ",") {
+		if header = strings.TrimSpace(header); header != "" {
+			allowed = append(allowed, header)
+		}
+	}
+	policy.headers = strings.Join(allowed, ", ")
+	return policy
+}
+
+// allow sets the CORS headers of the responses of handler to the allowed origins, and answers their
+// preflight requests, which carry no API token. A nil policy serves handler as is
+func (p *corsPolicy) allow(handler http.HandlerFunc) http.HandlerFunc {
+	if p == nil {
+		return handler
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		w.Header().Add("Vary", "Origin")
+		if origin != "" && (p.anyOrigin || p.origins[origin]) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", p.headers)
+				w.Header().Set("Access-Control-Max-Age", "600")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		handler(w, r)
+	}
+}
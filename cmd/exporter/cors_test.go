@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSPolicy(t *testing.T) {
+	if newCORSPolicy("", "Authorization") != nil {
+		t.Error("expected no policy without allowed origins")
+	}
+	policy := newCORSPolicy("https://dashboard.example.com/, https://grafana.example.com", "Authorization, Content-Type")
+	handler := policy.allow(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "a valid API token is required", http.StatusUnauthorized)
+	})
+
+	// Preflight requests are answered without a token
+	r := httptest.NewRequest("OPTIONS", "/api/v1/status", nil)
+	r.Header.Set("Origin", "https://dashboard.example.com")
+	r.Header.Set("Access-Control-Request-Method", "GET")
+	w := httptest.NewRecorder()
+	handler(w, r)
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "https://dashboard.example.com" ||
+		w.Header().Get("Access-Control-Allow-Headers") != "Authorization, Content-Type" {
+		t.Errorf("unexpected preflight response %d %v", w.Code, w.Header())
+	}
+
+	// Other origins get no CORS headers
+	r = httptest.NewRequest("GET", "/api/v1/status", nil)
+	r.Header.Set("Origin", "https://evil.example.com")
+	w = httptest.NewRecorder()
+	handler(w, r)
+	if w.Code != http.StatusUnauthorized || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("unexpected response %d %v", w.Code, w.Header())
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"k8s.io/client-go/rest"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/version"
+)
+
+// Generate a Grafana dashboard of the engines collected by a single pass & exit, set by the gen-dashboard command
+var generateDashboard bool
+
+// Value mappings of the experiment states, as set on the c_exp_<experiment> gauges
+var dashboardStateMappings = []map[string]interface{}{{
+	"type": "value",
+	"options": map[string]interface{}{
+		"0": map[string]interface{}{"text": "not-executed", "color": "text"},
+		"1": map[string]interface{}{"text": "running", "color": "blue"},
+		"2": map[string]interface{}{"text": "fail", "color": "red"},
+		"3": map[string]interface{}{"text": "pass", "color": "green"},
+	},
+}}
+
+// dashboardDatasource is the datasource of the panels, picked on import through the datasource variable
+var dashboardDatasource = map[string]string{"type": "prometheus", "uid": "${datasource}"}
+
+// buildDashboard returns a Grafana dashboard of the experiment counts of all engines, and a row per engine of
+// engines plotting the states & verdict transitions of its experiments
+func buildDashboard(engines []engineStatus) map[string]interface{} {
+	panels := []map[string]interface{}{
+		dashboardStat(0, "Experiments", "sum(c_engine_experiment_count)", "blue"),
+		dashboardStat(8, "Passed experiments", "sum(c_engine_passed_experiments)", "green"),
+		dashboardStat(16, "Failed experiments", "sum(c_engine_failed_experiments)", "red"),
+	}
+	y := 4
+	for _, engine := range engines {
+		panels = append(panels, map[string]interface{}{
+			"type":      "row",
+			"title":     engine.Namespace + "/" + engine.Name,
+			"collapsed": false,
+			"gridPos":   map[string]int{"x": 0, "y": y, "w": 24, "h": 1},
+			"panels":    []interface{}{},
+		})
+		y++
+		selector := fmt.Sprintf(`{chaos_namespace=%q,engine_name=%q}`, engine.Namespace, labelNormalization.normalize(engine.Name))
+		var targets []map[string]interface{}
+		for i, experiment := range engine.Experiments {
+			targets = append(targets, map[string]interface{}{
+				"datasource":   dashboardDatasource,
+				"refId":        fmt.Sprintf("E%d", i),
+				"expr":         "c_exp_" + strings.Replace(experiment.Name, "-", "_", -1) + selector,
+				"legendFormat": experiment.Name,
+			})
+		}
+		panels = append(panels, map[string]interface{}{
+			"type":        "timeseries",
+			"title":       "Experiment states",
+			"datasource":  dashboardDatasource,
+			"gridPos":     map[string]int{"x": 0, "y": y, "w": 16, "h": 8},
+			"targets":     targets,
+			"fieldConfig": map[string]interface{}{"defaults": map[string]interface{}{"mappings": dashboardStateMappings, "min": 0, "max": 3, "custom": map[string]interface{}{"drawStyle": "line", "lineInterpolation": "stepAfter"}}},
+		}, map[string]interface{}{
+			"type":       "timeseries",
+			"title":      "Verdict transitions",
+			"datasource": dashboardDatasource,
+			"gridPos":    map[string]int{"x": 16, "y": y, "w": 8, "h": 8},
+			"targets": []map[string]interface{}{{
+				"datasource":   dashboardDatasource,
+				"refId":        "A",
+				"expr":         "sum by (to) (increase(chaos_experiment_verdict_transitions_total" + selector + "[$__range]))",
+				"legendFormat": "{{to}}",
+			}},
+			"fieldConfig": map[string]interface{}{"defaults": map[string]interface{}{"custom": map[string]interface{}{"drawStyle": "bars"}}},
+		})
+		y += 8
+	}
+	for i, panel := range panels {
+		panel["id"] = i + 1
+	}
+	return map[string]interface{}{
+		"title":         "LitmusChaos",
+		"uid":           "litmuschaos-exporter",
+		"tags":          []string{"litmuschaos", "chaos"},
+		"schemaVersion": 30,
+		"version":       1,
+		"editable":      true,
+		"refresh":       "30s",
+		"time":          map[string]string{"from": "now-6h", "to": "now"},
+		"templating": map[string]interface{}{"list": []map[string]interface{}{{
+			"name":  "datasource",
+			"label": "Data source",
+			"type":  "datasource",
+			"query": "prometheus",
+		}}},
+		"panels": panels,
+	}
+}
+
+// dashboardStat returns a stat panel of the first row, at x
+func dashboardStat(x int, title string, expr string, color string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "stat",
+		"title":      title,
+		"datasource": dashboardDatasource,
+		"gridPos":    map[string]int{"x": x, "y": 0, "w": 8, "h": 4},
+		"targets":    []map[string]interface{}{{"datasource": dashboardDatasource, "refId": "A", "expr": expr}},
+		"fieldConfig": map[string]interface{}{"defaults": map[string]interface{}{
+			"color": map[string]string{"mode": "fixed", "fixedColor": color},
+		}},
+	}
+}
+
+// dashboardRun runs a single collection pass of the namespaces of settings, as dryRun does, and writes the
+// dashboard of the engines collected to out. The engines that failed to collect are left out with a warning
+func dashboardRun(ctx context.Context, cfg *rest.Config, settings exporterSettings, versions *version.Provider, out io.Writer) error {
+	if err := collectOnce(ctx, cfg, settings, versions); err != nil {
+		log.Warn("The dashboard lacks the engines not collected: ", err)
+	}
+	return writeDashboard(out, engineStatuses())
+}
+
+// writeDashboard writes the dashboard of engines to out, indented as served
+func writeDashboard(out io.Writer, engines []engineStatus) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(buildDashboard(engines))
+}
+
+// dashboardHandler serves a Grafana dashboard of the collected chaosengines in the scope of the token, and of the
+// namespace & engine query parameters if given, ready to import
+func dashboardHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, buildDashboard(requestEngineStatuses(r)))
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/version"
+	"k8s.io/client-go/rest"
+)
+
+func TestBuildDashboard(t *testing.T) {
+	dashboard := buildDashboard([]engineStatus{{
+		Namespace:   "litmus",
+		Name:        "engine-nginx",
+		Experiments: []experimentStatus{{Name: "pod-delete"}, {Name: "container-kill"}},
+	}})
+	panels := dashboard["panels"].([]map[string]interface{})
+	if len(panels) != 6 {
+		t.Fatalf("expected 3 stats, a row & 2 engine panels, got %d panels", len(panels))
+	}
+	if panels[3]["type"] != "row" || panels[3]["title"] != "litmus/engine-nginx" {
+		t.Errorf("unexpected row %v", panels[3])
+	}
+	targets := panels[4]["targets"].([]map[string]interface{})
+	if expr := targets[0]["expr"]; expr != `c_exp_pod_delete{chaos_namespace="litmus",engine_name="engine-nginx"}` {
+		t.Errorf("unexpected query %s", expr)
+	}
+	var out bytes.Buffer
+	if err := writeDashboard(&out, nil); err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || decoded["uid"] != "litmuschaos-exporter" {
+		t.Errorf("unexpected dashboard %s: %v", out.String(), err)
+	}
+}
+
+func TestDashboardRunOutput(t *testing.T) {
+	server := newChaosAPIServer()
+	defer server.Close()
+	defer replaceEngineSeries("litmus/engine-nginx", nil)
+	defer func() { deleteApplicationSLA(slaTracker.Forget("litmus/engine-nginx")) }()
+
+	cfg := &rest.Config{Host: server.URL}
+	var err error
+	out := captureStdout(t, func() {
+		err = dashboardRun(context.Background(), cfg, exporterSettings{appNamespace: "litmus", chaosEngine: "engine-nginx"},
+			version.NewProvider(cfg, "openebs", 0), os.Stdout)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dashboard struct {
+		Panels []struct {
+			Type  string `json:"type"`
+			Title string `json:"title"`
+		} `json:"panels"`
+	}
+	if err := json.Unmarshal(out, &dashboard); err != nil {
+		t.Fatalf("expected a JSON dashboard on stdout, got %q: %v", out, err)
+	}
+	found := false
+	for _, panel := range dashboard.Panels {
+		found = found || panel.Type == "row" && panel.Title == "litmus/engine-nginx"
+	}
+	if !found {
+		t.Errorf("expected a row of engine-nginx, got %+v", dashboard.Panels)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/credentials"
+	"github.com/litmuschaos/chaos-exporter/pkg/sinks"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Holds the settings of the Datadog sink, an empty API key reference disables it
+var (
+	datadogAPIKey  string
+	datadogSite    string
+	datadogTimeout time.Duration
+)
+
+// Holds the Datadog sink, nil unless it is enabled
+var datadogSink *sinks.Datadog
+
+// newDatadogSink returns the Datadog sink of the configured site, nil if no API key is configured. The API key
+// is a credential reference, for e.g. env:DD_API_KEY set from a Secret
+func newDatadogSink(apiKeyRef string, site string, timeout time.Duration) (*sinks.Datadog, error) {
+	if apiKeyRef == "" {
+		return nil, nil
+	}
+	if site == "" {
+		return nil, fmt.Errorf("the Datadog site is required, e.g. datadoghq.com")
+	}
+	apiKey, err := credentials.New(apiKeyRef)
+	if err != nil {
+		return nil, err
+	}
+	return &sinks.Datadog{URL: "https://api." + site, APIKey: apiKey, Client: &http.Client{Timeout: timeout}}, nil
+}
+
+// Datadog alert types of the verdicts an experiment transitions to
+var datadogAlertTypes = map[string]string{"fail": "error", "pass": "success"}
+
+// runDatadogEvents posts every verdict transition to the Datadog event stream until ctx is done
+func runDatadogEvents(ctx context.Context, sink *sinks.Datadog) {
+	events, unsubscribe := verdictEvents.subscribe()
+	defer unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			alertType, ok := datadogAlertTypes[event.To]
+			if !ok {
+				alertType = "info"
+			}
+			err := sink.PostEvent(ctx, sinks.DatadogEvent{
+				Title: fmt.Sprintf("Chaos experiment %s of %s/%s: %s", event.Experiment, event.Namespace, event.Engine, event.To),
+				Text:  fmt.Sprintf("The verdict of experiment %s of chaosengine %s/%s changed from %s to %s", event.Experiment, event.Namespace, event.Engine, event.From, event.To),
+				Tags: []string{
+					sinks.DatadogTag("chaos_namespace", event.Namespace),
+					sinks.DatadogTag("engine_name", event.Engine),
+					sinks.DatadogTag("experiment", event.Experiment),
+				},
+				AlertType:      alertType,
+				AggregationKey: event.Namespace + "/" + event.Engine + "/" + event.Experiment,
+				SourceType:     "litmuschaos",
+				DateHappened:   event.Time.Unix(),
+			})
+			recordDatadogRequest("event", err)
+		}
+	}
+}
+
+// submitDatadogSeries submits the series of the last collection of every chaosengine as Datadog gauges,
+// tagged with their labels. Called after each collection pass, it does nothing unless the sink is enabled
+func submitDatadogSeries(ctx context.Context, sink *sinks.Datadog) {
+	if sink == nil {
+		return
+	}
+	_, families, err := gatherEngineFamilies()
+	if err != nil {
+		recordDatadogRequest("series", err)
+		return
+	}
+	now := float64(exporterClock.Now().Unix())
+	var series []sinks.DatadogSeries
+	for _, engine := range families {
+		for _, family := range engine {
+			if family.GetType() != dto.MetricType_GAUGE {
+				continue
+			}
+			for _, metric := range family.Metric {
+				tags := make([]string, 0, len(metric.Label))
+				for _, label := range metric.Label {
+					tags = append(tags, sinks.DatadogTag(label.GetName(), label.GetValue()))
+				}
+				series = append(series, sinks.DatadogSeries{
+					Metric: family.GetName(),
+					Points: [][2]float64{{now, metric.GetGauge().GetValue()}},
+					Type:   "gauge",
+					Tags:   tags,
+				})
+			}
+		}
+	}
+	if len(series) == 0 {
+		return
+	}
+	recordDatadogRequest("series", sink.SubmitSeries(ctx, series))
+}
+
+// recordDatadogRequest counts the outcome of a request to Datadog, logging failures
+func recordDatadogRequest(request string, err error) {
+	if err == nil {
+		datadogRequests.WithLabelValues(request, "success").Inc()
+		return
+	}
+	datadogRequests.WithLabelValues(request, "failure").Inc()
+	if logger := logSampling.sample(exporterLog, "datadog/"+request); logger != nil {
+		logger.Warnf("Unable to submit the %s to Datadog: %v", request, err)
+	}
+}
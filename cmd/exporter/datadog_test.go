@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDatadogSink(t *testing.T) {
+	payloads := make(chan map[string]interface{}, 2)
+	var apiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey = r.Header.Get("DD-API-KEY")
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		payload["path"] = r.URL.Path
+		payloads <- payload
+	}))
+	defer server.Close()
+
+	os.Setenv("TEST_DD_API_KEY", "dd-key")
+	defer os.Unsetenv("TEST_DD_API_KEY")
+	sink, err := newDatadogSink("env:TEST_DD_API_KEY", "datadoghq.eu", time.Second)
+	if err != nil || sink.URL != "https://api.datadoghq.eu" {
+		t.Fatalf("unexpected sink %+v, %v", sink, err)
+	}
+	sink.URL = server.URL
+
+	series := make(seriesSet)
+	series.setVersioned(failedExperiments, 1, "litmus", "uid", "engine-datadog", "1.13", "1.0")
+	replaceEngineSeries("litmus/engine-datadog", series)
+	defer replaceEngineSeries("litmus/engine-datadog", nil)
+	submitDatadogSeries(context.Background(), sink)
+	payload := <-payloads
+	if payload["path"] != "/api/v1/series" || apiKey != "dd-key" {
+		t.Errorf("unexpected request to %v with key %q", payload["path"], apiKey)
+	}
+	submitted, _ := json.Marshal(payload["series"])
+	if !strings.Contains(string(submitted), `"metric":"c_engine_failed_experiments"`) || !strings.Contains(string(submitted), `"engine_name:engine-datadog"`) {
+		t.Errorf("unexpected series %s", submitted)
+	}
+
+	broker := verdictEvents
+	verdictEvents = &eventBroker{subscribers: make(map[chan verdictEvent]bool)}
+	defer func() { verdictEvents = broker }()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runDatadogEvents(ctx, sink)
+	// Wait for the sink to subscribe, the events published before are not delivered to it
+	for subscribed := 0; subscribed == 0; time.Sleep(time.Millisecond) {
+		verdictEvents.mu.Lock()
+		subscribed = len(verdictEvents.subscribers)
+		verdictEvents.mu.Unlock()
+	}
+	verdictEvents.publish(verdictEvent{Namespace: "litmus", Engine: "engine-datadog", Experiment: "pod-delete", From: "running", To: "fail"})
+	payload = <-payloads
+	if payload["path"] != "/api/v1/events" || payload["alert_type"] != "error" {
+		t.Errorf("unexpected event %v", payload)
+	}
+}
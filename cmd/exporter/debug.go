@@ -0,0 +1,29 @@
+package main
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// debugHandler serves the pprof profiles under /debug/pprof/. The pprof package also registers them on
+// http.DefaultServeMux, which is why the metrics & API are served by a mux of their own
+func debugHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// serveDebug serves the pprof profiles on address, apart from the metrics so that they are never exposed
+// along with them
+func serveDebug(address string) {
+	log.Info("Serving the pprof profiles on ", address)
+	if err := http.ListenAndServe(address, debugHandler()); err != nil {
+		log.Error("Unable to serve the pprof profiles: ", err)
+	}
+}
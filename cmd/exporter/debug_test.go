@@ -0,0 +1,20 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDebugHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	debugHandler().ServeHTTP(w, httptest.NewRequest("GET", "/debug/pprof/cmdline", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected the pprof cmdline to be served, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	debugHandler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected the metrics not to be served on the debug port, got %d", w.Code)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/client-go/rest"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/version"
+)
+
+// Prefixes of the families of the Go & process collectors, left out of the dry-run output
+var runtimeFamilyPrefixes = []string{"go_", "process_", "promhttp_"}
+
+// runtimeFamily checks whether a family is one of the Go & process collectors
+func runtimeFamily(name string) bool {
+	for _, prefix := range runtimeFamilyPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// dryRun runs a single collection pass of the namespaces of settings, without watches nor caches, and writes
+// the chaos metric families to out in the Prometheus text format. An error is returned if any namespace
+// failed to collect, once the families collected are written
+func dryRun(ctx context.Context, cfg *rest.Config, settings exporterSettings, versions *version.Provider, gatherer prometheus.Gatherer, out io.Writer) error {
+	collectErr := collectOnce(ctx, cfg, settings, versions)
+	if err := writeFamilies(gatherer, out); err != nil {
+		return err
+	}
+	return collectErr
+}
+
+// collectOnce runs a single collection pass of the namespaces of settings, returning an error if any namespace
+// failed to collect
+func collectOnce(ctx context.Context, cfg *rest.Config, settings exporterSettings, versions *version.Provider) error {
+	kubernetesVersion, openebsVersion := versions.Versions()
+	setVersionInfo(kubernetesVersion, openebsVersion)
+
+	var failed []string
+	for _, ns := range splitNamespaces(settings.appNamespace) {
+		if _, err := collectNamespace(ctx, cfg, settings, ns, kubernetesVersion, openebsVersion); err != nil {
+			failed = append(failed, fmt.Sprintf("%q: %v", ns, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("unable to collect namespace(s) %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// writeFamilies writes the families of gatherer, but those of the Go & process collectors, in the text format
+func writeFamilies(gatherer prometheus.Gatherer, out io.Writer) error {
+	families, err := gatherer.Gather()
+	if err != nil {
+		return err
+	}
+	encoder := expfmt.NewEncoder(out, expfmt.FmtText)
+	written := 0
+	for _, family := range families {
+		if runtimeFamily(family.GetName()) {
+			continue
+		}
+		if err := encoder.Encode(family); err != nil {
+			return err
+		}
+		written++
+	}
+	log.Infof("dry run collected %d metric families", written)
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/version"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/client-go/rest"
+)
+
+func TestWriteFamilies(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewGoCollector())
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "litmuschaos_passed_experiments", Help: "passed"}, []string{"chaosengine_name"})
+	gauge.WithLabelValues("engine-nginx").Set(2)
+	registry.MustRegister(gauge)
+
+	var out bytes.Buffer
+	if err := writeFamilies(registry, &out); err != nil {
+		t.Fatal(err)
+	}
+	expected := "# HELP litmuschaos_passed_experiments passed\n# TYPE litmuschaos_passed_experiments gauge\nlitmuschaos_passed_experiments{chaosengine_name=\"engine-nginx\"} 2\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
+
+// newChaosAPIServer returns an apiserver serving the chaosengine engine-nginx of litmus, listing pod-delete, and
+// NotFound for every other resource
+func newChaosAPIServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/version":
+			fmt.Fprint(w, `{"gitVersion":"v1.13.0"}`)
+		case "/apis/litmuschaos.io/v1alpha1/namespaces/litmus/chaosengines/engine-nginx":
+			fmt.Fprint(w, `{"apiVersion":"litmuschaos.io/v1alpha1","kind":"ChaosEngine","metadata":{"name":"engine-nginx","namespace":"litmus",`+
+				`"ownerReferences":[{"apiVersion":"argoproj.io/v1alpha1","kind":"Workflow","name":"wf-nginx","uid":"wf-uid"}]},`+
+				`"spec":{"appinfo":{"appns":"default","applabel":"app=nginx"},"experiments":[{"name":"pod-delete"}]}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"apiVersion":"v1","kind":"Status","status":"Failure","reason":"NotFound","code":404}`)
+		}
+	}))
+}
+
+// captureStdout returns what run writes to the standard output
+func captureStdout(t *testing.T, run func()) []byte {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	output := make(chan []byte)
+	go func() {
+		data, _ := ioutil.ReadAll(reader)
+		output <- data
+	}()
+	defer func() {
+		os.Stdout = stdout
+	}()
+	run()
+	writer.Close()
+	return <-output
+}
+
+func TestDryRunOutput(t *testing.T) {
+	server := newChaosAPIServer()
+	defer server.Close()
+	defer replaceEngineSeries("litmus/engine-nginx", nil)
+	defer func() { deleteApplicationSLA(slaTracker.Forget("litmus/engine-nginx")) }()
+
+	cfg := &rest.Config{Host: server.URL}
+	var err error
+	out := captureStdout(t, func() {
+		err = dryRun(context.Background(), cfg, exporterSettings{appNamespace: "litmus", chaosEngine: "engine-nginx"},
+			version.NewProvider(cfg, "openebs", 0), prometheus.DefaultGatherer, os.Stdout)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("expected the text format on stdout, got %q: %v", out, err)
+	}
+	family, ok := families["c_engine_experiment_count"]
+	if !ok || len(family.Metric) != 1 || family.Metric[0].GetGauge().GetValue() != 1 {
+		t.Errorf("expected the experiment count of engine-nginx, got %v", family)
+	}
+}
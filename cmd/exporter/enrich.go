@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Holds the keys of the chaosengine labels copied onto litmuschaos_engine_labels, set from the engineLabels
+// of the ChaosExporterConfig CR. Guarded by stateMutex
+var engineLabelKeys []string
+
+// Holds the litmuschaos_engine_labels gauges, keyed by their joined label keys, as the label set follows
+// engineLabels. Guarded by stateMutex
+var engineLabelGauges = make(map[string]*engineGauge)
+
+// Matches the characters of a label key that are not valid in a Prometheus label name
+var invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// engineLabelName returns the Prometheus label carrying a chaosengine label, for e.g. label_app_kubernetes_io_team
+// for app.kubernetes.io/team
+func engineLabelName(key string) string {
+	return "label_" + invalidLabelChars.ReplaceAllString(key, "_")
+}
+
+// validateEngineLabels checks the keys of engineLabels are valid kubernetes label keys, mapping to distinct
+// Prometheus labels
+func validateEngineLabels(keys []string) error {
+	names := make(map[string]string)
+	for _, key := range keys {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid engineLabels key %q: %s", key, strings.Join(errs, ", "))
+		}
+		name := engineLabelName(key)
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("engineLabels keys %q and %q both map to the label %s", other, key, name)
+		}
+		names[name] = key
+	}
+	return nil
+}
+
+// setEngineLabelKeys replaces the chaosengine labels copied onto litmuschaos_engine_labels
+func setEngineLabelKeys(keys []string) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+	engineLabelKeys = append([]string(nil), keys...)
+}
+
+// engineLabelsGauge returns the litmuschaos_engine_labels gauge of the current engineLabels, defining it on
+// first use, or nil if no label is copied
+func engineLabelsGauge() (*engineGauge, []string) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+
+	if len(engineLabelKeys) == 0 {
+		return nil, nil
+	}
+	key := strings.Join(engineLabelKeys, ",")
+	gauge, ok := engineLabelGauges[key]
+	if !ok {
+		names := []string{"engine_name"}
+		for _, label := range engineLabelKeys {
+			names = append(names, engineLabelName(label))
+		}
+		name := prometheus.BuildFQName("litmuschaos", "engine", "labels")
+		gauge = &engineGauge{name: name, desc: prometheus.NewDesc(
+			name, "Set to 1, labelled with the chaosengine labels listed in the engineLabels of the chaosexporterconfig", labelNames(names...), nil,
+		)}
+		engineLabelGauges[key] = gauge
+	}
+	return gauge, engineLabelKeys
+}
+
+// setEngineLabelSeries sets the series carrying the labels of a chaosengine, to be joined (group_left) onto its
+// other series. Nothing is set unless the ChaosExporterConfig CR lists engineLabels
+func setEngineLabelSeries(series seriesSet, appNS string, chaosEngine string, labels map[string]string) {
+	gauge, keys := engineLabelsGauge()
+	if gauge == nil {
+		return
+	}
+	values := []string{chaosEngine}
+	for _, key := range keys {
+		values = append(values, labels[key])
+	}
+	series.set(gauge, 1, labelValues(appNS, values...)...)
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+)
+
+// Prefix of the ENVs mapped to the flags, e.g. CHAOS_EXPORTER_WEB_LISTEN_ADDRESS sets --web.listen-address
+const envPrefix = "CHAOS_EXPORTER_"
+
+// Flags not mapped to an ENV nor set by the settings file: --config (& its deprecated --config-file alias) is
+// looked up ahead of the others, and --version would clash with the version of an image
+var unmappedFlags = map[string]bool{"config": true, "config-file": true, "version": true}
+
+// Deprecated ENVs, still read as the defaults of the flags they are an alias of. The ENVs of the prefix
+// override them, the flags override both
+var legacyEnvs = map[string]string{
+	"APP_UUID":                  "app-uuid",
+	"CHAOSENGINE":               "chaosengine",
+	"APP_NAMESPACE":             "watch-namespace",
+	"WATCH_NAMESPACE":           "watch-namespace",
+	"OPENEBS_NAMESPACE":         "openebs-namespace",
+	"EXPORTER_CONFIG":           "exporter-config",
+	"EXPORTER_NAMESPACE":        "exporter-namespace",
+	"RESYNC_PERIOD":             "resync-period",
+	"LABEL_LOWERCASE":           "label-lowercase",
+	"LABEL_REPLACE":             "label-replace",
+	"LABEL_VALUE_MAP":           "label-value-map",
+	"API_TOKENS_FILE":           "api-tokens-file",
+	"CONFIG_FILE":               "config",
+	"WEB_CONFIG_FILE":           "web.config.file",
+	"WEB_HEALTH_LISTEN_ADDRESS": "web.health-listen-address",
+	"WEB_API_RATE_LIMIT":        "web.api-rate-limit",
+	"WEB_API_CLIENT_RATE_LIMIT": "web.api-client-rate-limit",
+	"WEB_API_RATE_BURST":        "web.api-rate-burst",
+	"WEB_CORS_ALLOWED_ORIGINS":  "web.cors-allowed-origins",
+	"WEB_CORS_ALLOWED_HEADERS":  "web.cors-allowed-headers",
+	"WEB_ACCESS_LOG":            "web.access-log",
+	"WEB_SHUTDOWN_TIMEOUT":      "web.shutdown-timeout",
+	"WEB_TELEMETRY_PATH":        "web.telemetry-path",
+	"ENABLE_PPROF":              "enable-pprof",
+	"DEBUG_LISTEN_ADDRESS":      "debug.listen-address",
+	"FAILURE_BUDGET":            "failure-budget",
+	"SNAPSHOT_FILE":             "snapshot-file",
+	"METRICS_USERNAME":          "web.metrics-username",
+	"METRICS_PASSWORD_HASH":     "web.metrics-password-hash",
+	"METRICS_BEARER_TOKEN":      "web.metrics-bearer-token",
+	"ENGINE_RESOURCE":           "engine-resource",
+	"RESULT_RESOURCE":           "result-resource",
+	"WEB_TLS_CERT_FILE":         "web.tls-cert-file",
+	"WEB_TLS_KEY_FILE":          "web.tls-key-file",
+	"WEB_CLIENT_CA_FILE":        "web.client-ca-file",
+	"EXPORTER_MODE":             "mode",
+	"WEB_LISTEN_ADDRESS":        "web.listen-address",
+	"CHAOS_PROVIDERS":           "chaos-providers",
+	"ENGINE_SELECTOR":           "engine-selector",
+	"LEADER_ELECT":              "leader-elect",
+	"COLLECTION_WORKERS":        "collection-workers",
+	"COLLECTION_DEADLINE":       "collection-deadline",
+	"STATE_RETENTION":           "state-retention",
+	"STATE_GC_INTERVAL":         "state-gc-interval",
+	"SELFTEST_NAMESPACE":        "selftest-namespace",
+	"SELFTEST_INTERVAL":         "selftest-interval",
+	"ENGINE_TIMEOUT":            "engine-timeout",
+	"TIME_SOURCE":               "time-source",
+	"KUBE_API_QPS":              "kube-api-qps",
+	"KUBE_API_BURST":            "kube-api-burst",
+	"KUBE_API_TIMEOUT":          "kube-api-timeout",
+	"VERSION_REFRESH_INTERVAL":  "version-refresh-interval",
+	"SERIES_REPLACEMENT":        "series-replacement",
+	"CHAOSRESULT_RESYNC_PERIOD": "chaosresult-resync",
+	"HEATMAP_DAYS":              "heatmap-days",
+	"SINK_CHECK_TIMEOUT":        "sink-check-timeout",
+	"MAX_CONSECUTIVE_FAILURES":  "max-consecutive-failures",
+}
+
+// flagEnvName returns the ENV of the prefix mapped to a flag, e.g. CHAOS_EXPORTER_WEB_LISTEN_ADDRESS
+func flagEnvName(name string) string {
+	return envPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(name))
+}
+
+// applyFlagEnvs sets the flags of fs from the ENVs of the prefix. Run ahead of parsing, so that the
+// command line overrides them
+func applyFlagEnvs(fs *flag.FlagSet) error {
+	var invalid []string
+	fs.VisitAll(func(f *flag.Flag) {
+		if unmappedFlags[f.Name] {
+			return
+		}
+		if value, ok := os.LookupEnv(flagEnvName(f.Name)); ok {
+			if err := fs.Set(f.Name, value); err != nil {
+				invalid = append(invalid, fmt.Sprintf("%s: invalid value %q", flagEnvName(f.Name), value))
+			}
+		}
+	})
+	if len(invalid) > 0 {
+		return fmt.Errorf("%s", strings.Join(invalid, ", "))
+	}
+	return nil
+}
+
+// legacyEnvWarnings lists the deprecated ENVs that are set, along with the ENV of the prefix replacing them
+func legacyEnvWarnings() []string {
+	var warnings []string
+	for legacy, name := range legacyEnvs {
+		if _, ok := os.LookupEnv(legacy); !ok {
+			continue
+		}
+		if _, ok := os.LookupEnv(flagEnvName(name)); ok {
+			warnings = append(warnings, fmt.Sprintf("%s is deprecated and ignored, overridden by %s", legacy, flagEnvName(name)))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("%s is deprecated, set %s (or --%s) instead", legacy, flagEnvName(name), name))
+		}
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// Flags defaulted by the settings file, which count as set explicitly although they are not visited as set by
+// the flag package, mapped to the default the file replaced
+var fileSettings map[string]string
+
+// settingSet reports whether the flag name was set explicitly, on the command line, by the ENV of the prefix,
+// by one of its deprecated ENVs or by the settings file
+func settingSet(name string) bool {
+	_, defaulted := fileSettings[name]
+	return flagSet(name) || legacyEnvSet(name) || defaulted
+}
+
+// settingOverridden reports whether the flag name of fs was set on the command line, by the ENV of the prefix
+// or by one of its deprecated ENVs, all of which override the settings file
+func settingOverridden(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set || legacyEnvSet(name)
+}
+
+// legacyEnvSet reports whether one of the deprecated ENVs of the flag name is set
+func legacyEnvSet(name string) bool {
+	for legacy, flagName := range legacyEnvs {
+		if _, ok := os.LookupEnv(legacy); ok && flagName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// configArg returns the path given by the --config flag of args, or by its deprecated --config-file alias. It is
+// looked up ahead of flag parsing, as the file defaults the flags the ENVs & the command line override. The ENV
+// of the prefix, then the deprecated CONFIG_FILE ENV, are the fallback
+func configArg(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)
+		if parts[0] != "config" && parts[0] != "config-file" {
+			continue
+		}
+		if len(parts) == 2 {
+			return parts[1]
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	if path, ok := os.LookupEnv(flagEnvName("config")); ok {
+		return path
+	}
+	return os.Getenv("CONFIG_FILE")
+}
+
+// readSettingsFile reads the settings file at path, a YAML map of flag names (or of the ENVs of the prefix
+// mapped to them) to their values, into the values of the flags of fs it sets, by flag name. Lists are joined
+// with commas, for e.g. watch-namespace: [litmus, payments]
+func readSettingsFile(fs *flag.FlagSet, path string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var settings map[string]interface{}
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+
+	envFlags := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		if !unmappedFlags[f.Name] {
+			envFlags[flagEnvName(f.Name)] = f.Name
+		}
+	})
+	values := make(map[string]string)
+	for key, value := range settings {
+		name, ok := envFlags[key]
+		if !ok && fs.Lookup(key) != nil && !unmappedFlags[key] {
+			name, ok = key, true
+		}
+		if !ok {
+			return nil, fmt.Errorf("invalid setting %q, expected the name of a flag or of its %s ENV, e.g. watch-namespace", key, envPrefix)
+		}
+		if _, ok := values[name]; ok {
+			return nil, fmt.Errorf("%s is set twice", name)
+		}
+		if values[name], err = settingValue(value); err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", key, err)
+		}
+	}
+	return values, nil
+}
+
+// applySettingsFile reads the settings file at path into the defaults of the flags of fs. Run ahead of
+// applyFlagEnvs, the flags the deprecated ENVs of which are set keep their ENV value: flags, ENVs of the prefix
+// & deprecated ENVs thereby override the file. It returns the names of the flags the file defaulted, mapped to
+// the default it replaced
+func applySettingsFile(fs *flag.FlagSet, path string) (map[string]string, error) {
+	values, err := readSettingsFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	defaulted := make(map[string]string)
+	for name, value := range values {
+		if legacyEnvSet(name) {
+			continue
+		}
+		f := fs.Lookup(name)
+		replaced := f.DefValue
+		if err := f.Value.Set(value); err != nil {
+			return nil, fmt.Errorf("invalid %s: invalid value %q", name, value)
+		}
+		f.DefValue = value
+		defaulted[name] = replaced
+	}
+	return defaulted, nil
+}
+
+// settingValue formats a value of the settings file as a flag value
+func settingValue(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case []interface{}:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			formatted, err := settingValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, formatted)
+		}
+		return strings.Join(items, ","), nil
+	}
+	return "", fmt.Errorf("expected a scalar or a list, got %T", value)
+}
+
+// getnamespaceEnv checks whether an ENV variable has been set, else sets a default value
+func getNamespaceEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+// envFloat returns the numeric value of an ENV variable, or the fallback if it is unset or invalid.
+// Invalid values are reported by the settings validation
+func envFloat(key string, fallback float64) float64 {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		recordInvalidEnv(key, raw)
+		return fallback
+	}
+	return value
+}
+
+// envDuration returns the duration held by an ENV variable, or the fallback if it is unset or invalid.
+// Invalid values are reported by the settings validation
+func envDuration(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	value, err := time.ParseDuration(raw)
+	if err != nil {
+		recordInvalidEnv(key, raw)
+		return fallback
+	}
+	return value
+}
+
+// get
+func getOpenebsEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSettingsFile(t *testing.T) {
+	for args, expected := range map[string]string{
+		"--config=/etc/chaos-exporter/config.yaml": "/etc/chaos-exporter/config.yaml",
+		"-config /etc/config.yaml --mode sidecar":  "/etc/config.yaml",
+		"--config-file /etc/reloaded.yaml":         "/etc/reloaded.yaml",
+	} {
+		if path := configArg(strings.Fields(args)); path != expected {
+			t.Errorf("expected %q from %q, got %q", expected, args, path)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "chaos-exporter-settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	write := func(content string) {
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.Setenv("ENGINE_SELECTOR", "team=checkout")
+	os.Setenv("CHAOS_EXPORTER_COLLECTION_WORKERS", "4")
+	defer os.Unsetenv("ENGINE_SELECTOR")
+	defer os.Unsetenv("CHAOS_EXPORTER_COLLECTION_WORKERS")
+	newFlags := func() *flag.FlagSet {
+		fs := flag.NewFlagSet("exporter", flag.ContinueOnError)
+		fs.String("watch-namespace", os.Getenv("WATCH_NAMESPACE"), "")
+		fs.String("engine-selector", os.Getenv("ENGINE_SELECTOR"), "")
+		fs.Int("collection-workers", 0, "")
+		fs.Bool("leader-elect", false, "")
+		fs.String("web.listen-address", ":8080", "")
+		fs.String("config", "", "")
+		return fs
+	}
+
+	fs := newFlags()
+	write("CHAOS_EXPORTER_WATCH_NAMESPACE: [litmus, payments]\nengine-selector: team=payments\nCHAOS_EXPORTER_COLLECTION_WORKERS: 8\nleader-elect: true\n")
+	defaulted, err := applySettingsFile(fs, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The legacy ENV keeps its value, the others are defaulted by the file
+	if _, ok := defaulted["watch-namespace"]; !ok || len(defaulted) != 3 || defaulted["collection-workers"] != "0" || defaulted["leader-elect"] != "false" {
+		t.Errorf("unexpected flags defaulted by the file %v", defaulted)
+	}
+	// The file values are defaults, not explicitly set
+	fs.Visit(func(f *flag.Flag) {
+		t.Errorf("expected --%s not to be set by the file", f.Name)
+	})
+	if err := applyFlagEnvs(fs); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Parse([]string{"--web.listen-address", ":9090"}); err != nil {
+		t.Fatal(err)
+	}
+	// The legacy ENVs, the ENVs of the prefix & the flags override the file
+	for name, expected := range map[string]string{"watch-namespace": "litmus,payments", "engine-selector": "team=checkout", "collection-workers": "4", "leader-elect": "true", "web.listen-address": ":9090"} {
+		if value := fs.Lookup(name).Value.String(); value != expected {
+			t.Errorf("expected --%s=%s, got %q", name, expected, value)
+		}
+	}
+	if warnings := legacyEnvWarnings(); len(warnings) != 1 || !strings.HasPrefix(warnings[0], "ENGINE_SELECTOR is deprecated") {
+		t.Errorf("expected a warning for the ENGINE_SELECTOR ENV alone, got %q", warnings)
+	}
+
+	for _, content := range []string{"APP_NAMESPACE: litmus\n", "config: other.yaml\n", "engine-selector: a=b\nCHAOS_EXPORTER_ENGINE_SELECTOR: c=d\n", "collection-workers: many\n"} {
+		write(content)
+		if _, err := applySettingsFile(newFlags(), path); err == nil {
+			t.Errorf("expected %q to be rejected", content)
+		}
+	}
+}
+
+func TestFlagEnvs(t *testing.T) {
+	if name := flagEnvName("web.listen-address"); name != "CHAOS_EXPORTER_WEB_LISTEN_ADDRESS" {
+		t.Errorf("unexpected ENV name %s", name)
+	}
+
+	os.Setenv("CHAOS_EXPORTER_WEB_LISTEN_ADDRESS", ":9091")
+	os.Setenv("CHAOS_EXPORTER_RESYNC_PERIOD", "30s")
+	os.Setenv("RESYNC_PERIOD", "90s")
+	os.Setenv("APP_UUID", "uuid")
+	defer func() {
+		for _, key := range []string{"CHAOS_EXPORTER_WEB_LISTEN_ADDRESS", "CHAOS_EXPORTER_RESYNC_PERIOD", "RESYNC_PERIOD", "APP_UUID"} {
+			os.Unsetenv(key)
+		}
+	}()
+	fs := flag.NewFlagSet("exporter", flag.ContinueOnError)
+	listenAddress := fs.String("web.listen-address", ":8080", "")
+	resyncPeriod := fs.Duration("resync-period", envDuration("RESYNC_PERIOD", time.Minute), "")
+	if err := applyFlagEnvs(fs); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Parse([]string{"--web.listen-address", ":9092"}); err != nil {
+		t.Fatal(err)
+	}
+	if *listenAddress != ":9092" || *resyncPeriod != 30*time.Second {
+		t.Errorf("expected the flag to override the prefixed ENV, overriding the legacy ENV, got %s & %s", *listenAddress, *resyncPeriod)
+	}
+
+	expected := []string{
+		"APP_UUID is deprecated, set CHAOS_EXPORTER_APP_UUID (or --app-uuid) instead",
+		"RESYNC_PERIOD is deprecated and ignored, overridden by CHAOS_EXPORTER_RESYNC_PERIOD",
+	}
+	if warnings := legacyEnvWarnings(); !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("expected %q, got %q", expected, warnings)
+	}
+
+	os.Setenv("CHAOS_EXPORTER_RESYNC_PERIOD", "soon")
+	if err := applyFlagEnvs(fs); err == nil || !strings.Contains(err.Error(), "CHAOS_EXPORTER_RESYNC_PERIOD") {
+		t.Errorf("expected an invalid ENV error, got %v", err)
+	}
+}
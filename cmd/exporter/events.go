@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Interval at which a comment is sent on idle event streams, so that proxies do not close them
+var eventsHeartbeat = 30 * time.Second
+
+// Number of events buffered per subscriber, further events are dropped until the subscriber catches up
+const eventsBuffer = 64
+
+// verdictEvent is a state change of an experiment, as streamed by /api/v1/events
+type verdictEvent struct {
+	Namespace  string    `json:"namespace"`
+	Engine     string    `json:"engine"`
+	Experiment string    `json:"experiment"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Time       time.Time `json:"time"`
+	// Time the verdict transitioned from was first observed at
+	Since time.Time `json:"since"`
+	// Step the experiment failed at, when it transitioned to fail & the chaosresult reports it
+	FailStep string `json:"failStep,omitempty"`
+	// Verdict of the engine, pass or fail, set on the transition completing all its experiments
+	EngineVerdict string `json:"engineVerdict,omitempty"`
+}
+
+// eventBroker fans the verdict events out to the subscribed streams
+type eventBroker struct {
+	mu          sync.Mutex
+	subscribers map[chan verdictEvent]bool
+	// Set once shut down, the channels of later subscribers are closed right away
+	closed bool
+}
+
+// Holds the subscribers of the verdict events, published by recordTransitions
+var verdictEvents = &eventBroker{subscribers: make(map[chan verdictEvent]bool)}
+
+// subscribe returns a channel receiving the events published from now on, and the function unsubscribing it
+func (b *eventBroker) subscribe() (<-chan verdictEvent, func()) {
+	events := make(chan verdictEvent, eventsBuffer)
+	b.mu.Lock()
+	if b.closed {
+		close(events)
+	} else {
+		b.subscribers[events] = true
+	}
+	b.mu.Unlock()
+	return events, func() {
+		b.mu.Lock()
+		delete(b.subscribers, events)
+		b.mu.Unlock()
+	}
+}
+
+// publish sends event to the subscribers without blocking, so that a slow stream never holds up the collection
+func (b *eventBroker) publish(event verdictEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for events := range b.subscribers {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+// shutdown closes the channels of the subscribers, ending their streams
+func (b *eventBroker) shutdown() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for events := range b.subscribers {
+		close(events)
+		delete(b.subscribers, events)
+	}
+	b.closed = true
+}
+
+// eventsHandler streams the verdict events as Server-Sent Events, restricted to the engines in the scope of
+// the token, and to those of the namespace & engine query parameters if given
+func eventsHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	scope := requestScope(r)
+	namespace, engine := r.URL.Query().Get("namespace"), r.URL.Query().Get("engine")
+
+	events, unsubscribe := verdictEvents.subscribe()
+	defer unsubscribe()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-exporterClock.After(eventsHeartbeat):
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if !scope.allows(event.Namespace, event.Engine) {
+				continue
+			}
+			if (namespace != "" && event.Namespace != namespace) || (engine != "" && event.Engine != engine) {
+				continue
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: verdict\ndata: %s\n\n", data)
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEventsHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(eventsHandler))
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/api/v1/events?namespace=litmus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+	defer func() {
+		recordTransitions("litmus", "events-engine", nil, nil)
+		recordTransitions("default", "events-engine", nil, nil)
+	}()
+
+	// First observations are not transitions, and other namespaces are filtered out
+	recordTransitions("litmus", "events-engine", map[string]float64{"pod-delete": 1}, nil)
+	recordTransitions("default", "events-engine", map[string]float64{"pod-delete": 1}, nil)
+	recordTransitions("default", "events-engine", map[string]float64{"pod-delete": 3}, nil)
+	recordTransitions("litmus", "events-engine", map[string]float64{"pod-delete": 2}, nil)
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 2 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	if lines[0] != "event: verdict" {
+		t.Fatalf("unexpected event %q", lines[0])
+	}
+	var event verdictEvent
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(lines[1], "data: ")), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Namespace != "litmus" || event.Experiment != "pod-delete" || event.From != "running" || event.To != "fail" || event.EngineVerdict != "fail" {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestEventsShutdown(t *testing.T) {
+	broker := verdictEvents
+	verdictEvents = &eventBroker{subscribers: make(map[chan verdictEvent]bool)}
+	defer func() { verdictEvents = broker }()
+
+	server := httptest.NewServer(http.HandlerFunc(eventsHandler))
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/api/v1/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// Shutting the broker down ends the stream, and the streams opened later
+	verdictEvents.shutdown()
+	if _, err := ioutil.ReadAll(resp.Body); err != nil {
+		t.Fatal(err)
+	}
+	events, unsubscribe := verdictEvents.subscribe()
+	defer unsubscribe()
+	if _, ok := <-events; ok {
+		t.Error("expected the events of a shut down broker to be closed")
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Time after which the state tracked for a chaosengine that is no longer collected is evicted, and the interval
+// at which it is checked for
+var (
+	stateRetention  time.Duration
+	stateGCInterval time.Duration
+)
+
+// Holds the time each chaosengine was last collected, keyed by <namespace>/<engine>
+var engineLastCollected = make(map[string]time.Time)
+
+// markCollected records the collection of a chaosengine
+func markCollected(appNS string, chaosEngine string) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+	engineLastCollected[appNS+"/"+chaosEngine] = exporterClock.Now()
+}
+
+// collectGarbage evicts the state tracked for the chaosengines not collected within the retention, for e.g.
+// deleted ones: their series, last verdicts, failures & SLA contribution. It returns the number of engines evicted
+func collectGarbage(now time.Time) int {
+	stateMutex.Lock()
+	var expired []string
+	for key, collected := range engineLastCollected {
+		if now.Sub(collected) < stateRetention {
+			continue
+		}
+		expired = append(expired, key)
+		delete(engineLastCollected, key)
+		delete(engineFailures, key)
+		for verdictKey := range lastVerdicts {
+			if strings.HasPrefix(verdictKey, key+"/") {
+				delete(lastVerdicts, verdictKey)
+			}
+		}
+		parts := strings.SplitN(key, "/", 2)
+		delete(staleEngines, types.NamespacedName{Namespace: parts[0], Name: parts[1]})
+	}
+	stateMutex.Unlock()
+
+	for _, key := range expired {
+		parts := strings.SplitN(key, "/", 2)
+		setInvalidReasons(parts[0], parts[1], nil)
+		deleteApplicationSLA(slaTracker.Forget(key))
+		replaceEngineSeries(key, nil)
+	}
+	updateTrackedState()
+	return len(expired)
+}
+
+// updateTrackedState sets the number of keys held by the tracked state
+func updateTrackedState() {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+	trackedState.WithLabelValues("engines").Set(float64(len(engineLastCollected)))
+	trackedState.WithLabelValues("verdicts").Set(float64(len(lastVerdicts)))
+	trackedState.WithLabelValues("failures").Set(float64(len(engineFailures)))
+}
+
+// runStateGC collects the garbage of the tracked state every stateGCInterval, until ctx is done
+func runStateGC(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-exporterClock.After(stateGCInterval):
+		}
+		if evicted := collectGarbage(exporterClock.Now()); evicted > 0 {
+			log.Infof("Evicted the state of %d chaosengines not collected for %s", evicted, stateRetention)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/sla"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestCollectGarbage(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	defer func(c clock.Clock) { exporterClock = c }(exporterClock)
+	exporterClock = fakeClock
+	stateRetention = time.Hour
+	defer func() { stateRetention = 0 }()
+	// Forget the engines collected by the other tests
+	engineLastCollected = make(map[string]time.Time)
+	slaTracker = sla.NewTracker()
+	appSLA.Reset()
+
+	collect := func(engine string) {
+		markCollected("litmus", engine)
+		recordTransitions("litmus", engine, map[string]float64{"pod-delete": 2}, nil)
+		replaceEngineSeries("litmus/"+engine, seriesSet{engineOwner: {"": {labels: []string{"litmus", engine, "Workflow", "wf"}, value: 1}}})
+		report, _ := slaTracker.Observe("default", "app="+engine, "litmus/"+engine, sla.EngineState{Available: true})
+		appSLA.WithLabelValues(report.AppNamespace, report.AppLabel).Set(report.SLAPercent)
+	}
+	collect("engine-deleted")
+	fakeClock.Step(30 * time.Minute)
+	collect("engine-kept")
+	fakeClock.Step(30 * time.Minute)
+
+	if evicted := collectGarbage(fakeClock.Now()); evicted != 1 {
+		t.Fatalf("expected a single engine to be evicted, got %d", evicted)
+	}
+	if _, ok := lastVerdicts["litmus/engine-deleted/pod-delete"]; ok {
+		t.Error("expected the verdicts of the deleted engine to be evicted")
+	}
+	if _, ok := collectedValue(engineOwner, "litmus", "engine-deleted", "Workflow", "wf"); ok {
+		t.Error("expected the series of the deleted engine to be evicted")
+	}
+	if _, ok := collectedValue(engineOwner, "litmus", "engine-kept", "Workflow", "wf"); !ok {
+		t.Error("expected the series of the collected engine to be kept")
+	}
+	if applications := applicationLabels(); !reflect.DeepEqual(applications, []string{"app=engine-kept"}) {
+		t.Errorf("expected the SLA of the application of the deleted engine to be evicted, got %v", applications)
+	}
+	metric := &dto.Metric{}
+	if err := trackedState.WithLabelValues("engines").Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	if value := metric.GetGauge().GetValue(); value != 1 {
+		t.Errorf("expected a single tracked engine, got %v", value)
+	}
+
+	fakeClock.Step(time.Hour)
+	collectGarbage(fakeClock.Now())
+}
+
+// applicationLabels returns the app_label of the SLA series
+func applicationLabels() []string {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		appSLA.Collect(metrics)
+		close(metrics)
+	}()
+	var labels []string
+	for metric := range metrics {
+		written := &dto.Metric{}
+		metric.Write(written)
+		for _, label := range written.Label {
+			if label.GetName() == "app_label" {
+				labels = append(labels, label.GetValue())
+			}
+		}
+	}
+	sort.Strings(labels)
+	return labels
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/sinks"
+	dto "github.com/prometheus/client_model/go"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// Scope of the Cloud Monitoring API required to write time series
+const cloudMonitoringScope = "https://www.googleapis.com/auth/monitoring.write"
+
+// Cloud Monitoring rejects the points of a time series written more often than every 5s, the points of the
+// collection passes following each other closer than this are not written
+const cloudMonitoringMinInterval = 10 * time.Second
+
+// Holds the settings of the Cloud Monitoring export, disabled unless enabled is set
+var (
+	cloudMonitoringEnabled  bool
+	cloudMonitoringProject  string
+	cloudMonitoringCluster  string
+	cloudMonitoringLocation string
+	cloudMonitoringPrefix   string
+	cloudMonitoringTimeout  time.Duration
+)
+
+// Holds the Cloud Monitoring sink, nil unless the export is enabled
+var cloudMonitoringSink *cloudMonitoringPublisher
+
+// cloudMonitoringPublisher writes the series of the chaosengines as custom metrics of Cloud Monitoring
+type cloudMonitoringPublisher struct {
+	client *sinks.CloudMonitoring
+	// Prefix of the metric types, e.g. custom.googleapis.com/litmuschaos
+	prefix  string
+	written time.Time
+}
+
+// newCloudMonitoringPublisher returns the publisher authenticated with the application default credentials
+// (the Workload Identity of the serviceaccount on GKE, or GOOGLE_APPLICATION_CREDENTIALS), nil if the export is
+// disabled. The project defaults to that of the credentials. With a cluster name & location the series are
+// written for the k8s_cluster resource, for the global one otherwise
+func newCloudMonitoringPublisher(ctx context.Context, enabled bool, project string, cluster string, location string, prefix string, timeout time.Duration) (*cloudMonitoringPublisher, error) {
+	if !enabled {
+		return nil, nil
+	}
+	credentials, err := google.FindDefaultCredentials(ctx, cloudMonitoringScope)
+	if err != nil {
+		return nil, err
+	}
+	if project == "" {
+		project = credentials.ProjectID
+	}
+	if project == "" {
+		return nil, fmt.Errorf("the project could not be found from the credentials, set --gcp.project")
+	}
+
+	resource := sinks.CloudMonitoringResource{Type: "global", Labels: map[string]string{"project_id": project}}
+	if cluster != "" {
+		resource = sinks.CloudMonitoringResource{Type: "k8s_cluster", Labels: map[string]string{
+			"project_id": project, "location": location, "cluster_name": cluster,
+		}}
+	}
+	client := &http.Client{
+		Transport: &oauth2.Transport{Source: credentials.TokenSource, Base: http.DefaultTransport},
+		Timeout:   timeout,
+	}
+	return &cloudMonitoringPublisher{
+		client: &sinks.CloudMonitoring{Project: project, Resource: resource, Client: client},
+		prefix: prefix,
+	}, nil
+}
+
+// publish writes the gauges of the last collection of every chaosengine, e.g. the experiment verdicts SLOs are
+// defined on. Called after each collection pass, it does nothing unless the export is enabled
+func (p *cloudMonitoringPublisher) publish(ctx context.Context) {
+	if p == nil {
+		return
+	}
+	now := exporterClock.Now()
+	if now.Sub(p.written) < cloudMonitoringMinInterval {
+		return
+	}
+	_, engines, err := gatherEngineFamilies()
+	if err != nil {
+		recordCloudMonitoringWrite(err)
+		return
+	}
+	var series []sinks.CloudMonitoringSeries
+	for _, families := range engines {
+		for _, family := range families {
+			if family.GetType() != dto.MetricType_GAUGE {
+				continue
+			}
+			for _, metric := range family.Metric {
+				labels := make(map[string]string, len(metric.Label))
+				for _, label := range metric.Label {
+					labels[label.GetName()] = label.GetValue()
+				}
+				series = append(series, sinks.CloudMonitoringSeries{
+					MetricType: p.prefix + "/" + family.GetName(),
+					Labels:     labels,
+					Value:      metric.GetGauge().GetValue(),
+				})
+			}
+		}
+	}
+	if len(series) == 0 {
+		return
+	}
+	p.written = now
+	recordCloudMonitoringWrite(p.client.WriteTimeSeries(ctx, series, now))
+}
+
+// recordCloudMonitoringWrite counts the outcome of a write, logging failures
+func recordCloudMonitoringWrite(err error) {
+	if err == nil {
+		cloudMonitoringWrites.WithLabelValues("success").Inc()
+		return
+	}
+	cloudMonitoringWrites.WithLabelValues("failure").Inc()
+	if logger := logSampling.sample(exporterLog, "cloudmonitoring"); logger != nil {
+		logger.Warn("Unable to write the metrics to Cloud Monitoring: ", err)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/sinks"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Holds the settings of the Graphite output, an empty address disables it
+var (
+	graphiteAddress  string
+	graphitePrefix   string
+	graphiteInterval time.Duration
+	graphiteTimeout  time.Duration
+)
+
+// runGraphiteFlush sends the families of gatherer, but those of the Go & process collectors, to carbon every
+// interval, until ctx is done
+func runGraphiteFlush(ctx context.Context, client *sinks.Graphite, interval time.Duration, gatherer prometheus.Gatherer) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-exporterClock.After(interval):
+		}
+		result := "success"
+		if err := flushGraphite(ctx, client, gatherer); err != nil {
+			if logger := logSampling.sample(exporterLog, "graphite"); logger != nil {
+				logger.Warn("Unable to send the metrics to Graphite: ", err)
+			}
+			result = "failure"
+		}
+		graphiteFlushes.WithLabelValues(result).Inc()
+	}
+}
+
+// flushGraphite sends the chaos metric families of gatherer once
+func flushGraphite(ctx context.Context, client *sinks.Graphite, gatherer prometheus.Gatherer) error {
+	families, err := gatherChaosFamilies(gatherer)
+	if err != nil {
+		return err
+	}
+	return client.Send(ctx, families, exporterClock.Now())
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+// Set to 1 once a collection pass succeeded, and once this replica collects (always without leader election)
+var (
+	collectionSucceeded int32
+	collectingReplica   int32
+)
+
+// healthzHandler reports the exporter alive as long as it serves HTTP, for the liveness probe
+func healthzHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, "ok")
+}
+
+// registerHealth registers the probe endpoints on mux
+func registerHealth(mux instrumentedMux) {
+	mux.HandleFunc("/healthz", healthzHandler)
+	mux.HandleFunc("/readyz", readyzHandler)
+}
+
+// readyzHandler reports the exporter ready once a collection pass succeeded, so that a freshly started replica
+// is not scraped before it exposes any chaos metric. The Kubernetes client is initialized by then. Standby
+// replicas are ready as is, they only expose the exporter's own series until elected
+func readyzHandler(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case atomic.LoadInt32(&collectionSucceeded) == 1:
+		fmt.Fprintln(w, "ok")
+	case leaderElect && atomic.LoadInt32(&collectingReplica) == 0:
+		fmt.Fprintln(w, "ok, standby")
+	default:
+		http.Error(w, "waiting for the first collection", http.StatusServiceUnavailable)
+	}
+}
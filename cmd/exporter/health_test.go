@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestReadyz(t *testing.T) {
+	defer func() {
+		atomic.StoreInt32(&collectionSucceeded, 0)
+		atomic.StoreInt32(&collectingReplica, 0)
+		leaderElect = false
+	}()
+	status := func() int {
+		w := httptest.NewRecorder()
+		readyzHandler(w, httptest.NewRequest("GET", "/readyz", nil))
+		return w.Code
+	}
+
+	if code := status(); code != http.StatusServiceUnavailable {
+		t.Errorf("expected not ready before the first collection, got %d", code)
+	}
+	leaderElect = true
+	if code := status(); code != http.StatusOK {
+		t.Errorf("expected a standby replica to be ready, got %d", code)
+	}
+	atomic.StoreInt32(&collectingReplica, 1)
+	if code := status(); code != http.StatusServiceUnavailable {
+		t.Errorf("expected the elected replica not to be ready before the first collection, got %d", code)
+	}
+	atomic.StoreInt32(&collectionSucceeded, 1)
+	if code := status(); code != http.StatusOK {
+		t.Errorf("expected ready after the first collection, got %d", code)
+	}
+}
+
+func TestRegisterHealth(t *testing.T) {
+	mux := newInstrumentedMux()
+	registerHealth(mux)
+	for path, code := range map[string]int{"/healthz": http.StatusOK, "/readyz": http.StatusServiceUnavailable, "/metrics": http.StatusNotFound} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != code {
+			t.Errorf("expected %s to answer %d, got %d", path, code, w.Code)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/credentials"
+	"github.com/litmuschaos/chaos-exporter/pkg/sinks"
+)
+
+// Holds the settings of the incident creation, an empty provider disables it
+var (
+	incidentProvider  string
+	incidentKey       string
+	incidentURL       string
+	incidentThreshold int
+	incidentTimeout   time.Duration
+)
+
+// Holds the incident manager, nil unless the incident creation is enabled
+var incidentManager sinks.IncidentManager
+
+// newIncidentManager returns the manager of the configured provider, pagerduty or opsgenie, nil if none is
+// configured. The key is a credential reference of the PagerDuty integration key or of the Opsgenie API key
+func newIncidentManager(provider string, keyRef string, address string, threshold int, timeout time.Duration) (sinks.IncidentManager, error) {
+	if provider == "" {
+		return nil, nil
+	}
+	if keyRef == "" {
+		return nil, fmt.Errorf("the key is required along with the provider")
+	}
+	if threshold < 1 {
+		return nil, fmt.Errorf("expected a threshold of at least 1 failure, got %d", threshold)
+	}
+	if err := validatePushURL(address); err != nil {
+		return nil, err
+	}
+	key, err := credentials.New(keyRef)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: timeout}
+	switch provider {
+	case "pagerduty":
+		return &sinks.PagerDuty{URL: address, RoutingKey: key, Client: client}, nil
+	case "opsgenie":
+		return &sinks.Opsgenie{URL: address, APIKey: key, Client: client}, nil
+	}
+	return nil, fmt.Errorf("invalid provider %q, expected pagerduty or opsgenie", provider)
+}
+
+// incidentTracker counts the consecutive experiment failures of every chaosengine, keyed by
+// <namespace>/<engine>, to open an incident once they reach the threshold & close it on the next pass
+type incidentTracker struct {
+	threshold int
+	failures  map[string]int
+	open      map[string]bool
+}
+
+func newIncidentTracker(threshold int) *incidentTracker {
+	return &incidentTracker{threshold: threshold, failures: make(map[string]int), open: make(map[string]bool)}
+}
+
+// observe returns the action event calls for on the incident of its engine, open, close or none
+func (t *incidentTracker) observe(event verdictEvent) string {
+	engine := event.Namespace + "/" + event.Engine
+	switch event.To {
+	case "fail":
+		t.failures[engine]++
+		if t.failures[engine] >= t.threshold && !t.open[engine] {
+			return "open"
+		}
+	case "pass":
+		delete(t.failures, engine)
+		if t.open[engine] {
+			return "close"
+		}
+	}
+	return ""
+}
+
+// runIncidents opens an incident when the experiments of a chaosengine fail threshold times in a row, closing it
+// when one of them passes next, until ctx is done. Failed requests are retried on the next failure or pass
+func runIncidents(ctx context.Context, manager sinks.IncidentManager, threshold int) {
+	events, unsubscribe := verdictEvents.subscribe()
+	defer unsubscribe()
+	tracker := newIncidentTracker(threshold)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			engine := event.Namespace + "/" + event.Engine
+			key := "litmuschaos/" + engine
+			switch tracker.observe(event) {
+			case "open":
+				err := manager.Open(ctx, sinks.Incident{
+					Key:     key,
+					Summary: fmt.Sprintf("Chaos experiments of %s failed %d times in a row", engine, tracker.failures[engine]),
+					Source:  engine,
+					Details: map[string]string{
+						"namespace":  event.Namespace,
+						"engine":     event.Engine,
+						"experiment": event.Experiment,
+						"failStep":   event.FailStep,
+						"failures":   fmt.Sprint(tracker.failures[engine]),
+					},
+				})
+				tracker.open[engine] = err == nil
+				recordIncidentRequest("open", err)
+			case "close":
+				err := manager.Close(ctx, key)
+				tracker.open[engine] = err != nil
+				recordIncidentRequest("close", err)
+			}
+		}
+	}
+}
+
+// recordIncidentRequest counts the outcome of a request to the incident management service, logging failures
+func recordIncidentRequest(request string, err error) {
+	if err == nil {
+		incidentRequests.WithLabelValues(request, "success").Inc()
+		return
+	}
+	incidentRequests.WithLabelValues(request, "failure").Inc()
+	if logger := logSampling.sample(exporterLog, "incidents/"+request); logger != nil {
+		logger.Warnf("Unable to %s the incident: %v", request, err)
+	}
+}
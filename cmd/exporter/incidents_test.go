@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestIncidentTracker(t *testing.T) {
+	tracker := newIncidentTracker(2)
+	event := func(engine string, to string) verdictEvent {
+		return verdictEvent{Namespace: "litmus", Engine: engine, Experiment: "pod-delete", To: to}
+	}
+	steps := []struct {
+		event    verdictEvent
+		expected string
+	}{
+		{event("engine-nginx", "fail"), ""},
+		{event("engine-nginx", "running"), ""},
+		{event("engine-redis", "fail"), ""},
+		{event("engine-nginx", "fail"), "open"},
+		{event("engine-redis", "pass"), ""},
+		{event("engine-nginx", "pass"), "close"},
+	}
+	for i, step := range steps {
+		action := tracker.observe(step.event)
+		if action != step.expected {
+			t.Errorf("step %d: expected action %q, got %q", i, step.expected, action)
+		}
+		if action != "" {
+			tracker.open["litmus/"+step.event.Engine] = action == "open"
+		}
+	}
+	if tracker.failures["litmus/engine-nginx"] != 0 {
+		t.Errorf("expected the failures reset by the pass, got %d", tracker.failures["litmus/engine-nginx"])
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/credentials"
+	"github.com/litmuschaos/chaos-exporter/pkg/sinks"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Holds the settings of the InfluxDB output, an empty URL disables it
+var (
+	influxURL     string
+	influxOrg     string
+	influxBucket  string
+	influxToken   string
+	influxTimeout time.Duration
+)
+
+// Holds the InfluxDB sink, nil unless the output is enabled
+var influxSink *sinks.InfluxDB
+
+// newInfluxSink returns the InfluxDB v2 sink of the configured bucket, nil if no URL is configured. The token is
+// a credential reference, for e.g. env:INFLUX_TOKEN set from a Secret
+func newInfluxSink(address string, org string, bucket string, tokenRef string, timeout time.Duration) (*sinks.InfluxDB, error) {
+	if address == "" {
+		return nil, nil
+	}
+	if err := validatePushURL(address); err != nil {
+		return nil, err
+	}
+	if org == "" || bucket == "" {
+		return nil, fmt.Errorf("the org & bucket are required along with the URL")
+	}
+	sink := &sinks.InfluxDB{URL: address, Org: org, Bucket: bucket, Client: &http.Client{Timeout: timeout}}
+	if tokenRef != "" {
+		token, err := credentials.New(tokenRef)
+		if err != nil {
+			return nil, err
+		}
+		sink.Token = token
+	}
+	return sink, nil
+}
+
+// writeInfluxPoints writes the series of the last collection of every chaosengine to InfluxDB, a point per
+// series measured as its metric, tagged with its labels & holding its value as the value field. Called after
+// each collection pass, it does nothing unless the output is enabled
+func writeInfluxPoints(ctx context.Context, sink *sinks.InfluxDB) {
+	if sink == nil {
+		return
+	}
+	_, engines, err := gatherEngineFamilies()
+	if err != nil {
+		recordInfluxWrite(err)
+		return
+	}
+	var points []sinks.InfluxPoint
+	for _, families := range engines {
+		for _, family := range families {
+			if family.GetType() != dto.MetricType_GAUGE {
+				continue
+			}
+			for _, metric := range family.Metric {
+				value := metric.GetGauge().GetValue()
+				if math.IsNaN(value) || math.IsInf(value, 0) {
+					// Not representable in the line protocol
+					continue
+				}
+				point := sinks.InfluxPoint{Measurement: family.GetName(), Fields: map[string]float64{"value": value}}
+				for _, label := range metric.Label {
+					point.Tags = append(point.Tags, sinks.Tag{Name: label.GetName(), Value: label.GetValue()})
+				}
+				points = append(points, point)
+			}
+		}
+	}
+	if len(points) == 0 {
+		return
+	}
+	recordInfluxWrite(sink.Write(ctx, points, exporterClock.Now()))
+}
+
+// recordInfluxWrite counts the outcome of a write, logging failures
+func recordInfluxWrite(err error) {
+	if err == nil {
+		influxWrites.WithLabelValues("success").Inc()
+		return
+	}
+	influxWrites.WithLabelValues("failure").Inc()
+	if logger := logSampling.sample(exporterLog, "influxdb"); logger != nil {
+		logger.Warn("Unable to write the metrics to InfluxDB: ", err)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	clientV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/clientset/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Create Events on the chaosengines for the verdict transitions of their experiments
+var kubernetesEvents bool
+
+// Reasons of the Events of the verdicts an experiment transitions to, ExperimentStateChanged for the others
+var kubernetesEventReasons = map[string]string{
+	"pass":    "ExperimentPassed",
+	"fail":    "ExperimentFailed",
+	"running": "ExperimentRunning",
+}
+
+// engineEventWriter looks up the chaosengines and creates the Events involving them
+type engineEventWriter interface {
+	engineUID(ns string, name string) (types.UID, error)
+	create(event *corev1.Event) (*corev1.Event, error)
+}
+
+// kubeEngineEvents creates the Events in the apiserver
+type kubeEngineEvents struct {
+	client  kubernetes.Interface
+	engines *clientV1alpha1.ExampleV1Alpha1Client
+}
+
+func newKubeEngineEvents(cfg *rest.Config) (*kubeEngineEvents, error) {
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	engines, err := clientV1alpha1.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &kubeEngineEvents{client: client, engines: engines}, nil
+}
+
+func (k *kubeEngineEvents) engineUID(ns string, name string) (types.UID, error) {
+	engine, err := k.engines.ChaosEngines(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	return engine.UID, nil
+}
+
+func (k *kubeEngineEvents) create(event *corev1.Event) (*corev1.Event, error) {
+	return k.client.CoreV1().Events(event.Namespace).Create(event)
+}
+
+// runKubernetesEvents creates an Event on the chaosengine of every verdict transition until ctx is done, so that
+// kubectl describe chaosengine lists the transitions the metrics show
+func runKubernetesEvents(ctx context.Context, writer engineEventWriter) {
+	events, unsubscribe := verdictEvents.subscribe()
+	defer unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			err := createEngineEvent(writer, event)
+			if err != nil {
+				if logger := logSampling.sample(engineLogger(event.Namespace, event.Engine), "k8sevents"); logger != nil {
+					logger.Warn("Unable to create the Event of the verdict transition: ", err)
+				}
+			}
+		}
+	}
+}
+
+// createEngineEvent creates the Event of a verdict transition on its chaosengine
+func createEngineEvent(writer engineEventWriter, event verdictEvent) error {
+	uid, err := writer.engineUID(event.Namespace, event.Engine)
+	if err != nil {
+		return err
+	}
+	engines, _ := clientV1alpha1.Resources()
+	reason, ok := kubernetesEventReasons[event.To]
+	if !ok {
+		reason = "ExperimentStateChanged"
+	}
+	eventType := corev1.EventTypeNormal
+	if event.To == "fail" {
+		eventType = corev1.EventTypeWarning
+	}
+	message := fmt.Sprintf("Experiment %s changed from %s to %s", event.Experiment, event.From, event.To)
+	if event.FailStep != "" {
+		message += ", failed at: " + event.FailStep
+	}
+	observed := metav1.NewTime(event.Time)
+	_, err = writer.create(&corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s.%x", event.Engine, event.Experiment, event.Time.UnixNano()),
+			Namespace: event.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:       "ChaosEngine",
+			APIVersion: engines.GroupVersion().String(),
+			Namespace:  event.Namespace,
+			Name:       event.Engine,
+			UID:        uid,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: "chaos-exporter"},
+		FirstTimestamp: observed,
+		LastTimestamp:  observed,
+		Count:          1,
+	})
+	return err
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// fakeEngineEvents records the Events created on the engines of uids
+type fakeEngineEvents struct {
+	uids    map[string]types.UID
+	created []*corev1.Event
+}
+
+func (f *fakeEngineEvents) engineUID(ns string, name string) (types.UID, error) {
+	uid, ok := f.uids[ns+"/"+name]
+	if !ok {
+		return "", fmt.Errorf("chaosengine %s/%s not found", ns, name)
+	}
+	return uid, nil
+}
+
+func (f *fakeEngineEvents) create(event *corev1.Event) (*corev1.Event, error) {
+	f.created = append(f.created, event)
+	return event, nil
+}
+
+func TestCreateEngineEvent(t *testing.T) {
+	writer := &fakeEngineEvents{uids: map[string]types.UID{"litmus/engine-nginx": "b0e7c0d2"}}
+	failed := verdictEvent{Namespace: "litmus", Engine: "engine-nginx", Experiment: "pod-delete", From: "running", To: "fail", FailStep: "Unable to get the application pods", Time: time.Unix(60, 0)}
+	if err := createEngineEvent(writer, failed); err != nil {
+		t.Fatal(err)
+	}
+	if err := createEngineEvent(writer, verdictEvent{Namespace: "default", Engine: "engine-gone", To: "pass"}); err == nil {
+		t.Error("expected an error for an engine not found")
+	}
+	if len(writer.created) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(writer.created))
+	}
+	event := writer.created[0]
+	if event.Reason != "ExperimentFailed" || event.Type != corev1.EventTypeWarning || event.InvolvedObject.UID != "b0e7c0d2" || event.InvolvedObject.Kind != "ChaosEngine" {
+		t.Errorf("unexpected event %+v", event)
+	}
+	if expected := "Experiment pod-delete changed from running to fail, failed at: Unable to get the application pods"; event.Message != expected {
+		t.Errorf("expected message %q, got %q", expected, event.Message)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/sinks"
+)
+
+// Holds the settings of the Kafka publishing, an empty REST Proxy URL disables it
+var (
+	kafkaRESTURL string
+	kafkaTopic   string
+	kafkaFormat  string
+	kafkaTimeout time.Duration
+)
+
+// Holds the Kafka sink, nil unless the publishing is enabled
+var kafkaSink *sinks.KafkaRESTProxy
+
+// Avro schema of the verdict events, the times are in milliseconds since the epoch
+const kafkaVerdictSchema = `{"type":"record","name":"VerdictEvent","namespace":"io.litmuschaos.exporter","fields":[` +
+	`{"name":"namespace","type":"string"},{"name":"engine","type":"string"},{"name":"experiment","type":"string"},` +
+	`{"name":"from","type":"string"},{"name":"to","type":"string"},` +
+	`{"name":"time","type":{"type":"long","logicalType":"timestamp-millis"}},` +
+	`{"name":"since","type":{"type":"long","logicalType":"timestamp-millis"}},` +
+	`{"name":"failStep","type":"string","default":""}]}`
+
+// newKafkaSink returns the sink producing to the configured topic through the REST Proxy, nil if no URL is
+// configured
+func newKafkaSink(address string, topic string, format string, timeout time.Duration) (*sinks.KafkaRESTProxy, error) {
+	if address == "" {
+		return nil, nil
+	}
+	if err := validatePushURL(address); err != nil {
+		return nil, err
+	}
+	if topic == "" {
+		return nil, fmt.Errorf("the topic is required along with the URL")
+	}
+	sink := &sinks.KafkaRESTProxy{URL: address, Topic: topic, Client: &http.Client{Timeout: timeout}}
+	switch format {
+	case "json":
+	case "avro":
+		sink.ValueSchema = kafkaVerdictSchema
+	default:
+		return nil, fmt.Errorf("invalid format %q, expected json or avro", format)
+	}
+	return sink, nil
+}
+
+// runKafkaEvents produces every verdict transition to the Kafka topic until ctx is done, keyed by the
+// experiment so that the transitions of an experiment are kept in order
+func runKafkaEvents(ctx context.Context, sink *sinks.KafkaRESTProxy) {
+	events, unsubscribe := verdictEvents.subscribe()
+	defer unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			record := sinks.KafkaRecord{Key: event.Namespace + "/" + event.Engine + "/" + event.Experiment, Value: event}
+			if sink.ValueSchema != "" {
+				record.Value = kafkaAvroValue(event)
+			}
+			recordKafkaEvent(sink.Produce(ctx, []sinks.KafkaRecord{record}))
+		}
+	}
+}
+
+// kafkaAvroValue returns event in the JSON encoding of kafkaVerdictSchema
+func kafkaAvroValue(event verdictEvent) map[string]interface{} {
+	millis := func(t time.Time) int64 {
+		if t.IsZero() {
+			return 0
+		}
+		return t.UnixNano() / int64(time.Millisecond)
+	}
+	return map[string]interface{}{
+		"namespace":  event.Namespace,
+		"engine":     event.Engine,
+		"experiment": event.Experiment,
+		"from":       event.From,
+		"to":         event.To,
+		"time":       millis(event.Time),
+		"since":      millis(event.Since),
+		"failStep":   event.FailStep,
+	}
+}
+
+// recordKafkaEvent counts the outcome of the publishing of an event, logging failures
+func recordKafkaEvent(err error) {
+	if err == nil {
+		kafkaEvents.WithLabelValues("success").Inc()
+		return
+	}
+	kafkaEvents.WithLabelValues("failure").Inc()
+	if logger := logSampling.sample(exporterLog, "kafka"); logger != nil {
+		logger.Warn("Unable to publish the verdict event to Kafka: ", err)
+	}
+}
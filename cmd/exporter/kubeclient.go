@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	exporterapis "github.com/litmuschaos/chaos-exporter/pkg/apis"
+	"github.com/litmuschaos/chaos-exporter/pkg/chaosmetrics"
+	clientV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/clientset/v1alpha1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Path to the kubeconfig file, the in-cluster config is used if unset
+var kubeconfig string
+
+// Context of the kubeconfig file to use, its current context if unset
+var kubeContext string
+
+// User & comma separated groups the apiserver requests are impersonated as, none if unset
+var impersonateUser, impersonateGroups string
+
+// Client side rate limit & request timeout of the apiserver requests, 0 keeps the client-go defaults
+var (
+	kubeQPS     float64
+	kubeBurst   int
+	kubeTimeout time.Duration
+)
+
+// Holds the last measured offset of the apiserver clock from the local clock, in nanoseconds
+var apiserverSkew int64
+
+// loadConfig builds the client config from the kubeconfig file (in kubeContext if set), or the in-cluster
+// config if none is set. Both are read from disk, so that a reload picks up rotated credentials
+func loadConfig() (*rest.Config, error) {
+	var cfg *rest.Config
+	var err error
+	if kubeconfig == "" {
+		cfg, err = rest.InClusterConfig()
+	} else {
+		cfg, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
+			&clientcmd.ConfigOverrides{CurrentContext: kubeContext},
+		).ClientConfig()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if impersonateUser != "" {
+		cfg.Impersonate = rest.ImpersonationConfig{UserName: impersonateUser}
+		for _, group := range strings.Split(impersonateGroups, ",") {
+			if group = strings.TrimSpace(group); group != "" {
+				cfg.Impersonate.Groups = append(cfg.Impersonate.Groups, group)
+			}
+		}
+	}
+	cfg.QPS = float32(kubeQPS)
+	cfg.Burst = kubeBurst
+	cfg.Timeout = kubeTimeout
+	return cfg, nil
+}
+
+// reloadRejectedConfig reloads the client config if the apiserver rejected the credentials of one of errs, the
+// token having likely expired (rotated kubeconfig or projected serviceaccount token). It returns nil if none
+// was rejected or the config cannot be reloaded
+func reloadRejectedConfig(errs []error) *rest.Config {
+	unauthorized := false
+	for _, err := range errs {
+		unauthorized = unauthorized || k8serrors.IsUnauthorized(err)
+	}
+	if !unauthorized {
+		return nil
+	}
+	reloaded, err := loadConfig()
+	if err != nil {
+		log.Error("Unable to reload the client config: ", err.Error())
+		return nil
+	}
+	log.Info("apiserver rejected the credentials, rebuilt the clients from a reloaded config")
+	return reloaded
+}
+
+// parseResource parses a resource given as resource.version.group, for e.g. chaosengines.v1alpha1.litmuschaos.io
+func parseResource(arg string) (schema.GroupVersionResource, error) {
+	resource, _ := schema.ParseResourceArg(arg)
+	if resource == nil || resource.Resource == "" || resource.Version == "" || resource.Group == "" {
+		return schema.GroupVersionResource{}, fmt.Errorf("%q is not of the form resource.version.group", arg)
+	}
+	return *resource, nil
+}
+
+// updateClockSkew measures the offset of the apiserver clock, retaining the previous measurement on failure
+func updateClockSkew(ctx context.Context, cfg *rest.Config) {
+	skew, err := chaosmetrics.GetClockSkew(ctx, cfg)
+	if err != nil {
+		log.Debug("Unable to measure the apiserver clock skew: ", err.Error())
+		return
+	}
+	if skew != time.Duration(atomic.SwapInt64(&apiserverSkew, int64(skew))) && skew != 0 {
+		log.Infof("apiserver clock skew is %s, positive when ahead of the local clock", skew)
+	}
+	clockSkew.Set(skew.Seconds())
+}
+
+// apiserverClock is the local clock corrected by the measured apiserver clock skew
+type apiserverClock struct {
+	clock.RealClock
+}
+
+// Now returns the current apiserver time
+func (apiserverClock) Now() time.Time {
+	return time.Now().Add(time.Duration(atomic.LoadInt64(&apiserverSkew)))
+}
+
+// Since returns the apiserver time elapsed since ts
+func (c apiserverClock) Since(ts time.Time) time.Duration {
+	return c.Now().Sub(ts)
+}
+
+// setupCluster checks the cluster settings and, unless validateOnly is set, builds the client config from them
+func setupCluster(validateOnly bool, problems *configProblems) {
+	if impersonateGroups != "" && impersonateUser == "" {
+		problems.add("--as-group (CHAOS_EXPORTER_AS_GROUP)", "requires --as, groups cannot be impersonated without a user")
+	}
+	if kubeContext != "" && kubeconfig == "" {
+		problems.add("--kube-context (CHAOS_EXPORTER_KUBE_CONTEXT)", "requires --kubeconfig, the in-cluster config has no contexts")
+	}
+	// Validation does not connect to the cluster
+	if validateOnly {
+		return
+	}
+	if kubeconfig == "" {
+		log.Info("using the in-cluster config")
+	} else if kubeContext != "" {
+		log.Infof("using context %s of the configuration from: %s", kubeContext, kubeconfig)
+	} else {
+		log.Info("using configuration from: ", kubeconfig)
+	}
+	if impersonateUser != "" {
+		log.Infof("impersonating user %s, groups [%s]", impersonateUser, impersonateGroups)
+	}
+	var err error
+	config, err = loadConfig()
+	problems.addErr("--kubeconfig, --kube-context & --as (CHAOS_EXPORTER_KUBECONFIG)", err)
+}
+
+// setupChaosResources sets the resources the chaosengines & chaosresults are read from, for forked or renamed
+// CRDs. Otherwise, unless validateOnly is set, it checks that they are served in a version the exporter can decode
+func setupChaosResources(engineResourceArg string, resultResourceArg string, validateOnly bool, problems *configProblems) {
+	// Register the exporter's own custom resources
+	if err := exporterapis.AddToScheme(scheme.Scheme); err != nil {
+		log.Fatal("Unable to register the exporter types: ", err)
+	}
+
+	if engineResourceArg != "" || resultResourceArg != "" {
+		// Forked or renamed CRDs, read from the given resources as is
+		engines, results := clientV1alpha1.Resources()
+		if engineResourceArg != "" {
+			parsed, err := parseResource(engineResourceArg)
+			problems.addErr("--engine-resource (ENGINE_RESOURCE)", err)
+			if err == nil {
+				engines, results = parsed, parsed.GroupVersion().WithResource(results.Resource)
+			}
+		}
+		if resultResourceArg != "" {
+			parsed, err := parseResource(resultResourceArg)
+			problems.addErr("--result-resource (RESULT_RESOURCE)", err)
+			if err == nil {
+				results = parsed
+			}
+		}
+		clientV1alpha1.SetResources(engines, results)
+		log.Infof("Reading chaosengines from %s, chaosresults from %s", engines, results)
+	} else if !validateOnly && config != nil {
+		apiVersion, served, err := chaosmetrics.DetectAPIVersion(config)
+		problems.addErr("--engine-resource (ENGINE_RESOURCE)", err)
+		if err == nil {
+			log.Infof("litmuschaos.io served in %s, reading %s", strings.Join(served, ", "), apiVersion)
+		}
+	}
+}
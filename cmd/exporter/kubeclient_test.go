@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestParseResource(t *testing.T) {
+	resource, err := parseResource("chaosengines.v1beta1.chaos.example.com")
+	if err != nil || resource.Resource != "chaosengines" || resource.Version != "v1beta1" || resource.Group != "chaos.example.com" {
+		t.Errorf("unexpected resource %v, %v", resource, err)
+	}
+	for _, arg := range []string{"", "chaosengines", "chaosengines.litmuschaos"} {
+		if _, err := parseResource(arg); err == nil {
+			t.Errorf("expected %q to be rejected", arg)
+		}
+	}
+}
+
+func TestLoadConfigClientLimits(t *testing.T) {
+	file, err := ioutil.TempFile("", "chaos-exporter-kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString(`apiVersion: v1
+kind: Config
+clusters:
+- name: litmus
+  cluster:
+    server: https://litmus.example:6443
+contexts:
+- name: litmus
+  context:
+    cluster: litmus
+current-context: litmus
+`)
+	file.Close()
+
+	kubeconfig, kubeQPS, kubeBurst, kubeTimeout = file.Name(), 50, 100, 15*time.Second
+	defer func() {
+		kubeconfig, kubeQPS, kubeBurst, kubeTimeout = "", 0, 0, 0
+	}()
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "https://litmus.example:6443" || cfg.QPS != 50 || cfg.Burst != 100 || cfg.Timeout != 15*time.Second {
+		t.Errorf("expected the client limits to be applied, got host %s, qps %v, burst %d & timeout %s", cfg.Host, cfg.QPS, cfg.Burst, cfg.Timeout)
+	}
+}
+
+func TestReloadRejectedConfig(t *testing.T) {
+	file, err := ioutil.TempFile("", "chaos-exporter-kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	// The kubeconfig holds the rotated token by the time the apiserver rejects the expired one
+	file.WriteString(`apiVersion: v1
+kind: Config
+clusters:
+- name: litmus
+  cluster:
+    server: https://litmus.example:6443
+users:
+- name: exporter
+  user:
+    token: rotated
+contexts:
+- name: litmus
+  context:
+    cluster: litmus
+    user: exporter
+current-context: litmus
+`)
+	file.Close()
+	kubeconfig = file.Name()
+	defer func() { kubeconfig = "" }()
+
+	if cfg := reloadRejectedConfig([]error{nil, errors.New("connection refused")}); cfg != nil {
+		t.Errorf("expected the config to be kept unless the credentials are rejected, got %+v", cfg)
+	}
+	cfg := reloadRejectedConfig([]error{nil, k8serrors.NewUnauthorized("token expired")})
+	if cfg == nil || cfg.BearerToken != "rotated" {
+		t.Errorf("expected the rotated token to be reloaded, got %+v", cfg)
+	}
+}
+
+func TestLoadConfigContext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config")
+	if err := ioutil.WriteFile(path, []byte(`apiVersion: v1
+kind: Config
+current-context: staging
+clusters:
+- name: staging
+  cluster: {server: "https://staging.example.com"}
+- name: production
+  cluster: {server: "https://production.example.com"}
+users:
+- name: ci
+  user: {token: secret}
+contexts:
+- name: staging
+  context: {cluster: staging, user: ci}
+- name: production
+  context: {cluster: production, user: ci}
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { kubeconfig, kubeContext = "", "" }()
+
+	kubeconfig = path
+	for context, host := range map[string]string{"": "https://staging.example.com", "production": "https://production.example.com"} {
+		kubeContext = context
+		cfg, err := loadConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Host != host {
+			t.Errorf("expected %s in context %q, got %s", host, context, cfg.Host)
+		}
+	}
+	kubeContext = "development"
+	if _, err := loadConfig(); err == nil {
+		t.Error("expected an error for a context missing from the kubeconfig")
+	}
+}
+
+func TestLoadConfigImpersonation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config")
+	if err := ioutil.WriteFile(path, []byte(`apiVersion: v1
+kind: Config
+current-context: staging
+clusters:
+- name: staging
+  cluster: {server: "https://staging.example.com"}
+users:
+- name: ci
+  user: {token: secret}
+contexts:
+- name: staging
+  context: {cluster: staging, user: ci}
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { kubeconfig, impersonateUser, impersonateGroups = "", "", "" }()
+
+	kubeconfig, impersonateUser, impersonateGroups = path, "auditor", "security, readers"
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Impersonate.UserName != "auditor" || !reflect.DeepEqual(cfg.Impersonate.Groups, []string{"security", "readers"}) {
+		t.Errorf("unexpected impersonation %+v", cfg.Impersonate)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+)
+
+// landingEndpoint is an endpoint listed by the landing page
+type landingEndpoint struct {
+	Path        string
+	Description string
+}
+
+var landingTemplate = template.Must(template.New("landing").Parse(`<html>
+<head><title>Litmus Chaos Exporter</title></head>
+<body>
+<h1>Litmus Chaos Exporter</h1>
+<ul>
+{{- range .Endpoints}}
+<li><a href="{{.Path}}">{{.Path}}</a> {{.Description}}</li>
+{{- end}}
+</ul>
+<h2>Build</h2>
+<table>
+{{- range .Info}}
+<tr><td>{{index . 0}}</td><td>{{index . 1}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// landingHandler serves an index of the endpoints at /, and the build info of the startup summary.
+// Other paths, which the / pattern also matches, are not found
+func landingHandler(endpoints []landingEndpoint) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		var info [][2]string
+		for _, field := range []string{"version", "goVersion", "mode", "kubernetesVersion", "openebsVersion"} {
+			if value, ok := startupSummary[field]; ok {
+				info = append(info, [2]string{field, fmt.Sprint(value)})
+			}
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		landingTemplate.Execute(w, map[string]interface{}{"Endpoints": endpoints, "Info": info})
+	}
+}
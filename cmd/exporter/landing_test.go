@@ -0,0 +1,29 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+func TestLandingHandler(t *testing.T) {
+	startupSummary = log.Fields{"mode": modeStandalone, "goVersion": "go1.13"}
+	defer func() { startupSummary = log.Fields{} }()
+	handler := landingHandler([]landingEndpoint{{Path: "/chaos/metrics", Description: "chaos metrics"}})
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/", nil))
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(body, `<a href="/chaos/metrics">`) || !strings.Contains(body, "go1.13") {
+		t.Errorf("unexpected landing page %d: %s", w.Code, body)
+	}
+
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected unknown paths to be not found, got %d", w.Code)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/leaderelection"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// newLeaderElector returns the elector for the Lease named by LEADER_ELECTION_ID (defaults to chaos-exporter)
+// in the exporter namespace. Replicas are identified by their pod name, passed as the POD_NAME ENV
+func newLeaderElector(cfg *rest.Config, namespace string) (*leaderelection.LeaderElector, error) {
+	clientSet, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := os.Getenv("POD_NAME")
+	if identity == "" {
+		if identity, err = os.Hostname(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &leaderelection.LeaderElector{
+		Client:        clientSet.CoordinationV1().Leases(getNamespaceEnv("LEADER_ELECTION_NAMESPACE", namespace)),
+		Name:          getNamespaceEnv("LEADER_ELECTION_ID", "chaos-exporter"),
+		Identity:      identity,
+		LeaseDuration: 15 * time.Second,
+		RetryPeriod:   2 * time.Second,
+	}, nil
+}
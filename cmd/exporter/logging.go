@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Formats of the logs, text for humans or json for Loki/ELK
+var logFormatters = map[string]func() log.Formatter{
+	"text": func() log.Formatter { return &log.TextFormatter{} },
+	"json": func() log.Formatter { return &log.JSONFormatter{} },
+}
+
+// configureLogging sets the level & format of logger, leaving it as is on error
+func configureLogging(logger *log.Logger, level string, format string) error {
+	parsed, err := log.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid level %q, expected debug, info, warn, error or fatal", level)
+	}
+	formatter, ok := logFormatters[format]
+	if !ok {
+		return fmt.Errorf("invalid format %q, expected text or json", format)
+	}
+	logger.SetLevel(parsed)
+	logger.SetFormatter(formatter())
+	return nil
+}
+
+// Holds the logger the exporter logs to, the standard logger unless replaced (e.g. by the tests)
+var exporterLog log.FieldLogger = log.StandardLogger()
+
+// engineLogger returns the logger of a chaosengine, attaching its namespace & name to every line. The
+// lines of an experiment add it with WithField("experiment", name)
+func engineLogger(namespace string, engine string) log.FieldLogger {
+	return exporterLog.WithFields(log.Fields{"namespace": namespace, "engine": engine})
+}
+
+// sampledMessage is the sampling state of a message
+type sampledMessage struct {
+	logged     time.Time
+	suppressed int
+}
+
+// logSampler logs a high-frequency message, e.g. logged on every collection pass, at most once per interval
+// per key (the message & the engine it is about), and counts the lines it drops in between
+type logSampler struct {
+	mu        sync.Mutex
+	interval  time.Duration
+	messages  map[string]*sampledMessage
+	lastSweep time.Time
+}
+
+// Holds the sampler of the high-frequency messages, set from --log.sample-interval
+var logSampling = newLogSampler(time.Minute)
+
+// newLogSampler returns a sampler logging a message once per interval, 0 disables the sampling
+func newLogSampler(interval time.Duration) *logSampler {
+	return &logSampler{interval: interval, messages: make(map[string]*sampledMessage)}
+}
+
+// sample returns the logger to log the message of key with, or nil if the line is to be dropped. The first
+// line logged after some were dropped carries their number as the suppressed field
+func (s *logSampler) sample(logger log.FieldLogger, key string) log.FieldLogger {
+	if s.interval <= 0 {
+		return logger
+	}
+	now := exporterClock.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Forget the messages no longer logged, e.g. those of deleted engines
+	if now.Sub(s.lastSweep) > s.interval {
+		for k, message := range s.messages {
+			if now.Sub(message.logged) > 2*s.interval {
+				delete(s.messages, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	message, ok := s.messages[key]
+	if !ok {
+		message = &sampledMessage{}
+		s.messages[key] = message
+	} else if now.Sub(message.logged) < s.interval {
+		message.suppressed++
+		return nil
+	}
+	suppressed := message.suppressed
+	message.logged, message.suppressed = now, 0
+	if suppressed > 0 {
+		return logger.WithField("suppressed", suppressed)
+	}
+	return logger
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestConfigureLogging(t *testing.T) {
+	logger := log.New()
+	if err := configureLogging(logger, "debug", "json"); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	logger.Out = &out
+	logger.WithField("engine", "engine-nginx").Debug("collected")
+	var entry map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a JSON log line, got %q: %v", out.String(), err)
+	}
+	if entry["level"] != "debug" || entry["msg"] != "collected" || entry["engine"] != "engine-nginx" {
+		t.Errorf("unexpected log entry %v", entry)
+	}
+
+	if err := configureLogging(logger, "verbose", "text"); err == nil {
+		t.Error("expected an invalid level error")
+	}
+	if err := configureLogging(logger, "info", "logfmt"); err == nil {
+		t.Error("expected an invalid format error")
+	}
+	if logger.Level != log.DebugLevel {
+		t.Errorf("expected the logger to be left as is, got level %s", logger.Level)
+	}
+}
+
+func TestEngineLogger(t *testing.T) {
+	var out bytes.Buffer
+	logger := log.New()
+	logger.Out = &out
+	logger.Formatter = &log.JSONFormatter{}
+	exporterLog = logger
+	defer func() { exporterLog = log.StandardLogger() }()
+	fakeClock := clock.NewFakeClock(time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC))
+	defer func(c clock.Clock) { exporterClock = c }(exporterClock)
+	exporterClock = fakeClock
+
+	sampler := newLogSampler(time.Minute)
+	for i := 0; i < 3; i++ {
+		if logger := sampler.sample(engineLogger("litmus", "engine-nginx").WithField("experiment", "pod-delete"), "invalid/litmus/engine-nginx"); logger != nil {
+			logger.Warn("chaosengine is invalid")
+		}
+	}
+	fakeClock.Step(time.Minute)
+	if logger := sampler.sample(engineLogger("litmus", "engine-nginx"), "invalid/litmus/engine-nginx"); logger != nil {
+		logger.Warn("chaosengine is invalid")
+	}
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected the repeated lines to be sampled, got %q", out.String())
+	}
+	if entries[0]["namespace"] != "litmus" || entries[0]["engine"] != "engine-nginx" || entries[0]["experiment"] != "pod-delete" {
+		t.Errorf("expected the engine fields, got %v", entries[0])
+	}
+	if entries[1]["suppressed"] != 2.0 {
+		t.Errorf("expected the number of suppressed lines, got %v", entries[1])
+	}
+}
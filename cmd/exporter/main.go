@@ -21,184 +21,350 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
-	//"fmt"
-	"flag"
-	"net/http"
-	"strings"
-
 	log "github.com/Sirupsen/logrus"
 	"github.com/litmuschaos/chaos-exporter/pkg/chaosmetrics"
 	"github.com/litmuschaos/chaos-exporter/pkg/version"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
-// Declare general variables (cluster ops, error handling, misc)
-var kubeconfig string
+// Label selector of the chaosengines to monitor, all of them if unset
+var engineSelector string
+
 var config *rest.Config
 var err error
-var registeredResultMetrics []string
-
-// Declare the fixed chaos metrics. Dynamic (testStatus) metrics are defined in metrics()
-var (
-	experimentsTotal = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "c",
-		Subsystem: "engine",
-		Name:      "experiment_count",
-		Help:      "Total number of experiments executed by the chaos engine",
-	},
-		[]string{"app_uid", "engine_name", "kubernetes_version", "openebs_version"},
-	)
-
-	passedExperiments = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "c",
-		Subsystem: "engine",
-		Name:      "passed_experiments",
-		Help:      "Total number of passed experiments",
-	},
-		[]string{"app_uid", "engine_name", "kubernetes_version", "openebs_version"},
-	)
-
-	failedExperiments = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "c",
-		Subsystem: "engine",
-		Name:      "failed_experiments",
-		Help:      "Total number of failed experiments",
-	},
-		[]string{"app_uid", "engine_name", "kubernetes_version", "openebs_version"},
-	)
-)
 
-// contains checks if the a string is already part of a list of strings
-func contains(l []string, e string) bool {
-	for _, i := range l {
-		if i == e {
-			return true
-		}
+// serve runs the exporter with the command line args. With validateOnly set, the settings are checked
+// without connecting to the cluster, and serve returns once they are found valid
+func serve(args []string, validateOnly bool) {
+
+	// Path of the settings file, looked up ahead of parsing as it defaults the flags
+	settingsFile := configArg(args)
+
+	// Get app details & chaoengine name from the flags, defaulting to the legacy ENVs
+	var applicationUUID, chaosEngine, appNamespace string
+	flag.StringVar(&applicationUUID, "app-uuid", os.Getenv("APP_UUID"), "UID of the application under test, required along with --chaosengine")
+	flag.BoolVar(&autodetectAppUUID, "app-uuid-autodetect", true, "resolve the UID of the application under test from the appinfo of the chaosengines when --app-uuid is not set, by following the owner references of its pods")
+	flag.StringVar(&chaosEngine, "chaosengine", os.Getenv("CHAOSENGINE"), "name of the single chaosengine to monitor (sidecar mode), all the chaosengines of the namespaces are monitored if unset")
+	// WATCH_NAMESPACE overrides APP_NAMESPACE, an empty value monitors chaosengines in all namespaces
+	flag.StringVar(&appNamespace, "watch-namespace", getNamespaceEnv("WATCH_NAMESPACE", getNamespaceEnv("APP_NAMESPACE", "default")), "comma separated list of the namespaces to monitor, empty for all the namespaces")
+	//openEBS installation namespace
+	var openebsNamespace string
+	flag.StringVar(&openebsNamespace, "openebs-namespace", getOpenebsEnv("OPENEBS_NAMESPACE", "openebs"), "namespace openebs is installed in, to look up its version")
+	// Optional ChaosExporterConfig CR, which overrides the above settings at runtime
+	var exporterConfig, exporterNamespace string
+	flag.StringVar(&exporterConfig, "exporter-config", os.Getenv("EXPORTER_CONFIG"), "name of a chaosexporterconfig overriding the namespace, engine & selector settings at runtime")
+	flag.StringVar(&exporterNamespace, "exporter-namespace", getNamespaceEnv("EXPORTER_NAMESPACE", getNamespaceEnv("APP_NAMESPACE", "default")), "namespace of the chaosexporterconfig")
+	// Period after which metrics are collected even if no change was observed
+	var resyncPeriod time.Duration
+	flag.DurationVar(&resyncPeriod, "resync-period", envDuration("RESYNC_PERIOD", 60*time.Second), "period after which the metrics are collected even if no change was observed")
+	// Normalization of the label values, e.g. engine_name, applied before exposition
+	var labelLowercase bool
+	var labelReplace, labelValueMap string
+	flag.BoolVar(&labelLowercase, "label-lowercase", os.Getenv("LABEL_LOWERCASE") == "true", "lowercase the label values")
+	flag.StringVar(&labelReplace, "label-replace", os.Getenv("LABEL_REPLACE"), "regexp=replacement applied to the label values")
+	flag.StringVar(&labelValueMap, "label-value-map", os.Getenv("LABEL_VALUE_MAP"), "comma separated list of value=replacement pairs applied to the label values")
+
+	flag.StringVar(&settingsFile, "config", settingsFile, "path to a YAML file of flag names (or their CHAOS_EXPORTER_* ENVs) & values, defaulting the flags, overridden by the ENVs & the command line. The namespace, engine selector, resync & label settings are reloaded on SIGHUP or once the file is modified")
+	flag.StringVar(&settingsFile, "config-file", settingsFile, "deprecated alias of --config")
+	// Settings of the servers of the metrics, the JSON API & the health endpoints
+	var web webSettings
+	flag.StringVar(&web.tokensFile, "api-tokens-file", os.Getenv("API_TOKENS_FILE"), "path to a YAML file listing the tokens accepted by the JSON API & their scopes, the API is open if unset")
+	var providers, mode string
+	var engineResourceArg, resultResourceArg string
+	var snapshotFile, failureBudgetArg string
+	flag.StringVar(&web.configFile, "web.config.file", os.Getenv("WEB_CONFIG_FILE"), "path to a web config file of the Prometheus exporters, setting TLS & HTTP/2 in place of the --web.tls-* flags")
+	flag.StringVar(&web.healthListenAddress, "web.health-listen-address", os.Getenv("WEB_HEALTH_LISTEN_ADDRESS"), "host:port to serve /healthz & /readyz on instead of the metrics port, e.g. :8081")
+	flag.Float64Var(&web.apiRateLimit, "web.api-rate-limit", envFloat("WEB_API_RATE_LIMIT", 0), "maximum requests per second to the JSON API over all clients, 0 disables the limit")
+	flag.Float64Var(&web.apiClientRateLimit, "web.api-client-rate-limit", envFloat("WEB_API_CLIENT_RATE_LIMIT", 0), "maximum requests per second to the JSON API per client IP, 0 disables the limit")
+	flag.IntVar(&web.apiRateBurst, "web.api-rate-burst", int(envFloat("WEB_API_RATE_BURST", 10)), "number of requests to the JSON API allowed in a burst above the rate limits")
+	flag.StringVar(&web.corsOrigins, "web.cors-allowed-origins", os.Getenv("WEB_CORS_ALLOWED_ORIGINS"), "comma separated list of the origins allowed to call the JSON API from a browser, * for any, e.g. https://dashboard.example.com")
+	flag.StringVar(&web.corsHeaders, "web.cors-allowed-headers", getNamespaceEnv("WEB_CORS_ALLOWED_HEADERS", "Authorization, Content-Type"), "comma separated list of the request headers allowed from the origins")
+	flag.BoolVar(&accessLog, "web.access-log", os.Getenv("WEB_ACCESS_LOG") == "true", "log a line per request served, including the scrapes")
+	flag.DurationVar(&web.shutdownTimeout, "web.shutdown-timeout", envDuration("WEB_SHUTDOWN_TIMEOUT", 20*time.Second), "time the in-flight requests are given to complete on shutdown before their connections are closed, 0 waits for them indefinitely")
+	flag.StringVar(&web.telemetryPath, "web.telemetry-path", getNamespaceEnv("WEB_TELEMETRY_PATH", "/metrics"), "path the metrics are served under, e.g. /chaos/metrics behind a rewriting ingress")
+	flag.BoolVar(&web.enablePprof, "enable-pprof", os.Getenv("ENABLE_PPROF") == "true", "serve the pprof profiles on the debug listen address")
+	flag.StringVar(&web.debugListenAddress, "debug.listen-address", getNamespaceEnv("DEBUG_LISTEN_ADDRESS", "localhost:6060"), "host:port to serve the pprof profiles on, apart from the metrics")
+	flag.StringVar(&failureBudgetArg, "failure-budget", os.Getenv("FAILURE_BUDGET"), "experiment failures an engine is expected to have, as <failures>/<window> (e.g. 1/168h), unless set by its litmuschaos.io/failure-budget annotation")
+	flag.StringVar(&snapshotFile, "snapshot-file", os.Getenv("SNAPSHOT_FILE"), "path the engine series are saved to on shutdown and restored from at startup, until collected again")
+	flag.StringVar(&web.metricsUsername, "web.metrics-username", os.Getenv("METRICS_USERNAME"), "basic auth username scrapers present to read /metrics")
+	flag.StringVar(&web.metricsPasswordHash, "web.metrics-password-hash", os.Getenv("METRICS_PASSWORD_HASH"), "credential reference (file:, env: or vault:) of the bcrypt hash of the basic auth password, e.g. from htpasswd -nbBC 10 \"\" <password>")
+	flag.StringVar(&web.metricsBearerToken, "web.metrics-bearer-token", os.Getenv("METRICS_BEARER_TOKEN"), "credential reference (file:, env: or vault:) of a bearer token accepted on /metrics")
+	flag.StringVar(&engineResourceArg, "engine-resource", os.Getenv("ENGINE_RESOURCE"), "resource.version.group the chaosengines are read from, for forked or renamed CRDs, defaults to chaosengines.v1alpha1.litmuschaos.io")
+	flag.StringVar(&resultResourceArg, "result-resource", os.Getenv("RESULT_RESOURCE"), "resource.version.group the chaosresults are read from, defaults to chaosresults in the group & version of the engines")
+	flag.StringVar(&web.tlsCertFile, "web.tls-cert-file", os.Getenv("WEB_TLS_CERT_FILE"), "path to the server certificate, serves HTTPS when set along with the key")
+	flag.StringVar(&web.tlsKeyFile, "web.tls-key-file", os.Getenv("WEB_TLS_KEY_FILE"), "path to the server private key")
+	flag.StringVar(&web.clientCAFile, "web.client-ca-file", os.Getenv("WEB_CLIENT_CA_FILE"), "path to the CA bundle client certificates are verified against, requires a client certificate when set")
+	flag.StringVar(&mode, "mode", os.Getenv("EXPORTER_MODE"), "deployment mode, sidecar (CHAOSENGINE set) or standalone, detected if unset")
+	flag.StringVar(&web.listenAddress, "web.listen-address", getNamespaceEnv("WEB_LISTEN_ADDRESS", ":8080"), "host:port to serve the metrics & API on, e.g. 127.0.0.1:9091 to only serve on loopback")
+	var discovery string
+	flag.StringVar(&discovery, "discovery", "", "how the chaosengines are discovered, empty for every chaosengine of the namespaces, or annotation for those targeting a deployment annotated with litmuschaos.io/chaos=true")
+	flag.StringVar(&providers, "chaos-providers", getNamespaceEnv("CHAOS_PROVIDERS", chaosmetrics.LitmusProviderName), "comma separated list of the chaos tools to collect, litmus and/or chaosmesh")
+	flag.StringVar(&kubeconfig, "kubeconfig", "", "path to the kubeconfig file")
+	flag.StringVar(&impersonateUser, "as", "", "user to impersonate in the apiserver requests, e.g. system:serviceaccount:litmus:chaos-exporter-audit")
+	flag.StringVar(&impersonateGroups, "as-group", "", "comma separated list of the groups to impersonate, along with --as")
+	flag.StringVar(&kubeContext, "kube-context", "", "context of the kubeconfig file to use, its current context if unset")
+	flag.StringVar(&engineSelector, "engine-selector", os.Getenv("ENGINE_SELECTOR"), "label selector of the chaosengines to monitor, e.g. team=payments")
+	flag.BoolVar(&leaderElect, "leader-elect", os.Getenv("LEADER_ELECT") == "true", "elect a single replica to collect metrics using a Lease")
+	flag.IntVar(&collectionWorkers, "collection-workers", int(envFloat("COLLECTION_WORKERS", 4)), "number of chaosengines collected in parallel, defaults to 1 in sidecar mode")
+	flag.DurationVar(&collectionDeadline, "collection-deadline", envDuration("COLLECTION_DEADLINE", 0), "time after which a collection pass exposes the engines collected so far and continues the others in the next pass, 0 disables the deadline")
+	flag.DurationVar(&stateRetention, "state-retention", envDuration("STATE_RETENTION", time.Hour), "time after which the series & state of a chaosengine that is no longer collected (e.g. deleted) are evicted")
+	flag.DurationVar(&stateGCInterval, "state-gc-interval", envDuration("STATE_GC_INTERVAL", 10*time.Minute), "interval at which the state of the chaosengines no longer collected is evicted")
+	flag.StringVar(&selfTestNamespace, "selftest-namespace", os.Getenv("SELFTEST_NAMESPACE"), "namespace the self-test periodically creates a dummy chaosengine & result in, to verify they are exposed as expected. Empty to disable the self-test")
+	flag.DurationVar(&selfTestInterval, "selftest-interval", envDuration("SELFTEST_INTERVAL", 15*time.Minute), "interval at which the self-test is run")
+	flag.DurationVar(&engineTimeout, "engine-timeout", envDuration("ENGINE_TIMEOUT", 30*time.Second), "time after which the collection of a chaosengine is abandoned, 0 disables the timeout")
+	flag.StringVar(&timeSource, "time-source", getNamespaceEnv("TIME_SOURCE", "local"), "clock used to account chaos windows, local or apiserver (local clock corrected by the measured skew)")
+	flag.Float64Var(&kubeQPS, "kube-api-qps", envFloat("KUBE_API_QPS", 0), "maximum queries per second to the apiserver, 0 keeps the client-go default (5)")
+	flag.IntVar(&kubeBurst, "kube-api-burst", int(envFloat("KUBE_API_BURST", 0)), "maximum burst of queries to the apiserver, 0 keeps the client-go default (10)")
+	flag.DurationVar(&kubeTimeout, "kube-api-timeout", envDuration("KUBE_API_TIMEOUT", 0), "timeout of a single apiserver request, 0 disables it")
+	flag.DurationVar(&versionRefreshInterval, "version-refresh-interval", envDuration("VERSION_REFRESH_INTERVAL", time.Hour), "interval at which the kubernetes & openebs versions are looked up again, 0 looks them up only once")
+	flag.StringVar(&seriesReplacement, "series-replacement", getNamespaceEnv("SERIES_REPLACEMENT", replaceSwap), "how series labelled with outdated versions are replaced: swap (delete once replaced, no gaps) or reset (delete before replacing, no overlap)")
+	flag.DurationVar(&chaosResultResync, "chaosresult-resync", envDuration("CHAOSRESULT_RESYNC_PERIOD", 10*time.Minute), "period after which the cached chaosresults are relisted, 0 only relists when the watch is lost")
+	flag.IntVar(&heatmapDays, "heatmap-days", int(envFloat("HEATMAP_DAYS", 30)), "number of days of experiment verdicts served by the heatmap")
+	flag.DurationVar(&sinkCheckTimeout, "sink-check-timeout", envDuration("SINK_CHECK_TIMEOUT", 5*time.Second), "time after which the startup reachability check of a sink endpoint fails")
+	flag.StringVar(&pushURL, "push.url", "", "URL of a Prometheus Pushgateway the series of every chaosengine are pushed to after each collection pass, for clusters Prometheus cannot scrape. Empty disables the push")
+	flag.StringVar(&pushJob, "push.job", "chaos-exporter", "job label of the groups pushed to the Pushgateway")
+	flag.DurationVar(&pushTimeout, "push.timeout", 10*time.Second, "time after which a request to the Pushgateway fails")
+	flag.StringVar(&statsdAddress, "statsd.address", "", "UDP host:port of a StatsD daemon the verdict transitions & the series of the chaosengines are sent to. Empty disables StatsD")
+	flag.StringVar(&statsdPrefix, "statsd.prefix", "litmuschaos", "prefix of the metric names sent to StatsD")
+	flag.StringVar(&statsdFormat, "statsd.format", "statsd", "format of the StatsD lines, statsd (labels appended to the name) or dogstatsd (labels sent as tags)")
+	flag.StringVar(&datadogAPIKey, "datadog.api-key", "", "credential reference of the Datadog API key the series & verdict transition events are submitted with, e.g. env:DD_API_KEY. Empty disables Datadog")
+	flag.StringVar(&datadogSite, "datadog.site", "datadoghq.com", "Datadog site the series & events are submitted to, e.g. datadoghq.eu")
+	flag.DurationVar(&datadogTimeout, "datadog.timeout", 10*time.Second, "time after which a submission to Datadog fails")
+	flag.StringVar(&cloudWatchNamespace, "cloudwatch.namespace", "", "CloudWatch namespace the experiment counts, verdicts & chaos durations are published to, e.g. LitmusChaos. Empty disables CloudWatch")
+	flag.StringVar(&cloudWatchRegion, "cloudwatch.region", os.Getenv("AWS_REGION"), "AWS region of CloudWatch, defaults to AWS_REGION")
+	flag.StringVar(&cloudWatchDimensions, "cloudwatch.dimensions", "chaos_namespace,engine_name", "comma separated list of the labels published as CloudWatch dimensions, the others are dropped")
+	flag.StringVar(&cloudWatchStaticDimensions, "cloudwatch.static-dimensions", "", "comma separated list of name=value dimensions added to every metric, e.g. Cluster=prod-eks")
+	flag.DurationVar(&cloudWatchTimeout, "cloudwatch.timeout", 10*time.Second, "time after which a publishing to CloudWatch fails")
+	flag.BoolVar(&cloudMonitoringEnabled, "gcp.enabled", false, "write the series of the chaosengines as custom metrics of Google Cloud Monitoring, with the application default credentials")
+	flag.StringVar(&cloudMonitoringProject, "gcp.project", os.Getenv("GOOGLE_CLOUD_PROJECT"), "project the custom metrics are written to, defaults to GOOGLE_CLOUD_PROJECT or the project of the credentials")
+	flag.StringVar(&cloudMonitoringCluster, "gcp.cluster-name", "", "name of the GKE cluster the metrics are written for (k8s_cluster resource), along with --gcp.location. The global resource is used if unset")
+	flag.StringVar(&cloudMonitoringLocation, "gcp.location", "", "location (zone or region) of the GKE cluster")
+	flag.StringVar(&cloudMonitoringPrefix, "gcp.metric-prefix", "custom.googleapis.com/litmuschaos", "prefix of the types of the custom metrics")
+	flag.DurationVar(&cloudMonitoringTimeout, "gcp.timeout", 10*time.Second, "time after which a write to Cloud Monitoring fails")
+	flag.StringVar(&azureMonitorResourceID, "azure.resource-id", "", "ID of the Azure resource the series of the chaosengines are published as custom metrics of, e.g. the AKS cluster /subscriptions/<id>/resourceGroups/<group>/providers/Microsoft.ContainerService/managedClusters/<name>. Empty disables Azure Monitor")
+	flag.StringVar(&azureMonitorRegion, "azure.region", "", "Azure region of the resource, e.g. westeurope")
+	flag.StringVar(&azureMonitorNamespace, "azure.metric-namespace", "LitmusChaos", "namespace of the custom metrics")
+	flag.StringVar(&azureMonitorDimensions, "azure.dimensions", "chaos_namespace,engine_name", "comma separated list of the labels published as dimensions, at most 10, the others are dropped")
+	flag.DurationVar(&azureMonitorTimeout, "azure.timeout", 10*time.Second, "time after which a publishing to Azure Monitor fails")
+	flag.StringVar(&newRelicLicenseKey, "newrelic.license-key", "", "credential reference of the New Relic license key the experiment counts, verdicts & chaos durations are sent with to the Metric API, e.g. env:NEW_RELIC_LICENSE_KEY. Empty disables New Relic")
+	flag.StringVar(&newRelicRegion, "newrelic.region", "us", "region of the New Relic account, us or eu")
+	flag.StringVar(&newRelicClusterName, "newrelic.cluster-name", "", "k8s.cluster.name attribute of the metrics, the cluster name of the New Relic Kubernetes integration to link them to its entity")
+	flag.DurationVar(&newRelicTimeout, "newrelic.timeout", 10*time.Second, "time after which a send to New Relic fails")
+	flag.StringVar(&influxURL, "influxdb.url", "", "URL of an InfluxDB v2 server the series of the chaosengines are written to after each collection pass, e.g. http://influxdb:8086. Empty disables InfluxDB")
+	flag.StringVar(&influxOrg, "influxdb.org", "", "organization of the InfluxDB bucket")
+	flag.StringVar(&influxBucket, "influxdb.bucket", "", "InfluxDB bucket the points are written to")
+	flag.StringVar(&influxToken, "influxdb.token", "", "credential reference of the InfluxDB API token, e.g. env:INFLUX_TOKEN")
+	flag.DurationVar(&influxTimeout, "influxdb.timeout", 10*time.Second, "time after which a write to InfluxDB fails")
+	flag.StringVar(&remoteWriteURL, "remote-write.url", "", "URL of a Prometheus remote_write endpoint the metrics are written to after each collection pass, for clusters without a local Prometheus, e.g. http://mimir:8080/api/v1/push. Empty disables remote_write")
+	flag.StringVar(&remoteWriteBearerToken, "remote-write.bearer-token", "", "credential reference of the bearer token sent to the remote_write endpoint, e.g. file:/etc/remote-write/token")
+	flag.StringVar(&remoteWriteUsername, "remote-write.username", "", "basic auth username sent to the remote_write endpoint")
+	flag.StringVar(&remoteWritePassword, "remote-write.password", "", "credential reference of the basic auth password sent to the remote_write endpoint")
+	flag.StringVar(&remoteWriteCAFile, "remote-write.ca-file", "", "path to the CA bundle the remote_write endpoint is verified against, the system CAs if empty")
+	flag.StringVar(&remoteWriteCertFile, "remote-write.cert-file", "", "path to the client certificate presented to the remote_write endpoint, along with --remote-write.key-file")
+	flag.StringVar(&remoteWriteKeyFile, "remote-write.key-file", "", "path to the key of the client certificate")
+	flag.BoolVar(&remoteWriteInsecureSkipVerify, "remote-write.insecure-skip-verify", false, "skip the verification of the certificate of the remote_write endpoint")
+	flag.DurationVar(&remoteWriteTimeout, "remote-write.timeout", 10*time.Second, "time after which a write to the remote_write endpoint fails")
+	flag.StringVar(&graphiteAddress, "graphite.address", "", "TCP host:port of a carbon daemon the metrics are sent to in the Graphite plaintext protocol, e.g. carbon:2003. Empty disables Graphite")
+	flag.StringVar(&graphitePrefix, "graphite.prefix", "litmuschaos", "prefix of the Graphite metric paths")
+	flag.DurationVar(&graphiteInterval, "graphite.interval", time.Minute, "interval at which the metrics are flushed to Graphite")
+	flag.DurationVar(&graphiteTimeout, "graphite.timeout", 10*time.Second, "time after which a flush to Graphite fails")
+	flag.StringVar(&kafkaRESTURL, "kafka.rest-url", "", "URL of the Kafka REST Proxy the verdict transitions are produced through, e.g. http://kafka-rest:8082. Empty disables Kafka")
+	flag.StringVar(&kafkaTopic, "kafka.topic", "litmuschaos-verdicts", "Kafka topic the verdict transitions are produced to")
+	flag.StringVar(&kafkaFormat, "kafka.format", "json", "encoding of the Kafka records, json or avro (registered in the Schema Registry of the REST Proxy)")
+	flag.DurationVar(&kafkaTimeout, "kafka.timeout", 10*time.Second, "time after which producing a record fails")
+	flag.StringVar(&chaosCenterURL, "chaoscenter.url", "", "URL of the GraphQL API of a Litmus ChaosCenter the runs of the chaosengines are reported to, e.g. http://litmusportal-server-service.litmus:9002/query. Empty disables the reports")
+	flag.StringVar(&chaosCenterInfraID, "chaoscenter.infra-id", "", "ID of the chaos infrastructure the runs are reported for")
+	flag.StringVar(&chaosCenterAccessKey, "chaoscenter.access-key", "", "credential reference of the access key of the chaos infrastructure, e.g. env:ACCESS_KEY")
+	flag.DurationVar(&chaosCenterTimeout, "chaoscenter.timeout", 10*time.Second, "time after which a report to the ChaosCenter fails")
+	flag.StringVar(&incidentProvider, "incidents.provider", "", "incident management service an incident is opened in when the experiments of an engine fail repeatedly, pagerduty or opsgenie. Empty disables the incidents")
+	flag.StringVar(&incidentKey, "incidents.key", "", "credential reference of the PagerDuty integration key or of the Opsgenie API key, e.g. env:PAGERDUTY_ROUTING_KEY")
+	flag.StringVar(&incidentURL, "incidents.url", "", "URL of the API of the incident management service, defaults to that of the provider, e.g. https://api.eu.opsgenie.com")
+	flag.IntVar(&incidentThreshold, "incidents.threshold", 3, "number of consecutive experiment failures of an engine an incident is opened at")
+	flag.DurationVar(&incidentTimeout, "incidents.timeout", 10*time.Second, "time after which a request to the incident management service fails")
+	flag.StringVar(&monitorKind, "monitor.kind", "", "kind of the prometheus-operator monitor the exporter creates or updates for itself on startup, ServiceMonitor or PodMonitor. Empty creates none")
+	flag.StringVar(&monitorName, "monitor.name", "chaos-exporter", "name of the monitor")
+	flag.StringVar(&monitorNamespace, "monitor.namespace", "", "namespace of the monitor & of the exporter service or pods it selects, the exporter namespace if empty")
+	flag.StringVar(&monitorSelector, "monitor.selector", "app=chaos-exporter", "comma separated key=value labels of the exporter service (ServiceMonitor) or pods (PodMonitor) the monitor selects")
+	flag.StringVar(&monitorLabels, "monitor.labels", "", "comma separated key=value labels of the monitor, for the Prometheus monitor selector to pick it, e.g. release=prometheus")
+	flag.StringVar(&monitorInterval, "monitor.interval", "", "scrape interval of the monitor, e.g. 30s, the Prometheus default if empty")
+	flag.BoolVar(&kubernetesEvents, "kubernetes-events", false, "create Events on the chaosengines for the verdict transitions of their experiments, requires the create permission on events")
+	flag.StringVar(&rulesFormat, "rules.format", "prometheusrule", "format of the rules generated by gen-rules, prometheusrule (a PrometheusRule of the Prometheus operator) or rules (a rule file)")
+	flag.StringVar(&rulesName, "rules.name", "chaos-exporter", "name of the PrometheusRule generated by gen-rules")
+	flag.StringVar(&rulesNamespace, "rules.namespace", "", "namespace of the PrometheusRule generated by gen-rules, none if empty")
+	flag.StringVar(&rulesJob, "rules.job", "chaos-exporter", "job of the exporter targets, alerted on by gen-rules when down")
+	flag.StringVar(&rulesMetricPrefix, "rules.metric-prefix", "", "prefix the exporter metrics are renamed with on scrape, if any, for the rules generated by gen-rules")
+	flag.StringVar(&rulesEngineLabels, "rules.engine-labels", "", "comma separated chaosengine labels, listed in the engineLabels of the ChaosExporterConfig, the alerts generated by gen-rules carry")
+	flag.IntVar(&maxConsecutiveFailures, "max-consecutive-failures", int(envFloat("MAX_CONSECUTIVE_FAILURES", 10)), "number of consecutive failed collections after which the exporter exits, 0 retries forever")
+	printVersionOnly := flag.Bool("version", false, "print the version of the exporter and exit")
+	logLevel := flag.String("log.level", "info", "level of the logs, debug, info, warn, error or fatal")
+	logFormat := flag.String("log.format", "text", "format of the logs, text or json for Loki/ELK")
+	logSampleInterval := flag.Duration("log.sample-interval", time.Minute, "interval at which the messages repeated on every collection (e.g. an invalid chaosengine) are logged at most once per engine, 0 logs every line")
+	dryRunOnly := flag.Bool("dry-run", false, "collect the chaos metrics once, print them to stdout in the Prometheus text format and exit, non-zero if the collection failed")
+	// Holds the invalid settings, reported all at once before anything is started
+	var problems configProblems
+	// The settings file defaults the flags but those of the legacy ENVs set, the ENVs of the prefix override
+	// both, the command line overrides them all
+	if settingsFile != "" {
+		fileSettings, err = applySettingsFile(flag.CommandLine, settingsFile)
+		problems.addErr("--config (CHAOS_EXPORTER_CONFIG)", err)
+	}
+	problems.addErr("CHAOS_EXPORTER_* ENVs", applyFlagEnvs(flag.CommandLine))
+	flag.CommandLine.Parse(args)
+	if *printVersionOnly {
+		printVersion(os.Stdout)
+		return
 	}
-	return false
-}
 
-// getnamespaceEnv checks whether an ENV variable has been set, else sets a default value
-func getNamespaceEnv(key, fallback string) string {
-	if value, ok := os.LookupEnv(key); ok {
-		return value
+	if err := configureLogging(log.StandardLogger(), *logLevel, *logFormat); err != nil {
+		problems.add("--log.level & --log.format", "%v", err)
+	}
+	logSampling = newLogSampler(*logSampleInterval)
+	for _, warning := range legacyEnvWarnings() {
+		log.Warn(warning)
 	}
-	return fallback
-}
 
-// get
-func getOpenebsEnv(key, fallback string) string {
-	if value, ok := os.LookupEnv(key); ok {
-		return value
+	if resyncPeriod <= 0 {
+		problems.add("--resync-period (RESYNC_PERIOD)", "expected a positive duration, e.g. 60s, got %s", resyncPeriod)
+	}
+	normalizer, err := newLabelNormalizer(labelLowercase, labelReplace, labelValueMap)
+	problems.addErr("--label-replace (LABEL_REPLACE) & --label-value-map (LABEL_VALUE_MAP)", err)
+	if normalizer == nil {
+		normalizer, _ = newLabelNormalizer(false, "", "")
 	}
-	return fallback
-}
 
-// exporter continuously collects the chaos metrics for a given chaosengine
-func exporter(cfg *rest.Config, chaosEngine string, appUUID string, appNS string, kubernetesVersion string, openebsVersion string) {
+	otlp := readOTLPSettings(&problems)
+	validateSinks(&problems)
+	if generateRules {
+		problems.addErr("--rules.format, --rules.name & --rules.engine-labels", validateRulesSettings(rulesFormat, rulesName, rulesEngineLabels))
+	}
+	setupCluster(validateOnly, &problems)
+	setupChaosResources(engineResourceArg, resultResourceArg, validateOnly, &problems)
+	web.validate(&problems)
+	problems.addErr("--monitor.kind, --monitor.name, --monitor.selector & --monitor.labels", validateMonitorSettings(monitorKind, monitorName, monitorSelector, monitorLabels))
+	defaultFailureBudget, err = parseFailureBudget(failureBudgetArg)
+	problems.addErr("--failure-budget (FAILURE_BUDGET)", err)
+	setupProviders(providers, discovery, &problems)
+	validateCollectionSettings(&problems)
 
-	for {
-		// Get the chaos metrics for the specified chaosengine
-		expTotal, passTotal, failTotal, expMap, err := chaosmetrics.GetLitmusChaosMetrics(cfg, chaosEngine, appNS)
-		if err != nil {
-			//panic(err.Error())
-			log.Fatal("Unable to get metrics: ", err.Error())
+	// Validate availability of mandatory ENV, these may instead be supplied by the config file or the
+	// ChaosExporterConfig CR
+	base := runtimeSettings{
+		defaults:   exporterSettings{chaosEngine: chaosEngine, appUUID: applicationUUID, appNamespace: appNamespace, engineSelector: engineSelector},
+		resync:     resyncPeriod,
+		normalizer: normalizer,
+	}
+	runtime := base
+	var reloader *settingsReloader
+	if settingsFile != "" {
+		reloader = newSettingsReloader()
+	}
+	labelNormalization = runtime.normalizer
+	defaults := runtime.defaults
+	if exporterConfig != "" && !validateOnly && config != nil {
+		log.Infof("reading exporter settings from chaosexporterconfig %s/%s", exporterNamespace, exporterConfig)
+		defaults, err = getConfigSettings(config, defaults, exporterConfig, exporterNamespace)
+		problems.addErr("--exporter-config (EXPORTER_CONFIG)", err)
+	}
+	validateExporterSettings(defaults, &problems)
+	// Default the settings not set explicitly for the deployment mode
+	if mode, err = detectMode(mode, defaults.chaosEngine); err != nil {
+		problems.add("--mode (EXPORTER_MODE)", "%v", err)
+		mode = modeStandalone
+	}
+	applyModeDefaults(mode, &base, &runtime)
+	runtime.defaults = defaults
+	problems.addInvalidEnvs()
+	problems.check()
+	if web.tokensFile != "" {
+		log.Infof("JSON API restricted to the %d tokens of %s", len(apiTokens), web.tokensFile)
+	}
+	if generateRules {
+		rules := buildRules(rulesMetricPrefix, rulesJob, splitList(rulesEngineLabels))
+		if err := writeRules(os.Stdout, rulesFormat, rulesName, rulesNamespace, rules); err != nil {
+			log.Fatal("Unable to write the rules: ", err)
 		}
-
-		// Define, register & set the dynamically obtained chaos metrics (experiment state)
-		for index, verdict := range expMap {
-			sanitizedExpName := strings.Replace(index, "-", "_", -1)
-			var (
-				tmpExp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-					Namespace: "c",
-					Subsystem: "exp",
-					Name:      sanitizedExpName,
-					Help:      "",
-				},
-					[]string{"app_uid", "engine_name", "kubernetes_version", "openebs_version"},
-				)
-			)
-
-			if contains(registeredResultMetrics, sanitizedExpName) {
-				prometheus.Unregister(tmpExp)
-				prometheus.MustRegister(tmpExp)
-				tmpExp.WithLabelValues(appUUID, chaosEngine, kubernetesVersion, openebsVersion).Set(verdict)
-			} else {
-				prometheus.MustRegister(tmpExp)
-				tmpExp.WithLabelValues(appUUID, chaosEngine, kubernetesVersion, openebsVersion).Set(verdict)
-				registeredResultMetrics = append(registeredResultMetrics, sanitizedExpName)
-			}
-
-			// Set the fixed chaos metrics
-			experimentsTotal.WithLabelValues(appUUID, chaosEngine, kubernetesVersion, openebsVersion).Set(expTotal)
-			passedExperiments.WithLabelValues(appUUID, chaosEngine, kubernetesVersion, openebsVersion).Set(passTotal)
-			failedExperiments.WithLabelValues(appUUID, chaosEngine, kubernetesVersion, openebsVersion).Set(failTotal)
+		return
+	}
+	if validateOnly {
+		log.Infof("configuration valid, %s mode", mode)
+		return
+	}
+	// Looks up the kubernetes & openebs versions, refreshing them periodically to reflect upgrades
+	versions := version.NewProvider(config, openebsNamespace, versionRefreshInterval)
+	// Register the fixed (count) chaos metrics
+	registerMetrics()
+	if generateDashboard {
+		if err := dashboardRun(context.Background(), config, defaults, versions, os.Stdout); err != nil {
+			log.Fatal("Unable to write the dashboard: ", err)
 		}
-
-		time.Sleep(1000 * time.Millisecond)
+		return
 	}
-}
-
-func main() {
-
-	// Get app details & chaoengine name from ENV
-	// Add checks for default
-	applicationUUID := os.Getenv("APP_UUID")
-	chaosEngine := os.Getenv("CHAOSENGINE")
-	appNamespace := getNamespaceEnv("APP_NAMESPACE", "default")
-	//openEBS installation namespace
-	openebsNamespace := getOpenebsEnv("OPENEBS_NAMESPACE", "openebs")
-
-	flag.StringVar(&kubeconfig, "kubeconfig", "", "path to the kubeconfig file")
-	flag.Parse()
-
-	// Use in-cluster config if kubeconfig file not available
-	if kubeconfig == "" {
-		log.Info("using the in-cluster config")
-		config, err = rest.InClusterConfig()
-	} else {
-		log.Info("using configuration from: ", kubeconfig)
-		config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if *dryRunOnly {
+		if err := dryRun(context.Background(), config, defaults, versions, prometheus.DefaultGatherer, os.Stdout); err != nil {
+			log.Fatal("Dry run failed: ", err)
+		}
+		return
 	}
-
-	if err != nil {
-		panic(err.Error())
+	if snapshotFile != "" {
+		restoreSnapshot(snapshotFile)
 	}
+	connectSinks(otlp)
+
+	// Log the settings as a single structured line, also served by /debug/status
+	kubernetesVersion, openebsVersion := versions.Versions()
+	startupSummary = newStartupSummary(mode, runtime, kubernetesVersion, openebsVersion)
+	startupSummary["listenAddress"] = web.listenAddress
+	startupSummary["telemetryPath"] = web.telemetryPath
+	startupSummary["tls"] = web.tlsConfig != nil
+	startupSummary["clientCertificates"] = web.clientCAFile != ""
+	startupSummary["settingsFile"] = settingsFile
+	log.WithFields(startupSummary).Info("chaos-exporter starting")
 
-	// Validate availability of mandatory ENV
-	if chaosEngine == "" || applicationUUID == "" {
-		log.Fatal("ERROR: please specify correct APP_UUID & CHAOSENGINE ENVs")
-		os.Exit(1)
+	// Collection stops once SIGTERM (or SIGINT) is received
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	if reloader != nil {
+		go reloader.watchConfigFile(ctx, flag.CommandLine, settingsFile, base)
 	}
-	// This function gets the kubernetes version
-	kubernetesVersion, err := version.GetKubernetesVersion(config)
-	if err != nil {
-		log.Info("Unable to get Kubernetes Version : ", err)
-		//kubernetesVersion = "N/A"
+	runSinks(ctx, otlp)
+	if monitorKind != "" {
+		if monitorNamespace == "" {
+			monitorNamespace = exporterNamespace
+		}
+		monitor, err := buildMonitor(monitorKind, monitorName, monitorNamespace, monitorSelector, monitorLabels, monitorInterval, web.listenAddress, web.telemetryPath, web.tlsConfig != nil)
+		if err != nil {
+			log.Fatal("Unable to build the monitor: ", err)
+		}
+		go registerMonitor(config, monitor)
 	}
-	// This function gets the openebs version
-	openebsVersion, err := version.GetOpenebsVersion(config, openebsNamespace)
-	if err != nil {
-		log.Info("Unable to get OpenEBS Version : ", err)
-		//openebsVersion = "N/A"
+	go runNotifier(ctx)
+	if stateGCInterval > 0 {
+		go runStateGC(ctx)
 	}
-	// Register the fixed (count) chaos metrics
-	prometheus.MustRegister(experimentsTotal)
-	prometheus.MustRegister(passedExperiments)
-	prometheus.MustRegister(failedExperiments)
-
-	// Trigger the chaos metrics collection
-	go exporter(config, chaosEngine, applicationUUID, appNamespace, kubernetesVersion, openebsVersion)
+	collecting := startCollection(ctx, runtime, exporterConfig, exporterNamespace, versions, reloader)
 
 	//This section will start the HTTP server and expose
 	//any metrics on the /metrics endpoint.
-	http.Handle("/metrics", promhttp.Handler())
-	log.Info("Beginning to serve on port :8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	server, healthServer := web.start()
+
+	sig := <-signals
+	log.Infof("received %s, shutting down", sig)
+	cancel()
+	collecting.Lock()
+	if snapshotFile != "" {
+		if err := saveSnapshot(snapshotFile); err != nil {
+			log.Error("Unable to save the snapshot: ", err)
+		}
+	}
+	web.shutdown(server, healthServer)
+	log.Info("shutdown complete")
 }
@@ -6,6 +6,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"testing"
 )
 
@@ -13,3 +14,8 @@ import (
 func TestChaosExporter(t *testing.T) {
 	fmt.Println("..Test Chaos Exporter..")
 }
+
+func TestMain(m *testing.M) {
+	registerMetrics()
+	os.Exit(m.Run())
+}
@@ -0,0 +1,443 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Declare the fixed chaos metrics. Dynamic (testStatus) metrics are defined in collectEngine()
+var (
+	experimentsTotal       *engineGauge
+	passedExperiments      *engineGauge
+	failedExperiments      *engineGauge
+	verdictTransitions     *prometheus.CounterVec
+	probeStatus            *engineGauge
+	expectedIterations     *engineGauge
+	actualIterations       *engineGauge
+	engineInvalid          *prometheus.GaugeVec
+	engineStale            *prometheus.GaugeVec
+	exporterLeader         prometheus.Gauge
+	collectionErrors       prometheus.Counter
+	trackedState           *prometheus.GaugeVec
+	selfTestSuccess        prometheus.Gauge
+	httpRequests           *prometheus.CounterVec
+	httpRequestDuration    *prometheus.HistogramVec
+	clockSkew              prometheus.Gauge
+	sinkReachable          *prometheus.GaugeVec
+	versionInfo            *prometheus.GaugeVec
+	engineOwner            *engineGauge
+	failureBudgetRemaining *engineGauge
+	appSLA                 *prometheus.GaugeVec
+	appChaosSeconds        *prometheus.GaugeVec
+	appAvailableSecs       *prometheus.GaugeVec
+	notificationsSent      *prometheus.CounterVec
+	pushes                 *prometheus.CounterVec
+	otlpExports            *prometheus.CounterVec
+	datadogRequests        *prometheus.CounterVec
+	cloudWatchPublishes    *prometheus.CounterVec
+	cloudMonitoringWrites  *prometheus.CounterVec
+	influxWrites           *prometheus.CounterVec
+	graphiteFlushes        *prometheus.CounterVec
+	kafkaEvents            *prometheus.CounterVec
+	incidentRequests       *prometheus.CounterVec
+	remoteWrites           *prometheus.CounterVec
+	chaosCenterReports     *prometheus.CounterVec
+	azureMonitorPublishes  *prometheus.CounterVec
+	newRelicSends          *prometheus.CounterVec
+)
+
+// labelNames returns the label names of a chaos metric, prefixed by chaos_namespace. The namespace is carried in
+// every mode, so that series merged from many exporters (for e.g. sidecars) in one Prometheus remain distinct
+func labelNames(names ...string) []string {
+	return append([]string{"chaos_namespace"}, names...)
+}
+
+// labelValues returns the normalized label values of a series, prefixed by the chaosengine namespace
+func labelValues(namespace string, values ...string) []string {
+	normalized := []string{namespace}
+	for _, value := range values {
+		normalized = append(normalized, labelNormalization.normalize(value))
+	}
+	return normalized
+}
+
+// newExperimentGauge defines the dynamic gauge holding the state of an experiment
+func newExperimentGauge(sanitizedExpName string) *engineGauge {
+	name := prometheus.BuildFQName("c", "exp", sanitizedExpName)
+	return &engineGauge{name: name, desc: prometheus.NewDesc(
+		name, "", labelNames("app_uid", "engine_name", "kubernetes_version", "openebs_version"), nil,
+	)}
+}
+
+// registerMetrics defines & registers the fixed chaos metrics, with the label set of the selected mode
+func registerMetrics() {
+	metricFamilies = []metricFamily{{
+		Name:   "c_exp_<experiment>",
+		Type:   "gauge",
+		Labels: labelNames("app_uid", "engine_name", "kubernetes_version", "openebs_version"),
+		Help:   "State of an experiment, one family per experiment {not-executed:0, running:1, fail:2, pass:3}",
+		Since:  "0.1.0",
+	}, {
+		Name:   "litmuschaos_engine_labels",
+		Type:   "gauge",
+		Labels: labelNames("engine_name", "label_<key>"),
+		Help:   "Set to 1, labelled with the chaosengine labels listed in the engineLabels of the chaosexporterconfig",
+		Since:  "0.2.0",
+	}}
+
+	experimentsTotal = newEngineGauge("0.1.0", prometheus.GaugeOpts{
+		Namespace: "c",
+		Subsystem: "engine",
+		Name:      "experiment_count",
+		Help:      "Total number of experiments executed by the chaos engine",
+	},
+		labelNames("app_uid", "engine_name", "kubernetes_version", "openebs_version"),
+	)
+
+	passedExperiments = newEngineGauge("0.1.0", prometheus.GaugeOpts{
+		Namespace: "c",
+		Subsystem: "engine",
+		Name:      "passed_experiments",
+		Help:      "Total number of passed experiments",
+	},
+		labelNames("app_uid", "engine_name", "kubernetes_version", "openebs_version"),
+	)
+
+	failedExperiments = newEngineGauge("0.1.0", prometheus.GaugeOpts{
+		Namespace: "c",
+		Subsystem: "engine",
+		Name:      "failed_experiments",
+		Help:      "Total number of failed experiments",
+	},
+		labelNames("app_uid", "engine_name", "kubernetes_version", "openebs_version"),
+	)
+
+	verdictTransitions = newCounterVec("0.2.0", prometheus.CounterOpts{
+		Namespace: "chaos",
+		Subsystem: "experiment",
+		Name:      "verdict_transitions_total",
+		Help:      "Total number of experiment state changes, by previous and new state",
+	},
+		labelNames("engine_name", "from", "to"),
+	)
+
+	probeStatus = newEngineGauge("0.2.0", prometheus.GaugeOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "probe",
+		Name:      "status",
+		Help:      "Outcome of the steady-state probes of an experiment {fail:0, pass:1}",
+	},
+		labelNames("engine_name", "probe", "type", "experiment"),
+	)
+
+	expectedIterations = newEngineGauge("0.2.0", prometheus.GaugeOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "experiment",
+		Name:      "expected_iterations",
+		Help:      "Number of chaos injections an experiment is configured for, derived from TOTAL_CHAOS_DURATION & CHAOS_INTERVAL",
+	},
+		labelNames("engine_name", "experiment"),
+	)
+
+	actualIterations = newEngineGauge("0.2.0", prometheus.GaugeOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "experiment",
+		Name:      "actual_iterations",
+		Help:      "Number of chaos injections performed by an experiment, as reported by ChaosInject events",
+	},
+		labelNames("engine_name", "experiment"),
+	)
+
+	engineInvalid = newGaugeVec("0.2.0", prometheus.GaugeOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "engine",
+		Name:      "invalid",
+		Help:      "Set to 1 for each reason a monitored chaosengine is invalid for",
+	},
+		labelNames("engine_name", "reason"),
+	)
+
+	engineStale = newGaugeVec("0.2.0", prometheus.GaugeOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "engine",
+		Name:      "stale",
+		Help:      "Set to 1 for a chaosengine left uncollected by the last pass, which hit the collection deadline",
+	},
+		labelNames("engine_name"),
+	)
+
+	engineOwner = newEngineGauge("0.2.0", prometheus.GaugeOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "engine",
+		Name:      "owner_info",
+		Help:      "Set to 1 for each owner of a chaosengine, for e.g. the workflow or schedule that created it",
+	},
+		labelNames("engine_name", "owner_kind", "owner_name"),
+	)
+
+	failureBudgetRemaining = newEngineGauge("0.2.0", prometheus.GaugeOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "engine",
+		Name:      "failure_budget_remaining",
+		Help:      "Experiment failures a chaosengine may still have within the window of its failure budget, negative once overrun",
+	},
+		labelNames("engine_name"),
+	)
+
+	exporterLeader = newGauge("0.2.0", prometheus.GaugeOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "leader",
+		Help:      "Set to 1 on the replica collecting chaos metrics, 0 on standby replicas",
+	})
+
+	collectionErrors = newCounter("0.2.0", prometheus.CounterOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "collection_errors_total",
+		Help:      "Total number of collection passes that failed to get the metrics of one or more chaosengines",
+	})
+
+	trackedState = newGaugeVec("0.2.0", prometheus.GaugeOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "tracked_state_keys",
+		Help:      "Number of keys held by the state tracked across passes: collected engines, experiment verdicts & engine failures",
+	},
+		[]string{"state"},
+	)
+
+	selfTestSuccess = newGauge("0.2.0", prometheus.GaugeOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "selftest_success",
+		Help:      "Set to 1 if the last self-test found its dummy chaosengine & result exposed as expected, 0 otherwise",
+	})
+
+	httpRequests = newCounterVec("0.2.0", prometheus.CounterOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "http_requests_total",
+		Help:      "Total number of requests served by the exporter, by endpoint, method & status code",
+	},
+		[]string{"handler", "method", "code"},
+	)
+
+	httpRequestDuration = newHistogramVec("0.2.0", prometheus.HistogramOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to serve the requests of the exporter, by endpoint & method",
+		Buckets:   prometheus.DefBuckets,
+	},
+		[]string{"handler", "method"},
+	)
+
+	clockSkew = newGauge("0.2.0", prometheus.GaugeOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "clock_skew_seconds",
+		Help:      "Offset of the apiserver clock relative to the exporter clock, positive when the apiserver is ahead",
+	})
+
+	sinkReachable = newGaugeVec("0.2.0", prometheus.GaugeOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "sink_reachable",
+		Help:      "Set to 1 if the endpoint of a sink could be resolved & connected to at startup, 0 otherwise",
+	},
+		[]string{"sink", "endpoint"},
+	)
+
+	versionInfo = newGaugeVec("0.2.0", prometheus.GaugeOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "cluster",
+		Name:      "info",
+		Help:      "Set to 1, labelled with the kubernetes & openebs versions of the cluster",
+	},
+		[]string{"kubernetes_version", "openebs_version"},
+	)
+
+	appSLA = newGaugeVec("0.2.0", prometheus.GaugeOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "application",
+		Name:      "resilience_sla_percent",
+		Help:      "Percentage of the time under chaos during which the application stayed available",
+	},
+		[]string{"app_namespace", "app_label"},
+	)
+
+	appChaosSeconds = newGaugeVec("0.2.0", prometheus.GaugeOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "application",
+		Name:      "chaos_seconds",
+		Help:      "Time the application spent under chaos since the exporter started",
+	},
+		[]string{"app_namespace", "app_label"},
+	)
+
+	appAvailableSecs = newGaugeVec("0.2.0", prometheus.GaugeOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "application",
+		Name:      "available_seconds",
+		Help:      "Time the application stayed available (no failing probes) while under chaos",
+	},
+		[]string{"app_namespace", "app_label"},
+	)
+
+	notificationsSent = newCounterVec("0.2.0", prometheus.CounterOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "notifications_total",
+		Help:      "Total number of verdict events posted to the notification targets of the chaosexporterconfig, by result",
+	},
+		[]string{"target", "result"},
+	)
+
+	pushes = newCounterVec("0.2.0", prometheus.CounterOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "pushgateway_requests_total",
+		Help:      "Total number of requests to the Pushgateway, by request (push or delete of a chaosengine group) & result",
+	},
+		[]string{"request", "result"},
+	)
+
+	otlpExports = newCounterVec("0.2.0", prometheus.CounterOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "otlp_exports_total",
+		Help:      "Total number of exports of the metrics to the OTLP endpoint, by result",
+	},
+		[]string{"result"},
+	)
+
+	datadogRequests = newCounterVec("0.2.0", prometheus.CounterOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "datadog_requests_total",
+		Help:      "Total number of submissions to the Datadog API, by request (series or event) & result",
+	},
+		[]string{"request", "result"},
+	)
+
+	cloudWatchPublishes = newCounterVec("0.2.0", prometheus.CounterOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "cloudwatch_publishes_total",
+		Help:      "Total number of publishings of the metrics to CloudWatch, by result",
+	},
+		[]string{"result"},
+	)
+
+	cloudMonitoringWrites = newCounterVec("0.2.0", prometheus.CounterOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "cloud_monitoring_writes_total",
+		Help:      "Total number of writes of the metrics to Google Cloud Monitoring, by result",
+	},
+		[]string{"result"},
+	)
+
+	influxWrites = newCounterVec("0.2.0", prometheus.CounterOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "influxdb_writes_total",
+		Help:      "Total number of writes of the metrics to InfluxDB, by result",
+	},
+		[]string{"result"},
+	)
+
+	graphiteFlushes = newCounterVec("0.2.0", prometheus.CounterOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "graphite_flushes_total",
+		Help:      "Total number of flushes of the metrics to Graphite, by result",
+	},
+		[]string{"result"},
+	)
+
+	kafkaEvents = newCounterVec("0.2.0", prometheus.CounterOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "kafka_events_total",
+		Help:      "Total number of verdict events produced to Kafka, by result",
+	},
+		[]string{"result"},
+	)
+
+	incidentRequests = newCounterVec("0.2.0", prometheus.CounterOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "incident_requests_total",
+		Help:      "Total number of requests opening & closing incidents, by request & result",
+	},
+		[]string{"request", "result"},
+	)
+
+	remoteWrites = newCounterVec("0.2.0", prometheus.CounterOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "remote_writes_total",
+		Help:      "Total number of writes of the metrics to the remote_write endpoint, by result",
+	},
+		[]string{"result"},
+	)
+
+	chaosCenterReports = newCounterVec("0.2.0", prometheus.CounterOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "chaoscenter_reports_total",
+		Help:      "Total number of reports of the runs to the Litmus ChaosCenter, by result",
+	},
+		[]string{"result"},
+	)
+
+	azureMonitorPublishes = newCounterVec("0.2.0", prometheus.CounterOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "azure_monitor_publishes_total",
+		Help:      "Total number of publishings of the metrics to Azure Monitor, by result",
+	},
+		[]string{"result"},
+	)
+
+	newRelicSends = newCounterVec("0.2.0", prometheus.CounterOpts{
+		Namespace: "litmuschaos",
+		Subsystem: "exporter",
+		Name:      "newrelic_sends_total",
+		Help:      "Total number of sends of the metrics to the New Relic Metric API, by result",
+	},
+		[]string{"result"},
+	)
+
+	prometheus.MustRegister(engineCollector{})
+	prometheus.MustRegister(verdictTransitions)
+	prometheus.MustRegister(engineInvalid)
+	prometheus.MustRegister(engineStale)
+	prometheus.MustRegister(exporterLeader)
+	prometheus.MustRegister(collectionErrors)
+	prometheus.MustRegister(clockSkew)
+	prometheus.MustRegister(trackedState)
+	prometheus.MustRegister(selfTestSuccess)
+	prometheus.MustRegister(httpRequests)
+	prometheus.MustRegister(httpRequestDuration)
+	prometheus.MustRegister(sinkReachable)
+	prometheus.MustRegister(versionInfo)
+	prometheus.MustRegister(appSLA)
+	prometheus.MustRegister(appChaosSeconds)
+	prometheus.MustRegister(appAvailableSecs)
+	prometheus.MustRegister(notificationsSent)
+	prometheus.MustRegister(pushes)
+	prometheus.MustRegister(otlpExports)
+	prometheus.MustRegister(datadogRequests)
+	prometheus.MustRegister(cloudWatchPublishes)
+	prometheus.MustRegister(cloudMonitoringWrites)
+	prometheus.MustRegister(influxWrites)
+	prometheus.MustRegister(graphiteFlushes)
+	prometheus.MustRegister(kafkaEvents)
+	prometheus.MustRegister(incidentRequests)
+	prometheus.MustRegister(remoteWrites)
+	prometheus.MustRegister(chaosCenterReports)
+	prometheus.MustRegister(azureMonitorPublishes)
+	prometheus.MustRegister(newRelicSends)
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"testing"
+)
+
+// TestLabelValues verifies that the namespace is carried as a label
+func TestLabelValues(t *testing.T) {
+	if got := labelNames("engine_name"); len(got) != 2 || got[0] != "chaos_namespace" {
+		t.Errorf("unexpected label names %v", got)
+	}
+	if got := labelValues("litmus", "engine-nginx"); len(got) != 2 || got[0] != "litmus" {
+		t.Errorf("unexpected label values %v", got)
+	}
+}
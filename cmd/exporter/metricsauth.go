@@ -0,0 +1,166 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/litmuschaos/chaos-exporter/pkg/credentials"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// metricsAuth holds the credentials a scraper presents to read /metrics, either of which is accepted
+type metricsAuth struct {
+	username string
+	// bcrypt hash of the basic auth password
+	passwordHash credentials.Provider
+	bearerToken  credentials.Provider
+	// bcrypt hashes of the passwords of the basic_auth_users of the web config file, by username
+	users map[string][]byte
+	// Holds the SHA-256 of the user credentials verified, as bcrypt is too slow to run on every scrape
+	mu       sync.Mutex
+	verified map[[sha256.Size]byte]bool
+}
+
+// newMetricsAuth returns the credentials required on /metrics, nil if none are configured. The password hash &
+// bearer token are credential references, resolved on every scrape so that rotated secrets are picked up
+func newMetricsAuth(username string, passwordHashRef string, bearerTokenRef string) (*metricsAuth, error) {
+	if username == "" && passwordHashRef == "" && bearerTokenRef == "" {
+		return nil, nil
+	}
+	if (username == "") != (passwordHashRef == "") {
+		return nil, fmt.Errorf("basic auth requires both a username & a password hash")
+	}
+
+	auth := &metricsAuth{username: username, verified: make(map[[sha256.Size]byte]bool)}
+	var err error
+	if passwordHashRef != "" {
+		if auth.passwordHash, err = credentials.New(passwordHashRef); err != nil {
+			return nil, err
+		}
+	}
+	if bearerTokenRef != "" {
+		if auth.bearerToken, err = credentials.New(bearerTokenRef); err != nil {
+			return nil, err
+		}
+	}
+	return auth, nil
+}
+
+// withUsers returns a, or new credentials if nil, also accepting the basic auth users of the web config file,
+// the bcrypt hashes of their passwords by username
+func (a *metricsAuth) withUsers(users map[string]string) *metricsAuth {
+	if len(users) == 0 {
+		return a
+	}
+	if a == nil {
+		a = &metricsAuth{}
+	}
+	a.users = make(map[string][]byte, len(users))
+	for username, hash := range users {
+		a.users[username] = []byte(hash)
+	}
+	if a.verified == nil {
+		a.verified = make(map[[sha256.Size]byte]bool)
+	}
+	return a
+}
+
+// protect requires the configured credentials before serving handler. A nil metricsAuth serves it as is
+func (a *metricsAuth) protect(handler http.Handler) http.Handler {
+	if a == nil {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.authenticated(r) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		a.challenge(w)
+	})
+}
+
+// requireUsers serves handler, the root handler of the server, to the basic auth users of the web config file
+// only, on every path as the other Prometheus exporters do. The credentials of /metrics & the tokens of the JSON
+// API are accepted as well, their paths checking them again. A nil metricsAuth, or one without users, serves
+// handler as is
+func (a *metricsAuth) requireUsers(handler http.Handler) http.Handler {
+	if a == nil || len(a.users) == 0 {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := presentedToken(r); ok || a.authenticated(r) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		if len(apiTokens) > 0 && a.bearerToken == nil {
+			w.Header().Add("WWW-Authenticate", "Bearer")
+		}
+		a.challenge(w)
+	})
+}
+
+// challenge answers a request without valid credentials, listing the authentication schemes accepted
+func (a *metricsAuth) challenge(w http.ResponseWriter) {
+	if a.passwordHash != nil || len(a.users) > 0 {
+		w.Header().Add("WWW-Authenticate", `Basic realm="chaos-exporter"`)
+	}
+	if a.bearerToken != nil {
+		w.Header().Add("WWW-Authenticate", "Bearer")
+	}
+	http.Error(w, "valid credentials are required", http.StatusUnauthorized)
+}
+
+// authenticated reports whether the request carries the basic auth credentials or the bearer token
+func (a *metricsAuth) authenticated(r *http.Request) bool {
+	if username, password, ok := r.BasicAuth(); ok && (a.passwordHash != nil || len(a.users) > 0) {
+		if hash, ok := a.users[username]; ok {
+			return a.verifyUser(username, hash, password)
+		}
+		if a.passwordHash == nil {
+			return false
+		}
+		expected, err := a.passwordHash.Get()
+		if err != nil {
+			log.Error("Unable to read the metrics password hash: ", err)
+			return false
+		}
+		hash := []byte(strings.TrimSpace(expected))
+		if _, err := bcrypt.Cost(hash); err != nil {
+			log.Error("Invalid bcrypt hash of the metrics password: ", err)
+			return false
+		}
+		return subtle.ConstantTimeCompare([]byte(username), []byte(a.username)) == 1 && a.verifyUser(username, hash, password)
+	}
+	if presented := r.Header.Get("Authorization"); strings.HasPrefix(presented, "Bearer ") && a.bearerToken != nil {
+		expected, err := a.bearerToken.Get()
+		if err != nil {
+			log.Error("Unable to read the metrics bearer token: ", err)
+			return false
+		}
+		return expected != "" && subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(presented, "Bearer ")), []byte(expected)) == 1
+	}
+	return false
+}
+
+// verifyUser reports whether password matches the bcrypt hash of the user, remembering the credentials verified
+func (a *metricsAuth) verifyUser(username string, hash []byte, password string) bool {
+	key := sha256.Sum256([]byte(username + "\x00" + string(hash) + "\x00" + password))
+	a.mu.Lock()
+	verified := a.verified[key]
+	a.mu.Unlock()
+	if verified {
+		return true
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil {
+		return false
+	}
+	a.mu.Lock()
+	a.verified[key] = true
+	a.mu.Unlock()
+	return true
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/credentials"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestMetricsAuth(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("TEST_METRICS_PASSWORD_HASH", string(hash))
+	os.Setenv("TEST_METRICS_TOKEN", "scrape-token")
+	defer os.Unsetenv("TEST_METRICS_PASSWORD_HASH")
+	defer os.Unsetenv("TEST_METRICS_TOKEN")
+
+	if auth, err := newMetricsAuth("", "", ""); auth != nil || err != nil {
+		t.Errorf("expected /metrics to be open without credentials, got %v, %v", auth, err)
+	}
+	if _, err := newMetricsAuth("prometheus", "", ""); err == nil {
+		t.Error("expected a username without a password hash to be rejected")
+	}
+	auth, err := newMetricsAuth("prometheus", "env:TEST_METRICS_PASSWORD_HASH", "env:TEST_METRICS_TOKEN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := auth.protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		name     string
+		prepare  func(r *http.Request)
+		expected int
+	}{
+		{"none", func(r *http.Request) {}, http.StatusUnauthorized},
+		{"basic", func(r *http.Request) { r.SetBasicAuth("prometheus", "s3cret") }, http.StatusOK},
+		{"wrong password", func(r *http.Request) { r.SetBasicAuth("prometheus", "guess") }, http.StatusUnauthorized},
+		{"wrong username", func(r *http.Request) { r.SetBasicAuth("admin", "s3cret") }, http.StatusUnauthorized},
+		{"basic verified", func(r *http.Request) { r.SetBasicAuth("prometheus", "s3cret") }, http.StatusOK},
+		{"bearer", func(r *http.Request) { r.Header.Set("Authorization", "Bearer scrape-token") }, http.StatusOK},
+		{"wrong bearer", func(r *http.Request) { r.Header.Set("Authorization", "Bearer guess") }, http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/metrics", nil)
+		test.prepare(r)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != test.expected {
+			t.Errorf("%s: expected %d, got %d", test.name, test.expected, w.Code)
+		}
+	}
+}
+
+func TestMetricsAuthUsers(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var auth *metricsAuth
+	if auth.withUsers(nil) != nil {
+		t.Error("expected /metrics to be open without users")
+	}
+	handler := auth.withUsers(map[string]string{"prometheus": string(hash)}).protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, test := range []struct {
+		username, password string
+		expected           int
+	}{
+		{"prometheus", "s3cret", http.StatusOK},
+		// Served from the verified credentials
+		{"prometheus", "s3cret", http.StatusOK},
+		{"prometheus", "guess", http.StatusUnauthorized},
+		{"admin", "s3cret", http.StatusUnauthorized},
+	} {
+		r := httptest.NewRequest("GET", "/metrics", nil)
+		r.SetBasicAuth(test.username, test.password)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != test.expected {
+			t.Errorf("%s:%s: expected %d, got %d", test.username, test.password, test.expected, w.Code)
+		}
+	}
+
+	// The users are required on every path, the tokens of the JSON API are accepted in their place
+	os.Setenv("TEST_API_TOKEN", "api-secret")
+	defer os.Unsetenv("TEST_API_TOKEN")
+	apiTokens = []apiToken{{credential: &credentials.EnvProvider{Name: "TEST_API_TOKEN"}}}
+	defer func() { apiTokens = nil }()
+	if auth.requireUsers(http.NotFoundHandler()) == nil {
+		t.Error("expected the server to be open without users")
+	}
+	server := auth.withUsers(map[string]string{"prometheus": string(hash)}).requireUsers(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, test := range []struct {
+		path     string
+		prepare  func(r *http.Request)
+		expected int
+	}{
+		{"/version", func(r *http.Request) {}, http.StatusUnauthorized},
+		{"/api/v1/status", func(r *http.Request) {}, http.StatusUnauthorized},
+		{"/version", func(r *http.Request) { r.SetBasicAuth("prometheus", "s3cret") }, http.StatusOK},
+		{"/api/v1/status", func(r *http.Request) { r.Header.Set("Authorization", "Bearer api-secret") }, http.StatusOK},
+		{"/api/v1/status", func(r *http.Request) { r.Header.Set("Authorization", "Bearer guess") }, http.StatusUnauthorized},
+	} {
+		r := httptest.NewRequest("GET", test.path, nil)
+		test.prepare(r)
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, r)
+		if w.Code != test.expected {
+			t.Errorf("%s %s: expected %d, got %d", test.path, r.Header.Get("Authorization"), test.expected, w.Code)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Set to log a line per request served by the exporter
+var accessLog bool
+
+// gzipResponseWriter compresses the body of a response, unless its status has no body
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	writer      *gzip.Writer
+	wroteHeader bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if code != http.StatusNoContent && code != http.StatusNotModified {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Del("Content-Length")
+		w.writer = gzip.NewWriter(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.writer == nil {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.writer.Write(data)
+}
+
+// close flushes the compressed body, if any
+func (w *gzipResponseWriter) close() {
+	if w.writer != nil {
+		w.writer.Close()
+	}
+}
+
+// gzipAccepted reports whether the client of r accepts gzip encoded responses, i.e. whether gzip, or failing
+// that *, is listed in its Accept-Encoding with a non zero quality
+func gzipAccepted(r *http.Request) bool {
+	gzipQuality, wildcardQuality := -1.0, -1.0
+	for _, coding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		params := strings.Split(coding, ";")
+		quality := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if len(param) > 2 && strings.EqualFold(param[:2], "q=") {
+				value, err := strconv.ParseFloat(param[2:], 64)
+				if err != nil {
+					value = 0
+				}
+				quality = value
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(params[0])) {
+		case "gzip", "x-gzip":
+			gzipQuality = quality
+		case "*":
+			wildcardQuality = quality
+		}
+	}
+	if gzipQuality >= 0 {
+		return gzipQuality > 0
+	}
+	return wildcardQuality > 0
+}
+
+// compress gzips the responses of handler for the clients accepting it, but for HEAD requests & the
+// responses without a body. The metrics handler compresses its responses itself
+func compress(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if r.Method == http.MethodHead || !gzipAccepted(r) {
+			handler(w, r)
+			return
+		}
+		writer := &gzipResponseWriter{ResponseWriter: w}
+		defer writer.close()
+		handler(writer, r)
+	}
+}
+
+// instrumentedMux is a ServeMux the handlers of which are counted & timed, labelled by the pattern they are
+// registered under so that unknown paths don't create series of their own, and logged if accessLog is set
+type instrumentedMux struct {
+	*http.ServeMux
+}
+
+// newInstrumentedMux returns an empty instrumentedMux
+func newInstrumentedMux() instrumentedMux {
+	return instrumentedMux{ServeMux: http.NewServeMux()}
+}
+
+// Handle registers handler for pattern, instrumented
+func (m instrumentedMux) Handle(pattern string, handler http.Handler) {
+	labels := prometheus.Labels{"handler": pattern}
+	m.ServeMux.Handle(pattern, promhttp.InstrumentHandlerCounter(httpRequests.MustCurryWith(labels),
+		promhttp.InstrumentHandlerDuration(httpRequestDuration.MustCurryWith(labels), logRequests(pattern, handler))))
+}
+
+// HandleFunc registers handler for pattern, instrumented
+func (m instrumentedMux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// Unregistered vec labelled by code, the collector of the requestObservers
+var accessLogVec = prometheus.NewSummaryVec(prometheus.SummaryOpts{Name: "access_log", Help: "Access log"}, []string{"code"})
+
+// requestObserver holds the status code & the value the promhttp instrumentation observed for a single request
+type requestObserver struct {
+	prometheus.ObserverVec
+	code  string
+	value float64
+}
+
+// With records the status code of the request
+func (o *requestObserver) With(labels prometheus.Labels) prometheus.Observer {
+	o.code = labels["code"]
+	return o
+}
+
+// Observe records the value observed for the request
+func (o *requestObserver) Observe(value float64) {
+	o.value = value
+}
+
+// logRequests logs a line per request served by handler if accessLog is set. The status code, size & duration of
+// the responses are observed by the promhttp instrumentation, which keeps the interfaces of the response writer
+func logRequests(pattern string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !accessLog {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		size, duration := &requestObserver{ObserverVec: accessLogVec}, &requestObserver{ObserverVec: accessLogVec}
+		promhttp.InstrumentHandlerResponseSize(size, promhttp.InstrumentHandlerDuration(duration, handler)).ServeHTTP(w, r)
+		log.WithFields(log.Fields{
+			"method":   r.Method,
+			"path":     r.URL.Path,
+			"handler":  pattern,
+			"code":     size.code,
+			"bytes":    int(size.value),
+			"duration": time.Duration(duration.value * float64(time.Second)).String(),
+			"remote":   r.RemoteAddr,
+		}).Info("request served")
+	})
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestInstrument(t *testing.T) {
+	mux := newInstrumentedMux()
+	mux.HandleFunc("/api/v1/status", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/", http.NotFound)
+	mux.HandleFunc("/api/v1/events", func(w http.ResponseWriter, r *http.Request) {
+		// The response writer keeps its interfaces
+		if _, ok := w.(http.Flusher); !ok {
+			t.Error("expected the response writer to be a flusher")
+		}
+	})
+	accessLog = true
+	defer func() { accessLog = false }()
+	for _, path := range []string{"/api/v1/status", "/api/v1/status", "/unknown", "/api/v1/events"} {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	count := func(labels ...string) float64 {
+		metric := &dto.Metric{}
+		if err := httpRequests.WithLabelValues(labels...).Write(metric); err != nil {
+			t.Fatal(err)
+		}
+		return metric.GetCounter().GetValue()
+	}
+	if value := count("/api/v1/status", "get", "503"); value != 2 {
+		t.Errorf("expected 2 requests to the status endpoint, got %v", value)
+	}
+	// Unknown paths share the series of the catch-all pattern
+	if value := count("/", "get", "404"); value != 1 {
+		t.Errorf("expected 1 unknown request, got %v", value)
+	}
+	httpRequests.Reset()
+	httpRequestDuration.Reset()
+}
+
+func TestCompress(t *testing.T) {
+	handler := compress(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{"status": "ok"})
+	})
+
+	r := httptest.NewRequest("GET", "/api/v1/status", nil)
+	r.Header.Set("Accept-Encoding", "deflate, gzip")
+	w := httptest.NewRecorder()
+	handler(w, r)
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected a gzip encoded response, got %v", w.Header())
+	}
+	reader, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(reader).Decode(&body); err != nil || body["status"] != "ok" {
+		t.Errorf("unexpected body %v, %v", body, err)
+	}
+
+	// Clients not accepting gzip get the plain response
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/api/v1/status", nil))
+	if w.Header().Get("Content-Encoding") != "" || !strings.Contains(w.Body.String(), `"ok"`) {
+		t.Errorf("unexpected plain response %v: %s", w.Header(), w.Body.String())
+	}
+
+	// Neither HEAD requests nor the responses without a body are compressed
+	r = httptest.NewRequest("HEAD", "/api/v1/status", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	handler(w, r)
+	if w.Header().Get("Content-Encoding") != "" {
+		t.Errorf("expected a plain response to HEAD, got %v", w.Header())
+	}
+	notModified := compress(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	})
+	r = httptest.NewRequest("GET", "/api/v1/status", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	notModified(w, r)
+	if w.Code != http.StatusNotModified || w.Header().Get("Content-Encoding") != "" || w.Body.Len() != 0 {
+		t.Errorf("unexpected not modified response %d %v: %q", w.Code, w.Header(), w.Body.String())
+	}
+}
+
+func TestGzipAccepted(t *testing.T) {
+	for acceptEncoding, accepted := range map[string]bool{
+		"":                   false,
+		"gzip":               true,
+		"deflate, GZIP":      true,
+		"gzip;q=0.5":         true,
+		"gzip;q=0":           false,
+		"gzip; q=0.0":        false,
+		"gzip;q=0.000":       false,
+		"gzip;q=0, *":        false,
+		"*":                  true,
+		"*;q=0":              false,
+		"deflate, br":        false,
+		"gzipped, identity":  false,
+		"identity, *;q=0.1":  true,
+		"gzip;level=1;q=0.3": true,
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+		if gzipAccepted(r) != accepted {
+			t.Errorf("expected %q to accept gzip: %v", acceptEncoding, accepted)
+		}
+	}
+}
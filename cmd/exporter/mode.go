@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+)
+
+// Deployment modes of the exporter
+const (
+	// Runs alongside a single chaosengine, set by CHAOSENGINE
+	modeSidecar = "sidecar"
+	// Discovers the chaosengines of one or more namespaces
+	modeStandalone = "standalone"
+)
+
+// modeSettings holds the defaults of a deployment mode, applied to the settings not set explicitly
+type modeSettings struct {
+	resync  time.Duration
+	workers int
+}
+
+// Holds the defaults of each deployment mode. A sidecar follows a single engine closely, a standalone
+// exporter spreads the collection of many engines over several workers
+var modeDefaults = map[string]modeSettings{
+	modeSidecar:    {resync: 30 * time.Second, workers: 1},
+	modeStandalone: {resync: 60 * time.Second, workers: 4},
+}
+
+// detectMode returns the deployment mode, as requested or else derived from whether a single chaosengine is set
+func detectMode(requested string, chaosEngine string) (string, error) {
+	switch requested {
+	case "":
+		if chaosEngine != "" {
+			return modeSidecar, nil
+		}
+		return modeStandalone, nil
+	case modeSidecar:
+		if chaosEngine == "" {
+			return "", fmt.Errorf("sidecar mode requires CHAOSENGINE to be set")
+		}
+		return modeSidecar, nil
+	case modeStandalone:
+		if chaosEngine != "" {
+			return "", fmt.Errorf("standalone mode discovers the chaosengines, CHAOSENGINE must not be set")
+		}
+		return modeStandalone, nil
+	}
+	return "", fmt.Errorf("invalid mode %q, expected %s or %s", requested, modeSidecar, modeStandalone)
+}
+
+// applyModeDefaults defaults the resync period of base & runtime, unless reloaded from the config file, and
+// the collection workers to those of mode, for the settings not set explicitly
+func applyModeDefaults(mode string, base, runtime *runtimeSettings) {
+	if !settingSet("resync-period") {
+		if runtime.resync == base.resync {
+			runtime.resync = modeDefaults[mode].resync
+		}
+		base.resync = modeDefaults[mode].resync
+	}
+	if !settingSet("collection-workers") {
+		collectionWorkers = modeDefaults[mode].workers
+	}
+}
+
+// flagSet checks whether a flag was set on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestDetectMode verifies that the mode is derived from CHAOSENGINE, and that a requested mode must match it
+func TestDetectMode(t *testing.T) {
+	tests := []struct {
+		requested, chaosEngine, mode string
+	}{
+		{"", "engine-nginx", modeSidecar},
+		{"", "", modeStandalone},
+		{modeStandalone, "", modeStandalone},
+		{modeSidecar, "", ""},
+		{modeStandalone, "engine-nginx", ""},
+		{"daemonset", "", ""},
+	}
+	for _, test := range tests {
+		mode, err := detectMode(test.requested, test.chaosEngine)
+		if mode != test.mode || (err == nil) != (test.mode != "") {
+			t.Errorf("detectMode(%q, %q) = %q, %v, expected %q", test.requested, test.chaosEngine, mode, err, test.mode)
+		}
+	}
+}
+
+func TestApplyModeDefaults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chaos-exporter-settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(path, []byte("resync-period: 5m\ncollection-workers: 9\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fs := flag.NewFlagSet("exporter", flag.ContinueOnError)
+	resync := fs.Duration("resync-period", 60*time.Second, "")
+	workers := fs.Int("collection-workers", 4, "")
+	defer func(previous int) { collectionWorkers = previous }(collectionWorkers)
+
+	// The values of the settings file are kept over the defaults of the mode
+	if fileSettings, err = applySettingsFile(fs, path); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { fileSettings = nil }()
+	collectionWorkers = *workers
+	base := runtimeSettings{resync: *resync}
+	runtime := base
+	applyModeDefaults(modeSidecar, &base, &runtime)
+	if base.resync != 5*time.Minute || runtime.resync != 5*time.Minute || collectionWorkers != 9 {
+		t.Errorf("expected the settings of the file, got resync %s & %d workers", runtime.resync, collectionWorkers)
+	}
+
+	// Those not set are defaulted for the mode
+	fileSettings = nil
+	applyModeDefaults(modeSidecar, &base, &runtime)
+	if base.resync != 30*time.Second || runtime.resync != 30*time.Second || collectionWorkers != 1 {
+		t.Errorf("expected the sidecar defaults, got resync %s & %d workers", runtime.resync, collectionWorkers)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/credentials"
+	"github.com/litmuschaos/chaos-exporter/pkg/sinks"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Holds the settings of the New Relic sink, an empty license key disables it
+var (
+	newRelicLicenseKey  string
+	newRelicRegion      string
+	newRelicClusterName string
+	newRelicTimeout     time.Duration
+)
+
+// Holds the New Relic sink, nil unless it is enabled
+var newRelicSink *newRelicPublisher
+
+// newRelicPublisher sends the experiment counts, verdicts & chaos durations to New Relic
+type newRelicPublisher struct {
+	client *sinks.NewRelic
+}
+
+// newNewRelicPublisher returns the publisher of the account of the license key, a credential reference, nil if no
+// key is configured. The metrics carry the k8s.cluster.name of the cluster, for New Relic to link them to its
+// entity along with those of its Kubernetes integration
+func newNewRelicPublisher(licenseKeyRef string, region string, clusterName string, timeout time.Duration) (*newRelicPublisher, error) {
+	if licenseKeyRef == "" {
+		return nil, nil
+	}
+	if sinks.NewRelicEndpoint(region) == "" {
+		return nil, fmt.Errorf("invalid region %q, expected us or eu", region)
+	}
+	licenseKey, err := credentials.New(licenseKeyRef)
+	if err != nil {
+		return nil, err
+	}
+	common := map[string]string{
+		"instrumentation.provider": "litmuschaos",
+		"instrumentation.name":     "chaos-exporter",
+		"instrumentation.version":  exporterVersion,
+		"service.name":             "chaos-exporter",
+	}
+	if clusterName != "" {
+		common["k8s.cluster.name"] = clusterName
+	}
+	return &newRelicPublisher{client: &sinks.NewRelic{
+		Region:           region,
+		LicenseKey:       licenseKey,
+		CommonAttributes: common,
+		Client:           &http.Client{Timeout: timeout},
+	}}, nil
+}
+
+// publish sends the gauges of the last collection of every chaosengine, and the chaos durations of the
+// applications under test. Called after each collection pass, it does nothing unless the sink is enabled
+func (p *newRelicPublisher) publish(ctx context.Context, gatherer prometheus.Gatherer) {
+	if p == nil {
+		return
+	}
+	_, engines, err := gatherEngineFamilies()
+	if err != nil {
+		recordNewRelicSend(err)
+		return
+	}
+	var metrics []sinks.NewRelicMetric
+	for _, families := range engines {
+		metrics = append(metrics, newRelicMetrics(families)...)
+	}
+	families, err := gatherer.Gather()
+	if err != nil {
+		recordNewRelicSend(err)
+		return
+	}
+	for _, family := range families {
+		if applicationDurations[family.GetName()] {
+			metrics = append(metrics, newRelicMetrics([]*dto.MetricFamily{family})...)
+		}
+	}
+	if len(metrics) == 0 {
+		return
+	}
+	recordNewRelicSend(p.client.Send(ctx, metrics, exporterClock.Now()))
+}
+
+// newRelicMetrics converts the gauges of families to New Relic metrics, attributed with their non empty labels
+// and the k8s.namespace.name of the application under test, or of the chaosengine
+func newRelicMetrics(families []*dto.MetricFamily) []sinks.NewRelicMetric {
+	var metrics []sinks.NewRelicMetric
+	for _, family := range families {
+		if family.GetType() != dto.MetricType_GAUGE {
+			continue
+		}
+		for _, metric := range family.Metric {
+			attributes := make(map[string]string, len(metric.Label)+1)
+			for _, label := range metric.Label {
+				if label.GetValue() != "" {
+					attributes[label.GetName()] = label.GetValue()
+				}
+			}
+			if namespace := attributes["app_namespace"]; namespace != "" {
+				attributes["k8s.namespace.name"] = namespace
+			} else if namespace := attributes["chaos_namespace"]; namespace != "" {
+				attributes["k8s.namespace.name"] = namespace
+			}
+			metrics = append(metrics, sinks.NewRelicMetric{Name: family.GetName(), Value: metric.GetGauge().GetValue(), Attributes: attributes})
+		}
+	}
+	return metrics
+}
+
+// recordNewRelicSend counts the outcome of a send, logging failures
+func recordNewRelicSend(err error) {
+	if err == nil {
+		newRelicSends.WithLabelValues("success").Inc()
+		return
+	}
+	newRelicSends.WithLabelValues("failure").Inc()
+	if logger := logSampling.sample(exporterLog, "newrelic"); logger != nil {
+		logger.Warn("Unable to send the metrics to New Relic: ", err)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestNewRelicMetrics(t *testing.T) {
+	family := &dto.MetricFamily{
+		Name: proto.String("c_engine_failed_experiments"),
+		Type: dto.MetricType_GAUGE.Enum(),
+		Metric: []*dto.Metric{{
+			Label: []*dto.LabelPair{{Name: proto.String("chaos_namespace"), Value: proto.String("litmus")}, {Name: proto.String("app_uid"), Value: proto.String("")}},
+			Gauge: &dto.Gauge{Value: proto.Float64(1)},
+		}},
+	}
+	metrics := newRelicMetrics([]*dto.MetricFamily{family})
+	expected := map[string]string{"chaos_namespace": "litmus", "k8s.namespace.name": "litmus"}
+	if len(metrics) != 1 || metrics[0].Value != 1 || !reflect.DeepEqual(metrics[0].Attributes, expected) {
+		t.Errorf("expected a metric attributed with %v, got %+v", expected, metrics)
+	}
+	if _, err := newNewRelicPublisher("env:NEW_RELIC_LICENSE_KEY", "apac", "", time.Second); err == nil {
+		t.Error("expected an error for an unknown region")
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// labelNormalizer rewrites the label values of the chaos metrics before exposition, so that engines
+// named inconsistently across teams (e.g. Engine_Nginx, engine-nginx) end up in the same series
+type labelNormalizer struct {
+	// Values mapped as is, taking precedence over the rules below
+	mapping   map[string]string
+	lowercase bool
+	replacer  *strings.Replacer
+}
+
+// Holds the normalization applied by labelValues, nil leaves the label values untouched
+var labelNormalization *labelNormalizer
+
+// newLabelNormalizer parses the normalization rules. replace is a comma separated list of old:new
+// character pairs (e.g. "_:-,.:-") and mapping a comma separated list of from=to values
+func newLabelNormalizer(lowercase bool, replace string, mapping string) (*labelNormalizer, error) {
+	if !lowercase && replace == "" && mapping == "" {
+		return nil, nil
+	}
+
+	n := &labelNormalizer{lowercase: lowercase, mapping: make(map[string]string)}
+	if replace != "" {
+		var pairs []string
+		for _, pair := range strings.Split(replace, ",") {
+			parts := strings.SplitN(pair, ":", 2)
+			if len(parts) != 2 || parts[0] == "" {
+				return nil, fmt.Errorf("invalid replacement %q, expected old:new", pair)
+			}
+			pairs = append(pairs, parts[0], parts[1])
+		}
+		n.replacer = strings.NewReplacer(pairs...)
+	}
+	if mapping != "" {
+		for _, pair := range strings.Split(mapping, ",") {
+			parts := strings.SplitN(pair, "=", 2)
+			if len(parts) != 2 || parts[0] == "" {
+				return nil, fmt.Errorf("invalid mapping %q, expected from=to", pair)
+			}
+			n.mapping[parts[0]] = parts[1]
+		}
+	}
+	return n, nil
+}
+
+// normalize returns the exposed form of a label value
+func (n *labelNormalizer) normalize(value string) string {
+	if n == nil {
+		return value
+	}
+	if mapped, ok := n.mapping[value]; ok {
+		return mapped
+	}
+	if n.lowercase {
+		value = strings.ToLower(value)
+	}
+	if n.replacer != nil {
+		value = n.replacer.Replace(value)
+	}
+	return value
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+)
+
+// TestLabelNormalizer verifies that mapped values take precedence over the lowercasing & replacement rules
+func TestLabelNormalizer(t *testing.T) {
+	n, err := newLabelNormalizer(true, "_:-,.:-", "Payments_Engine=payments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"Engine_Nginx":    "engine-nginx",
+		"engine.nginx":    "engine-nginx",
+		"engine-nginx":    "engine-nginx",
+		"Payments_Engine": "payments",
+	}
+	for value, expected := range tests {
+		if got := n.normalize(value); got != expected {
+			t.Errorf("normalize(%q) = %q, expected %q", value, got, expected)
+		}
+	}
+
+	if n, err := newLabelNormalizer(false, "", ""); err != nil || n != nil || n.normalize("Engine_Nginx") != "Engine_Nginx" {
+		t.Errorf("expected no normalization when no rule is set")
+	}
+	if _, err := newLabelNormalizer(false, "_", ""); err == nil {
+		t.Errorf("expected an error for a replacement without a new value")
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/apis/litmuschaos/v1alpha1"
+	"github.com/litmuschaos/chaos-exporter/pkg/credentials"
+)
+
+// Time after which the delivery of a verdict event to a notification target fails
+var notificationTimeout = 10 * time.Second
+
+// notificationTarget is a target of the ChaosExporterConfig CR, along with the provider of its urlFrom if set
+type notificationTarget struct {
+	v1alpha1.NotificationTarget
+	urlFrom credentials.Provider
+}
+
+// Holds the targets the verdict events are posted to, set from the notificationTargets of the
+// ChaosExporterConfig CR
+var (
+	notificationTargetsMu sync.Mutex
+	notificationTargets   []notificationTarget
+)
+
+// Formats of the notification targets, json posting every verdict event as is
+var notificationFormats = map[string]bool{"": true, "json": true, "slack": true, "teams": true}
+
+// Severities of the notifications: a failed experiment is critical, an engine completed with failures warning
+var notificationSeverities = map[string]bool{"critical": true, "warning": true, "info": true}
+
+// validateNotificationTargets checks the targets are named once, with an http(s) URL or a credential reference of
+// it, a known format & severities
+func validateNotificationTargets(targets []v1alpha1.NotificationTarget) error {
+	names := make(map[string]bool)
+	for _, target := range targets {
+		if target.Name == "" {
+			return fmt.Errorf("notificationTargets entry without a name")
+		}
+		if names[target.Name] {
+			return fmt.Errorf("notificationTargets %q listed twice", target.Name)
+		}
+		names[target.Name] = true
+		switch {
+		case (target.URL == "") == (target.URLFrom == ""):
+			return fmt.Errorf("notificationTargets %q: expected either a url or a urlFrom", target.Name)
+		case target.URLFrom != "":
+			if _, err := credentials.New(target.URLFrom); err != nil {
+				return fmt.Errorf("notificationTargets %q: invalid urlFrom: %v", target.Name, err)
+			}
+		case !webhookURL(target.URL):
+			return fmt.Errorf("notificationTargets %q: expected an http(s) URL, got %q", target.Name, target.URL)
+		}
+		if !notificationFormats[target.Format] {
+			return fmt.Errorf("notificationTargets %q: invalid format %q, expected json, slack or teams", target.Name, target.Format)
+		}
+		for _, severity := range target.Severities {
+			if !notificationSeverities[severity] {
+				return fmt.Errorf("notificationTargets %q: invalid severity %q, expected critical, warning or info", target.Name, severity)
+			}
+		}
+	}
+	return nil
+}
+
+// webhookURL reports whether address is an http(s) URL
+func webhookURL(address string) bool {
+	parsed, err := url.Parse(address)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// setNotificationTargets replaces the targets the verdict events are posted to, once validated
+func setNotificationTargets(targets []v1alpha1.NotificationTarget) {
+	resolved := make([]notificationTarget, 0, len(targets))
+	for _, target := range targets {
+		entry := notificationTarget{NotificationTarget: target}
+		if target.URLFrom != "" {
+			entry.urlFrom, _ = credentials.New(target.URLFrom)
+		}
+		resolved = append(resolved, entry)
+	}
+	notificationTargetsMu.Lock()
+	defer notificationTargetsMu.Unlock()
+	notificationTargets = resolved
+}
+
+// address returns the URL the notifications are posted to, reading its credential reference on every call so
+// that a rotated URL is picked up. The value is left out of the errors, as it holds a secret
+func (t notificationTarget) address() (string, error) {
+	if t.urlFrom == nil {
+		return t.URL, nil
+	}
+	address, err := t.urlFrom.Get()
+	if err != nil {
+		return "", fmt.Errorf("unable to read the urlFrom: %v", err)
+	}
+	if !webhookURL(address) {
+		return "", fmt.Errorf("the urlFrom does not hold an http(s) URL")
+	}
+	return address, nil
+}
+
+// runNotifier posts the verdict events routed to each notification target until ctx is done. Failed
+// deliveries are counted per target, not retried
+func runNotifier(ctx context.Context) {
+	events, unsubscribe := verdictEvents.subscribe()
+	defer unsubscribe()
+	client := &http.Client{Timeout: notificationTimeout}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			notificationTargetsMu.Lock()
+			targets := notificationTargets
+			notificationTargetsMu.Unlock()
+			for _, target := range targets {
+				notify(ctx, client, target, event)
+			}
+		}
+	}
+}
+
+// notify posts event to target if routed to it, counting the outcome
+func notify(ctx context.Context, client *http.Client, target notificationTarget, event verdictEvent) {
+	payload, ok := notificationPayload(target.NotificationTarget, event)
+	if !ok {
+		return
+	}
+	result := "success"
+	address, err := target.address()
+	if err == nil {
+		err = postEvent(ctx, client, address, payload)
+		// The errors of the client carry the URL
+		if urlErr, ok := err.(*url.Error); ok && target.urlFrom != nil {
+			err = urlErr.Err
+		}
+	}
+	if err != nil {
+		if logger := logSampling.sample(exporterLog.WithField("target", target.Name), "notify/"+target.Name); logger != nil {
+			logger.Warn("Unable to notify the verdict event: ", err)
+		}
+		result = "failure"
+	}
+	notificationsSent.WithLabelValues(target.Name, result).Inc()
+}
+
+// eventSeverity returns the severity of event: critical for a failed experiment, warning for an engine completed
+// with failures, info otherwise
+func eventSeverity(event verdictEvent) string {
+	switch {
+	case event.To == "fail":
+		return "critical"
+	case event.EngineVerdict == "fail":
+		return "warning"
+	}
+	return "info"
+}
+
+// notificationPayload returns the payload event is posted to target as, false if it is not routed to target: json
+// targets receive every verdict event, slack & teams ones a message on the failures & engine completions. The
+// severities of target, if any, restrict both to the events of those severities
+func notificationPayload(target v1alpha1.NotificationTarget, event verdictEvent) (interface{}, bool) {
+	severity := eventSeverity(event)
+	if len(target.Severities) > 0 {
+		routed := false
+		for _, s := range target.Severities {
+			routed = routed || s == severity
+		}
+		if !routed {
+			return nil, false
+		}
+	}
+	if target.Format == "" || target.Format == "json" {
+		return event, true
+	}
+	if event.To != "fail" && event.EngineVerdict == "" {
+		return nil, false
+	}
+
+	var title string
+	var lines []string
+	if event.To == "fail" {
+		title = fmt.Sprintf("Chaos experiment %s of %s/%s failed", event.Experiment, event.Namespace, event.Engine)
+		if event.FailStep != "" {
+			lines = append(lines, "Fail step: "+event.FailStep)
+		}
+	}
+	if event.EngineVerdict != "" {
+		completion := fmt.Sprintf("Chaos engine %s/%s completed with verdict %s", event.Namespace, event.Engine, event.EngineVerdict)
+		if title == "" {
+			title = completion
+		} else {
+			lines = append(lines, completion)
+		}
+	}
+	link := strings.NewReplacer("{namespace}", url.PathEscape(event.Namespace), "{engine}", url.PathEscape(event.Engine), "{experiment}", url.PathEscape(event.Experiment)).Replace(target.Link)
+	color := map[string]string{"critical": "#d00000", "warning": "#f2a900", "info": "#2eb886"}[severity]
+
+	if target.Format == "teams" {
+		card := map[string]interface{}{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"summary":    title,
+			"title":      title,
+			"themeColor": strings.TrimPrefix(color, "#"),
+			"text":       strings.Join(lines, "\n\n"),
+			"sections": []map[string]interface{}{{"facts": []map[string]string{
+				{"name": "Engine", "value": event.Namespace + "/" + event.Engine},
+				{"name": "Experiment", "value": event.Experiment},
+				{"name": "Verdict", "value": event.From + " → " + event.To},
+				{"name": "Severity", "value": severity},
+			}}},
+		}
+		if link != "" {
+			card["potentialAction"] = []map[string]interface{}{{
+				"@type": "OpenUri", "name": "View", "targets": []map[string]string{{"os": "default", "uri": link}},
+			}}
+		}
+		return card, true
+	}
+	attachment := map[string]interface{}{
+		"fallback": title,
+		"color":    color,
+		"title":    title,
+		"text":     strings.Join(lines, "\n"),
+		"fields": []map[string]interface{}{
+			{"title": "Engine", "value": event.Namespace + "/" + event.Engine, "short": true},
+			{"title": "Experiment", "value": event.Experiment, "short": true},
+			{"title": "Verdict", "value": event.From + " → " + event.To, "short": true},
+			{"title": "Severity", "value": severity, "short": true},
+		},
+		"ts": event.Time.Unix(),
+	}
+	if link != "" {
+		attachment["title_link"] = link
+	}
+	return map[string]interface{}{"text": title, "attachments": []map[string]interface{}{attachment}}, true
+}
+
+// postEvent posts payload as JSON to address, failing on a non 2xx response
+func postEvent(ctx context.Context, client *http.Client, address string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest(http.MethodPost, address, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/apis/litmuschaos/v1alpha1"
+)
+
+func TestNotificationPayload(t *testing.T) {
+	failed := verdictEvent{Namespace: "litmus", Engine: "engine-nginx", Experiment: "pod-delete", From: "running", To: "fail", FailStep: "Unable to get the application pods"}
+	completed := verdictEvent{Namespace: "litmus", Engine: "engine-nginx", Experiment: "pod-delete", From: "running", To: "pass", EngineVerdict: "pass"}
+	running := verdictEvent{Namespace: "litmus", Engine: "engine-nginx", Experiment: "pod-delete", From: "not-executed", To: "running"}
+
+	slack := v1alpha1.NotificationTarget{Name: "slack", URL: "https://hooks.slack.com/services/x", Format: "slack", Link: "https://grafana/d/chaos?var-engine={engine}"}
+	payload, ok := notificationPayload(slack, failed)
+	if !ok {
+		t.Fatal("expected the failure to be posted to slack")
+	}
+	body, _ := json.Marshal(payload)
+	for _, expected := range []string{"Chaos experiment pod-delete of litmus/engine-nginx failed", "Fail step: Unable to get the application pods", `"title_link":"https://grafana/d/chaos?var-engine=engine-nginx"`} {
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("expected %s in %s", expected, body)
+		}
+	}
+	if _, ok := notificationPayload(slack, running); ok {
+		t.Error("expected no message for a running experiment")
+	}
+	if payload, ok := notificationPayload(v1alpha1.NotificationTarget{Format: "teams"}, completed); !ok || payload.(map[string]interface{})["@type"] != "MessageCard" {
+		t.Errorf("expected a message card for the completed engine, got %v", payload)
+	}
+
+	critical := v1alpha1.NotificationTarget{Name: "pager", URL: "https://example.com", Severities: []string{"critical"}}
+	if _, ok := notificationPayload(critical, completed); ok {
+		t.Error("expected the info completion not to be routed to the critical target")
+	}
+	if payload, ok := notificationPayload(critical, failed); !ok || payload.(verdictEvent).To != "fail" {
+		t.Errorf("expected the failure as JSON, got %v", payload)
+	}
+	invalid := []v1alpha1.NotificationTarget{{Name: "hook", URL: "https://example.com", Severities: []string{"major"}}}
+	if err := validateNotificationTargets(invalid); err == nil {
+		t.Error("expected an error for an unknown severity")
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/sinks"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// otlpSettings holds the OTLP export settings, read from the standard OTEL_* ENVs
+type otlpSettings struct {
+	// URL the metrics are posted to, empty if the OTLP export is disabled
+	endpoint string
+	headers  map[string]string
+	resource map[string]string
+	timeout  time.Duration
+	interval time.Duration
+	// Encodes the requests in protobuf (http/protobuf), in JSON (http/json) otherwise
+	protobuf bool
+}
+
+// otlpEnv returns the metrics specific OTEL_EXPORTER_OTLP_METRICS_<name> ENV if set, OTEL_EXPORTER_OTLP_<name>
+// otherwise
+func otlpEnv(name string) string {
+	if value := os.Getenv("OTEL_EXPORTER_OTLP_METRICS_" + name); value != "" {
+		return value
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_" + name)
+}
+
+// otlpMillis reads an ENV holding milliseconds, as the OTEL_* durations are
+func otlpMillis(key string, value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	millis, err := strconv.Atoi(value)
+	if err != nil || millis <= 0 {
+		recordInvalidEnv(key, value)
+		return fallback
+	}
+	return time.Duration(millis) * time.Millisecond
+}
+
+// parseKeyValues parses a comma separated list of URL encoded key=value pairs, as OTEL_EXPORTER_OTLP_HEADERS &
+// OTEL_RESOURCE_ATTRIBUTES are
+func parseKeyValues(list string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for _, pair := range strings.Split(list, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("expected key=value, got %q", pair)
+		}
+		value, err := url.QueryUnescape(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %s: %v", parts[0], err)
+		}
+		pairs[strings.TrimSpace(parts[0])] = value
+	}
+	return pairs, nil
+}
+
+// readOTLPSettings reads the OTLP export settings from the OTEL_* ENVs, recording the invalid ones in problems.
+// The export is enabled by setting OTEL_EXPORTER_OTLP_ENDPOINT (the /v1/metrics path is appended) or
+// OTEL_EXPORTER_OTLP_METRICS_ENDPOINT (used as is), unless OTEL_METRICS_EXPORTER is none
+func readOTLPSettings(problems *configProblems) otlpSettings {
+	settings := otlpSettings{
+		timeout:  otlpMillis("OTEL_EXPORTER_OTLP_TIMEOUT", otlpEnv("TIMEOUT"), 10*time.Second),
+		interval: otlpMillis("OTEL_METRIC_EXPORT_INTERVAL", os.Getenv("OTEL_METRIC_EXPORT_INTERVAL"), time.Minute),
+	}
+	if exporter := os.Getenv("OTEL_METRICS_EXPORTER"); exporter == "none" {
+		return settings
+	} else if exporter != "" && exporter != "otlp" {
+		problems.add("OTEL_METRICS_EXPORTER", "expected otlp or none, got %q", exporter)
+	}
+
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT"); endpoint != "" {
+		settings.endpoint = endpoint
+	} else if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		settings.endpoint = strings.TrimSuffix(endpoint, "/") + "/v1/metrics"
+	}
+	if settings.endpoint == "" {
+		return settings
+	}
+	if parsed, err := url.Parse(settings.endpoint); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		problems.add("OTEL_EXPORTER_OTLP_ENDPOINT", "expected an http(s) URL, got %q", settings.endpoint)
+	}
+	// Only OTLP/HTTP is supported, http/protobuf by default as the specification requires. OTLP/gRPC is not built
+	// in, the exporter vendors no gRPC client
+	switch protocol := otlpEnv("PROTOCOL"); protocol {
+	case "", "http/protobuf":
+		settings.protobuf = true
+	case "http/json":
+	default:
+		problems.add("OTEL_EXPORTER_OTLP_PROTOCOL", "expected http/protobuf or http/json, got %q", protocol)
+	}
+
+	var err error
+	settings.headers, err = parseKeyValues(otlpEnv("HEADERS"))
+	problems.addErr("OTEL_EXPORTER_OTLP_HEADERS", err)
+	settings.resource, err = parseKeyValues(os.Getenv("OTEL_RESOURCE_ATTRIBUTES"))
+	problems.addErr("OTEL_RESOURCE_ATTRIBUTES", err)
+	if settings.resource == nil {
+		settings.resource = make(map[string]string)
+	}
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		settings.resource["service.name"] = name
+	} else if _, ok := settings.resource["service.name"]; !ok {
+		settings.resource["service.name"] = "chaos-exporter"
+	}
+	if _, ok := settings.resource["service.version"]; !ok {
+		settings.resource["service.version"] = currentBuild().Version
+	}
+	return settings
+}
+
+// runOTLPExport exports the families of gatherer, but those of the Go & process collectors, to the OTLP
+// endpoint of settings every interval, until ctx is done
+func runOTLPExport(ctx context.Context, settings otlpSettings, gatherer prometheus.Gatherer) {
+	exporter := &sinks.OTLPExporter{
+		Endpoint: settings.endpoint,
+		Headers:  settings.headers,
+		Resource: settings.resource,
+		Start:    exporterClock.Now(),
+		Protobuf: settings.protobuf,
+		Client:   &http.Client{Timeout: settings.timeout},
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-exporterClock.After(settings.interval):
+		}
+		result := "success"
+		if err := exportOTLP(ctx, exporter, gatherer); err != nil {
+			if logger := logSampling.sample(exporterLog, "otlp"); logger != nil {
+				logger.Warn("Unable to export the metrics over OTLP: ", err)
+			}
+			result = "failure"
+		}
+		otlpExports.WithLabelValues(result).Inc()
+	}
+}
+
+// exportOTLP exports the chaos metric families of gatherer once
+func exportOTLP(ctx context.Context, exporter *sinks.OTLPExporter, gatherer prometheus.Gatherer) error {
+	families, err := gatherChaosFamilies(gatherer)
+	if err != nil {
+		return err
+	}
+	return exporter.Export(ctx, families, exporterClock.Now())
+}
+
+// gatherChaosFamilies gathers the families of gatherer, but those of the Go & process collectors
+func gatherChaosFamilies(gatherer prometheus.Gatherer) ([]*dto.MetricFamily, error) {
+	families, err := gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+	chaosFamilies := families[:0]
+	for _, family := range families {
+		if !runtimeFamily(family.GetName()) {
+			chaosFamilies = append(chaosFamilies, family)
+		}
+	}
+	return chaosFamilies, nil
+}
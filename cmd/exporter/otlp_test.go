@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestReadOTLPSettings(t *testing.T) {
+	envs := map[string]string{
+		"OTEL_EXPORTER_OTLP_ENDPOINT": "http://otel-collector:4318/",
+		"OTEL_EXPORTER_OTLP_HEADERS":  "Authorization=Bearer%20token,X-Scope-OrgID=chaos",
+		"OTEL_RESOURCE_ATTRIBUTES":    "deployment.environment=staging",
+		"OTEL_METRIC_EXPORT_INTERVAL": "30000",
+	}
+	for key, value := range envs {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+
+	var problems configProblems
+	settings := readOTLPSettings(&problems)
+	if len(problems) > 0 {
+		t.Fatalf("unexpected problems %v", problems)
+	}
+	if settings.endpoint != "http://otel-collector:4318/v1/metrics" || settings.interval != 30*time.Second || !settings.protobuf {
+		t.Errorf("unexpected settings %+v", settings)
+	}
+	if settings.headers["Authorization"] != "Bearer token" || settings.resource["service.name"] != "chaos-exporter" || settings.resource["deployment.environment"] != "staging" {
+		t.Errorf("unexpected headers %v or resource %v", settings.headers, settings.resource)
+	}
+
+	os.Setenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", "http/json")
+	defer os.Unsetenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL")
+	if settings = readOTLPSettings(&problems); settings.protobuf || len(problems) > 0 {
+		t.Errorf("expected the JSON encoding, got %+v & problems %v", settings, problems)
+	}
+
+	os.Setenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", "grpc")
+	problems = nil
+	readOTLPSettings(&problems)
+	if len(problems) != 1 {
+		t.Errorf("expected the unsupported protocol to be reported, got %v", problems)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/sinks"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Holds the settings of the Pushgateway push mode, an empty URL disables it
+var (
+	pushURL     string
+	pushJob     string
+	pushTimeout time.Duration
+)
+
+// validatePushURL checks the Pushgateway URL is an http(s) URL, if set
+func validatePushURL(address string) error {
+	if address == "" {
+		return nil
+	}
+	parsed, err := url.Parse(address)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("expected an http(s) URL, got %q", address)
+	}
+	return nil
+}
+
+// enginePusher pushes the series of every chaosengine to a group of its own of the Pushgateway, and deletes
+// the groups of the engines no longer collected
+type enginePusher struct {
+	gateway *sinks.Pushgateway
+	// Holds the groups pushed by the previous pass, keyed by <namespace>/<engine>
+	pushed map[string]map[string]string
+}
+
+// newEnginePusher returns the pusher of the configured Pushgateway, nil if the push mode is disabled
+func newEnginePusher() *enginePusher {
+	if pushURL == "" {
+		return nil
+	}
+	return &enginePusher{
+		gateway: &sinks.Pushgateway{URL: pushURL, Job: pushJob, Client: &http.Client{Timeout: pushTimeout}},
+		pushed:  make(map[string]map[string]string),
+	}
+}
+
+// push pushes the series of the last collection of every chaosengine, grouped by chaos_namespace &
+// engine_name. A push replaces the group as a whole, so the series dropped by the collection disappear
+func (p *enginePusher) push(ctx context.Context) {
+	if p == nil {
+		return
+	}
+	groups, families, err := gatherEngineFamilies()
+	if err != nil {
+		exporterLog.Warn("Unable to gather the series to push: ", err)
+		return
+	}
+	for key, grouping := range groups {
+		p.record(p.gateway.Push(ctx, grouping, families[key]), "push", key)
+	}
+	// Deletions that failed are retried by the next pass
+	pushed := make(map[string]map[string]string, len(groups))
+	for key, grouping := range groups {
+		pushed[key] = grouping
+	}
+	for key, grouping := range p.pushed {
+		if _, ok := groups[key]; !ok && p.record(p.gateway.Delete(ctx, grouping), "delete", key) {
+			pushed[key] = grouping
+		}
+	}
+	p.pushed = pushed
+}
+
+// record counts the outcome of a request to the Pushgateway, returning true on failure
+func (p *enginePusher) record(err error, request string, key string) bool {
+	if err == nil {
+		pushes.WithLabelValues(request, "success").Inc()
+		return false
+	}
+	pushes.WithLabelValues(request, "failure").Inc()
+	if logger := logSampling.sample(exporterLog.WithField("group", key), "push/"+request+"/"+key); logger != nil {
+		logger.Warnf("Unable to %s the group on the Pushgateway: %v", request, err)
+	}
+	return true
+}
+
+// gatherEngineFamilies returns the grouping labels & metric families of the series of every chaosengine, keyed
+// by <namespace>/<engine>
+func gatherEngineFamilies() (map[string]map[string]string, map[string][]*dto.MetricFamily, error) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+
+	groups := make(map[string]map[string]string, len(engineSeries))
+	families := make(map[string][]*dto.MetricFamily, len(engineSeries))
+	for key, series := range engineSeries {
+		registry := prometheus.NewRegistry()
+		if err := registry.Register(seriesCollector(series)); err != nil {
+			return nil, nil, err
+		}
+		gathered, err := registry.Gather()
+		if err != nil {
+			return nil, nil, err
+		}
+		// The grouping labels carry the values exposed on the series, i.e. normalized
+		parts := strings.SplitN(key, "/", 2)
+		values := labelValues(parts[0], parts[1])
+		groups[key] = map[string]string{"chaos_namespace": values[0], "engine_name": values[1]}
+		families[key] = gathered
+	}
+	return groups, families, nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEnginePusher(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		requests[r.Method+" "+r.URL.Path] = string(body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+	defer func(address string) { pushURL = address }(pushURL)
+	pushURL, pushJob, pushTimeout = server.URL, "chaos-exporter", time.Second
+
+	series := make(seriesSet)
+	series.setVersioned(experimentsTotal, 2, "litmus", "uid", "engine-push", "1.13", "1.0")
+	replaceEngineSeries("litmus/engine-push", series)
+	defer replaceEngineSeries("litmus/engine-push", nil)
+
+	pusher := newEnginePusher()
+	pusher.push(context.Background())
+	body, ok := requests["PUT /metrics/job/chaos-exporter/chaos_namespace/litmus/engine_name/engine-push"]
+	if !ok || !strings.Contains(body, `c_engine_experiment_count{app_uid="uid",chaos_namespace="litmus",engine_name="engine-push"`) {
+		t.Errorf("expected the series of the engine to be pushed to its group, got %v", requests)
+	}
+
+	// The group of a deleted engine is deleted
+	replaceEngineSeries("litmus/engine-push", nil)
+	pusher.push(context.Background())
+	if _, ok := requests["DELETE /metrics/job/chaos-exporter/chaos_namespace/litmus/engine_name/engine-push"]; !ok {
+		t.Errorf("expected the group of the deleted engine to be deleted, got %v", requests)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Time after which the bucket of a client that made no request is dropped
+const clientLimiterIdle = 10 * time.Minute
+
+// clientLimiter is the token bucket of a client, and the time of its last request
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// apiRateLimiter limits the requests to the JSON API with a token bucket per client IP, and one shared by
+// all the clients, so that a misbehaving consumer cannot starve the collection
+type apiRateLimiter struct {
+	mu         sync.Mutex
+	global     *rate.Limiter
+	clientRate rate.Limit
+	burst      int
+	clients    map[string]*clientLimiter
+	lastSweep  time.Time
+}
+
+// Holds the limiter of the JSON API, nil to serve it without limits
+var apiLimiter *apiRateLimiter
+
+// newAPIRateLimiter returns a limiter of globalRate requests per second over all clients, and of clientRate
+// per client IP, each with the given burst. A rate of 0 is not limited, nil is returned if neither is
+func newAPIRateLimiter(globalRate float64, clientRate float64, burst int) *apiRateLimiter {
+	if globalRate <= 0 && clientRate <= 0 {
+		return nil
+	}
+	limiter := &apiRateLimiter{global: rate.NewLimiter(rate.Inf, burst), clientRate: rate.Inf, burst: burst, clients: make(map[string]*clientLimiter)}
+	if globalRate > 0 {
+		limiter.global = rate.NewLimiter(rate.Limit(globalRate), burst)
+	}
+	if clientRate > 0 {
+		limiter.clientRate = rate.Limit(clientRate)
+	}
+	return limiter
+}
+
+// allow reports whether a request of client may be served now, consuming its tokens
+func (l *apiRateLimiter) allow(client string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Drop the buckets of the idle clients, which are full again anyway
+	if now.Sub(l.lastSweep) > clientLimiterIdle {
+		for key, entry := range l.clients {
+			if now.Sub(entry.lastSeen) > clientLimiterIdle {
+				delete(l.clients, key)
+			}
+		}
+		l.lastSweep = now
+	}
+	entry, ok := l.clients[client]
+	if !ok {
+		entry = &clientLimiter{limiter: rate.NewLimiter(l.clientRate, l.burst)}
+		l.clients[client] = entry
+	}
+	entry.lastSeen = now
+	// The client bucket is checked first, so that a throttled client does not drain the shared one
+	return entry.limiter.AllowN(now, 1) && l.global.AllowN(now, 1)
+}
+
+// limit answers the requests over the limits with 429 Too Many Requests. A nil limiter serves handler as is
+func (l *apiRateLimiter) limit(handler http.HandlerFunc) http.HandlerFunc {
+	if l == nil {
+		return handler
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		client, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			client = r.RemoteAddr
+		}
+		if !l.allow(client, exporterClock.Now()) {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+		handler(w, r)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAPIRateLimiter(t *testing.T) {
+	if newAPIRateLimiter(0, 0, 10) != nil {
+		t.Error("expected no limiter without limits")
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newAPIRateLimiter(3, 1, 2)
+
+	// Each client bursts to 2 requests, the shared bucket to 2 as well
+	if !limiter.allow("10.0.0.1", now) || !limiter.allow("10.0.0.1", now) || limiter.allow("10.0.0.1", now) {
+		t.Error("expected the client to be limited after its burst")
+	}
+	if limiter.allow("10.0.0.2", now) {
+		t.Error("expected another client to be limited once the shared burst is used")
+	}
+	// Tokens are refilled at the rates
+	now = now.Add(time.Second)
+	if !limiter.allow("10.0.0.2", now) {
+		t.Error("expected the refilled buckets to allow a request")
+	}
+
+	// The buckets of idle clients are dropped
+	limiter.allow("10.0.0.3", now.Add(2*clientLimiterIdle))
+	if len(limiter.clients) != 1 {
+		t.Errorf("expected the idle clients to be dropped, got %d", len(limiter.clients))
+	}
+}
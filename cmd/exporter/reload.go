@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Interval at which the settings file is checked for modifications
+var configPollInterval = 10 * time.Second
+
+// runtimeSettings holds the settings which can be changed without a restart
+type runtimeSettings struct {
+	defaults   exporterSettings
+	resync     time.Duration
+	normalizer *labelNormalizer
+}
+
+// loadConfigFile reloads the settings of the settings file at path which apply without a restart, the watched
+// namespaces, engine selector, resync period & label normalization, on base, the settings of the startup. The
+// flags of fs set on the command line or by an ENV override the file as they do at startup, and a setting the
+// file no longer holds goes back to the default it replaced
+func loadConfigFile(fs *flag.FlagSet, path string, base runtimeSettings) (runtimeSettings, error) {
+	values, err := readSettingsFile(fs, path)
+	if err != nil {
+		return base, err
+	}
+	// fileValue returns the value the file gives the flag name, if it applies
+	fileValue := func(name string) (string, bool) {
+		if settingOverridden(fs, name) {
+			return "", false
+		}
+		if value, ok := values[name]; ok {
+			return value, true
+		}
+		value, ok := fileSettings[name]
+		return value, ok
+	}
+
+	settings := base
+	if value, ok := fileValue("watch-namespace"); ok {
+		settings.defaults.appNamespace = value
+	}
+	if value, ok := fileValue("engine-selector"); ok {
+		if _, err := labels.Parse(value); err != nil {
+			return base, fmt.Errorf("invalid engine-selector: %v", err)
+		}
+		settings.defaults.engineSelector = value
+	}
+	if value, ok := fileValue("resync-period"); ok {
+		if settings.resync, err = time.ParseDuration(value); err != nil || settings.resync <= 0 {
+			return base, fmt.Errorf("invalid resync-period %q", value)
+		}
+	}
+	// The label normalization is rebuilt as a whole, from the flag values of the settings the file doesn't hold
+	labelSettings := make(map[string]string)
+	reloadLabels := false
+	for _, name := range []string{"label-lowercase", "label-replace", "label-value-map"} {
+		value, ok := fileValue(name)
+		if !ok && fs.Lookup(name) != nil {
+			value = fs.Lookup(name).Value.String()
+		}
+		labelSettings[name] = value
+		reloadLabels = reloadLabels || ok
+	}
+	if reloadLabels {
+		lowercase, err := strconv.ParseBool(labelSettings["label-lowercase"])
+		if err != nil {
+			return base, fmt.Errorf("invalid label-lowercase %q", labelSettings["label-lowercase"])
+		}
+		if settings.normalizer, err = newLabelNormalizer(lowercase, labelSettings["label-replace"], labelSettings["label-value-map"]); err != nil {
+			return base, fmt.Errorf("invalid labels: %v", err)
+		}
+	}
+	if namespaces := splitNamespaces(settings.defaults.appNamespace); settings.defaults.chaosEngine != "" && (len(namespaces) > 1 || namespaces[0] == "") {
+		return base, fmt.Errorf("CHAOSENGINE requires watch-namespace to be a single namespace")
+	}
+	return settings, nil
+}
+
+// settingsReloader hands the reloaded settings over to the collection loop, which applies them between passes
+type settingsReloader struct {
+	mu      sync.Mutex
+	pending *runtimeSettings
+	// Notified once settings are pending, buffered so that notifications coalesce
+	notify chan struct{}
+}
+
+func newSettingsReloader() *settingsReloader {
+	return &settingsReloader{notify: make(chan struct{}, 1)}
+}
+
+// offer replaces the pending settings
+func (r *settingsReloader) offer(settings runtimeSettings) {
+	r.mu.Lock()
+	r.pending = &settings
+	r.mu.Unlock()
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
+
+// take returns the pending settings, if any. A nil reloader never has any
+func (r *settingsReloader) take() (runtimeSettings, bool) {
+	if r == nil {
+		return runtimeSettings{}, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending == nil {
+		return runtimeSettings{}, false
+	}
+	settings := *r.pending
+	r.pending = nil
+	return settings, true
+}
+
+// reloaded is notified once settings are pending. It is nil, and so never ready, for a nil reloader
+func (r *settingsReloader) reloaded() <-chan struct{} {
+	if r == nil {
+		return nil
+	}
+	return r.notify
+}
+
+// watchConfigFile reloads the settings file at path, that of the flags of fs, on SIGHUP, or once its modification
+// time or size changes, until ctx is done. Invalid files are logged and the previous settings retained
+func (r *settingsReloader) watchConfigFile(ctx context.Context, fs *flag.FlagSet, path string, base runtimeSettings) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	lastModified, lastSize := fileVersion(path)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			log.Info("SIGHUP received, reloading ", path)
+		case <-exporterClock.After(configPollInterval):
+			modified, size := fileVersion(path)
+			if modified.Equal(lastModified) && size == lastSize {
+				continue
+			}
+			log.Infof("%s modified, reloading it", path)
+		}
+		lastModified, lastSize = fileVersion(path)
+
+		settings, err := loadConfigFile(fs, path, base)
+		if err != nil {
+			log.Error("Unable to reload the settings file, retaining previous settings: ", err)
+			continue
+		}
+		r.offer(settings)
+	}
+}
+
+// fileVersion returns the modification time & size of the file at path, zero if it cannot be read
+func fileVersion(path string) (time.Time, int64) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, 0
+	}
+	return info.ModTime(), info.Size()
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/chaosmetrics"
+)
+
+// reloadableFlags returns a flag set of the settings reloaded from the settings file
+func reloadableFlags() *flag.FlagSet {
+	fs := flag.NewFlagSet("exporter", flag.ContinueOnError)
+	fs.String("watch-namespace", "default", "")
+	fs.String("engine-selector", "", "")
+	fs.Duration("resync-period", time.Minute, "")
+	fs.Bool("label-lowercase", false, "")
+	fs.String("label-replace", "", "")
+	fs.String("label-value-map", "", "")
+	return fs
+}
+
+// TestLoadConfigFile verifies that a reload of the settings file overrides the settings it sets, but those of
+// the command line & ENVs, and that it wakes the collection loop up
+func TestLoadConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chaos-exporter-settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	write := func(content string) {
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fs := reloadableFlags()
+	write("engine-selector: team=payments\n")
+	if fileSettings, err = applySettingsFile(fs, path); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { fileSettings = nil }()
+	base := runtimeSettings{defaults: exporterSettings{appNamespace: "default", engineSelector: "team=payments"}, resync: time.Minute}
+
+	write("watch-namespace: \"\"\nresync-period: 30s\nlabel-lowercase: true\n")
+	settings, err := loadConfigFile(fs, path, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The engine selector no longer set goes back to its default
+	if settings.defaults.appNamespace != "" || settings.defaults.engineSelector != "" || settings.resync != 30*time.Second {
+		t.Errorf("unexpected settings %+v", settings)
+	}
+	if settings.normalizer.normalize("Engine-Nginx") != "engine-nginx" {
+		t.Error("expected the label values to be lowercased")
+	}
+
+	// The settings given on the command line or by an ENV override the file
+	os.Setenv("CHAOS_EXPORTER_LABEL_LOWERCASE", "false")
+	defer os.Unsetenv("CHAOS_EXPORTER_LABEL_LOWERCASE")
+	pinned := reloadableFlags()
+	if err := applyFlagEnvs(pinned); err != nil {
+		t.Fatal(err)
+	}
+	if err := pinned.Parse([]string{"--watch-namespace=litmus"}); err != nil {
+		t.Fatal(err)
+	}
+	base.defaults.appNamespace = "litmus"
+	if settings, err := loadConfigFile(pinned, path, base); err != nil {
+		t.Fatal(err)
+	} else if settings.defaults.appNamespace != "litmus" || settings.resync != 30*time.Second || settings.normalizer != nil {
+		t.Errorf("expected the namespace & labels of the command line & ENVs, got %+v", settings)
+	}
+
+	// A single chaosengine cannot be monitored cluster-wide
+	base.defaults.chaosEngine = "engine-nginx"
+	if _, err := loadConfigFile(fs, path, base); err == nil {
+		t.Error("expected an error for a cluster-wide namespace with CHAOSENGINE set")
+	}
+	write("resync-period: often\n")
+	if _, err := loadConfigFile(fs, path, base); err == nil {
+		t.Error("expected an invalid resync period to be rejected")
+	}
+
+	reloader := newSettingsReloader()
+	reloader.offer(settings)
+	waitForChange(context.Background(), make(chan chaosmetrics.ChaosEvent), "", time.Hour, reloader.reloaded(), nil)
+	if reloaded, ok := reloader.take(); !ok || reloaded.resync != 30*time.Second {
+		t.Errorf("expected the reloaded settings to be pending, got %+v", reloaded)
+	}
+	if _, ok := reloader.take(); ok {
+		t.Error("expected the reloaded settings to be taken once")
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/credentials"
+	"github.com/litmuschaos/chaos-exporter/pkg/sinks"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Holds the settings of the remote_write output, an empty URL disables it
+var (
+	remoteWriteURL                string
+	remoteWriteBearerToken        string
+	remoteWriteUsername           string
+	remoteWritePassword           string
+	remoteWriteCAFile             string
+	remoteWriteCertFile           string
+	remoteWriteKeyFile            string
+	remoteWriteInsecureSkipVerify bool
+	remoteWriteTimeout            time.Duration
+)
+
+// Holds the remote_write sink, nil unless the output is enabled
+var remoteWriteSink *sinks.RemoteWrite
+
+// newRemoteWriteSink returns the sink of the configured remote_write endpoint, nil if no URL is configured. The
+// bearer token & password are credential references, for e.g. file: of a mounted Secret
+func newRemoteWriteSink(address string, bearerTokenRef string, username string, passwordRef string, caFile string, certFile string, keyFile string, insecureSkipVerify bool, timeout time.Duration) (*sinks.RemoteWrite, error) {
+	if address == "" {
+		return nil, nil
+	}
+	if err := validatePushURL(address); err != nil {
+		return nil, err
+	}
+	sink := &sinks.RemoteWrite{URL: address, Username: username}
+	if bearerTokenRef != "" {
+		if username != "" {
+			return nil, fmt.Errorf("either a bearer token or basic auth credentials are set, not both")
+		}
+		token, err := credentials.New(bearerTokenRef)
+		if err != nil {
+			return nil, err
+		}
+		sink.BearerToken = token
+	}
+	if passwordRef != "" {
+		if username == "" {
+			return nil, fmt.Errorf("the username is required along with the password")
+		}
+		password, err := credentials.New(passwordRef)
+		if err != nil {
+			return nil, err
+		}
+		sink.Password = password
+	}
+	tlsConfig, err := clientTLSConfig(caFile, certFile, keyFile, insecureSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	sink.Client = &http.Client{Timeout: timeout, Transport: transport}
+	return sink, nil
+}
+
+// writeRemoteSamples writes the metrics, the same set as served on /metrics but for the Go & process ones, to the
+// remote_write endpoint. Called after each collection pass, it does nothing unless the output is enabled
+func writeRemoteSamples(ctx context.Context, sink *sinks.RemoteWrite, gatherer prometheus.Gatherer) {
+	if sink == nil {
+		return
+	}
+	families, err := gatherChaosFamilies(gatherer)
+	if err != nil {
+		recordRemoteWrite(err)
+		return
+	}
+	recordRemoteWrite(sink.Write(ctx, families, exporterClock.Now()))
+}
+
+// recordRemoteWrite counts the outcome of a write, logging failures
+func recordRemoteWrite(err error) {
+	if err == nil {
+		remoteWrites.WithLabelValues("success").Inc()
+		return
+	}
+	remoteWrites.WithLabelValues("failure").Inc()
+	if logger := logSampling.sample(exporterLog, "remote-write"); logger != nil {
+		logger.Warn("Unable to write the metrics to the remote_write endpoint: ", err)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewRemoteWriteSink(t *testing.T) {
+	if sink, err := newRemoteWriteSink("", "", "", "", "", "", "", false, time.Second); sink != nil || err != nil {
+		t.Errorf("expected remote_write disabled, got %v, %v", sink, err)
+	}
+	for _, invalid := range [][]string{
+		{"mimir:8080", "", "", ""},
+		{"http://mimir:8080/api/v1/push", "env:TOKEN", "chaos", ""},
+		{"http://mimir:8080/api/v1/push", "", "", "env:PASSWORD"},
+	} {
+		if _, err := newRemoteWriteSink(invalid[0], invalid[1], invalid[2], invalid[3], "", "", "", false, time.Second); err == nil {
+			t.Errorf("expected an error for %v", invalid)
+		}
+	}
+	if _, err := newRemoteWriteSink("https://mimir:8080/api/v1/push", "", "", "", "", "client.crt", "", false, time.Second); err == nil {
+		t.Error("expected an error for a client certificate without a key")
+	}
+	sink, err := newRemoteWriteSink("https://mimir:8080/api/v1/push", "env:TOKEN", "", "", "", "", "", true, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sink.Client.Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify {
+		t.Error("expected the verification of the endpoint skipped")
+	}
+}
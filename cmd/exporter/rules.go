@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// Generate the alerting rules of the exporter metrics & exit, set by the gen-rules command
+var generateRules bool
+
+// Holds the settings of the generated rules
+var (
+	rulesFormat       string
+	rulesName         string
+	rulesNamespace    string
+	rulesJob          string
+	rulesMetricPrefix string
+	rulesEngineLabels string
+)
+
+// alertingRule is a Prometheus alerting rule
+type alertingRule struct {
+	Alert       string            `json:"alert"`
+	Expr        string            `json:"expr"`
+	For         string            `json:"for,omitempty"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// ruleGroup is a group of rules, as in a rule file & the spec of a PrometheusRule
+type ruleGroup struct {
+	Name  string         `json:"name"`
+	Rules []alertingRule `json:"rules"`
+}
+
+// validateRulesSettings checks the settings of the generated rules
+func validateRulesSettings(format string, name string, engineLabels string) error {
+	if format != "prometheusrule" && format != "rules" {
+		return fmt.Errorf("invalid format %q, expected prometheusrule or rules", format)
+	}
+	if format == "prometheusrule" && name == "" {
+		return fmt.Errorf("the name of the PrometheusRule is required")
+	}
+	return validateEngineLabels(splitList(engineLabels))
+}
+
+// splitList returns the non empty items of a comma separated list
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// buildRules returns the alerting rules on the exporter metrics: failed experiments, stalled engines, failing
+// collections & the exporter down. The metric names carry prefix, for metrics renamed on scrape, and the alerts
+// of the engines the labels of engineLabels, joined from litmuschaos_engine_labels, for e.g. to route them by team
+func buildRules(prefix string, job string, engineLabels []string) ruleGroup {
+	join := ""
+	if len(engineLabels) > 0 {
+		names := make([]string, 0, len(engineLabels))
+		for _, key := range engineLabels {
+			names = append(names, engineLabelName(key))
+		}
+		join = fmt.Sprintf(" * on(chaos_namespace, engine_name) group_left(%s) %slitmuschaos_engine_labels", strings.Join(names, ", "), prefix)
+	}
+	upSelector := fmt.Sprintf(`{job=%q}`, job)
+	return ruleGroup{Name: "litmuschaos", Rules: []alertingRule{{
+		Alert:  "ChaosExperimentFailed",
+		Expr:   fmt.Sprintf("(max by (chaos_namespace, engine_name) (%sc_engine_failed_experiments) > 0)%s", prefix, join),
+		Labels: map[string]string{"severity": "critical"},
+		Annotations: map[string]string{
+			"summary":     "Chaos experiments of {{ $labels.chaos_namespace }}/{{ $labels.engine_name }} failed",
+			"description": "{{ $value }} experiment(s) of chaosengine {{ $labels.chaos_namespace }}/{{ $labels.engine_name }} have a fail verdict.",
+		},
+	}, {
+		Alert:  "ChaosEngineStalled",
+		Expr:   fmt.Sprintf("(max by (chaos_namespace, engine_name) (%slitmuschaos_engine_stale) == 1)%s", prefix, join),
+		For:    "15m",
+		Labels: map[string]string{"severity": "warning"},
+		Annotations: map[string]string{
+			"summary":     "Chaosengine {{ $labels.chaos_namespace }}/{{ $labels.engine_name }} is not collected",
+			"description": "The collection of chaosengine {{ $labels.chaos_namespace }}/{{ $labels.engine_name }} has hit the collection deadline for 15 minutes, its metrics are stale.",
+		},
+	}, {
+		Alert:  "ChaosExporterCollectionFailing",
+		Expr:   fmt.Sprintf("increase(%slitmuschaos_exporter_collection_errors_total[15m]) > 0", prefix),
+		For:    "15m",
+		Labels: map[string]string{"severity": "warning"},
+		Annotations: map[string]string{
+			"summary":     "The chaos exporter fails to collect chaosengines",
+			"description": "Collection passes of {{ $labels.instance }} failed to get the metrics of some chaosengines for 15 minutes.",
+		},
+	}, {
+		Alert:  "ChaosExporterDown",
+		Expr:   fmt.Sprintf("absent(up%s == 1)", upSelector),
+		For:    "5m",
+		Labels: map[string]string{"severity": "critical"},
+		Annotations: map[string]string{
+			"summary":     "The chaos exporter is down",
+			"description": fmt.Sprintf("No target of job %s has been up for 5 minutes, the chaos metrics are not collected.", job),
+		},
+	}}}
+}
+
+// writeRules writes the rules in format, a PrometheusRule of name & namespace or a rule file, as YAML to out
+func writeRules(out io.Writer, format string, name string, namespace string, rules ruleGroup) error {
+	var document interface{} = map[string][]ruleGroup{"groups": {rules}}
+	if format == "prometheusrule" {
+		metadata := map[string]interface{}{"name": name, "labels": map[string]string{"app.kubernetes.io/name": "chaos-exporter"}}
+		if namespace != "" {
+			metadata["namespace"] = namespace
+		}
+		document = map[string]interface{}{
+			"apiVersion": "monitoring.coreos.com/v1",
+			"kind":       "PrometheusRule",
+			"metadata":   metadata,
+			"spec":       document,
+		}
+	}
+	data, err := yaml.Marshal(document)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenerateRules(t *testing.T) {
+	rules := buildRules("cluster_", "chaos-exporter", []string{"team"})
+	expected := "(max by (chaos_namespace, engine_name) (cluster_c_engine_failed_experiments) > 0) * on(chaos_namespace, engine_name) group_left(label_team) cluster_litmuschaos_engine_labels"
+	if rules.Rules[0].Expr != expected {
+		t.Errorf("expected the expression %q, got %q", expected, rules.Rules[0].Expr)
+	}
+	var out bytes.Buffer
+	if err := writeRules(&out, "prometheusrule", "chaos-exporter", "monitoring", rules); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"kind: PrometheusRule",
+		"namespace: monitoring",
+		"alert: ChaosExperimentFailed",
+		`expr: absent(up{job="chaos-exporter"} == 1)`,
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in\n%s", expected, out.String())
+		}
+	}
+	out.Reset()
+	if err := writeRules(&out, "rules", "", "", buildRules("", "chaos-exporter", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "groups:") || strings.Contains(out.String(), "group_left") {
+		t.Errorf("unexpected rule file\n%s", out.String())
+	}
+	if err := validateRulesSettings("alertmanager", "chaos-exporter", ""); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
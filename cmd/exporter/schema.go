@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Version of the /schema document layout, bumped on incompatible changes to its fields
+const schemaVersion = "v1"
+
+// metricFamily describes a metric family the exporter can emit
+type metricFamily struct {
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	Labels []string `json:"labels"`
+	Help   string   `json:"help"`
+	// Exporter release the family was introduced in
+	Since string `json:"since"`
+}
+
+// Holds the families defined by registerMetrics, in definition order
+var metricFamilies []metricFamily
+
+// describe records the family defined by a metric constructor
+func describe(metricType string, since string, opts prometheus.Opts, labels []string) {
+	metricFamilies = append(metricFamilies, metricFamily{
+		Name:   prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
+		Type:   metricType,
+		Labels: append([]string{}, labels...),
+		Help:   opts.Help,
+		Since:  since,
+	})
+}
+
+func newGaugeVec(since string, opts prometheus.GaugeOpts, labels []string) *prometheus.GaugeVec {
+	describe("gauge", since, prometheus.Opts(opts), labels)
+	return prometheus.NewGaugeVec(opts, labels)
+}
+
+func newEngineGauge(since string, opts prometheus.GaugeOpts, labels []string) *engineGauge {
+	describe("gauge", since, prometheus.Opts(opts), labels)
+	name := prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name)
+	return &engineGauge{name: name, desc: prometheus.NewDesc(name, opts.Help, labels, opts.ConstLabels)}
+}
+
+func newGauge(since string, opts prometheus.GaugeOpts) prometheus.Gauge {
+	describe("gauge", since, prometheus.Opts(opts), nil)
+	return prometheus.NewGauge(opts)
+}
+
+func newHistogramVec(since string, opts prometheus.HistogramOpts, labels []string) *prometheus.HistogramVec {
+	describe("histogram", since, prometheus.Opts{Namespace: opts.Namespace, Subsystem: opts.Subsystem, Name: opts.Name, Help: opts.Help}, labels)
+	return prometheus.NewHistogramVec(opts, labels)
+}
+
+func newCounterVec(since string, opts prometheus.CounterOpts, labels []string) *prometheus.CounterVec {
+	describe("counter", since, prometheus.Opts(opts), labels)
+	return prometheus.NewCounterVec(opts, labels)
+}
+
+func newCounter(since string, opts prometheus.CounterOpts) prometheus.Counter {
+	describe("counter", since, prometheus.Opts(opts), nil)
+	return prometheus.NewCounter(opts)
+}
+
+// schemaHandler serves the families the exporter can emit, so that dashboards & alerts can be generated
+// and verified against the running release. The per experiment gauges are described by a single
+// c_exp_<experiment> family
+func schemaHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]interface{}{"version": schemaVersion, "metrics": metricFamilies})
+}
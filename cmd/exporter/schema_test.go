@@ -0,0 +1,53 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TestMetricFamilies verifies that every gathered chaos metric family is described, with its label names
+func TestMetricFamilies(t *testing.T) {
+	engineInvalid.WithLabelValues("litmus", "engine-schema", "no-experiments").Set(1)
+	collectionErrors.Inc()
+	defer engineInvalid.DeleteLabelValues("litmus", "engine-schema", "no-experiments")
+
+	described := make(map[string]metricFamily)
+	for _, family := range metricFamilies {
+		if _, ok := described[family.Name]; ok {
+			t.Errorf("family %s described twice", family.Name)
+		}
+		described[family.Name] = family
+	}
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		name := family.GetName()
+		if strings.HasPrefix(name, "go_") || strings.HasPrefix(name, "process_") || strings.HasPrefix(name, "c_exp_") {
+			continue
+		}
+		schema, ok := described[name]
+		if !ok {
+			t.Errorf("family %s is not described", name)
+			continue
+		}
+		if schema.Type != strings.ToLower(family.GetType().String()) {
+			t.Errorf("family %s described as %s, gathered as %s", name, schema.Type, family.GetType())
+		}
+		var labels []string
+		for _, pair := range family.GetMetric()[0].GetLabel() {
+			labels = append(labels, pair.GetName())
+		}
+		// Gathered label pairs are sorted by name
+		expected := append([]string{}, schema.Labels...)
+		sort.Strings(expected)
+		if strings.Join(labels, ",") != strings.Join(expected, ",") {
+			t.Errorf("family %s described with labels %v, gathered with %v", name, schema.Labels, labels)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/chaosmetrics"
+	clientV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/clientset/v1alpha1"
+	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+)
+
+// Names of the dummy chaosengine & experiment created by the self-test. The chaosresult is named after
+// both, as the operator does
+const (
+	selfTestEngine     = "chaos-exporter-selftest"
+	selfTestExperiment = "exporter-selftest"
+)
+
+// Namespace the self-test creates its dummy chaosengine & result in, empty to disable the self-test
+var selfTestNamespace string
+
+// Interval at which the self-test is run
+var selfTestInterval time.Duration
+
+// Time the chaosresult of the self-test is given to reach the chaosresult informer cache
+var selfTestTimeout = 30 * time.Second
+
+// isSelfTestEngine reports whether engine is the dummy chaosengine of the self-test, which is left out of
+// the collection so that it never shows up in the exported series
+func isSelfTestEngine(engine types.NamespacedName) bool {
+	return selfTestNamespace != "" && engine.Namespace == selfTestNamespace && engine.Name == selfTestEngine
+}
+
+// runSelfTests runs the self-test every selfTestInterval, until ctx is done
+func runSelfTests(ctx context.Context, cfg *rest.Config) {
+	for {
+		if err := selfTest(ctx, cfg, selfTestNamespace); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			engineLogger(selfTestNamespace, selfTestEngine).Error("Self-test failed: ", err)
+			selfTestSuccess.Set(0)
+		} else {
+			selfTestSuccess.Set(1)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-exporterClock.After(selfTestInterval):
+		}
+	}
+}
+
+// selfTest creates a dummy chaosengine with a passed chaosresult in ns, collects it like any other engine
+// and verifies the experiment is exposed as passed. The dummy resources are deleted before returning
+func selfTest(ctx context.Context, cfg *rest.Config, ns string) error {
+	clientSet, err := clientV1alpha1.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	resultName := fmt.Sprintf("%s-%s", selfTestEngine, selfTestExperiment)
+	cleanup := func() error {
+		if err := clientSet.ChaosResults(ns).Delete(resultName, &metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+		if err := clientSet.ChaosEngines(ns).Delete(selfTestEngine, &metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	// Remove the leftovers of an interrupted run
+	if err := cleanup(); err != nil {
+		return fmt.Errorf("unable to delete the previous self-test resources: %v", err)
+	}
+	defer func() {
+		if err := cleanup(); err != nil {
+			engineLogger(ns, selfTestEngine).Error("Unable to delete the self-test resources: ", err)
+		}
+	}()
+
+	engine := &v1alpha1.ChaosEngine{ObjectMeta: metav1.ObjectMeta{Name: selfTestEngine, Namespace: ns}}
+	engine.Spec.Experiments = []v1alpha1.ExperimentList{{Name: selfTestExperiment}}
+	if _, err := clientSet.ChaosEngines(ns).Create(engine); err != nil {
+		return fmt.Errorf("unable to create the self-test chaosengine: %v", err)
+	}
+	result := &v1alpha1.ChaosResult{ObjectMeta: metav1.ObjectMeta{Name: resultName, Namespace: ns}}
+	result.Spec.ExperimentStatus = v1alpha1.TestStatus{Phase: "Completed", Verdict: "Pass"}
+	if _, err := clientSet.ChaosResults(ns).Create(result); err != nil {
+		return fmt.Errorf("unable to create the self-test chaosresult: %v", err)
+	}
+
+	// The chaosresult is read from the informer cache once synced, wait for it to catch up
+	deadline := exporterClock.Now().Add(selfTestTimeout)
+	for {
+		engineMetrics, err := chaosmetrics.LitmusProvider{}.GetEngineMetrics(ctx, cfg, selfTestEngine, ns)
+		if err == nil {
+			err = verifySelfTestSeries(engineMetrics, ns)
+		}
+		if err == nil || !exporterClock.Now().Before(deadline) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-exporterClock.After(time.Second):
+		}
+	}
+}
+
+// verifySelfTestSeries sets the series of the self-test chaosengine, without applying them, and checks
+// they are gathered with the experiment passed
+func verifySelfTestSeries(engineMetrics *chaosmetrics.EngineMetrics, ns string) error {
+	series := make(seriesSet)
+	setExperimentSeries(series, engineMetrics, ns, "", selfTestEngine, "", "")
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(seriesCollector(series)); err != nil {
+		return err
+	}
+	families, err := registry.Gather()
+	if err != nil {
+		return fmt.Errorf("unable to gather the self-test series: %v", err)
+	}
+
+	// The engine label is normalized like those of the collected engines
+	engineLabel := labelValues(ns, selfTestEngine)[1]
+	expected := map[string]float64{
+		experimentsTotal.name:                     1,
+		passedExperiments.name:                    1,
+		failedExperiments.name:                    0,
+		experimentGauge("exporter_selftest").name: 3,
+	}
+	for _, family := range families {
+		want, ok := expected[family.GetName()]
+		if !ok {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "engine_name" && label.GetValue() == engineLabel {
+					if got := metric.GetGauge().GetValue(); got != want {
+						return fmt.Errorf("%s is %v, expected %v", family.GetName(), got, want)
+					}
+					delete(expected, family.GetName())
+				}
+			}
+		}
+	}
+	for name := range expected {
+		return fmt.Errorf("%s is not exposed", name)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/chaosmetrics"
+)
+
+func TestSelfTest(t *testing.T) {
+	passed := &chaosmetrics.EngineMetrics{TotalExperiments: 1, PassedExperiments: 1, ExperimentStatus: map[string]float64{selfTestExperiment: 3}}
+	if err := verifySelfTestSeries(passed, "sandbox"); err != nil {
+		t.Errorf("expected the passed self-test to verify, got %v", err)
+	}
+	notStarted := &chaosmetrics.EngineMetrics{TotalExperiments: 1, ExperimentStatus: map[string]float64{selfTestExperiment: 0}}
+	if err := verifySelfTestSeries(notStarted, "sandbox"); err == nil {
+		t.Error("expected the self-test to fail while the experiment is not reported passed")
+	}
+
+	// The dummy engine is left out of the collection of the sandbox namespace
+	provider := &fakeProvider{engines: []string{"engine-a", selfTestEngine}}
+	chaosProviders = []chaosmetrics.ChaosProvider{provider}
+	selfTestNamespace = "sandbox"
+	defer func() {
+		chaosProviders = []chaosmetrics.ChaosProvider{chaosmetrics.LitmusProvider{}}
+		selfTestNamespace = ""
+	}()
+	if _, err := collectNamespace(context.Background(), nil, exporterSettings{}, "sandbox", "1.13", "1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(provider.order, ",") != "engine-a" {
+		t.Errorf("expected only engine-a to be collected, got %v", provider.order)
+	}
+	replaceEngineSeries("sandbox/engine-a", nil)
+}
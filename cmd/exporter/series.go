@@ -0,0 +1,179 @@
+package main
+
+import (
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Series replacement modes, selecting how the series labelled with outdated versions are replaced
+const (
+	// The outdated series are deleted once the collection pass has set their replacements, so that
+	// scrapes never miss both, at the cost of briefly exposing both
+	replaceSwap = "swap"
+	// The outdated series are deleted before the collection pass, so that scrapes never expose both,
+	// at the cost of a gap until their replacements are set
+	replaceReset = "reset"
+)
+
+// Holds the selected series replacement mode
+var seriesReplacement string
+
+// engineGauge is a gauge whose series are set by the collection of the chaosengines. Rather than being held
+// by the registry, its series are exposed by engineCollector from the last collection of each engine, so that
+// they disappear along with the engine
+type engineGauge struct {
+	// Fully qualified name of the metric
+	name string
+	desc *prometheus.Desc
+}
+
+// pruneVersionedSeries deletes the series carrying other versions than the given ones
+func pruneVersionedSeries(kubernetesVersion string, openebsVersion string) {
+	// Label values are normalized before exposition
+	kubernetesVersion = labelNormalization.normalize(kubernetesVersion)
+	openebsVersion = labelNormalization.normalize(openebsVersion)
+
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+
+	for _, engine := range engineSeries {
+		for _, series := range engine {
+			for id, value := range series {
+				n := len(value.labels)
+				if !value.versioned || (value.labels[n-2] == kubernetesVersion && value.labels[n-1] == openebsVersion) {
+					continue
+				}
+				delete(series, id)
+			}
+		}
+	}
+}
+
+// seriesValue is a series set by the collection of a chaosengine
+type seriesValue struct {
+	labels []string
+	value  float64
+	// Set for the series carrying the kubernetes & openebs versions as their last two labels
+	versioned bool
+}
+
+// seriesSet holds the series set by a collection of a chaosengine, keyed by gauge & joined label values.
+// The series are only exposed once the set is applied by replaceEngineSeries, so that scrapes see the
+// result of a collection as a whole
+type seriesSet map[*engineGauge]map[string]*seriesValue
+
+// Holds the series set by the last collection of each chaosengine, keyed by <namespace>/<engine>
+var engineSeries = make(map[string]seriesSet)
+
+// set records a series in the set
+func (s seriesSet) set(gauge *engineGauge, value float64, labels ...string) {
+	s.record(gauge, &seriesValue{labels: labels, value: value})
+}
+
+// setVersioned records a series carrying the kubernetes & openebs versions as its last two labels in the set
+func (s seriesSet) setVersioned(gauge *engineGauge, value float64, labels ...string) {
+	s.record(gauge, &seriesValue{labels: labels, value: value, versioned: true})
+}
+
+func (s seriesSet) record(gauge *engineGauge, series *seriesValue) {
+	if s[gauge] == nil {
+		s[gauge] = make(map[string]*seriesValue)
+	}
+	s[gauge][strings.Join(series.labels, "\xff")] = series
+}
+
+// replaceEngineSeries replaces the series of a chaosengine by those set by its latest collection, dropping
+// those of the previous collection that were not set again, for e.g. those of experiments removed from the
+// engine. A nil set drops all the series of the engine. Experiment & engine label gauges left without series are
+// forgotten
+func replaceEngineSeries(key string, current seriesSet) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+
+	previous := engineSeries[key]
+	delete(restoredEngines, key)
+	if current == nil {
+		delete(engineSeries, key)
+	} else {
+		engineSeries[key] = current
+	}
+
+	for name, gauge := range experimentGauges {
+		if _, ok := previous[gauge]; ok && !seriesInUse(gauge) {
+			delete(experimentGauges, name)
+		}
+	}
+	for keys, gauge := range engineLabelGauges {
+		if _, ok := previous[gauge]; ok && !seriesInUse(gauge) {
+			delete(engineLabelGauges, keys)
+		}
+	}
+}
+
+// engineCollector exposes the series of the last collection of every chaosengine as const metrics. It is
+// unchecked (describes no metric), as the experiment gauges are only known once collected
+type engineCollector struct{}
+
+// Describe sends no descriptor, see engineCollector
+func (engineCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect sends a const metric per series. A series set by several engines (only possible for engines of
+// different providers sharing a name) is sent once
+func (engineCollector) Collect(ch chan<- prometheus.Metric) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+
+	sets := make([]seriesSet, 0, len(engineSeries))
+	for _, engine := range engineSeries {
+		sets = append(sets, engine)
+	}
+	collectSeries(ch, sets)
+}
+
+// seriesCollector exposes the series of a set that is not applied, for e.g. to check their exposition
+type seriesCollector seriesSet
+
+// Describe sends no descriptor, see engineCollector
+func (seriesCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect sends a const metric per series
+func (s seriesCollector) Collect(ch chan<- prometheus.Metric) {
+	collectSeries(ch, []seriesSet{seriesSet(s)})
+}
+
+// collectSeries sends a const metric per series of the sets, once per gauge & label values
+func collectSeries(ch chan<- prometheus.Metric, sets []seriesSet) {
+	sent := make(map[*engineGauge]map[string]bool)
+	for _, engine := range sets {
+		for gauge, series := range engine {
+			if sent[gauge] == nil {
+				sent[gauge] = make(map[string]bool)
+			}
+			for id, value := range series {
+				if sent[gauge][id] {
+					continue
+				}
+				sent[gauge][id] = true
+				metric, err := prometheus.NewConstMetric(gauge.desc, prometheus.GaugeValue, value.value, value.labels...)
+				if err != nil {
+					// For e.g. an experiment name which is not a valid metric name
+					log.Debugf("Unable to expose %s: %v", gauge.desc, err)
+					continue
+				}
+				ch <- metric
+			}
+		}
+	}
+}
+
+// seriesInUse checks whether any chaosengine holds a series of gauge
+func seriesInUse(gauge *engineGauge) bool {
+	for _, series := range engineSeries {
+		if len(series[gauge]) > 0 {
+			return true
+		}
+	}
+	return false
+}
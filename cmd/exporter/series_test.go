@@ -0,0 +1,87 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// collectedValue returns the value of a series of gauge exposed by engineCollector, if any
+func collectedValue(gauge *engineGauge, labels ...string) (float64, bool) {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		engineCollector{}.Collect(metrics)
+		close(metrics)
+	}()
+
+	// Label pairs are written sorted by name, compare the sorted values
+	expected := append([]string{}, labels...)
+	sort.Strings(expected)
+	value, found := 0.0, false
+	for metric := range metrics {
+		if metric.Desc() != gauge.desc {
+			continue
+		}
+		m := &dto.Metric{}
+		metric.Write(m)
+		var values []string
+		for _, pair := range m.GetLabel() {
+			values = append(values, pair.GetValue())
+		}
+		sort.Strings(values)
+		if strings.Join(values, ",") == strings.Join(expected, ",") {
+			value, found = m.GetGauge().GetValue(), true
+		}
+	}
+	return value, found
+}
+
+// TestPruneVersionedSeries verifies that only the series carrying outdated versions are deleted
+func TestPruneVersionedSeries(t *testing.T) {
+	series := make(seriesSet)
+	series.setVersioned(experimentsTotal, 1, "litmus", "uid", "engine-upgrade", "v1.13.0", "1.0.0")
+	series.setVersioned(experimentsTotal, 1, "litmus", "uid", "engine-upgrade", "v1.14.0", "1.0.0")
+	replaceEngineSeries("litmus/engine-upgrade", series)
+	defer replaceEngineSeries("litmus/engine-upgrade", nil)
+	pruneVersionedSeries("v1.14.0", "1.0.0")
+
+	if _, ok := collectedValue(experimentsTotal, "litmus", "uid", "engine-upgrade", "v1.13.0", "1.0.0"); ok {
+		t.Error("expected the series of the previous kubernetes version to be deleted")
+	}
+	if _, ok := collectedValue(experimentsTotal, "litmus", "uid", "engine-upgrade", "v1.14.0", "1.0.0"); !ok {
+		t.Error("expected the series of the current kubernetes version to be retained")
+	}
+}
+
+// TestReplaceEngineSeries verifies that the series of an experiment removed from the engine are no longer
+// exposed and its gauge forgotten, and that the series of a deleted engine disappear with it
+func TestReplaceEngineSeries(t *testing.T) {
+	key := "litmus/engine-removal"
+	gauge := experimentGauge("removed_experiment")
+
+	series := make(seriesSet)
+	series.set(probeStatus, 1, "litmus", "engine-removal", "check", "httpProbe", "removed-experiment")
+	series.setVersioned(gauge, 3, "litmus", "uid", "engine-removal", "v1.14.0", "1.0.0")
+	replaceEngineSeries(key, series)
+	if value, ok := collectedValue(gauge, "litmus", "uid", "engine-removal", "v1.14.0", "1.0.0"); !ok || value != 3 {
+		t.Errorf("expected the experiment series to be exposed with 3, got %v", value)
+	}
+
+	series = make(seriesSet)
+	series.set(probeStatus, 0, "litmus", "engine-removal", "check", "httpProbe", "kept-experiment")
+	replaceEngineSeries(key, series)
+	if _, ok := collectedValue(probeStatus, "litmus", "engine-removal", "check", "httpProbe", "removed-experiment"); ok {
+		t.Error("expected the probe series of the removed experiment to be deleted")
+	}
+	if _, ok := experimentGauges["removed_experiment"]; ok {
+		t.Error("expected the gauge of the removed experiment to be forgotten")
+	}
+
+	replaceEngineSeries(key, nil)
+	if _, ok := collectedValue(probeStatus, "litmus", "engine-removal", "check", "httpProbe", "kept-experiment"); ok {
+		t.Error("expected the series of the deleted engine to be deleted")
+	}
+}
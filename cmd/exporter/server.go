@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// webSettings holds the settings of the HTTP servers of the metrics, the JSON API & the health endpoints
+type webSettings struct {
+	listenAddress       string
+	telemetryPath       string
+	healthListenAddress string
+	shutdownTimeout     time.Duration
+	enablePprof         bool
+	debugListenAddress  string
+	configFile          string
+	tlsCertFile         string
+	tlsKeyFile          string
+	clientCAFile        string
+	metricsUsername     string
+	metricsPasswordHash string
+	metricsBearerToken  string
+	tokensFile          string
+	corsOrigins         string
+	corsHeaders         string
+	apiRateLimit        float64
+	apiClientRateLimit  float64
+	apiRateBurst        int
+
+	// Built from the above by validate
+	auth         *metricsAuth
+	tlsConfig    *tls.Config
+	serverConfig *webConfig
+}
+
+// validate checks the web settings, building the credentials, the TLS config & the JSON API policies out of them
+func (web *webSettings) validate(problems *configProblems) {
+	var err error
+	problems.addErr("--web.listen-address (WEB_LISTEN_ADDRESS)", validateAddress(web.listenAddress))
+	problems.addErr("--web.telemetry-path (WEB_TELEMETRY_PATH)", validateTelemetryPath(web.telemetryPath))
+	if web.enablePprof {
+		problems.addErr("--debug.listen-address (DEBUG_LISTEN_ADDRESS)", validateAddress(web.debugListenAddress))
+	}
+	if web.healthListenAddress != "" {
+		problems.addErr("--web.health-listen-address (WEB_HEALTH_LISTEN_ADDRESS)", validateAddress(web.healthListenAddress))
+	}
+	if web.shutdownTimeout < 0 {
+		problems.add("--web.shutdown-timeout (WEB_SHUTDOWN_TIMEOUT)", "expected a duration of 0 or more, got %s", web.shutdownTimeout)
+	}
+
+	web.auth, err = newMetricsAuth(web.metricsUsername, web.metricsPasswordHash, web.metricsBearerToken)
+	problems.addErr("--web.metrics-username, --web.metrics-password-hash & --web.metrics-bearer-token", err)
+
+	web.tlsConfig, err = serverTLSConfig(web.tlsCertFile, web.tlsKeyFile, web.clientCAFile)
+	problems.addErr("--web.tls-cert-file, --web.tls-key-file & --web.client-ca-file", err)
+	if web.configFile != "" {
+		if web.tlsConfig != nil {
+			problems.add("--web.config.file (WEB_CONFIG_FILE)", "cannot be combined with the --web.tls-* flags")
+		}
+		web.serverConfig, err = loadWebConfig(web.configFile)
+		problems.addErr("--web.config.file (WEB_CONFIG_FILE)", err)
+		if web.serverConfig != nil {
+			web.tlsConfig, err = web.serverConfig.tlsConfig()
+			problems.addErr("--web.config.file (WEB_CONFIG_FILE) tls_server_config", err)
+			if web.tlsConfig != nil && web.tlsConfig.ClientCAs != nil {
+				web.clientCAFile = web.serverConfig.TLSServerConfig.ClientCAFile
+			}
+			web.auth = web.auth.withUsers(web.serverConfig.BasicAuthUsers)
+		}
+	}
+
+	if web.tokensFile != "" {
+		apiTokens, err = loadAPITokens(web.tokensFile)
+		problems.addErr("--api-tokens-file (API_TOKENS_FILE)", err)
+	}
+	apiCORS = newCORSPolicy(web.corsOrigins, web.corsHeaders)
+	apiLimiter = newAPIRateLimiter(web.apiRateLimit, web.apiClientRateLimit, web.apiRateBurst)
+	if apiLimiter != nil && web.apiRateBurst < 1 {
+		problems.add("--web.api-rate-burst (WEB_API_RATE_BURST)", "expected a positive number of requests, got %d", web.apiRateBurst)
+	}
+}
+
+// newMux returns the mux of the metrics, the JSON API & the landing page, along with the health endpoints unless
+// they are served on a port of their own
+func (web *webSettings) newMux() instrumentedMux {
+	mux := newInstrumentedMux()
+	mux.Handle(web.telemetryPath, web.auth.protect(promhttp.Handler()))
+	// The JSON API serves the same data as /metrics: without API tokens, it requires the metrics credentials
+	authorizeAPI := authorize
+	if len(apiTokens) == 0 {
+		authorizeAPI = func(handler http.HandlerFunc) http.HandlerFunc {
+			return web.auth.protect(handler).ServeHTTP
+		}
+	}
+	// The JSON API is rate limited, served to browsers of the allowed origins, and compressed if accepted
+	api := func(handler http.HandlerFunc) http.HandlerFunc {
+		return compress(apiLimiter.limit(apiCORS.allow(authorizeAPI(handler))))
+	}
+	mux.HandleFunc("/api/v1/sla", api(slaHandler))
+	mux.HandleFunc("/api/v1/heatmap", api(heatmapHandler))
+	mux.HandleFunc("/api/v1/status", api(statusHandler))
+	mux.HandleFunc("/api/v1/dashboard", api(dashboardHandler))
+	mux.HandleFunc("/api/v1/events", apiLimiter.limit(apiCORS.allow(authorizeAPI(eventsHandler))))
+	mux.HandleFunc("/json", api(telegrafHandler))
+	mux.HandleFunc("/schema", api(schemaHandler))
+	mux.HandleFunc("/debug/status", api(debugStatusHandler))
+	mux.HandleFunc("/version", versionHandler)
+
+	endpoints := []landingEndpoint{{Path: web.telemetryPath, Description: "chaos metrics"}}
+	if web.healthListenAddress == "" {
+		registerHealth(mux)
+		endpoints = append(endpoints, landingEndpoint{Path: "/healthz", Description: "liveness"}, landingEndpoint{Path: "/readyz", Description: "readiness"})
+	}
+	mux.HandleFunc("/", landingHandler(append(endpoints, []landingEndpoint{
+		{Path: "/api/v1/status", Description: "state of the chaosengines as JSON"},
+		{Path: "/api/v1/events", Description: "stream of experiment state changes"},
+		{Path: "/api/v1/sla", Description: "resilience SLA of the applications"},
+		{Path: "/api/v1/heatmap", Description: "daily experiment verdicts"},
+		{Path: "/api/v1/dashboard", Description: "Grafana dashboard of the chaosengines"},
+		{Path: "/json", Description: "chaos metrics for Telegraf"},
+		{Path: "/schema", Description: "metric families the exporter can emit"},
+		{Path: "/debug/status", Description: "startup summary"},
+		{Path: "/version", Description: "version of the exporter build"},
+	}...)))
+	return mux
+}
+
+// start serves the metrics & the JSON API, the health endpoints & the pprof profiles on their own ports if
+// configured. It returns the servers of the metrics & of the health endpoints, nil unless served apart
+func (web *webSettings) start() (*http.Server, *http.Server) {
+	// Lightweight endpoints for the kubelet probes, rather than /metrics. Served on a port of their own if
+	// configured, so that NetworkPolicies can open them to the kubelet only
+	var healthServer *http.Server
+	if web.healthListenAddress != "" {
+		healthMux := newInstrumentedMux()
+		registerHealth(healthMux)
+		healthServer = &http.Server{Addr: web.healthListenAddress, Handler: healthMux}
+		go func() {
+			log.Info("Serving the health endpoints on ", web.healthListenAddress)
+			if err := healthServer.ListenAndServe(); err != http.ErrServerClosed {
+				log.Fatal(err)
+			}
+		}()
+	}
+	// The basic auth users of the web config file are required on every path
+	server := &http.Server{Addr: web.listenAddress, Handler: web.auth.requireUsers(web.newMux()), TLSConfig: web.tlsConfig}
+	if web.serverConfig != nil {
+		web.serverConfig.apply(server)
+	}
+	// End the event streams, which would otherwise hold the shutdown until the drain timeout
+	server.RegisterOnShutdown(verdictEvents.shutdown)
+	if web.enablePprof {
+		go serveDebug(web.debugListenAddress)
+	}
+	go func() {
+		log.Infof("Beginning to serve on %s, metrics under %s", web.listenAddress, web.telemetryPath)
+		serve := server.ListenAndServe
+		if web.tlsConfig != nil {
+			if web.tlsConfig.ClientCAs != nil {
+				log.Info("Requiring client certificates signed by the CAs of ", web.clientCAFile)
+			}
+			// The certificate is served by tlsConfig.GetCertificate
+			serve = func() error { return server.ListenAndServeTLS("", "") }
+		}
+		if err := serve(); err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+	return server, healthServer
+}
+
+// shutdown serves the in-flight scrapes before returning, cutting those still running after the drain timeout
+func (web *webSettings) shutdown(server *http.Server, healthServer *http.Server) {
+	drainCtx := context.Background()
+	if web.shutdownTimeout > 0 {
+		var cancelDrain context.CancelFunc
+		drainCtx, cancelDrain = context.WithTimeout(drainCtx, web.shutdownTimeout)
+		defer cancelDrain()
+	}
+	if err := server.Shutdown(drainCtx); err != nil {
+		log.Errorf("Unable to drain the HTTP server within %s, closing it: %v", web.shutdownTimeout, err)
+		server.Close()
+	}
+	if healthServer != nil {
+		healthServer.Close()
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+// Holds the settings of the monitor the exporter registers itself with, none unless monitorKind is set
+var (
+	monitorKind      string
+	monitorName      string
+	monitorNamespace string
+	monitorSelector  string
+	monitorLabels    string
+	monitorInterval  string
+)
+
+// Resources of the monitor kinds of the prometheus-operator
+var monitorResources = map[string]string{
+	"ServiceMonitor": "servicemonitors",
+	"PodMonitor":     "podmonitors",
+}
+
+// validateMonitorSettings checks the settings of the monitor, if one is created
+func validateMonitorSettings(kind string, name string, selector string, labels string) error {
+	if kind == "" {
+		return nil
+	}
+	if _, ok := monitorResources[kind]; !ok {
+		return fmt.Errorf("invalid kind %q, expected ServiceMonitor or PodMonitor", kind)
+	}
+	if name == "" {
+		return fmt.Errorf("the name of the %s is required", kind)
+	}
+	matchLabels, err := parseKeyValues(selector)
+	if err != nil {
+		return err
+	}
+	if len(matchLabels) == 0 {
+		return fmt.Errorf("the selector of the %s is required", kind)
+	}
+	_, err = parseKeyValues(labels)
+	return err
+}
+
+// buildMonitor returns the ServiceMonitor or PodMonitor scraping the exporter: the pods, or the endpoints of the
+// services, matching selector in namespace, on the port of listenAddress & under path, over HTTPS if secure
+func buildMonitor(kind string, name string, namespace string, selector string, labels string, interval string, listenAddress string, path string, secure bool) (map[string]interface{}, error) {
+	_, port, err := net.SplitHostPort(listenAddress)
+	if err != nil {
+		return nil, err
+	}
+	matchLabels, err := parseKeyValues(selector)
+	if err != nil {
+		return nil, err
+	}
+	ownLabels, err := parseKeyValues(labels)
+	if err != nil {
+		return nil, err
+	}
+	protocol := "http"
+	if secure {
+		protocol = "https"
+	}
+	endpoint := map[string]interface{}{"targetPort": json.Number(port), "path": path, "scheme": protocol}
+	if interval != "" {
+		endpoint["interval"] = interval
+	}
+	endpoints := "endpoints"
+	if kind == "PodMonitor" {
+		endpoints = "podMetricsEndpoints"
+	}
+	return map[string]interface{}{
+		"apiVersion": "monitoring.coreos.com/v1",
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace, "labels": ownLabels},
+		"spec": map[string]interface{}{
+			"selector":          map[string]interface{}{"matchLabels": matchLabels},
+			"namespaceSelector": map[string]interface{}{"matchNames": []string{namespace}},
+			endpoints:           []interface{}{endpoint},
+		},
+	}, nil
+}
+
+// applyMonitor creates the monitor, or updates it to its current settings if it exists
+func applyMonitor(cfg *rest.Config, monitor map[string]interface{}) error {
+	config := *cfg
+	config.ContentConfig.GroupVersion = &schema.GroupVersion{Group: "monitoring.coreos.com", Version: "v1"}
+	config.APIPath = "/apis"
+	config.NegotiatedSerializer = serializer.DirectCodecFactory{CodecFactory: scheme.Codecs}
+	config.UserAgent = rest.DefaultKubernetesUserAgent()
+	client, err := rest.RESTClientFor(&config)
+	if err != nil {
+		return err
+	}
+
+	metadata := monitor["metadata"].(map[string]interface{})
+	namespace, name := metadata["namespace"].(string), metadata["name"].(string)
+	resource := monitorResources[monitor["kind"].(string)]
+	existing, err := client.Get().Namespace(namespace).Resource(resource).Name(name).Do().Raw()
+	if errors.IsNotFound(err) {
+		body, err := json.Marshal(monitor)
+		if err != nil {
+			return err
+		}
+		return client.Post().Namespace(namespace).Resource(resource).Body(body).Do().Error()
+	}
+	if err != nil {
+		return err
+	}
+	// Replace the spec & labels of the existing monitor, conditioned on its resource version
+	var current struct {
+		Metadata struct {
+			ResourceVersion string `json:"resourceVersion"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(existing, &current); err != nil {
+		return err
+	}
+	metadata["resourceVersion"] = current.Metadata.ResourceVersion
+	body, err := json.Marshal(monitor)
+	if err != nil {
+		return err
+	}
+	return client.Put().Namespace(namespace).Resource(resource).Name(name).Body(body).Do().Error()
+}
+
+// registerMonitor creates or updates the monitor of the exporter, logging failures: the exporter serves its
+// metrics either way, e.g. to a Prometheus configured otherwise
+func registerMonitor(cfg *rest.Config, monitor map[string]interface{}) {
+	metadata := monitor["metadata"].(map[string]interface{})
+	target := fmt.Sprintf("%s %s/%s", monitor["kind"], metadata["namespace"], metadata["name"])
+	if err := applyMonitor(cfg, monitor); err != nil {
+		exporterLog.Warnf("Unable to apply the %s: %v", target, err)
+		return
+	}
+	exporterLog.Infof("applied the %s", target)
+}
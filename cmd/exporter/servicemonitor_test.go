@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestApplyMonitor(t *testing.T) {
+	var requests []string
+	var applied map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodGet && applied == nil:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+			return
+		case r.Method == http.MethodGet:
+			applied["metadata"].(map[string]interface{})["resourceVersion"] = "42"
+			json.NewEncoder(w).Encode(applied)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&applied)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(applied)
+	}))
+	defer server.Close()
+
+	monitor, err := buildMonitor("ServiceMonitor", "chaos-exporter", "litmus", "app=chaos-exporter", "release=prometheus", "30s", ":8080", "/metrics", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &rest.Config{Host: server.URL}
+	if err := applyMonitor(cfg, monitor); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyMonitor(cfg, monitor); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"GET /apis/monitoring.coreos.com/v1/namespaces/litmus/servicemonitors/chaos-exporter",
+		"POST /apis/monitoring.coreos.com/v1/namespaces/litmus/servicemonitors",
+		"GET /apis/monitoring.coreos.com/v1/namespaces/litmus/servicemonitors/chaos-exporter",
+		"PUT /apis/monitoring.coreos.com/v1/namespaces/litmus/servicemonitors/chaos-exporter",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected the requests %v, got %v", expected, requests)
+	}
+	endpoint := applied["spec"].(map[string]interface{})["endpoints"].([]interface{})[0].(map[string]interface{})
+	if endpoint["targetPort"] != 8080.0 || endpoint["path"] != "/metrics" || endpoint["scheme"] != "http" || endpoint["interval"] != "30s" {
+		t.Errorf("unexpected endpoint %v", endpoint)
+	}
+	if metadata := applied["metadata"].(map[string]interface{}); metadata["resourceVersion"] != "42" || metadata["labels"].(map[string]interface{})["release"] != "prometheus" {
+		t.Errorf("unexpected metadata %v", metadata)
+	}
+	if err := validateMonitorSettings("Probe", "chaos-exporter", "app=chaos-exporter", ""); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+}
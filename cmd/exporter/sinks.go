@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/litmuschaos/chaos-exporter/pkg/sinks"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Holds the endpoints of the configured sinks, checked for reachability at startup
+var sinkEndpoints []sinks.Endpoint
+
+// Time after which the reachability check of a sink endpoint fails
+var sinkCheckTimeout time.Duration
+
+// checkSinks checks the reachability of every sink endpoint concurrently, without delaying the startup.
+// The outcome is exported per endpoint, so misconfigured sinks are caught before a delivery fails
+func checkSinks(ctx context.Context, endpoints []sinks.Endpoint) {
+	for _, endpoint := range endpoints {
+		go func(endpoint sinks.Endpoint) {
+			reachable := 1.0
+			if err := sinks.Check(ctx, endpoint, sinkCheckTimeout); err != nil {
+				log.Warnf("%s sink endpoint %s is unreachable: %v", endpoint.Sink, endpoint.Address, err)
+				reachable = 0
+			}
+			sinkReachable.WithLabelValues(endpoint.Sink, endpoint.Address).Set(reachable)
+		}(endpoint)
+	}
+}
+
+// validateSinks checks the settings of the sinks, creating those that need no connection
+func validateSinks(problems *configProblems) {
+	var err error
+	problems.addErr("--push.url (CHAOS_EXPORTER_PUSH_URL)", validatePushURL(pushURL))
+	if pushURL != "" && pushJob == "" {
+		problems.add("--push.job (CHAOS_EXPORTER_PUSH_JOB)", "required along with --push.url")
+	}
+	problems.addErr("--statsd.address (CHAOS_EXPORTER_STATSD_ADDRESS) & --statsd.format", validateStatsD(statsdAddress, statsdFormat))
+	datadogSink, err = newDatadogSink(datadogAPIKey, datadogSite, datadogTimeout)
+	problems.addErr("--datadog.api-key (CHAOS_EXPORTER_DATADOG_API_KEY) & --datadog.site", err)
+	cloudWatchSink, err = newCloudWatchPublisher(cloudWatchNamespace, cloudWatchRegion, cloudWatchDimensions, cloudWatchStaticDimensions, cloudWatchTimeout)
+	problems.addErr("--cloudwatch.namespace (CHAOS_EXPORTER_CLOUDWATCH_NAMESPACE)", err)
+	if cloudMonitoringEnabled && (cloudMonitoringCluster == "") != (cloudMonitoringLocation == "") {
+		problems.add("--gcp.cluster-name & --gcp.location", "are set together")
+	}
+	azureMonitorSink, err = newAzureMonitorPublisher(azureMonitorResourceID, azureMonitorRegion, azureMonitorNamespace, azureMonitorDimensions, azureMonitorTimeout)
+	problems.addErr("--azure.resource-id (CHAOS_EXPORTER_AZURE_RESOURCE_ID), --azure.region & --azure.dimensions", err)
+	newRelicSink, err = newNewRelicPublisher(newRelicLicenseKey, newRelicRegion, newRelicClusterName, newRelicTimeout)
+	problems.addErr("--newrelic.license-key (CHAOS_EXPORTER_NEWRELIC_LICENSE_KEY) & --newrelic.region", err)
+	influxSink, err = newInfluxSink(influxURL, influxOrg, influxBucket, influxToken, influxTimeout)
+	problems.addErr("--influxdb.url (CHAOS_EXPORTER_INFLUXDB_URL), --influxdb.org, --influxdb.bucket & --influxdb.token", err)
+	remoteWriteSink, err = newRemoteWriteSink(remoteWriteURL, remoteWriteBearerToken, remoteWriteUsername, remoteWritePassword, remoteWriteCAFile, remoteWriteCertFile, remoteWriteKeyFile, remoteWriteInsecureSkipVerify, remoteWriteTimeout)
+	problems.addErr("--remote-write.url (CHAOS_EXPORTER_REMOTE_WRITE_URL), its credentials & TLS settings", err)
+	if graphiteAddress != "" {
+		problems.addErr("--graphite.address (CHAOS_EXPORTER_GRAPHITE_ADDRESS)", validateAddress(graphiteAddress))
+		if graphiteInterval <= 0 {
+			problems.add("--graphite.interval (CHAOS_EXPORTER_GRAPHITE_INTERVAL)", "expected a positive duration, got %s", graphiteInterval)
+		}
+	}
+	kafkaSink, err = newKafkaSink(kafkaRESTURL, kafkaTopic, kafkaFormat, kafkaTimeout)
+	problems.addErr("--kafka.rest-url (CHAOS_EXPORTER_KAFKA_REST_URL), --kafka.topic & --kafka.format", err)
+	chaosCenterSink, err = newChaosCenterSink(chaosCenterURL, chaosCenterInfraID, chaosCenterAccessKey, chaosCenterTimeout)
+	problems.addErr("--chaoscenter.url (CHAOS_EXPORTER_CHAOSCENTER_URL), --chaoscenter.infra-id & --chaoscenter.access-key", err)
+	incidentManager, err = newIncidentManager(incidentProvider, incidentKey, incidentURL, incidentThreshold, incidentTimeout)
+	problems.addErr("--incidents.provider (CHAOS_EXPORTER_INCIDENTS_PROVIDER), --incidents.key, --incidents.url & --incidents.threshold", err)
+}
+
+// connectSinks creates the sinks that connect on creation, and lists the endpoints of all the configured sinks
+func connectSinks(otlp otlpSettings) {
+	var err error
+	if pushURL != "" {
+		sinkEndpoints = append(sinkEndpoints, sinks.Endpoint{Sink: "pushgateway", Address: pushURL})
+	}
+	if statsdAddress != "" {
+		if statsdSink, err = sinks.NewStatsD(statsdAddress, statsdPrefix, statsdFormat == "dogstatsd"); err != nil {
+			log.Fatal("Unable to set up StatsD: ", err)
+		}
+		sinkEndpoints = append(sinkEndpoints, sinks.Endpoint{Sink: "statsd", Address: "udp://" + statsdAddress})
+	}
+	if datadogSink != nil {
+		sinkEndpoints = append(sinkEndpoints, sinks.Endpoint{Sink: "datadog", Address: datadogSink.URL})
+	}
+	if cloudMonitoringSink, err = newCloudMonitoringPublisher(context.Background(), cloudMonitoringEnabled, cloudMonitoringProject, cloudMonitoringCluster, cloudMonitoringLocation, cloudMonitoringPrefix, cloudMonitoringTimeout); err != nil {
+		log.Fatal("Unable to set up Cloud Monitoring: ", err)
+	}
+	if cloudMonitoringSink != nil {
+		sinkEndpoints = append(sinkEndpoints, sinks.Endpoint{Sink: "cloud-monitoring", Address: "https://monitoring.googleapis.com"})
+	}
+	if azureMonitorSink != nil {
+		sinkEndpoints = append(sinkEndpoints, sinks.Endpoint{Sink: "azure-monitor", Address: "https://" + azureMonitorRegion + ".monitoring.azure.com"})
+	}
+	if newRelicSink != nil {
+		sinkEndpoints = append(sinkEndpoints, sinks.Endpoint{Sink: "newrelic", Address: sinks.NewRelicEndpoint(newRelicRegion)})
+	}
+	if influxSink != nil {
+		sinkEndpoints = append(sinkEndpoints, sinks.Endpoint{Sink: "influxdb", Address: influxURL})
+	}
+	if remoteWriteSink != nil {
+		sinkEndpoints = append(sinkEndpoints, sinks.Endpoint{Sink: "remote-write", Address: remoteWriteURL})
+	}
+	if cloudWatchSink != nil {
+		sinkEndpoints = append(sinkEndpoints, sinks.Endpoint{Sink: "cloudwatch", Address: "https://monitoring." + cloudWatchRegion + ".amazonaws.com"})
+	}
+	if incidentManager != nil {
+		address := incidentURL
+		if address == "" {
+			address = map[string]string{"pagerduty": "https://events.pagerduty.com", "opsgenie": "https://api.opsgenie.com"}[incidentProvider]
+		}
+		sinkEndpoints = append(sinkEndpoints, sinks.Endpoint{Sink: incidentProvider, Address: address})
+	}
+	if chaosCenterSink != nil {
+		sinkEndpoints = append(sinkEndpoints, sinks.Endpoint{Sink: "chaoscenter", Address: chaosCenterURL})
+	}
+	if kafkaSink != nil {
+		sinkEndpoints = append(sinkEndpoints, sinks.Endpoint{Sink: "kafka", Address: kafkaRESTURL})
+	}
+	if graphiteAddress != "" {
+		sinkEndpoints = append(sinkEndpoints, sinks.Endpoint{Sink: "graphite", Address: graphiteAddress})
+	}
+	if otlp.endpoint != "" {
+		log.Infof("exporting the metrics every %s to the OTLP endpoint %s", otlp.interval, otlp.endpoint)
+		sinkEndpoints = append(sinkEndpoints, sinks.Endpoint{Sink: "otlp", Address: otlp.endpoint})
+	}
+}
+
+// runSinks checks the reachability of the sinks, and feeds those fed apart from the collection until ctx is done
+func runSinks(ctx context.Context, otlp otlpSettings) {
+	checkSinks(ctx, sinkEndpoints)
+	if statsdSink != nil {
+		go runStatsDTransitions(ctx, statsdSink)
+	}
+	if datadogSink != nil {
+		go runDatadogEvents(ctx, datadogSink)
+	}
+	if kafkaSink != nil {
+		go runKafkaEvents(ctx, kafkaSink)
+	}
+	if chaosCenterSink != nil {
+		go runChaosCenterFeed(ctx, chaosCenterSink)
+	}
+	if incidentManager != nil {
+		go runIncidents(ctx, incidentManager, incidentThreshold)
+	}
+	if kubernetesEvents {
+		writer, err := newKubeEngineEvents(config)
+		if err != nil {
+			log.Fatal("Unable to create the Events client: ", err)
+		}
+		go runKubernetesEvents(ctx, writer)
+	}
+	if otlp.endpoint != "" {
+		go runOTLPExport(ctx, otlp, prometheus.DefaultGatherer)
+	}
+	if graphiteAddress != "" {
+		graphite := &sinks.Graphite{Address: graphiteAddress, Prefix: graphitePrefix, Timeout: graphiteTimeout}
+		go runGraphiteFlush(ctx, graphite, graphiteInterval, prometheus.DefaultGatherer)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Version of the snapshot file layout, snapshots of other versions are ignored
+const snapshotVersion = 1
+
+// snapshotFile is the layout of the file the engine series are persisted to across restarts
+type snapshotFile struct {
+	Version int `json:"version"`
+	// Series of the last collection of each chaosengine, keyed by <namespace>/<engine>
+	Engines map[string][]snapshotSeries `json:"engines"`
+}
+
+type snapshotSeries struct {
+	Metric    string   `json:"metric"`
+	Labels    []string `json:"labels"`
+	Value     float64  `json:"value"`
+	Versioned bool     `json:"versioned,omitempty"`
+}
+
+// Holds the engines whose series were restored from a snapshot and not collected since
+var restoredEngines = make(map[string]bool)
+
+// saveSnapshot writes the series of the last collection of every chaosengine to path. The file is replaced
+// atomically, so that an interrupted save leaves the previous snapshot intact
+func saveSnapshot(path string) error {
+	stateMutex.Lock()
+	file := snapshotFile{Version: snapshotVersion, Engines: make(map[string][]snapshotSeries)}
+	for key, engine := range engineSeries {
+		for gauge, series := range engine {
+			for _, value := range series {
+				file.Engines[key] = append(file.Engines[key], snapshotSeries{
+					Metric:    gauge.name,
+					Labels:    value.labels,
+					Value:     value.value,
+					Versioned: value.versioned,
+				})
+			}
+		}
+	}
+	stateMutex.Unlock()
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// loadSnapshot restores the series persisted to path, until the engines are collected again. It returns the
+// number of engines restored, none if the file does not exist
+func loadSnapshot(path string) (int, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var file snapshotFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return 0, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+	if file.Version != snapshotVersion {
+		return 0, fmt.Errorf("%s is of version %d, expected %d", path, file.Version, snapshotVersion)
+	}
+
+	gauges := make(map[string]*engineGauge)
+	for _, gauge := range []*engineGauge{experimentsTotal, passedExperiments, failedExperiments, probeStatus, expectedIterations, actualIterations, engineOwner, failureBudgetRemaining} {
+		gauges[gauge.name] = gauge
+	}
+	restored := make(map[string]seriesSet)
+	for key, engine := range file.Engines {
+		series := make(seriesSet)
+		for _, value := range engine {
+			gauge, ok := gauges[value.Metric]
+			if !ok && strings.HasPrefix(value.Metric, "c_exp_") {
+				gauge = experimentGauge(strings.TrimPrefix(value.Metric, "c_exp_"))
+			} else if !ok {
+				// A metric dropped by this release
+				continue
+			}
+			series.record(gauge, &seriesValue{labels: value.Labels, value: value.Value, versioned: value.Versioned})
+		}
+		restored[key] = series
+	}
+
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+	for key, series := range restored {
+		if _, collected := engineSeries[key]; !collected {
+			engineSeries[key] = series
+			restoredEngines[key] = true
+		}
+	}
+	return len(restoredEngines), nil
+}
+
+// restoreSnapshot restores the engine series saved to path, served until the engines are collected again
+func restoreSnapshot(path string) {
+	restored, err := loadSnapshot(path)
+	if err != nil {
+		log.Warn("Unable to restore the snapshot, starting cold: ", err)
+		return
+	}
+	if restored > 0 {
+		// Serve the restored series right away, they are replaced as the engines are collected
+		log.Infof("Restored the series of %d chaosengines from %s", restored, path)
+		atomic.StoreInt32(&collectionSucceeded, 1)
+	}
+}
+
+// dropRestoredSeries drops the restored series of the engines a complete collection pass did not collect,
+// for e.g. those deleted while the exporter was down
+func dropRestoredSeries() {
+	stateMutex.Lock()
+	keys := make([]string, 0, len(restoredEngines))
+	for key := range restoredEngines {
+		keys = append(keys, key)
+	}
+	stateMutex.Unlock()
+
+	for _, key := range keys {
+		replaceEngineSeries(key, nil)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chaos-exporter-snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "snapshot.json")
+
+	series := make(seriesSet)
+	series.setVersioned(experimentsTotal, 2, "litmus", "uid", "engine-nginx", "1.13", "1.0")
+	series.setVersioned(experimentGauge("pod_delete"), 3, "litmus", "uid", "engine-nginx", "1.13", "1.0")
+	replaceEngineSeries("litmus/engine-nginx", series)
+	replaceEngineSeries("litmus/engine-deleted", seriesSet{engineOwner: {"": {labels: []string{"litmus", "engine-deleted", "Workflow", "wf"}, value: 1}}})
+	if err := saveSnapshot(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restart: the series are restored, until the engines are collected again
+	replaceEngineSeries("litmus/engine-nginx", nil)
+	replaceEngineSeries("litmus/engine-deleted", nil)
+	if restored, err := loadSnapshot(path); restored != 2 || err != nil {
+		t.Fatalf("expected 2 restored engines, got %d, %v", restored, err)
+	}
+	if value, ok := collectedValue(experimentGauge("pod_delete"), "litmus", "uid", "engine-nginx", "1.13", "1.0"); !ok || value != 3 {
+		t.Errorf("expected the restored experiment state, got %v", value)
+	}
+	replaceEngineSeries("litmus/engine-nginx", series)
+	dropRestoredSeries()
+	if _, ok := collectedValue(engineOwner, "litmus", "engine-deleted", "Workflow", "wf"); ok {
+		t.Error("expected the series of the uncollected engine to be dropped")
+	}
+	if value, ok := collectedValue(experimentsTotal, "litmus", "uid", "engine-nginx", "1.13", "1.0"); !ok || value != 2 {
+		t.Errorf("expected the series of the collected engine to be kept, got %v", value)
+	}
+	replaceEngineSeries("litmus/engine-nginx", nil)
+
+	if restored, err := loadSnapshot(filepath.Join(dir, "missing.json")); restored != 0 || err != nil {
+		t.Errorf("expected a missing snapshot to be ignored, got %d, %v", restored, err)
+	}
+}
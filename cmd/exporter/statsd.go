@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/sinks"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Holds the settings of the StatsD emission, an empty address disables it
+var (
+	statsdAddress string
+	statsdPrefix  string
+	statsdFormat  string
+)
+
+// Holds the StatsD client, nil unless the emission is enabled
+var statsdSink *sinks.StatsD
+
+// validateStatsD checks the StatsD settings, if an address is set
+func validateStatsD(address string, format string) error {
+	if address == "" {
+		return nil
+	}
+	if err := validateAddress(address); err != nil {
+		return err
+	}
+	if format != "statsd" && format != "dogstatsd" {
+		return fmt.Errorf("invalid format %q, expected statsd or dogstatsd", format)
+	}
+	return nil
+}
+
+// runStatsDTransitions counts every verdict transition on StatsD, as <prefix>.verdict_transitions tagged with
+// the namespace, engine, experiment & the verdicts transitioned from & to, until ctx is done
+func runStatsDTransitions(ctx context.Context, client *sinks.StatsD) {
+	events, unsubscribe := verdictEvents.subscribe()
+	defer unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			err := client.Count("verdict_transitions", 1,
+				sinks.Tag{Name: "chaos_namespace", Value: event.Namespace},
+				sinks.Tag{Name: "engine_name", Value: event.Engine},
+				sinks.Tag{Name: "experiment", Value: event.Experiment},
+				sinks.Tag{Name: "from", Value: event.From},
+				sinks.Tag{Name: "to", Value: event.To},
+			)
+			logStatsDError(err)
+		}
+	}
+}
+
+// emitStatsDGauges sends the series of the last collection of every chaosengine as StatsD gauges, tagged with
+// their labels. Called after each collection pass, it does nothing unless the emission is enabled
+func emitStatsDGauges(client *sinks.StatsD) {
+	if client == nil {
+		return
+	}
+	_, families, err := gatherEngineFamilies()
+	if err != nil {
+		logStatsDError(err)
+		return
+	}
+	for _, engine := range families {
+		for _, family := range engine {
+			if family.GetType() != dto.MetricType_GAUGE {
+				continue
+			}
+			for _, metric := range family.Metric {
+				tags := make([]sinks.Tag, 0, len(metric.Label))
+				for _, label := range metric.Label {
+					tags = append(tags, sinks.Tag{Name: label.GetName(), Value: label.GetValue()})
+				}
+				logStatsDError(client.Gauge(family.GetName(), metric.GetGauge().GetValue(), tags...))
+			}
+		}
+	}
+}
+
+// logStatsDError logs the failure to send to StatsD, if any
+func logStatsDError(err error) {
+	if err == nil {
+		return
+	}
+	if logger := logSampling.sample(exporterLog, "statsd"); logger != nil {
+		logger.Warn("Unable to send to StatsD: ", err)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/chaosmetrics"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// experimentStatus is the state of an experiment in the status document
+type experimentStatus struct {
+	Name    string `json:"name"`
+	Verdict string `json:"verdict"`
+	// Time the experiment was first observed with its verdict
+	Since time.Time `json:"since"`
+}
+
+// engineStatus is the state of a chaosengine in the status document
+type engineStatus struct {
+	Namespace      string             `json:"namespace"`
+	Name           string             `json:"name"`
+	LastCollected  time.Time          `json:"lastCollected"`
+	Stale          bool               `json:"stale"`
+	InvalidReasons []string           `json:"invalidReasons"`
+	Experiments    []experimentStatus `json:"experiments"`
+}
+
+// engineStatuses returns the state of the collected chaosengines, ordered by namespace & name
+func engineStatuses() []engineStatus {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+
+	engines := make(map[string]*engineStatus)
+	for key, collected := range engineLastCollected {
+		parts := strings.SplitN(key, "/", 2)
+		_, stale := staleEngines[types.NamespacedName{Namespace: parts[0], Name: parts[1]}]
+		engines[key] = &engineStatus{
+			Namespace:      parts[0],
+			Name:           parts[1],
+			LastCollected:  collected,
+			Stale:          stale,
+			InvalidReasons: append([]string{}, invalidEngines[key]...),
+			Experiments:    []experimentStatus{},
+		}
+	}
+	for key, observed := range lastVerdicts {
+		parts := strings.SplitN(key, "/", 3)
+		if engine, ok := engines[parts[0]+"/"+parts[1]]; ok {
+			engine.Experiments = append(engine.Experiments, experimentStatus{
+				Name:    parts[2],
+				Verdict: chaosmetrics.StatusName(observed.verdict),
+				Since:   observed.since,
+			})
+		}
+	}
+
+	statuses := make([]engineStatus, 0, len(engines))
+	for _, engine := range engines {
+		sort.Slice(engine.Experiments, func(i, j int) bool { return engine.Experiments[i].Name < engine.Experiments[j].Name })
+		statuses = append(statuses, *engine)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Namespace != statuses[j].Namespace {
+			return statuses[i].Namespace < statuses[j].Namespace
+		}
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// statusHandler serves the state of the collected chaosengines & their experiments, restricted to the engines
+// in the scope of the token, and to those of the namespace & engine query parameters if given
+func statusHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]interface{}{"engines": requestEngineStatuses(r)})
+}
+
+// requestEngineStatuses returns the state of the collected chaosengines in the scope of the token of r, and of
+// its namespace & engine query parameters if given
+func requestEngineStatuses(r *http.Request) []engineStatus {
+	scope := requestScope(r)
+	namespace, engine := r.URL.Query().Get("namespace"), r.URL.Query().Get("engine")
+	engines := []engineStatus{}
+	for _, status := range engineStatuses() {
+		if !scope.allows(status.Namespace, status.Name) {
+			continue
+		}
+		if (namespace != "" && status.Namespace != namespace) || (engine != "" && status.Name != engine) {
+			continue
+		}
+		engines = append(engines, status)
+	}
+	return engines
+}
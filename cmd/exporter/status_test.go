@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestStatusHandler(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	defer func(c clock.Clock) { exporterClock = c }(exporterClock)
+	exporterClock = fakeClock
+	engineLastCollected = make(map[string]time.Time)
+	defer func() { engineLastCollected = make(map[string]time.Time) }()
+
+	for _, engine := range []types.NamespacedName{{Namespace: "payments", Name: "engine-checkout"}, {Namespace: "litmus", Name: "engine-status"}} {
+		markCollected(engine.Namespace, engine.Name)
+		recordTransitions(engine.Namespace, engine.Name, map[string]float64{"pod-delete": 1}, nil)
+	}
+	fakeClock.Step(time.Minute)
+	recordTransitions("litmus", "engine-status", map[string]float64{"pod-delete": 3}, nil)
+
+	get := func(target string, scope *tokenScope) []engineStatus {
+		r := httptest.NewRequest("GET", target, nil)
+		r = r.WithContext(context.WithValue(r.Context(), scopeKey{}, scope))
+		w := httptest.NewRecorder()
+		statusHandler(w, r)
+		var document struct {
+			Engines []engineStatus `json:"engines"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &document); err != nil {
+			t.Fatal(err)
+		}
+		return document.Engines
+	}
+
+	engines := get("/api/v1/status", nil)
+	if len(engines) != 2 || engines[0].Name != "engine-status" || engines[1].Name != "engine-checkout" {
+		t.Fatalf("unexpected engines %v", engines)
+	}
+	experiment := engines[0].Experiments[0]
+	if experiment.Name != "pod-delete" || experiment.Verdict != "pass" || !experiment.Since.Equal(fakeClock.Now()) {
+		t.Errorf("unexpected experiment %v", experiment)
+	}
+	if engines := get("/api/v1/status?engine=engine-checkout", nil); len(engines) != 1 || engines[0].Experiments[0].Verdict != "running" {
+		t.Errorf("unexpected engines %v", engines)
+	}
+	if engines := get("/api/v1/status", &tokenScope{namespaces: map[string]bool{"payments": true}}); len(engines) != 1 || engines[0].Namespace != "payments" {
+		t.Errorf("expected the scope to restrict the engines, got %v", engines)
+	}
+}
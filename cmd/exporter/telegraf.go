@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// telegrafMetric is a flat JSON object, as consumed by the json data format of Telegraf's http input
+// (json_name_key = "name", tag_keys = the label names). Histograms & summaries are reduced to their
+// _sum & _count, as their buckets & quantiles do not map to a single value
+type telegrafMetric map[string]interface{}
+
+// gatherTelegraf returns the metrics of the gatherer as telegraf metrics, one per series
+func gatherTelegraf(gatherer prometheus.Gatherer) ([]telegrafMetric, error) {
+	families, err := gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := []telegrafMetric{}
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			for name, value := range seriesValues(family, m) {
+				metric := telegrafMetric{"name": name, "value": value}
+				for _, label := range m.GetLabel() {
+					metric[label.GetName()] = label.GetValue()
+				}
+				metrics = append(metrics, metric)
+			}
+		}
+	}
+	return metrics, nil
+}
+
+// seriesValues returns the values of a series, keyed by metric name
+func seriesValues(family *dto.MetricFamily, m *dto.Metric) map[string]float64 {
+	name := family.GetName()
+	switch family.GetType() {
+	case dto.MetricType_COUNTER:
+		return map[string]float64{name: m.GetCounter().GetValue()}
+	case dto.MetricType_GAUGE:
+		return map[string]float64{name: m.GetGauge().GetValue()}
+	case dto.MetricType_SUMMARY:
+		return map[string]float64{name + "_sum": m.GetSummary().GetSampleSum(), name + "_count": float64(m.GetSummary().GetSampleCount())}
+	case dto.MetricType_HISTOGRAM:
+		return map[string]float64{name + "_sum": m.GetHistogram().GetSampleSum(), name + "_count": float64(m.GetHistogram().GetSampleCount())}
+	default:
+		return map[string]float64{name: m.GetUntyped().GetValue()}
+	}
+}
+
+// scopeTelegraf returns the metrics whose chaos (or application) namespace and engine are in scope. Series
+// of neither, for e.g. the exporter's own metrics, are left out
+func scopeTelegraf(metrics []telegrafMetric, scope *tokenScope) []telegrafMetric {
+	scoped := []telegrafMetric{}
+	for _, metric := range metrics {
+		namespace, _ := metric["chaos_namespace"].(string)
+		if namespace == "" {
+			namespace, _ = metric["app_namespace"].(string)
+		}
+		engine, _ := metric["engine_name"].(string)
+		if namespace != "" && scope.allows(namespace, engine) {
+			scoped = append(scoped, metric)
+		}
+	}
+	return scoped
+}
+
+// telegrafHandler serves the metrics in the JSON shape expected by Telegraf's http input
+func telegrafHandler(w http.ResponseWriter, r *http.Request) {
+	metrics, err := gatherTelegraf(prometheus.DefaultGatherer)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if scope := requestScope(r); scope != nil {
+		metrics = scopeTelegraf(metrics, scope)
+	}
+	writeJSON(w, metrics)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TestGatherTelegraf verifies that every series is flattened into an object carrying its labels
+func TestGatherTelegraf(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "c_engine_experiment_count"}, []string{"engine_name"})
+	registry.MustRegister(gauge)
+	gauge.WithLabelValues("engine-nginx").Set(2)
+
+	metrics, err := gatherTelegraf(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics) != 1 {
+		t.Fatalf("expected a single metric, got %v", metrics)
+	}
+	metric := metrics[0]
+	if metric["name"] != "c_engine_experiment_count" || metric["engine_name"] != "engine-nginx" || metric["value"] != 2.0 {
+		t.Errorf("unexpected metric %v", metric)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+// serverTLSConfig returns the TLS config of the HTTP server, nil to serve plain HTTP. With a client CA bundle,
+// clients must present a certificate signed by one of its CAs before any metric or API response is served
+func serverTLSConfig(certFile string, keyFile string, clientCAFile string) (*tls.Config, error) {
+	if certFile == "" && keyFile == "" {
+		if clientCAFile != "" {
+			return nil, fmt.Errorf("client certificate authentication requires a server certificate & key")
+		}
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("both a server certificate & key are required")
+	}
+
+	keyPair := &keyPairReloader{certFile: certFile, keyFile: keyFile}
+	if _, err := keyPair.GetCertificate(nil); err != nil {
+		return nil, err
+	}
+	config := &tls.Config{GetCertificate: keyPair.GetCertificate, MinVersion: tls.VersionTLS12}
+
+	if clientCAFile != "" {
+		bundle, err := ioutil.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = x509.NewCertPool()
+		if !config.ClientCAs.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("no PEM certificate found in %s", clientCAFile)
+		}
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// keyPairReloader serves the server certificate, reloading it once the certificate file is modified,
+// for e.g. renewed by cert-manager
+type keyPairReloader struct {
+	certFile string
+	keyFile  string
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	modified time.Time
+	size     int64
+}
+
+// GetCertificate returns the current certificate, as a tls.Config callback
+func (k *keyPairReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	modified, size := fileVersion(k.certFile)
+	if k.cert != nil && modified.Equal(k.modified) && size == k.size {
+		return k.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(k.certFile, k.keyFile)
+	if err != nil {
+		if k.cert != nil {
+			// Keep serving the previous certificate, the key may not have been rotated yet
+			return k.cert, nil
+		}
+		return nil, err
+	}
+	k.cert, k.modified, k.size = &cert, modified, size
+	return k.cert, nil
+}
+
+// clientTLSConfig returns the TLS config of the requests to an endpoint, nil for the system defaults: the CA
+// bundle the endpoint is verified against, and the certificate presented to it
+func clientTLSConfig(caFile string, certFile string, keyFile string, insecureSkipVerify bool) (*tls.Config, error) {
+	if caFile == "" && certFile == "" && keyFile == "" && !insecureSkipVerify {
+		return nil, nil
+	}
+	config := &tls.Config{InsecureSkipVerify: insecureSkipVerify, MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		bundle, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("no PEM certificate found in %s", caFile)
+		}
+	}
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("both a client certificate & key are required")
+	}
+	if certFile != "" {
+		keyPair := &keyPairReloader{certFile: certFile, keyFile: keyFile}
+		if _, err := keyPair.GetCertificate(nil); err != nil {
+			return nil, err
+		}
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return keyPair.GetCertificate(nil)
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCertificate writes a certificate & key for localhost to dir, signed by parent or self-signed if nil
+func writeCertificate(t *testing.T, dir string, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		IsCA:                  parent == nil,
+		BasicConstraintsValid: true,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := ioutil.WriteFile(filepath.Join(dir, name+".crt"), certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, name+".key"), keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestServerTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chaos-exporter-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ca, caKey := writeCertificate(t, dir, "ca", nil, nil)
+	writeCertificate(t, dir, "server", ca, caKey)
+	writeCertificate(t, dir, "client", ca, caKey)
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	if config, err := serverTLSConfig("", "", ""); config != nil || err != nil {
+		t.Errorf("expected plain HTTP without any TLS file, got %v, %v", config, err)
+	}
+	if _, err := serverTLSConfig("", "", path("ca.crt")); err == nil {
+		t.Error("expected a client CA without a server certificate to be rejected")
+	}
+	if _, err := serverTLSConfig(path("server.crt"), "", ""); err == nil {
+		t.Error("expected a server certificate without a key to be rejected")
+	}
+	if _, err := serverTLSConfig(path("server.crt"), path("server.key"), path("server.key")); err == nil {
+		t.Error("expected a client CA bundle without certificates to be rejected")
+	}
+
+	config, err := serverTLSConfig(path("server.crt"), path("server.key"), path("ca.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	// StartTLS would replace the certificate served by config
+	server.Listener = tls.NewListener(server.Listener, config)
+	server.Start()
+	defer server.Close()
+	url := "https://" + server.Listener.Addr().String()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	get := func(certificates []tls.Certificate) (string, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certificates}}}
+		resp, err := client.Get(url)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	if _, err := get(nil); err == nil {
+		t.Error("expected a client without a certificate to be rejected")
+	}
+	clientCert, err := tls.LoadX509KeyPair(path("client.crt"), path("client.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, err := get([]tls.Certificate{clientCert}); err != nil || body != "client" {
+		t.Errorf("expected the client certificate to be verified, got %q, %v", body, err)
+	}
+}
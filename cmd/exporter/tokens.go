@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/ghodss/yaml"
+	"github.com/litmuschaos/chaos-exporter/pkg/credentials"
+)
+
+// tokensFile is the layout of the API tokens file
+type tokensFile struct {
+	Tokens []struct {
+		// Credential reference of the token, e.g. env:PAYMENTS_API_TOKEN
+		Token string `json:"token"`
+		// Namespaces & engines the token is restricted to, a token without namespaces is cluster-wide
+		Namespaces []string `json:"namespaces"`
+		Engines    []string `json:"engines"`
+	} `json:"tokens"`
+}
+
+// tokenScope holds the namespaces & engines whose data a token may read
+type tokenScope struct {
+	namespaces map[string]bool
+	// Empty to allow all the engines of the namespaces
+	engines map[string]bool
+}
+
+// apiToken is a token accepted by the JSON API
+type apiToken struct {
+	credential credentials.Provider
+	// nil for a cluster-wide token
+	scope *tokenScope
+}
+
+// Holds the tokens accepted by the JSON API, which is open if none are configured
+var apiTokens []apiToken
+
+// loadAPITokens reads the tokens listed in the file at path, resolving their values through the
+// credentials package on every request, so rotated tokens are picked up without a restart
+func loadAPITokens(path string) ([]apiToken, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file tokensFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+
+	var tokens []apiToken
+	for i, entry := range file.Tokens {
+		credential, err := credentials.New(entry.Token)
+		if err != nil {
+			return nil, fmt.Errorf("token %d: %v", i, err)
+		}
+		token := apiToken{credential: credential}
+		if len(entry.Namespaces) > 0 {
+			token.scope = &tokenScope{namespaces: make(map[string]bool), engines: make(map[string]bool)}
+			for _, ns := range entry.Namespaces {
+				token.scope.namespaces[ns] = true
+			}
+			for _, engine := range entry.Engines {
+				token.scope.engines[engine] = true
+			}
+		} else if len(entry.Engines) > 0 {
+			return nil, fmt.Errorf("token %d: engines require namespaces", i)
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// allows reports whether the data of the engine in namespace may be read. An empty engine stands for
+// data not attributed to an engine, allowed if the namespace is. A nil scope allows everything
+func (s *tokenScope) allows(namespace string, engine string) bool {
+	if s == nil {
+		return true
+	}
+	if !s.namespaces[namespace] {
+		return false
+	}
+	return engine == "" || len(s.engines) == 0 || s.engines[engine]
+}
+
+type scopeKey struct{}
+
+// requestScope returns the scope of the token a request was authorized with, nil if unrestricted
+func requestScope(r *http.Request) *tokenScope {
+	scope, _ := r.Context().Value(scopeKey{}).(*tokenScope)
+	return scope
+}
+
+// authorize requires a bearer token accepted by the JSON API, if any are configured, and passes its
+// scope on to the handler
+func authorize(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(apiTokens) == 0 {
+			handler(w, r)
+			return
+		}
+		if token, ok := presentedToken(r); ok {
+			handler(w, r.WithContext(context.WithValue(r.Context(), scopeKey{}, token.scope)))
+			return
+		}
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "a valid API token is required", http.StatusUnauthorized)
+	}
+}
+
+// presentedToken returns the token of the JSON API the request carries as its bearer token, if any
+func presentedToken(r *http.Request) (apiToken, bool) {
+	presented := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	for _, token := range apiTokens {
+		value, err := token.credential.Get()
+		if err != nil {
+			log.Error("Unable to read an API token: ", err)
+			continue
+		}
+		if value != "" && subtle.ConstantTimeCompare([]byte(presented), []byte(value)) == 1 {
+			return token, true
+		}
+	}
+	return apiToken{}, false
+}
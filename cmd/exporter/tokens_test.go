@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/credentials"
+	"github.com/litmuschaos/chaos-exporter/pkg/history"
+)
+
+// TestAuthorize verifies that the JSON API requires a configured token and only serves the data in its scope
+func TestAuthorize(t *testing.T) {
+	os.Setenv("TEST_PAYMENTS_TOKEN", "payments-secret")
+	defer os.Unsetenv("TEST_PAYMENTS_TOKEN")
+	apiTokens = []apiToken{{
+		credential: &credentials.EnvProvider{Name: "TEST_PAYMENTS_TOKEN"},
+		scope:      &tokenScope{namespaces: map[string]bool{"payments": true}},
+	}}
+	defer func() { apiTokens = nil }()
+
+	heatmap.Record("payments", "pod-delete", true)
+	heatmap.Record("orders", "pod-delete", true)
+	handler := authorize(heatmapHandler)
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest("GET", "/api/v1/heatmap", nil))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("expected a request without token to be rejected, got %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	request := httptest.NewRequest("GET", "/api/v1/heatmap", nil)
+	request.Header.Set("Authorization", "Bearer payments-secret")
+	handler(recorder, request)
+	var matrix history.Matrix
+	if err := json.Unmarshal(recorder.Body.Bytes(), &matrix); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range matrix.Experiments {
+		if row.Namespace != "payments" {
+			t.Errorf("expected only the payments experiments, got %s/%s", row.Namespace, row.Experiment)
+		}
+	}
+	if len(matrix.Experiments) != 1 {
+		t.Errorf("expected the payments experiment, got %v", matrix.Experiments)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Holds the ENVs whose value could not be parsed by envFloat & envDuration, which fall back to their default
+var (
+	invalidEnvsMu sync.Mutex
+	invalidEnvs   = make(map[string]string)
+)
+
+// recordInvalidEnv notes an ENV holding an invalid value, reported by the settings validation
+func recordInvalidEnv(key string, value string) {
+	invalidEnvsMu.Lock()
+	defer invalidEnvsMu.Unlock()
+	invalidEnvs[key] = value
+}
+
+// configProblems collects the invalid settings found at startup, so that all of them are reported at once
+// rather than one per restart
+type configProblems []string
+
+// add records a problem with a setting, named after its flag and/or ENV, e.g. --web.listen-address (WEB_LISTEN_ADDRESS)
+func (p *configProblems) add(setting string, format string, args ...interface{}) {
+	*p = append(*p, setting+": "+fmt.Sprintf(format, args...))
+}
+
+// addErr records err as a problem with a setting, if not nil
+func (p *configProblems) addErr(setting string, err error) {
+	if err != nil {
+		p.add(setting, "%v", err)
+	}
+}
+
+// addInvalidEnvs records the ENVs that could not be parsed
+func (p *configProblems) addInvalidEnvs() {
+	invalidEnvsMu.Lock()
+	defer invalidEnvsMu.Unlock()
+	keys := make([]string, 0, len(invalidEnvs))
+	for key := range invalidEnvs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		p.add(key, "invalid value %q", invalidEnvs[key])
+	}
+}
+
+// check logs every problem and exits if there is any
+func (p configProblems) check() {
+	if len(p) == 0 {
+		return
+	}
+	for _, problem := range p {
+		log.Error("invalid setting, ", problem)
+	}
+	log.Fatalf("ERROR: please fix the %d invalid setting(s) above", len(p))
+}
+
+// validateAddress checks a host:port or :port listen address
+func validateAddress(address string) error {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("expected host:port or :port, got %q", address)
+	}
+	if number, err := strconv.Atoi(port); err != nil || number < 0 || number > 65535 {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	return nil
+}
+
+// validateTelemetryPath checks the path the metrics are served under, which must not shadow the landing page
+func validateTelemetryPath(path string) error {
+	if !strings.HasPrefix(path, "/") || path == "/" {
+		return fmt.Errorf("expected a path starting with /, other than /, got %q", path)
+	}
+	return nil
+}
+
+// validateNamespaces checks the namespaces of a comma separated list are valid namespace names. An empty
+// list stands for all the namespaces
+func validateNamespaces(namespaces string) error {
+	var invalid []string
+	for _, ns := range splitNamespaces(namespaces) {
+		if ns != "" && len(validation.IsDNS1123Label(ns)) > 0 {
+			invalid = append(invalid, fmt.Sprintf("%q", ns))
+		}
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("invalid namespace name(s) %s", strings.Join(invalid, ", "))
+	}
+	return nil
+}
+
+// validateExporterSettings checks the namespace, engine & selector settings, once overlaid by the
+// ChaosExporterConfig CR, logging what is monitored
+func validateExporterSettings(defaults exporterSettings, problems *configProblems) {
+	if defaults.chaosEngine != "" && defaults.appUUID == "" && !autodetectAppUUID {
+		problems.add("--app-uuid (APP_UUID)", "required along with --chaosengine when --app-uuid-autodetect=false, set it to the UID of the application under test")
+	}
+	if _, err := labels.Parse(defaults.engineSelector); err != nil {
+		problems.add("--engine-selector (ENGINE_SELECTOR)", "%v", err)
+	}
+	problems.addErr("--watch-namespace (WATCH_NAMESPACE)", validateNamespaces(defaults.appNamespace))
+	if defaults.chaosEngine != "" && defaults.engineSelector != "" {
+		log.Warn("engine selector is ignored, as a single chaosengine is monitored via the CHAOSENGINE ENV")
+	}
+	namespaces := splitNamespaces(defaults.appNamespace)
+	if defaults.chaosEngine != "" && (len(namespaces) > 1 || namespaces[0] == "") {
+		problems.add("--chaosengine (CHAOSENGINE)", "requires a single namespace, it cannot be combined with a namespace list or a cluster-wide (empty) WATCH_NAMESPACE")
+	}
+	if namespaces[0] == "" {
+		log.Info("WATCH_NAMESPACE is empty, monitoring all chaosengines in the cluster")
+	} else if defaults.chaosEngine == "" {
+		log.Infof("CHAOSENGINE ENV not set, monitoring all chaosengines in namespace(s) %s", strings.Join(namespaces, ","))
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestConfigProblems(t *testing.T) {
+	defer func() { invalidEnvs = make(map[string]string) }()
+	os.Setenv("KUBE_API_QPS", "fast")
+	defer os.Unsetenv("KUBE_API_QPS")
+	if envFloat("KUBE_API_QPS", 5) != 5 {
+		t.Error("expected the fallback of an invalid ENV")
+	}
+
+	var problems configProblems
+	problems.addErr("--web.listen-address (WEB_LISTEN_ADDRESS)", validateAddress(":8080"))
+	problems.addErr("--web.listen-address (WEB_LISTEN_ADDRESS)", validateAddress("8080"))
+	problems.addErr("--debug.listen-address (DEBUG_LISTEN_ADDRESS)", validateAddress("localhost:70000"))
+	problems.addErr("APP_NAMESPACE (WATCH_NAMESPACE)", validateNamespaces("litmus, payments"))
+	problems.addErr("APP_NAMESPACE (WATCH_NAMESPACE)", validateNamespaces("litmus,Payments_NS"))
+	problems.addInvalidEnvs()
+
+	expected := configProblems{
+		`--web.listen-address (WEB_LISTEN_ADDRESS): expected host:port or :port, got "8080"`,
+		`--debug.listen-address (DEBUG_LISTEN_ADDRESS): invalid port "70000"`,
+		`APP_NAMESPACE (WATCH_NAMESPACE): invalid namespace name(s) "Payments_NS"`,
+		`KUBE_API_QPS: invalid value "fast"`,
+	}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("expected %q, got %q", expected, problems)
+	}
+}
+
+func TestValidateAddress(t *testing.T) {
+	for address, valid := range map[string]bool{
+		":8080":          true,
+		"127.0.0.1:9091": true,
+		"[::1]:9091":     true,
+		"localhost:0":    true,
+		"8080":           false,
+		"127.0.0.1":      false,
+		"localhost:http": false,
+		":65536":         false,
+	} {
+		if err := validateAddress(address); (err == nil) != valid {
+			t.Errorf("%s: expected valid %v, got %v", address, valid, err)
+		}
+	}
+}
+
+func TestValidateTelemetryPath(t *testing.T) {
+	for path, valid := range map[string]bool{
+		"/metrics":       true,
+		"/chaos/metrics": true,
+		"/":              false,
+		"metrics":        false,
+		"":               false,
+	} {
+		if err := validateTelemetryPath(path); (err == nil) != valid {
+			t.Errorf("%q: expected valid %v, got %v", path, valid, err)
+		}
+	}
+}
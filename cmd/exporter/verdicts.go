@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/chaosmetrics"
+	"github.com/litmuschaos/chaos-exporter/pkg/history"
+	"github.com/litmuschaos/chaos-exporter/pkg/sla"
+)
+
+// Holds the chaos windows of the applications under test, from which their resilience SLA is derived
+var slaTracker = sla.NewTracker()
+
+// Number of days of experiment verdicts retained for the heatmap
+var heatmapDays int
+
+// Holds the daily verdicts of the experiments, replaced once the retention is parsed
+var heatmap = history.NewHeatmap(30)
+
+// observedVerdict is the last observed state of an experiment, and the time it was first observed in it
+type observedVerdict struct {
+	verdict float64
+	since   time.Time
+}
+
+// engineVerdict returns the verdict of an engine, pass or fail once all its experiments have one, empty until then
+func engineVerdict(expMap map[string]float64) string {
+	verdict := "pass"
+	for _, status := range expMap {
+		switch chaosmetrics.StatusName(status) {
+		case "pass":
+		case "fail":
+			verdict = "fail"
+		default:
+			return ""
+		}
+	}
+	return verdict
+}
+
+// Holds the last observed state of each experiment, keyed by <namespace>/<engine>/<experiment>
+var lastVerdicts = make(map[string]observedVerdict)
+
+// recordTransitions counts the state changes of the experiments in expMap since the previous pass.
+// The first observation of an experiment is not counted, as its previous state is unknown. The events of the
+// transitions carry the steps of failSteps the experiments failed at, the last one the verdict of the engine when
+// they complete it
+func recordTransitions(appNS string, chaosEngine string, expMap map[string]float64, failSteps map[string]string) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+
+	var events []verdictEvent
+	for exp, verdict := range expMap {
+		key := appNS + "/" + chaosEngine + "/" + exp
+		last, ok := lastVerdicts[key]
+		if ok && last.verdict == verdict {
+			continue
+		}
+		if ok {
+			engineLogger(appNS, chaosEngine).WithField("experiment", exp).Infof("verdict changed from %s to %s", chaosmetrics.StatusName(last.verdict), chaosmetrics.StatusName(verdict))
+			events = append(events, verdictEvent{
+				Namespace:  appNS,
+				Engine:     chaosEngine,
+				Experiment: exp,
+				From:       chaosmetrics.StatusName(last.verdict),
+				To:         chaosmetrics.StatusName(verdict),
+				Time:       exporterClock.Now(),
+				Since:      last.since,
+				FailStep:   failSteps[exp],
+			})
+			verdictTransitions.WithLabelValues(labelValues(appNS, chaosEngine, chaosmetrics.StatusName(last.verdict), chaosmetrics.StatusName(verdict))...).Inc()
+			if status := chaosmetrics.StatusName(verdict); status == "pass" || status == "fail" {
+				heatmap.Record(appNS, labelNormalization.normalize(exp), status == "pass")
+			}
+			if chaosmetrics.StatusName(verdict) == "fail" {
+				engineFailures[appNS+"/"+chaosEngine] = append(engineFailures[appNS+"/"+chaosEngine], exporterClock.Now())
+			}
+		}
+		lastVerdicts[key] = observedVerdict{verdict: verdict, since: exporterClock.Now()}
+	}
+	if len(events) > 0 {
+		events[len(events)-1].EngineVerdict = engineVerdict(expMap)
+	}
+	for _, event := range events {
+		verdictEvents.publish(event)
+	}
+	// Forget the experiments removed from the engine
+	prefix := appNS + "/" + chaosEngine + "/"
+	for key := range lastVerdicts {
+		if strings.HasPrefix(key, prefix) {
+			if _, ok := expMap[strings.TrimPrefix(key, prefix)]; !ok {
+				delete(lastVerdicts, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// TestRecordTransitions verifies that only state changes are counted
+func TestRecordTransitions(t *testing.T) {
+	failedBefore := heatmapFailures("litmus", "pod-delete")
+	recordTransitions("litmus", "engine-test", map[string]float64{"pod-delete": 1}, nil)
+	recordTransitions("litmus", "engine-test", map[string]float64{"pod-delete": 1}, nil)
+	recordTransitions("litmus", "engine-test", map[string]float64{"pod-delete": 2}, nil)
+
+	metric := &dto.Metric{}
+	if err := verdictTransitions.WithLabelValues("litmus", "engine-test", "running", "fail").Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	if got := metric.GetCounter().GetValue(); got != 1 {
+		t.Errorf("expected 1 running->fail transition, got %v", got)
+	}
+	// The fail verdict is counted in the heatmap
+	if failed := heatmapFailures("litmus", "pod-delete") - failedBefore; failed != 1 {
+		t.Errorf("expected a failed pod-delete verdict in the heatmap, got %d", failed)
+	}
+}
+
+// heatmapFailures returns the failed verdicts of an experiment recorded in the heatmap today, by the tests run so far
+func heatmapFailures(namespace string, experiment string) int {
+	for _, row := range heatmap.Matrix(1).Experiments {
+		if row.Namespace == namespace && row.Experiment == experiment {
+			return row.Cells[0].Failed
+		}
+	}
+	return 0
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/ghodss/yaml"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// webConfig is the layout of the web config file shared by the Prometheus exporters (--web.config.file): its
+// TLS & HTTP server settings, and its basic auth users, required on every path
+type webConfig struct {
+	TLSServerConfig *struct {
+		CertFile       string `json:"cert_file"`
+		KeyFile        string `json:"key_file"`
+		ClientCAFile   string `json:"client_ca_file"`
+		ClientAuthType string `json:"client_auth_type"`
+		MinVersion     string `json:"min_version"`
+		MaxVersion     string `json:"max_version"`
+	} `json:"tls_server_config"`
+	HTTPServerConfig struct {
+		// Set to false to only serve HTTP/1.1, HTTP/2 is negotiated over TLS otherwise
+		HTTP2 *bool `json:"http2"`
+		// Set on every response, for e.g. Strict-Transport-Security
+		Headers map[string]string `json:"headers"`
+	} `json:"http_server_config"`
+	// bcrypt hashes of the passwords of the users, by username
+	BasicAuthUsers map[string]string `json:"basic_auth_users"`
+}
+
+// Client authentication types of the web config file
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"NoClientCert":               tls.NoClientCert,
+	"RequestClientCert":          tls.RequestClientCert,
+	"RequireAnyClientCert":       tls.RequireAnyClientCert,
+	"VerifyClientCertIfGiven":    tls.VerifyClientCertIfGiven,
+	"RequireAndVerifyClientCert": tls.RequireAndVerifyClientCert,
+}
+
+// TLS versions of the web config file
+var tlsVersions = map[string]uint16{
+	"TLS10": tls.VersionTLS10,
+	"TLS11": tls.VersionTLS11,
+	"TLS12": tls.VersionTLS12,
+	"TLS13": tls.VersionTLS13,
+}
+
+// loadWebConfig reads the web config file at path
+func loadWebConfig(path string) (*webConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config webConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+	for username, hash := range config.BasicAuthUsers {
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return nil, fmt.Errorf("invalid bcrypt hash of basic_auth_users %s: %v", username, err)
+		}
+	}
+	return &config, nil
+}
+
+// tlsConfig returns the TLS config of the HTTP server, nil to serve plain HTTP
+func (c *webConfig) tlsConfig() (*tls.Config, error) {
+	if c.TLSServerConfig == nil {
+		return nil, nil
+	}
+	settings := c.TLSServerConfig
+	config, err := serverTLSConfig(settings.CertFile, settings.KeyFile, settings.ClientCAFile)
+	if err != nil || config == nil {
+		return config, err
+	}
+	if settings.ClientAuthType != "" {
+		authType, ok := clientAuthTypes[settings.ClientAuthType]
+		if !ok {
+			return nil, fmt.Errorf("invalid client_auth_type %q", settings.ClientAuthType)
+		}
+		if authType >= tls.VerifyClientCertIfGiven && config.ClientCAs == nil {
+			return nil, fmt.Errorf("client_auth_type %s requires client_ca_file", settings.ClientAuthType)
+		}
+		config.ClientAuth = authType
+	}
+	if config.MinVersion, err = tlsVersion(settings.MinVersion, config.MinVersion); err != nil {
+		return nil, err
+	}
+	if config.MaxVersion, err = tlsVersion(settings.MaxVersion, config.MaxVersion); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// tlsVersion parses a TLS version of the file, fallback if unset
+func tlsVersion(setting string, fallback uint16) (uint16, error) {
+	if setting == "" {
+		return fallback, nil
+	}
+	version, ok := tlsVersions[setting]
+	if !ok {
+		return 0, fmt.Errorf("invalid TLS version %q", setting)
+	}
+	return version, nil
+}
+
+// apply sets the HTTP server settings of the file on server, and wraps its handler to set the headers
+func (c *webConfig) apply(server *http.Server) {
+	if c.HTTPServerConfig.HTTP2 != nil && !*c.HTTPServerConfig.HTTP2 {
+		// A non-nil map disables the automatic HTTP/2 support
+		server.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	}
+	if headers := c.HTTPServerConfig.Headers; len(headers) > 0 {
+		handler := server.Handler
+		server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for name, value := range headers {
+				w.Header().Set(name, value)
+			}
+			handler.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestWebConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chaos-exporter-web-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ca, caKey := writeCertificate(t, dir, "ca", nil, nil)
+	writeCertificate(t, dir, "server", ca, caKey)
+	load := func(content string) (*webConfig, error) {
+		path := filepath.Join(dir, "web-config.yml")
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return loadWebConfig(path)
+	}
+
+	if _, err := load("basic_auth_users:\n  prometheus: $2y$10$abc\n"); err == nil {
+		t.Error("expected an invalid bcrypt hash to be rejected")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if users, err := load(fmt.Sprintf("basic_auth_users:\n  prometheus: %s\n", hash)); err != nil || users.BasicAuthUsers["prometheus"] != string(hash) {
+		t.Errorf("expected the bcrypt basic auth users to be read, got %v, %v", users, err)
+	}
+	config, err := load(fmt.Sprintf(`tls_server_config:
+  cert_file: %[1]s/server.crt
+  key_file: %[1]s/server.key
+  client_ca_file: %[1]s/ca.crt
+  client_auth_type: VerifyClientCertIfGiven
+  min_version: TLS13
+http_server_config:
+  http2: false
+  headers:
+    Strict-Transport-Security: max-age=31536000
+`, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig.ClientAuth != tls.VerifyClientCertIfGiven || tlsConfig.MinVersion != tls.VersionTLS13 {
+		t.Errorf("unexpected TLS config %v %v", tlsConfig.ClientAuth, tlsConfig.MinVersion)
+	}
+
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})}
+	config.apply(server)
+	if server.TLSNextProto == nil {
+		t.Error("expected HTTP/2 to be disabled")
+	}
+	w := httptest.NewRecorder()
+	server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Header().Get("Strict-Transport-Security") != "max-age=31536000" {
+		t.Errorf("expected the configured headers, got %v", w.Header())
+	}
+
+	config.TLSServerConfig.ClientAuthType = "RequireAnyClientCert"
+	config.TLSServerConfig.MinVersion = "SSL3"
+	if _, err := config.tlsConfig(); err == nil {
+		t.Error("expected an invalid TLS version to be rejected")
+	}
+}
@@ -0,0 +1,14 @@
+package apis
+
+import (
+	"github.com/litmuschaos/chaos-exporter/pkg/apis/litmuschaos/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// AddToSchemes may be used to add all resources defined by the exporter to a Scheme
+var AddToSchemes = runtime.SchemeBuilder{v1alpha1.AddToScheme}
+
+// AddToScheme adds all exporter Resources to the Scheme
+func AddToScheme(s *runtime.Scheme) error {
+	return AddToSchemes.AddToScheme(s)
+}
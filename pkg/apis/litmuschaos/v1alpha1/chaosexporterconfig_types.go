@@ -0,0 +1,62 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ChaosExporterConfigSpec defines the settings the exporter picks up at runtime
+// +k8s:openapi-gen=true
+// Values left empty fall back to the ENVs the exporter was started with
+type ChaosExporterConfigSpec struct {
+	//Name of the chaosengine to be monitored
+	ChaosEngine string `json:"chaosEngine,omitempty"`
+	//Namespace of the chaosengine
+	AppNamespace string `json:"appNamespace,omitempty"`
+	//UID of the application under test, exported as the app_uid label
+	AppUUID string `json:"appUUID,omitempty"`
+	//Label selector of the chaosengines to monitor, when chaosEngine is not set
+	EngineSelector string `json:"engineSelector,omitempty"`
+	//Labels of the chaosengines exported by litmuschaos_engine_labels, as label_<name>
+	EngineLabels []string `json:"engineLabels,omitempty"`
+	//Webhooks the experiment verdict changes are posted to
+	NotificationTargets []NotificationTarget `json:"notificationTargets,omitempty"`
+}
+
+// NotificationTarget is a webhook the experiment verdict changes are posted to, as JSON, or a Slack or Microsoft
+// Teams incoming webhook the experiment failures & engine completions are posted to, as messages
+// +k8s:openapi-gen=true
+type NotificationTarget struct {
+	//Name of the target, exported as the target label
+	Name string `json:"name"`
+	//http(s) URL the verdict changes are posted to
+	URL string `json:"url,omitempty"`
+	//Credential reference (file:, env: or vault:) of the URL, in place of url for the webhooks whose URL holds
+	//a secret, e.g. Slack
+	URLFrom string `json:"urlFrom,omitempty"`
+	//Format of the notifications, json (default), slack or teams
+	Format string `json:"format,omitempty"`
+	//Severities routed to the target, critical, warning or info, all when empty
+	Severities []string `json:"severities,omitempty"`
+	//URL linked from the messages, where {namespace}, {engine} & {experiment} are replaced by those notified
+	Link string `json:"link,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ChaosExporterConfig is the Schema for the chaosexporterconfigs API
+// +k8s:openapi-gen=true
+type ChaosExporterConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ChaosExporterConfigSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ChaosExporterConfigList contains a list of ChaosExporterConfig
+type ChaosExporterConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ChaosExporterConfig `json:"items"`
+}
@@ -0,0 +1,75 @@
+package v1alpha1
+
+import (
+	"strings"
+
+	chaosv1alpha1 "github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ChaosResultStatus holds the status reported in the chaosresult by newer operators
+type ChaosResultStatus struct {
+	// Replaces spec.experimentstatus, with capitalized verdicts (Pass, Fail, Awaited)
+	ExperimentStatus ExperimentStatus `json:"experimentStatus,omitempty"`
+	// Outcome of the steady-state probes of the experiment
+	ProbeStatus []ProbeStatus `json:"probeStatus,omitempty"`
+}
+
+// ExperimentStatus is the status of the experiment reported by newer operators
+type ExperimentStatus struct {
+	chaosv1alpha1.TestStatus `json:",inline"`
+	// Step the experiment failed at, e.g. "Unable to get the application pods", empty unless it failed
+	FailStep string `json:"failStep,omitempty"`
+}
+
+// ProbeStatus holds the outcome of a single probe
+type ProbeStatus struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Either {"verdict": "Passed"} or the per-phase results, e.g. {"PreChaos": "Passed 👍"}
+	Status map[string]string `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ChaosResult is the chaosresult as served by newer operators, the vendored operator types do not model
+// its status. It is not registered in the scheme (the kind is registered for the operator type), the
+// clientset decodes it as plain JSON
+type ChaosResult struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   chaosv1alpha1.ChaosResultSpec `json:"spec,omitempty"`
+	Status ChaosResultStatus             `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ChaosResultList contains a list of ChaosResult
+type ChaosResultList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ChaosResult `json:"items"`
+}
+
+// Verdict returns the result of the experiment as pass, fail or running, reading the status reported by
+// newer operators if the spec carries none. Other verdicts are returned lowercased
+func (r *ChaosResult) Verdict() string {
+	status := r.Spec.ExperimentStatus
+	if status.Verdict == "" && status.Phase == "" {
+		status = r.Status.ExperimentStatus.TestStatus
+	}
+	verdict := strings.ToLower(status.Verdict)
+	if verdict != "pass" && verdict != "fail" && strings.EqualFold(status.Phase, "running") {
+		return "running"
+	}
+	return verdict
+}
+
+// FailStep returns the step the experiment failed at, as reported by newer operators, empty if unknown
+func (r *ChaosResult) FailStep() string {
+	if r.Verdict() != "fail" {
+		return ""
+	}
+	return r.Status.ExperimentStatus.FailStep
+}
@@ -0,0 +1,34 @@
+// Package v1alpha1 contains API Schema definitions owned by the chaos exporter
+// in the litmuschaos v1alpha1 API group
+// +k8s:deepcopy-gen=package,register
+// +groupName=litmuschaos.io
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GroupName is the name of api group for the custom resources
+const GroupName = "litmuschaos.io"
+
+// GroupVersion is the version of api group for the custom resources
+const GroupVersion = "v1alpha1"
+
+var (
+	// SchemeGroupVersion is group version used to register these objects
+	SchemeGroupVersion = schema.GroupVersion{Group: GroupName, Version: GroupVersion}
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
+	SchemeBuilder = runtime.NewSchemeBuilder(addKnownTypes)
+
+	// AddToScheme adds the exporter types to a scheme
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(SchemeGroupVersion, &ChaosExporterConfig{}, &ChaosExporterConfigList{})
+	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
+	return nil
+}
@@ -0,0 +1,91 @@
+// Package cache holds in-memory copies of chaos resources keyed by <namespace>/<name>, kept up to date
+// by an informer and read through the listers
+package cache
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// Store is a thread-safe store of objects. Stored objects are shared with the readers and must not be modified
+type Store struct {
+	mu    sync.RWMutex
+	items map[string]interface{}
+}
+
+// NewStore returns an empty Store
+func NewStore() *Store {
+	return &Store{items: make(map[string]interface{})}
+}
+
+// MetaNamespaceKey returns the <namespace>/<name> key of an object, or <name> for cluster scoped objects
+func MetaNamespaceKey(obj interface{}) (string, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", err
+	}
+	if accessor.GetNamespace() == "" {
+		return accessor.GetName(), nil
+	}
+	return accessor.GetNamespace() + "/" + accessor.GetName(), nil
+}
+
+// Add adds or replaces an object
+func (s *Store) Add(obj interface{}) error {
+	key, err := MetaNamespaceKey(obj)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[key] = obj
+	return nil
+}
+
+// Delete removes an object
+func (s *Store) Delete(obj interface{}) error {
+	key, err := MetaNamespaceKey(obj)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+	return nil
+}
+
+// Replace replaces the content of the store with objs, for e.g. with the result of a relist
+func (s *Store) Replace(objs []interface{}) error {
+	items := make(map[string]interface{}, len(objs))
+	for _, obj := range objs {
+		key, err := MetaNamespaceKey(obj)
+		if err != nil {
+			return err
+		}
+		items[key] = obj
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = items
+	return nil
+}
+
+// GetByKey returns the object stored under key
+func (s *Store) GetByKey(key string) (interface{}, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	obj, ok := s.items[key]
+	return obj, ok
+}
+
+// List returns all the stored objects
+func (s *Store) List() []interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	objs := make([]interface{}, 0, len(s.items))
+	for _, obj := range s.items {
+		objs = append(objs, obj)
+	}
+	return objs
+}
@@ -0,0 +1,53 @@
+package chaosmetrics
+
+import (
+	"fmt"
+	"strings"
+
+	chaosV1alpha1 "github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+)
+
+// Versions of the litmuschaos.io API the exporter decodes into its model, most preferred first
+var supportedAPIVersions = []string{chaosV1alpha1.SchemeGroupVersion.Version}
+
+// DetectAPIVersion returns the version of the litmuschaos.io API to read the chaos resources with, among the
+// versions served by the apiserver. Clusters mid-upgrade serve the CRDs in several versions, converting
+// between them, so any served version the exporter can decode yields consistent metrics
+func DetectAPIVersion(cfg *rest.Config) (string, []string, error) {
+	client, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return "", nil, err
+	}
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return "", nil, err
+	}
+	return selectAPIVersion(groups)
+}
+
+// selectAPIVersion returns the most preferred supported version of the litmuschaos.io group, and the
+// versions it is served in
+func selectAPIVersion(groups *metav1.APIGroupList) (string, []string, error) {
+	for _, group := range groups.Groups {
+		if group.Name != chaosV1alpha1.SchemeGroupVersion.Group {
+			continue
+		}
+		var served []string
+		for _, version := range group.Versions {
+			served = append(served, version.Version)
+		}
+		for _, supported := range supportedAPIVersions {
+			for _, version := range served {
+				if version == supported {
+					return version, served, nil
+				}
+			}
+		}
+		return "", served, fmt.Errorf("%s is served in %s, none of which is supported (%s)", group.Name,
+			strings.Join(served, ", "), strings.Join(supportedAPIVersions, ", "))
+	}
+	return "", nil, fmt.Errorf("%s is not served, are the litmus CRDs installed?", chaosV1alpha1.SchemeGroupVersion.Group)
+}
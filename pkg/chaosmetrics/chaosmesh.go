@@ -0,0 +1,208 @@
+package chaosmetrics
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+// ChaosMeshProviderName is the name of the Chaos Mesh provider
+const ChaosMeshProviderName = "chaosmesh"
+
+// Holds the Chaos Mesh API group & version of the experiment resources
+var chaosMeshGroupVersion = schema.GroupVersion{Group: "chaos-mesh.org", Version: "v1alpha1"}
+
+// Holds the Chaos Mesh experiment resources, those whose CRD is not installed are skipped
+var chaosMeshResources = []string{"podchaos", "networkchaos", "iochaos", "stresschaos", "timechaos", "kernelchaos", "dnschaos", "httpchaos"}
+
+// chaosMeshExperiment holds the fields of a Chaos Mesh experiment resource read by the exporter,
+// which are common to all its kinds
+type chaosMeshExperiment struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              struct {
+		Action   string `json:"action"`
+		Selector struct {
+			Namespaces     []string          `json:"namespaces"`
+			LabelSelectors map[string]string `json:"labelSelectors"`
+		} `json:"selector"`
+	} `json:"spec"`
+	Status struct {
+		Conditions []struct {
+			Type   string `json:"type"`
+			Status string `json:"status"`
+		} `json:"conditions"`
+		Experiment struct {
+			DesiredPhase string `json:"desiredPhase"`
+		} `json:"experiment"`
+	} `json:"status"`
+}
+
+type chaosMeshExperimentList struct {
+	Items []chaosMeshExperiment `json:"items"`
+}
+
+// ChaosMeshProvider collects the Chaos Mesh experiment resources, each as an engine running a single experiment
+// named <resource>-<action>, e.g. podchaos-pod-kill
+type ChaosMeshProvider struct {
+	mu sync.Mutex
+	// Holds the resource of each listed experiment, keyed by <namespace>/<name>
+	resources map[string]string
+}
+
+// NewChaosMeshProvider returns a ChaosMeshProvider
+func NewChaosMeshProvider() *ChaosMeshProvider {
+	return &ChaosMeshProvider{resources: make(map[string]string)}
+}
+
+// Name returns chaosmesh
+func (p *ChaosMeshProvider) Name() string {
+	return ChaosMeshProviderName
+}
+
+// ListEngines returns the experiments matching the selector, across the installed experiment resources
+func (p *ChaosMeshProvider) ListEngines(ctx context.Context, cfg *rest.Config, ns string, selector string) ([]types.NamespacedName, error) {
+	client, err := chaosMeshClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var engines []types.NamespacedName
+	for _, resource := range chaosMeshResources {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		request := client.Get().Namespace(ns).Resource(resource)
+		if selector != "" {
+			request = request.Param("labelSelector", selector)
+		}
+		raw, err := request.DoRaw()
+		if k8serrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var list chaosMeshExperimentList
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return nil, err
+		}
+
+		p.mu.Lock()
+		for _, experiment := range list.Items {
+			engines = append(engines, types.NamespacedName{Namespace: experiment.Namespace, Name: experiment.Name})
+			p.resources[experiment.Namespace+"/"+experiment.Name] = resource
+		}
+		p.mu.Unlock()
+	}
+	return engines, nil
+}
+
+// GetEngineMetrics returns the metrics of an experiment. Chaos Mesh does not judge experiments, an experiment
+// whose chaos was injected then fully recovered is reported as passed
+func (p *ChaosMeshProvider) GetEngineMetrics(ctx context.Context, cfg *rest.Config, name string, ns string) (*EngineMetrics, error) {
+	client, err := chaosMeshClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Experiments not listed yet (for e.g. set as CHAOSENGINE) are looked up in every resource
+	p.mu.Lock()
+	resource, ok := p.resources[ns+"/"+name]
+	p.mu.Unlock()
+	resources := chaosMeshResources
+	if ok {
+		resources = []string{resource}
+	}
+
+	for _, resource := range resources {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		raw, err := client.Get().Namespace(ns).Resource(resource).Name(name).DoRaw()
+		if k8serrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var experiment chaosMeshExperiment
+		if err := json.Unmarshal(raw, &experiment); err != nil {
+			return nil, err
+		}
+		return chaosMeshMetrics(resource, &experiment), nil
+	}
+
+	p.mu.Lock()
+	delete(p.resources, ns+"/"+name)
+	p.mu.Unlock()
+	return nil, k8serrors.NewNotFound(chaosMeshGroupVersion.WithResource(strings.Join(resources, ",")).GroupResource(), name)
+}
+
+// chaosMeshMetrics maps a Chaos Mesh experiment to the metrics of an engine running a single experiment
+func chaosMeshMetrics(resource string, experiment *chaosMeshExperiment) *EngineMetrics {
+	name := resource
+	if experiment.Spec.Action != "" {
+		name += "-" + experiment.Spec.Action
+	}
+
+	metrics := &EngineMetrics{
+		AppNamespace:     experiment.Namespace,
+		TotalExperiments: 1,
+		ExperimentStatus: map[string]float64{},
+		Owners:           experiment.OwnerReferences,
+		Annotations:      experiment.Annotations,
+		Labels:           experiment.Labels,
+	}
+	if len(experiment.Spec.Selector.Namespaces) > 0 {
+		metrics.AppNamespace = experiment.Spec.Selector.Namespaces[0]
+	}
+	var selectors []string
+	for key, value := range experiment.Spec.Selector.LabelSelectors {
+		selectors = append(selectors, key+"="+value)
+	}
+	sort.Strings(selectors)
+	metrics.AppLabel = strings.Join(selectors, ",")
+
+	verdict := chaosMeshVerdict(experiment)
+	if verdict == "pass" {
+		metrics.PassedExperiments = 1
+	}
+	metrics.ExperimentStatus[name] = statusConv(verdict)
+	return metrics
+}
+
+// chaosMeshVerdict derives the state of an experiment from its conditions: running while its chaos is
+// injected, pass once it is fully recovered, not-executed otherwise
+func chaosMeshVerdict(experiment *chaosMeshExperiment) string {
+	conditions := make(map[string]bool)
+	for _, condition := range experiment.Status.Conditions {
+		conditions[condition.Type] = condition.Status == "True"
+	}
+	switch {
+	case conditions["AllRecovered"] && !conditions["AllInjected"]:
+		return "pass"
+	case conditions["AllInjected"] && experiment.Status.Experiment.DesiredPhase == "Run":
+		return "running"
+	}
+	return "not-executed"
+}
+
+// chaosMeshClient returns a rest client of the Chaos Mesh API group, whose responses are decoded as plain JSON
+func chaosMeshClient(cfg *rest.Config) (*rest.RESTClient, error) {
+	config := *cfg
+	config.ContentConfig.GroupVersion = &chaosMeshGroupVersion
+	config.APIPath = "/apis"
+	config.NegotiatedSerializer = serializer.DirectCodecFactory{CodecFactory: scheme.Codecs}
+	config.UserAgent = rest.DefaultKubernetesUserAgent()
+	return rest.RESTClientFor(&config)
+}
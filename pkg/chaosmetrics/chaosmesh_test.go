@@ -0,0 +1,74 @@
+package chaosmetrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+)
+
+const podChaos = `{
+	"metadata": {"name": "nginx-kill", "namespace": "litmus"},
+	"spec": {"action": "pod-kill", "selector": {"namespaces": ["default"], "labelSelectors": {"app": "nginx"}}},
+	"status": {"conditions": [{"type": "AllInjected", "status": "False"}, {"type": "AllRecovered", "status": "True"}]}
+}`
+
+func TestChaosMeshProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/apis/chaos-mesh.org/v1alpha1/namespaces/litmus/podchaos":
+			w.Write([]byte(`{"items": [` + podChaos + `]}`))
+		case "/apis/chaos-mesh.org/v1alpha1/namespaces/litmus/podchaos/nginx-kill":
+			w.Write([]byte(podChaos))
+		default:
+			// The other experiment CRDs are not installed
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"kind": "Status", "apiVersion": "v1", "status": "Failure", "reason": "NotFound", "code": 404}`))
+		}
+	}))
+	defer server.Close()
+	cfg := &rest.Config{Host: server.URL}
+
+	provider := NewChaosMeshProvider()
+	engines, err := provider.ListEngines(context.Background(), cfg, "litmus", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(engines) != 1 || engines[0].Name != "nginx-kill" {
+		t.Fatalf("expected the nginx-kill experiment, got %v", engines)
+	}
+
+	metrics, err := provider.GetEngineMetrics(context.Background(), cfg, "nginx-kill", "litmus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metrics.AppNamespace != "default" || metrics.AppLabel != "app=nginx" || metrics.PassedExperiments != 1 {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+	if status, ok := metrics.ExperimentStatus["podchaos-pod-kill"]; !ok || StatusName(status) != "pass" {
+		t.Errorf("expected a passed podchaos-pod-kill experiment, got %v", metrics.ExperimentStatus)
+	}
+
+	if _, err := provider.GetEngineMetrics(context.Background(), cfg, "deleted", "litmus"); !k8serrors.IsNotFound(err) {
+		t.Errorf("expected a NotFound error for a deleted experiment, got %v", err)
+	}
+}
+
+func TestChaosMeshVerdict(t *testing.T) {
+	experiment := &chaosMeshExperiment{}
+	if got := chaosMeshVerdict(experiment); got != "not-executed" {
+		t.Errorf("expected an experiment without conditions not to be executed, got %s", got)
+	}
+	experiment.Status.Experiment.DesiredPhase = "Run"
+	experiment.Status.Conditions = append(experiment.Status.Conditions, struct {
+		Type   string `json:"type"`
+		Status string `json:"status"`
+	}{Type: "AllInjected", Status: "True"})
+	if got := chaosMeshVerdict(experiment); got != "running" {
+		t.Errorf("expected an injected experiment to be running, got %s", got)
+	}
+}
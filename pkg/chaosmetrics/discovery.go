@@ -0,0 +1,90 @@
+package chaosmetrics
+
+import (
+	"context"
+
+	clientV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/clientset/v1alpha1"
+	chaosV1alpha1 "github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// ChaosAnnotation marks the deployments chaos may be injected into, as set for the Litmus operator
+const ChaosAnnotation = "litmuschaos.io/chaos"
+
+// AnnotatedLitmusProvider collects the chaosengines of Litmus targeting a deployment annotated with
+// litmuschaos.io/chaos=true, rather than every chaosengine of the namespace
+type AnnotatedLitmusProvider struct {
+	LitmusProvider
+}
+
+// ListEngines returns the chaosengines matching the selector that target an annotated deployment
+func (AnnotatedLitmusProvider) ListEngines(ctx context.Context, cfg *rest.Config, ns string, selector string) ([]types.NamespacedName, error) {
+	clientSet, kubeClient, err := litmusClients.forConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return DiscoverChaosEngines(ctx, clientSet, kubeClient, ns, selector)
+}
+
+// DiscoverChaosEngines returns the chaosengines matching the label selector in a namespace (or in the cluster
+// if ns is empty) whose appinfo targets a deployment annotated with litmuschaos.io/chaos=true
+func DiscoverChaosEngines(ctx context.Context, clientSet *clientV1alpha1.ExampleV1Alpha1Client, kubeClient kubernetes.Interface, ns string, selector string) ([]types.NamespacedName, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	engineList, err := clientSet.ChaosEngines(ns).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	// List the deployments of every application namespace once
+	deployments := make(map[string][]appsv1.Deployment)
+	for _, engine := range engineList.Items {
+		appNS := engineAppNamespace(engine)
+		if _, ok := deployments[appNS]; ok {
+			continue
+		}
+		list, err := kubeClient.AppsV1().Deployments(appNS).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		deployments[appNS] = list.Items
+	}
+	return annotatedEngines(engineList.Items, deployments), nil
+}
+
+// engineAppNamespace returns the namespace of the application under test of an engine, its own if unset
+func engineAppNamespace(engine chaosV1alpha1.ChaosEngine) string {
+	if engine.Spec.Appinfo.Appns != "" {
+		return engine.Spec.Appinfo.Appns
+	}
+	return engine.Namespace
+}
+
+// annotatedEngines returns the engines whose applabel selects an annotated deployment of their application
+// namespace, by its labels or those of its pods. deployments are keyed by namespace
+func annotatedEngines(engines []chaosV1alpha1.ChaosEngine, deployments map[string][]appsv1.Deployment) []types.NamespacedName {
+	var discovered []types.NamespacedName
+	for _, engine := range engines {
+		selector, err := labels.Parse(engine.Spec.Appinfo.Applabel)
+		if err != nil || selector.Empty() {
+			continue
+		}
+		for _, deployment := range deployments[engineAppNamespace(engine)] {
+			if deployment.Annotations[ChaosAnnotation] != "true" {
+				continue
+			}
+			if selector.Matches(labels.Set(deployment.Labels)) || selector.Matches(labels.Set(deployment.Spec.Template.Labels)) {
+				discovered = append(discovered, types.NamespacedName{Namespace: engine.Namespace, Name: engine.Name})
+				break
+			}
+		}
+	}
+	return discovered
+}
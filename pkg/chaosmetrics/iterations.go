@@ -0,0 +1,142 @@
+package chaosmetrics
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	chaosV1alpha1 "github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Reason of the events raised on the chaosengine by experiments for every chaos injection
+const chaosInjectReason = "ChaosInject"
+
+// IterationStatus holds the expected & observed number of chaos injections of an experiment
+// configured with a CHAOS_INTERVAL
+type IterationStatus struct {
+	Experiment string
+	Expected   float64
+	Actual     float64
+}
+
+// expectedIterations derives the number of injections from the TOTAL_CHAOS_DURATION & CHAOS_INTERVAL
+// (in seconds) of the experiment. The experiment is not iterative when no interval is set
+func expectedIterations(env []chaosV1alpha1.ENVPair) (float64, bool) {
+	var duration, interval float64
+	for _, pair := range env {
+		value, err := strconv.ParseFloat(pair.Value, 64)
+		if err != nil {
+			continue
+		}
+		switch pair.Name {
+		case "TOTAL_CHAOS_DURATION":
+			duration = value
+		case "CHAOS_INTERVAL":
+			interval = value
+		}
+	}
+	if interval <= 0 || duration <= 0 {
+		return 0, false
+	}
+	// The experiment injects chaos at the start of every interval until the duration has elapsed
+	return math.Ceil(duration / interval), true
+}
+
+// getIterationStatus returns the iteration counts of the experiments of a chaosengine that carry a
+// CHAOS_INTERVAL. Injections are counted from the ChaosInject events raised on the engine by the current run
+// of every experiment
+func getIterationStatus(kubeClient kubernetes.Interface, engine *chaosV1alpha1.ChaosEngine, experiments map[string]*chaosV1alpha1.ChaosExperiment) ([]IterationStatus, error) {
+	expected := make(map[string]float64)
+	for name, experiment := range experiments {
+		if count, ok := expectedIterations(experiment.Spec.Definition.ENVList); ok {
+			expected[name] = count
+		}
+	}
+	if len(expected) == 0 {
+		return nil, nil
+	}
+
+	events, err := kubeClient.CoreV1().Events(engine.Namespace).List(metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=ChaosEngine,involvedObject.name=" + engine.Name + ",reason=" + chaosInjectReason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(engine.Spec.Experiments))
+	for _, element := range engine.Spec.Experiments {
+		names = append(names, element.Name)
+	}
+	var iterations []IterationStatus
+	for experiment, count := range expected {
+		status := IterationStatus{Experiment: experiment, Expected: count}
+		for _, event := range currentRunEvents(events.Items, engine, experiment, names) {
+			// Repeated events are aggregated by the event recorder into a single event w/ a count
+			if event.Count > 0 {
+				status.Actual += float64(event.Count)
+			} else {
+				status.Actual++
+			}
+		}
+		iterations = append(iterations, status)
+	}
+	return iterations, nil
+}
+
+// eventExperiment returns the experiment, among those of the engine, that raised event: the one the experiment
+// pod it is sourced from is named after (the longest matching name, so that pod-delete-extended-<id> is not
+// taken for pod-delete), or else the one named by a word of its message
+func eventExperiment(event corev1.Event, experiments []string) string {
+	owner := ""
+	for _, experiment := range experiments {
+		if component := event.Source.Component; component != "" {
+			if strings.HasPrefix(component, experiment+"-") && len(experiment) > len(owner) {
+				owner = experiment
+			}
+			continue
+		}
+		for _, word := range strings.FieldsFunc(event.Message, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+		}) {
+			if word == experiment {
+				return experiment
+			}
+		}
+	}
+	return owner
+}
+
+// currentRunEvents returns the events raised by experiment in its current run: those of its most recent pod,
+// but none raised before its run in progress started, if running
+func currentRunEvents(events []corev1.Event, engine *chaosV1alpha1.ChaosEngine, experiment string, experiments []string) []corev1.Event {
+	var started time.Time
+	for _, status := range engine.Status.Experiments {
+		if status.Name == experiment && strings.EqualFold(status.Status, "running") {
+			started = status.LastUpdateTime.Time
+		}
+	}
+
+	var matched []corev1.Event
+	latestPod, latestStart := "", time.Time{}
+	for _, event := range events {
+		if eventExperiment(event, experiments) != experiment || event.LastTimestamp.Time.Before(started) {
+			continue
+		}
+		matched = append(matched, event)
+		if event.FirstTimestamp.Time.After(latestStart) {
+			latestPod, latestStart = event.Source.Component, event.FirstTimestamp.Time
+		}
+	}
+	var current []corev1.Event
+	for _, event := range matched {
+		if event.Source.Component == latestPod {
+			current = append(current, event)
+		}
+	}
+	return current
+}
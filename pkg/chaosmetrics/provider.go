@@ -0,0 +1,95 @@
+package chaosmetrics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	clientV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/clientset/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// ChaosProvider collects the metrics of a chaos tool into EngineMetrics, so that the experiments of every
+// tool are exported with the same metric schema. An engine is the unit of chaos of the tool, for e.g. a
+// chaosengine for Litmus or an experiment resource (PodChaos, NetworkChaos, ...) for Chaos Mesh
+type ChaosProvider interface {
+	// Name identifies the provider, as selected by CHAOS_PROVIDERS
+	Name() string
+	// ListEngines returns the engines matching the label selector in a namespace, or in the cluster if ns is empty
+	ListEngines(ctx context.Context, cfg *rest.Config, ns string, selector string) ([]types.NamespacedName, error)
+	// GetEngineMetrics returns the metrics of an engine, or a NotFound error if it does not exist
+	GetEngineMetrics(ctx context.Context, cfg *rest.Config, name string, ns string) (*EngineMetrics, error)
+}
+
+// NewChaosProvider returns the provider of the given name, litmus or chaosmesh
+func NewChaosProvider(name string) (ChaosProvider, error) {
+	switch name {
+	case LitmusProviderName:
+		return LitmusProvider{}, nil
+	case ChaosMeshProviderName:
+		return NewChaosMeshProvider(), nil
+	}
+	return nil, fmt.Errorf("unsupported chaos provider %q, expected %s or %s", name, LitmusProviderName, ChaosMeshProviderName)
+}
+
+// LitmusProviderName is the name of the Litmus provider
+const LitmusProviderName = "litmus"
+
+// LitmusProvider collects the chaosengines of Litmus & their chaosresults
+type LitmusProvider struct{}
+
+// Name returns litmus
+func (LitmusProvider) Name() string {
+	return LitmusProviderName
+}
+
+// ListEngines returns the chaosengines matching the selector
+func (LitmusProvider) ListEngines(ctx context.Context, cfg *rest.Config, ns string, selector string) ([]types.NamespacedName, error) {
+	clientSet, _, err := litmusClients.forConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return ListChaosEngines(ctx, clientSet, ns, selector)
+}
+
+// GetEngineMetrics returns the metrics of a chaosengine
+func (LitmusProvider) GetEngineMetrics(ctx context.Context, cfg *rest.Config, name string, ns string) (*EngineMetrics, error) {
+	clientSet, kubeClient, err := litmusClients.forConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return GetChaosEngineMetrics(ctx, clientSet, kubeClient, name, ns)
+}
+
+// clientSets holds the clientsets of the Litmus providers, created once for a config and reused by the
+// collection passes until the config is replaced, for e.g. once the apiserver rejected its credentials
+type clientSets struct {
+	mu         sync.Mutex
+	cfg        *rest.Config
+	chaos      *clientV1alpha1.ExampleV1Alpha1Client
+	kubernetes kubernetes.Interface
+}
+
+// Holds the clientsets shared by the Litmus providers
+var litmusClients clientSets
+
+// forConfig returns the clientsets of cfg, creating them if cfg is not that of the clientsets held
+func (c *clientSets) forConfig(cfg *rest.Config) (*clientV1alpha1.ExampleV1Alpha1Client, kubernetes.Interface, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cfg == cfg {
+		return c.chaos, c.kubernetes, nil
+	}
+	chaos, err := clientV1alpha1.NewForConfig(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.cfg, c.chaos, c.kubernetes = cfg, chaos, kubeClient
+	return chaos, kubeClient, nil
+}
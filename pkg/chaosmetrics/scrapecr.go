@@ -1,28 +1,24 @@
 package chaosmetrics
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"sync"
 
-	"k8s.io/client-go/kubernetes/scheme"
-	"k8s.io/client-go/rest"
+	"k8s.io/client-go/kubernetes"
 
 	// auth for gcp: optional
 	//_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	exporterV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/apis/litmuschaos/v1alpha1"
 	clientV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/clientset/v1alpha1"
-	v1alpha1 "github.com/litmuschaos/chaos-operator/pkg/apis"
+	listers "github.com/litmuschaos/chaos-exporter/pkg/listers/v1alpha1"
+	chaosV1alpha1 "github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
-// Holds list of experiments in a chaosengine
-var chaosexperimentlist []string
-
-// Holds a map of experiment: result
-var chaosresultmap map[string]string
-
-// Holds a map of experiment: numeric representation(result)
-var statusmap map[string]float64
-
 // Holds a lookup of result: numericValue
 var numericstatus = map[string]float64{
 	"not-executed": 0,
@@ -31,8 +27,79 @@ var numericstatus = map[string]float64{
 	"pass":         3,
 }
 
-// Holds Error type
-var err error
+// EngineMetrics holds the chaos metrics gathered for a chaosengine
+type EngineMetrics struct {
+	// Namespace & label of the application under test
+	AppNamespace string
+	AppLabel     string
+	// Number of experiments listed in the chaosengine
+	TotalExperiments float64
+	// Number of experiments with a pass verdict
+	PassedExperiments float64
+	// Number of experiments with a fail verdict
+	FailedExperiments float64
+	// Holds a map of experiment: numeric representation(result)
+	ExperimentStatus map[string]float64
+	// Holds the steps the failed experiments failed at, when reported in their chaosresults
+	FailSteps map[string]string
+	// Holds the outcome of the steady-state probes of every experiment
+	Probes []ProbeStatus
+	// Holds the expected vs actual chaos injections of experiments run w/ a CHAOS_INTERVAL
+	Iterations []IterationStatus
+	// Holds the reasons the chaosengine spec is invalid for, metrics are still gathered for invalid engines
+	InvalidReasons []string
+	// Holds the lookups that failed & the spec problems found while gathering the metrics, for the caller to log
+	Warnings []string
+	// Holds the owners of the chaosengine, for e.g. the workflow or schedule that created it
+	Owners []metav1.OwnerReference
+	// Holds the annotations of the chaosengine, carrying per engine exporter settings
+	Annotations map[string]string
+	// Holds the labels of the chaosengine
+	Labels map[string]string
+}
+
+// ProbeStatus holds the outcome of a single probe of an experiment
+type ProbeStatus struct {
+	Experiment string
+	Name       string
+	Type       string
+	Passed     bool
+}
+
+// ChaosResultCache is an informer cache the chaosresult lookups are served from, once synced
+type ChaosResultCache interface {
+	HasSynced() bool
+	Lister() listers.ChaosResultLister
+}
+
+// Holds the chaosresult caches, keyed by namespace ("" for the cluster)
+var (
+	resultCachesMutex sync.RWMutex
+	resultCaches      = make(map[string]ChaosResultCache)
+)
+
+// SetChaosResultCaches serves the chaosresult lookups of the given namespaces ("" for the whole cluster) from
+// the caches, replacing those previously set. The chaosresults of other namespaces are read from the apiserver
+func SetChaosResultCaches(caches map[string]ChaosResultCache) {
+	resultCachesMutex.Lock()
+	defer resultCachesMutex.Unlock()
+	resultCaches = caches
+}
+
+// getChaosResult returns a chaosresult, from the cache of its namespace (or the cluster) once it has synced
+func getChaosResult(clientSet *clientV1alpha1.ExampleV1Alpha1Client, ns string, name string) (*exporterV1alpha1.ChaosResult, error) {
+	resultCachesMutex.RLock()
+	resultCache, ok := resultCaches[ns]
+	if !ok {
+		resultCache, ok = resultCaches[""]
+	}
+	resultCachesMutex.RUnlock()
+
+	if ok && resultCache.HasSynced() {
+		return resultCache.Lister().ChaosResults(ns).Get(name)
+	}
+	return clientSet.ChaosResults(ns).Get(name, metav1.GetOptions{})
+}
 
 // Utility fn to return numeric value for a result
 func statusConv(expstatus string) (numeric float64) {
@@ -43,54 +110,138 @@ func statusConv(expstatus string) (numeric float64) {
 	return 0
 }
 
+// StatusName returns the result name for a numeric status, the inverse of statusConv
+func StatusName(numeric float64) string {
+	for status, value := range numericstatus {
+		if value == numeric {
+			return status
+		}
+	}
+	return "not-executed"
+}
+
+// probePassed derives the outcome of a probe from its reported status. A probe passes
+// when its verdict, or the result of each of its phases, is "Passed"
+func probePassed(status map[string]string) bool {
+	if verdict, ok := status["verdict"]; ok {
+		return verdict == "Passed"
+	}
+	for _, result := range status {
+		if len(result) < 6 || result[:6] != "Passed" {
+			return false
+		}
+	}
+	return len(status) > 0
+}
+
+// engineVerdict derives the result of an experiment from the engine status, which lags behind the
+// chaosresult and so is only used in its absence
+func engineVerdict(engine *chaosV1alpha1.ChaosEngine, experiment string) string {
+	for _, status := range engine.Status.Experiments {
+		if status.Name != experiment {
+			continue
+		}
+		switch verdict := strings.ToLower(status.Verdict); {
+		case verdict == "pass" || verdict == "fail":
+			return verdict
+		case strings.EqualFold(status.Status, "running"):
+			return "running"
+		}
+	}
+	return "not-executed"
+}
+
+// ListChaosEngines returns the chaosengines matching the label selector in a namespace, or in the
+// cluster if ns is empty. An empty selector matches all chaosengines
+func ListChaosEngines(ctx context.Context, clientSet *clientV1alpha1.ExampleV1Alpha1Client, ns string, selector string) ([]types.NamespacedName, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	engineList, err := clientSet.ChaosEngines(ns).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	var engines []types.NamespacedName
+	for _, engine := range engineList.Items {
+		engines = append(engines, types.NamespacedName{Namespace: engine.Namespace, Name: engine.Name})
+	}
+	return engines, nil
+}
+
 /* Exported function to gather chaos metrics
 
    - TODO: Update the chaosresult to carry verdict alone. Status & Verdict are redundant
 */
 
 // GetLitmusChaosMetrics returns chaos metrics for a given chaosengine
-func GetLitmusChaosMetrics(cfg *rest.Config, cEngine string, ns string) (totalExpCount, totalPassedExp, totalFailedExp float64, rMap map[string]float64, err error) {
-
-	v1alpha1.AddToScheme(scheme.Scheme)
-	clientSet, err := clientV1alpha1.NewForConfig(cfg)
+func GetLitmusChaosMetrics(ctx context.Context, clientSet *clientV1alpha1.ExampleV1Alpha1Client, kubeClient kubernetes.Interface, cEngine string, ns string) (totalExpCount, totalPassedExp, totalFailedExp float64, rMap map[string]float64, err error) {
+	metrics, err := GetChaosEngineMetrics(ctx, clientSet, kubeClient, cEngine, ns)
 	if err != nil {
 		return 0, 0, 0, nil, err
 	}
+	return metrics.TotalExperiments, metrics.PassedExperiments, metrics.FailedExperiments, metrics.ExperimentStatus, nil
+}
+
+// GetChaosEngineMetrics returns the experiment counts, states and probe outcomes for a given chaosengine.
+// The collection is abandoned, returning the context error, once ctx is done
+func GetChaosEngineMetrics(ctx context.Context, clientSet *clientV1alpha1.ExampleV1Alpha1Client, kubeClient kubernetes.Interface, cEngine string, ns string) (*EngineMetrics, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	engine, err := clientSet.ChaosEngines(ns).Get(cEngine, metav1.GetOptions{})
 	if err != nil {
-		return 0, 0, 0, nil, err
+		return nil, err
 	}
 
+	metrics := &EngineMetrics{AppNamespace: engine.Spec.Appinfo.Appns, AppLabel: engine.Spec.Appinfo.Applabel, Owners: engine.OwnerReferences, Annotations: engine.Annotations, Labels: engine.Labels}
 	/////////////////////////////////////////////////////////
 	/*METRIC*/
-	totalExpCount = float64(len(engine.Spec.Experiments)) //
+	metrics.TotalExperiments = float64(len(engine.Spec.Experiments)) //
 	/////////////////////////////////////////////////////////
 
+	// Holds list of experiments in a chaosengine
+	var chaosexperimentlist []string
 	for _, element := range engine.Spec.Experiments {
 		chaosexperimentlist = append(chaosexperimentlist, element.Name)
 	}
 
-	// Initialize the chaosresult map before entering loop
+	// Holds a map of experiment: result
 	chaosresultmap := make(map[string]string)
 
-	// Set default values on the chaosresult map before populating w/ actual values
-	//for _, test:= range chaosexperimentlist{
-
 	for _, test := range chaosexperimentlist {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		chaosresultname := fmt.Sprintf("%s-%s", cEngine, test)
-		testresultdump, err := clientSet.ChaosResults(ns).Get(chaosresultname, metav1.GetOptions{})
+		testresultdump, err := getChaosResult(clientSet, ns, chaosresultname)
 		if err != nil {
-			if strings.Contains(err.Error(), "not found") {
-				// lack of result cr indicates experiment not executed
-				//chaosresultmap[chaosresultname] = "not-executed"
-				chaosresultmap[test] = "not-executed"
+			// lack of result cr indicates experiment not executed, unless the engine status reports otherwise
+			if !k8serrors.IsNotFound(err) {
+				metrics.Warnings = append(metrics.Warnings, fmt.Sprintf("unable to get chaosresult %s: %v", chaosresultname, err))
+			}
+			chaosresultmap[test] = engineVerdict(engine, test)
+			continue
+		}
+
+		chaosresultmap[test] = testresultdump.Verdict()
+		if step := testresultdump.FailStep(); step != "" {
+			if metrics.FailSteps == nil {
+				metrics.FailSteps = make(map[string]string)
 			}
-			//return 0, 0, 0, nil, err
+			metrics.FailSteps[test] = step
+		}
+
+		for _, probe := range testresultdump.Status.ProbeStatus {
+			metrics.Probes = append(metrics.Probes, ProbeStatus{
+				Experiment: test,
+				Name:       probe.Name,
+				Type:       probe.Type,
+				Passed:     probePassed(probe.Status),
+			})
 		}
-		result := testresultdump.Spec.ExperimentStatus.Verdict
-		//chaosresultmap[chaosresultname] = result
-		chaosresultmap[test] = result
 	}
 
 	pcount, fcount := 0, 0
@@ -103,19 +254,27 @@ func GetLitmusChaosMetrics(cfg *rest.Config, cEngine string, ns string) (totalEx
 	}
 
 	/////////////////////////////////////////////////
-	/*METRIC*/                       //
-	totalPassedExp = float64(pcount) //
-	totalFailedExp = float64(fcount) //
+	/*METRIC*/                                  //
+	metrics.PassedExperiments = float64(pcount) //
+	metrics.FailedExperiments = float64(fcount) //
 	/////////////////////////////////////////////////
-	//fmt.Printf("%+v %+v %+v\n", totalExpCount, totalPassedExp, totalFailedExp)
 
 	//Map verdict to numerical values {0-notstarted, 1-running, 2-fail, 3-pass}
-	statusmap := make(map[string]float64)
+	metrics.ExperimentStatus = make(map[string]float64)
 	for index, status := range chaosresultmap {
-		val := statusConv(status)
-		statusmap[index] = val
+		metrics.ExperimentStatus[index] = statusConv(status)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var experiments map[string]*chaosV1alpha1.ChaosExperiment
+	experiments = validateEngine(clientSet, engine, metrics)
+
+	metrics.Iterations, err = getIterationStatus(kubeClient, engine, experiments)
+	if err != nil {
+		metrics.Warnings = append(metrics.Warnings, fmt.Sprintf("unable to get the chaos iterations: %v", err))
 	}
-	fmt.Printf("%+v\n", statusmap)
 
-	return totalExpCount, totalPassedExp, totalFailedExp, statusmap, nil
+	return metrics, nil
 }
@@ -0,0 +1,265 @@
+package chaosmetrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	exporterV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/apis/litmuschaos/v1alpha1"
+	clientV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/clientset/v1alpha1"
+	chaosV1alpha1 "github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+)
+
+func TestProbePassed(t *testing.T) {
+	tests := []struct {
+		status map[string]string
+		passed bool
+	}{
+		{map[string]string{"verdict": "Passed"}, true},
+		{map[string]string{"verdict": "Failed"}, false},
+		{map[string]string{"PreChaos": "Passed 👍", "PostChaos": "Passed 👍"}, true},
+		{map[string]string{"PreChaos": "Passed 👍", "PostChaos": "Failed 👎"}, false},
+		{map[string]string{}, false},
+	}
+	for _, test := range tests {
+		if got := probePassed(test.status); got != test.passed {
+			t.Errorf("probePassed(%v) = %v, expected %v", test.status, got, test.passed)
+		}
+	}
+}
+
+func TestStatusName(t *testing.T) {
+	for status := range numericstatus {
+		if got := StatusName(statusConv(status)); got != status {
+			t.Errorf("StatusName(statusConv(%q)) = %q", status, got)
+		}
+	}
+}
+
+func TestExpectedIterations(t *testing.T) {
+	tests := []struct {
+		env       []chaosV1alpha1.ENVPair
+		expected  float64
+		iterative bool
+	}{
+		{[]chaosV1alpha1.ENVPair{{Name: "TOTAL_CHAOS_DURATION", Value: "60"}, {Name: "CHAOS_INTERVAL", Value: "10"}}, 6, true},
+		{[]chaosV1alpha1.ENVPair{{Name: "TOTAL_CHAOS_DURATION", Value: "45"}, {Name: "CHAOS_INTERVAL", Value: "10"}}, 5, true},
+		{[]chaosV1alpha1.ENVPair{{Name: "TOTAL_CHAOS_DURATION", Value: "60"}}, 0, false},
+		{[]chaosV1alpha1.ENVPair{{Name: "TOTAL_CHAOS_DURATION", Value: "60"}, {Name: "CHAOS_INTERVAL", Value: "abc"}}, 0, false},
+	}
+	for _, test := range tests {
+		got, iterative := expectedIterations(test.env)
+		if got != test.expected || iterative != test.iterative {
+			t.Errorf("expectedIterations(%v) = %v, %v, expected %v, %v", test.env, got, iterative, test.expected, test.iterative)
+		}
+	}
+}
+
+// TestSkewAt verifies that the skew is measured against the midpoint of the request
+func TestCurrentRunEvents(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	event := func(pod string, message string, first time.Duration, last time.Duration, count int32) corev1.Event {
+		return corev1.Event{
+			Source:         corev1.EventSource{Component: pod},
+			Message:        message,
+			FirstTimestamp: metav1.NewTime(start.Add(first)),
+			LastTimestamp:  metav1.NewTime(start.Add(last)),
+			Count:          count,
+		}
+	}
+	events := []corev1.Event{
+		// A previous run of pod-delete, and the current one
+		event("pod-delete-x1y2z3-abcde", "Injecting pod-delete chaos", 0, 5*time.Minute, 6),
+		event("pod-delete-q4r5s6-fghij", "Injecting pod-delete chaos", time.Hour, time.Hour+time.Minute, 2),
+		// Another experiment, prefixed by pod-delete
+		event("pod-delete-extended-t7u8v9-klmno", "Injecting pod-delete-extended chaos", time.Hour, time.Hour, 3),
+		// Events w/o a source are matched on a word of their message
+		event("", "Injecting pod-cpu-hog-extended chaos", time.Hour, time.Hour, 1),
+		event("", "Injecting pod-cpu-hog chaos", time.Hour, time.Hour, 4),
+	}
+	experiments := []string{"pod-delete", "pod-delete-extended", "pod-cpu-hog"}
+	engine := &chaosV1alpha1.ChaosEngine{}
+	count := func(experiment string) int32 {
+		var total int32
+		for _, event := range currentRunEvents(events, engine, experiment, experiments) {
+			total += event.Count
+		}
+		return total
+	}
+	for experiment, expected := range map[string]int32{"pod-delete": 2, "pod-delete-extended": 3, "pod-cpu-hog": 4} {
+		if got := count(experiment); got != expected {
+			t.Errorf("expected %d injections of %s, got %d", expected, experiment, got)
+		}
+	}
+
+	// A new run of pod-delete has started, its pod has yet to inject chaos
+	engine.Status.Experiments = []chaosV1alpha1.ExperimentStatuses{{Name: "pod-delete", Status: "Running", LastUpdateTime: metav1.NewTime(start.Add(2 * time.Hour))}}
+	if got := count("pod-delete"); got != 0 {
+		t.Errorf("expected no injection of the run in progress, got %d", got)
+	}
+}
+
+func TestSkewAt(t *testing.T) {
+	sent := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	received := sent.Add(200 * time.Millisecond)
+
+	if got := skewAt(sent, sent, received); got != 0 {
+		t.Errorf("expected no skew, got %s", got)
+	}
+	if got := skewAt(sent.Add(-30*time.Second), sent, received); got != -30*time.Second {
+		t.Errorf("expected a -30s skew, got %s", got)
+	}
+	if got := skewAt(sent.Add(5*time.Second), sent, received); got != 5*time.Second {
+		t.Errorf("expected a 5s skew, got %s", got)
+	}
+}
+
+func TestEngineVerdict(t *testing.T) {
+	engine := &chaosV1alpha1.ChaosEngine{Status: chaosV1alpha1.ChaosEngineStatus{Experiments: []chaosV1alpha1.ExperimentStatuses{
+		{Name: "pod-delete", Status: "Completed", Verdict: "Pass"},
+		{Name: "container-kill", Status: "Running", Verdict: "Awaited"},
+	}}}
+	tests := map[string]string{
+		"pod-delete":     "pass",
+		"container-kill": "running",
+		"disk-fill":      "not-executed",
+	}
+	for experiment, expected := range tests {
+		if got := engineVerdict(engine, experiment); got != expected {
+			t.Errorf("engineVerdict(%s) = %s, expected %s", experiment, got, expected)
+		}
+	}
+}
+
+func TestSelectAPIVersion(t *testing.T) {
+	groups := &metav1.APIGroupList{Groups: []metav1.APIGroup{
+		{Name: "apps", Versions: []metav1.GroupVersionForDiscovery{{Version: "v1"}}},
+		{Name: "litmuschaos.io", Versions: []metav1.GroupVersionForDiscovery{{Version: "v1beta1"}, {Version: "v1alpha1"}}},
+	}}
+	version, served, err := selectAPIVersion(groups)
+	if err != nil || version != "v1alpha1" || len(served) != 2 {
+		t.Errorf("expected v1alpha1 out of 2 served versions, got %s out of %v: %v", version, served, err)
+	}
+
+	groups.Groups[1].Versions = groups.Groups[1].Versions[:1]
+	if _, _, err := selectAPIVersion(groups); err == nil {
+		t.Error("expected an error when no supported version is served")
+	}
+}
+
+func TestChaosResultVerdict(t *testing.T) {
+	tests := []struct {
+		spec, status chaosV1alpha1.TestStatus
+		expected     string
+	}{
+		{spec: chaosV1alpha1.TestStatus{Phase: "completed", Verdict: "pass"}, expected: "pass"},
+		{status: chaosV1alpha1.TestStatus{Phase: "Completed", Verdict: "Fail"}, expected: "fail"},
+		{status: chaosV1alpha1.TestStatus{Phase: "Running", Verdict: "Awaited"}, expected: "running"},
+	}
+	for _, test := range tests {
+		result := exporterV1alpha1.ChaosResult{}
+		result.Spec.ExperimentStatus = test.spec
+		result.Status.ExperimentStatus.TestStatus = test.status
+		if got := result.Verdict(); got != test.expected {
+			t.Errorf("expected verdict %s for %+v, got %s", test.expected, test, got)
+		}
+	}
+}
+
+func TestAnnotatedEngines(t *testing.T) {
+	engine := func(name string, appns string, applabel string) chaosV1alpha1.ChaosEngine {
+		engine := chaosV1alpha1.ChaosEngine{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "litmus"}}
+		engine.Spec.Appinfo = chaosV1alpha1.ApplicationParams{Appns: appns, Applabel: applabel}
+		return engine
+	}
+	deployment := func(name string, annotated bool, podLabels map[string]string) appsv1.Deployment {
+		deployment := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"name": name}}}
+		if annotated {
+			deployment.Annotations = map[string]string{ChaosAnnotation: "true"}
+		}
+		deployment.Spec.Template.Labels = podLabels
+		return deployment
+	}
+	engines := []chaosV1alpha1.ChaosEngine{
+		engine("engine-nginx", "default", "app=nginx"),
+		engine("engine-redis", "default", "name=redis"),
+		engine("engine-mysql", "", "app=mysql"),
+		engine("engine-unlabelled", "default", ""),
+	}
+	deployments := map[string][]appsv1.Deployment{
+		"default": {deployment("nginx", true, map[string]string{"app": "nginx"}), deployment("redis", false, nil)},
+		"litmus":  {deployment("mysql", true, map[string]string{"app": "mysql"})},
+	}
+	expected := []types.NamespacedName{{Namespace: "litmus", Name: "engine-nginx"}, {Namespace: "litmus", Name: "engine-mysql"}}
+	if discovered := annotatedEngines(engines, deployments); !reflect.DeepEqual(discovered, expected) {
+		t.Errorf("expected %v, got %v", expected, discovered)
+	}
+}
+
+func TestListChaosEnginesSelector(t *testing.T) {
+	var selector string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		selector = r.URL.Query().Get("labelSelector")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"apiVersion":"litmuschaos.io/v1alpha1","kind":"ChaosEngineList","metadata":{},"items":[`+
+			`{"metadata":{"name":"engine-payments","namespace":"litmus","labels":{"team":"payments"}}}]}`)
+	}))
+	defer server.Close()
+
+	clientSet, err := clientV1alpha1.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	engines, err := ListChaosEngines(context.Background(), clientSet, "litmus", "team=payments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selector != "team=payments" {
+		t.Errorf("expected the engines to be listed by the selector, got %q", selector)
+	}
+	if expected := []types.NamespacedName{{Namespace: "litmus", Name: "engine-payments"}}; !reflect.DeepEqual(engines, expected) {
+		t.Errorf("expected %v, got %v", expected, engines)
+	}
+}
+
+func TestValidateEngineWarnings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/chaosexperiments/pod-delete") {
+			fmt.Fprint(w, `{"apiVersion":"litmuschaos.io/v1alpha1","kind":"ChaosExperiment","metadata":{"name":"pod-delete","namespace":"litmus"}}`)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"apiVersion":"v1","kind":"Status","status":"Failure","reason":"NotFound","code":404}`)
+	}))
+	defer server.Close()
+	clientSet, err := clientV1alpha1.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := &chaosV1alpha1.ChaosEngine{ObjectMeta: metav1.ObjectMeta{Name: "engine", Namespace: "litmus"}}
+	engine.Spec.Appinfo.Applabel = "app in (nginx"
+	engine.Spec.Experiments = []chaosV1alpha1.ExperimentList{{Name: "pod-delete"}, {Name: "pod-cpu-hog"}}
+	metrics := &EngineMetrics{}
+	experiments := validateEngine(clientSet, engine, metrics)
+	if _, ok := experiments["pod-delete"]; !ok || len(experiments) != 1 {
+		t.Errorf("unexpected experiments %v", experiments)
+	}
+	if !reflect.DeepEqual(metrics.InvalidReasons, []string{ReasonInvalidSelector, ReasonUnknownExperiment}) {
+		t.Errorf("unexpected reasons %v", metrics.InvalidReasons)
+	}
+	if len(metrics.Warnings) != 2 || !strings.HasPrefix(metrics.Warnings[0], `invalid applabel "app in (nginx"`) || metrics.Warnings[1] != "unknown experiment pod-cpu-hog" {
+		t.Errorf("unexpected warnings %q", metrics.Warnings)
+	}
+}
@@ -0,0 +1,51 @@
+package chaosmetrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// GetClockSkew returns the offset of the apiserver clock relative to the local clock, i.e. a positive skew
+// means the apiserver is ahead. It is estimated from the Date header of a /version request against the
+// midpoint of the request, so it carries the one second resolution of the header
+func GetClockSkew(ctx context.Context, cfg *rest.Config) (time.Duration, error) {
+	transport, err := rest.TransportFor(cfg)
+	if err != nil {
+		return 0, err
+	}
+	client := &http.Client{Transport: transport, Timeout: cfg.Timeout}
+
+	host := strings.TrimSuffix(cfg.Host, "/")
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	req, err := http.NewRequest(http.MethodGet, host+"/version", nil)
+	if err != nil {
+		return 0, err
+	}
+	sent := time.Now()
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	received := time.Now()
+	resp.Body.Close()
+
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse the apiserver Date header: %v", err)
+	}
+	return skewAt(serverTime, sent, received), nil
+}
+
+// skewAt returns the offset of serverTime from the midpoint of the request, rounded to the second
+// since the Date header is truncated to the second
+func skewAt(serverTime time.Time, sent time.Time, received time.Time) time.Duration {
+	midpoint := sent.Add(received.Sub(sent) / 2)
+	return serverTime.Add(500 * time.Millisecond).Sub(midpoint).Round(time.Second)
+}
@@ -0,0 +1,52 @@
+package chaosmetrics
+
+import (
+	"fmt"
+
+	clientV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/clientset/v1alpha1"
+	chaosV1alpha1 "github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Reasons for which a chaosengine is reported as invalid
+const (
+	// ReasonEngineNotFound indicates the monitored chaosengine does not exist
+	ReasonEngineNotFound = "engine_not_found"
+	// ReasonUnknownExperiment indicates an experiment listed in the engine has no chaosexperiment
+	ReasonUnknownExperiment = "unknown_experiment"
+	// ReasonInvalidSelector indicates the applabel of the engine is not a valid label selector
+	ReasonInvalidSelector = "invalid_selector"
+)
+
+// validateEngine checks the spec of a chaosengine, recording the reasons it is invalid for & their details in
+// metrics, and returns the chaosexperiments of the engine, keyed by name
+func validateEngine(clientSet *clientV1alpha1.ExampleV1Alpha1Client, engine *chaosV1alpha1.ChaosEngine, metrics *EngineMetrics) map[string]*chaosV1alpha1.ChaosExperiment {
+	var reasons []string
+
+	if _, err := labels.Parse(engine.Spec.Appinfo.Applabel); err != nil {
+		metrics.Warnings = append(metrics.Warnings, fmt.Sprintf("invalid applabel %q: %v", engine.Spec.Appinfo.Applabel, err))
+		reasons = append(reasons, ReasonInvalidSelector)
+	}
+
+	experiments := make(map[string]*chaosV1alpha1.ChaosExperiment)
+	unknown := false
+	for _, element := range engine.Spec.Experiments {
+		experiment, err := clientSet.ChaosExperiments(engine.Namespace).Get(element.Name, metav1.GetOptions{})
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				metrics.Warnings = append(metrics.Warnings, fmt.Sprintf("unknown experiment %s", element.Name))
+				unknown = true
+			}
+			continue
+		}
+		experiments[element.Name] = experiment
+	}
+	if unknown {
+		reasons = append(reasons, ReasonUnknownExperiment)
+	}
+
+	metrics.InvalidReasons = reasons
+	return experiments
+}
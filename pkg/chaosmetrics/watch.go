@@ -0,0 +1,176 @@
+package chaosmetrics
+
+import (
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	clientV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/clientset/v1alpha1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+)
+
+// Holds the wait period before a failed list or watch is retried
+var watchRetryInterval = 5 * time.Second
+
+// ChaosEvent describes an add, update or delete of a chaosengine or chaosresult
+type ChaosEvent struct {
+	Type      watch.EventType
+	Resource  string
+	Namespace string
+	Name      string
+}
+
+// listWatch lists a resource, returning the objects & the resource version of the list, and watches it from a
+// resource version
+type listWatch struct {
+	list  func() (string, []metav1.Object, error)
+	watch func(resourceVersion string) (watch.Interface, error)
+}
+
+// WatchChaosResources sends a ChaosEvent on events for every chaosengine & chaosresult change in ns,
+// or in all namespaces if ns is empty. The resources are listed, then watched from the resource version of
+// the list, as a reflector does, until stop is closed
+func WatchChaosResources(cfg *rest.Config, ns string, events chan<- ChaosEvent, stop <-chan struct{}) error {
+
+	// Watches are long running requests, which the request timeout would otherwise cut short
+	watchConfig := rest.CopyConfig(cfg)
+	watchConfig.Timeout = 0
+
+	clientSet, err := clientV1alpha1.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	watchClientSet, err := clientV1alpha1.NewForConfig(watchConfig)
+	if err != nil {
+		return err
+	}
+
+	go watchResource("chaosengines", listWatch{
+		list: func() (string, []metav1.Object, error) {
+			list, err := clientSet.ChaosEngines(ns).List(metav1.ListOptions{})
+			if err != nil {
+				return "", nil, err
+			}
+			objs := make([]metav1.Object, 0, len(list.Items))
+			for index := range list.Items {
+				objs = append(objs, &list.Items[index])
+			}
+			return list.ResourceVersion, objs, nil
+		},
+		watch: func(resourceVersion string) (watch.Interface, error) {
+			return watchClientSet.ChaosEngines(ns).Watch(metav1.ListOptions{ResourceVersion: resourceVersion})
+		},
+	}, events, stop)
+	go watchResource("chaosresults", listWatch{
+		list: func() (string, []metav1.Object, error) {
+			list, err := clientSet.ChaosResults(ns).List(metav1.ListOptions{})
+			if err != nil {
+				return "", nil, err
+			}
+			objs := make([]metav1.Object, 0, len(list.Items))
+			for index := range list.Items {
+				objs = append(objs, &list.Items[index])
+			}
+			return list.ResourceVersion, objs, nil
+		},
+		watch: func(resourceVersion string) (watch.Interface, error) {
+			return watchClientSet.ChaosResults(ns).Watch(metav1.ListOptions{ResourceVersion: resourceVersion})
+		},
+	}, events, stop)
+
+	return nil
+}
+
+// watchResource lists resource & watches it from the resource version of the list, forwarding its changes until
+// stop is closed. A watch closed by the apiserver is resumed from the last resource version observed; once that
+// version has expired the resource is listed again, the listed objects forwarded as the changes possibly missed
+func watchResource(resource string, lw listWatch, events chan<- ChaosEvent, stop <-chan struct{}) {
+	logger := log.WithField("resource", resource)
+	resourceVersion, listed := "", false
+	for {
+		var err error
+		if resourceVersion == "" {
+			resourceVersion, err = listResource(resource, lw, events, stop, listed)
+			listed = listed || err == nil
+		}
+		if err == nil {
+			resourceVersion, err = forwardEvents(resource, lw, resourceVersion, events, stop)
+		}
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		if err == nil {
+			continue
+		}
+
+		if k8serrors.IsGone(err) || k8serrors.IsResourceExpired(err) {
+			logger.Info("Watch expired, relisting: ", err)
+			resourceVersion = ""
+			continue
+		}
+		logger.Warnf("Unable to list or watch: %v, retrying in %s", err, watchRetryInterval)
+		select {
+		case <-stop:
+			return
+		case <-time.After(watchRetryInterval):
+		}
+	}
+}
+
+// listResource lists resource, returning the resource version to watch it from. The listed objects are forwarded
+// as changes if forward is set, for e.g. once relisted after the watch expired
+func listResource(resource string, lw listWatch, events chan<- ChaosEvent, stop <-chan struct{}, forward bool) (string, error) {
+	resourceVersion, objs, err := lw.list()
+	if err != nil {
+		return "", err
+	}
+	if !forward {
+		return resourceVersion, nil
+	}
+	for _, obj := range objs {
+		select {
+		case events <- ChaosEvent{Type: watch.Modified, Resource: resource, Namespace: obj.GetNamespace(), Name: obj.GetName()}:
+		case <-stop:
+			return "", nil
+		}
+	}
+	return resourceVersion, nil
+}
+
+// forwardEvents watches resource from resourceVersion & relays its events, until the watch is closed by the
+// apiserver, fails or stop is closed. The last resource version observed is returned to resume the watch from
+func forwardEvents(resource string, lw listWatch, resourceVersion string, events chan<- ChaosEvent, stop <-chan struct{}) (string, error) {
+	w, err := lw.watch(resourceVersion)
+	if err != nil {
+		return resourceVersion, err
+	}
+	defer w.Stop()
+	for {
+		select {
+		case <-stop:
+			return resourceVersion, nil
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return resourceVersion, nil
+			}
+			if event.Type == watch.Error {
+				return resourceVersion, k8serrors.FromObject(event.Object)
+			}
+			obj, err := meta.Accessor(event.Object)
+			if err != nil {
+				continue
+			}
+			resourceVersion = obj.GetResourceVersion()
+			select {
+			case events <- ChaosEvent{Type: event.Type, Resource: resource, Namespace: obj.GetNamespace(), Name: obj.GetName()}:
+			case <-stop:
+				return resourceVersion, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package chaosmetrics
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	chaosV1alpha1 "github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func TestWatchResource(t *testing.T) {
+	engine := func(name string, resourceVersion string) *chaosV1alpha1.ChaosEngine {
+		return &chaosV1alpha1.ChaosEngine{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "litmus", ResourceVersion: resourceVersion}}
+	}
+	lists := [][]metav1.Object{{engine("engine-a", "10")}, {engine("engine-b", "20")}}
+	// The first watch is closed by the apiserver, the second expires, the third is kept open
+	watchers := []*watch.FakeWatcher{watch.NewFakeWithChanSize(2, false), watch.NewFakeWithChanSize(2, false), watch.NewFake()}
+	watchers[0].Modify(engine("engine-a", "11"))
+	watchers[0].Stop()
+	watchers[1].Error(&metav1.Status{Status: metav1.StatusFailure, Code: 410, Reason: metav1.StatusReasonGone})
+
+	var mu sync.Mutex
+	var watchedFrom []string
+	lw := listWatch{
+		list: func() (string, []metav1.Object, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			objs := lists[0]
+			lists = lists[1:]
+			return objs[0].GetResourceVersion(), objs, nil
+		},
+		watch: func(resourceVersion string) (watch.Interface, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			watchedFrom = append(watchedFrom, resourceVersion)
+			w := watchers[0]
+			watchers = watchers[1:]
+			return w, nil
+		},
+	}
+	events := make(chan ChaosEvent)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		watchResource("chaosengines", lw, events, stop)
+		close(done)
+	}()
+
+	// The first list is not forwarded, the relist after the watch expired is
+	expected := []ChaosEvent{
+		{Type: watch.Modified, Resource: "chaosengines", Namespace: "litmus", Name: "engine-a"},
+		{Type: watch.Modified, Resource: "chaosengines", Namespace: "litmus", Name: "engine-b"},
+	}
+	for _, want := range expected {
+		if got := <-events; got != want {
+			t.Errorf("expected %+v, got %+v", want, got)
+		}
+	}
+	close(stop)
+	<-done
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(watchedFrom, []string{"10", "11", "20"}) {
+		t.Errorf("expected the watches to resume from 10, 11 & 20, got %v", watchedFrom)
+	}
+}
@@ -1,51 +1,135 @@
 package v1alpha1
 
 import (
+	"sync"
+
+	"github.com/litmuschaos/chaos-operator/pkg/apis"
 	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 )
 
+// Registers the litmus types once, so that the responses & watch events of the clients decode into them
+var registerTypes sync.Once
+
+var (
+	resourcesMu sync.RWMutex
+	// Resources the chaosengines & chaosresults are read from, see SetResources
+	engineResource = v1alpha1.SchemeGroupVersion.WithResource("chaosengines")
+	resultResource = v1alpha1.SchemeGroupVersion.WithResource("chaosresults")
+)
+
+// SetResources reads the chaosengines & chaosresults from the given resources, for distributions shipping the
+// litmus CRDs under another group, version or name. The litmus types are registered under their group versions,
+// so that the responses & watch events decode into them. Clients created before the call are not affected
+func SetResources(engines schema.GroupVersionResource, results schema.GroupVersionResource) {
+	resourcesMu.Lock()
+	defer resourcesMu.Unlock()
+
+	scheme.Scheme.AddKnownTypes(engines.GroupVersion(), &v1alpha1.ChaosEngine{}, &v1alpha1.ChaosEngineList{})
+	scheme.Scheme.AddKnownTypes(results.GroupVersion(), &v1alpha1.ChaosResult{}, &v1alpha1.ChaosResultList{})
+	metav1.AddToGroupVersion(scheme.Scheme, engines.GroupVersion())
+	if results.GroupVersion() != engines.GroupVersion() {
+		metav1.AddToGroupVersion(scheme.Scheme, results.GroupVersion())
+	}
+	engineResource, resultResource = engines, results
+}
+
+// Resources returns the resources the chaosengines & chaosresults are read from
+func Resources() (schema.GroupVersionResource, schema.GroupVersionResource) {
+	resourcesMu.RLock()
+	defer resourcesMu.RUnlock()
+	return engineResource, resultResource
+}
+
 //ExampleV1Alpha1Interface type defines chaosEngines & chaosResults
 type ExampleV1Alpha1Interface interface {
 	// ChaosEngines with namespace attribute
 	ChaosEngines(namespace string) ChaosEngineInterface
 	// ChaosResults with namespace attribute
 	ChaosResults(namespace string) ChaosResultInterface
+	// ChaosExperiments with namespace attribute
+	ChaosExperiments(namespace string) ChaosExperimentInterface
+	// ChaosExporterConfigs with namespace attribute
+	ChaosExporterConfigs(namespace string) ChaosExporterConfigInterface
 }
 
 //ExampleV1Alpha1Client type defines the rest client for chaos resources
 type ExampleV1Alpha1Client struct {
 	restClient rest.Interface
+	// Clients of the group versions of the chaosengines & chaosresults
+	engineClient   rest.Interface
+	resultClient   rest.Interface
+	engineResource string
+	resultResource string
 }
 
 //NewForConfig returns the kubeclient for the config provided
 func NewForConfig(c *rest.Config) (*ExampleV1Alpha1Client, error) {
-	config := *c
-	config.ContentConfig.GroupVersion = &schema.GroupVersion{Group: v1alpha1.GroupName, Version: v1alpha1.GroupVersion}
-	config.APIPath = "/apis"
-	config.NegotiatedSerializer = serializer.DirectCodecFactory{CodecFactory: scheme.Codecs}
-	config.UserAgent = rest.DefaultKubernetesUserAgent()
-
-	client, err := rest.RESTClientFor(&config)
+	registerTypes.Do(func() {
+		apis.AddToScheme(scheme.Scheme)
+	})
+	client, err := restClientFor(c, schema.GroupVersion{Group: v1alpha1.GroupName, Version: v1alpha1.GroupVersion})
+	if err != nil {
+		return nil, err
+	}
+	engines, results := Resources()
+	engineClient, err := restClientFor(c, engines.GroupVersion())
+	if err != nil {
+		return nil, err
+	}
+	resultClient, err := restClientFor(c, results.GroupVersion())
 	if err != nil {
 		return nil, err
 	}
 
-	return &ExampleV1Alpha1Client{restClient: client}, nil
+	return &ExampleV1Alpha1Client{
+		restClient:     client,
+		engineClient:   engineClient,
+		resultClient:   resultClient,
+		engineResource: engines.Resource,
+		resultResource: results.Resource,
+	}, nil
+}
+
+// restClientFor returns a rest client for the resources of groupVersion
+func restClientFor(c *rest.Config, groupVersion schema.GroupVersion) (rest.Interface, error) {
+	config := *c
+	config.ContentConfig.GroupVersion = &groupVersion
+	config.APIPath = "/apis"
+	config.NegotiatedSerializer = serializer.DirectCodecFactory{CodecFactory: scheme.Codecs}
+	config.UserAgent = rest.DefaultKubernetesUserAgent()
+	return rest.RESTClientFor(&config)
 }
 
 func (c *ExampleV1Alpha1Client) ChaosEngines(namespace string) ChaosEngineInterface {
 	return &chaosEngineClient{
-		restClient: c.restClient,
+		restClient: c.engineClient,
 		ns:         namespace,
+		resource:   c.engineResource,
 	}
 }
 
 func (c *ExampleV1Alpha1Client) ChaosResults(namespace string) ChaosResultInterface {
 	return &chaosResultClient{
+		restClient: c.resultClient,
+		ns:         namespace,
+		resource:   c.resultResource,
+	}
+}
+
+func (c *ExampleV1Alpha1Client) ChaosExperiments(namespace string) ChaosExperimentInterface {
+	return &chaosExperimentClient{
+		restClient: c.restClient,
+		ns:         namespace,
+	}
+}
+
+func (c *ExampleV1Alpha1Client) ChaosExporterConfigs(namespace string) ChaosExporterConfigInterface {
+	return &chaosExporterConfigClient{
 		restClient: c.restClient,
 		ns:         namespace,
 	}
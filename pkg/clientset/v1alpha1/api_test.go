@@ -0,0 +1,87 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	exporterV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/apis/litmuschaos/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+)
+
+func TestSetResources(t *testing.T) {
+	defaultEngines, defaultResults := Resources()
+	defer SetResources(defaultEngines, defaultResults)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/apis/chaos.example.com/v1beta1/namespaces/litmus/engines":
+			fmt.Fprint(w, `{"apiVersion":"chaos.example.com/v1beta1","kind":"ChaosEngineList","items":[{"metadata":{"name":"engine-nginx"}}]}`)
+		case "/apis/results.example.com/v1/namespaces/litmus/results/engine-nginx-pod-delete":
+			fmt.Fprint(w, `{"apiVersion":"results.example.com/v1","kind":"ChaosResult","spec":{"experimentstatus":{"verdict":"Pass"}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	SetResources(schema.GroupVersionResource{Group: "chaos.example.com", Version: "v1beta1", Resource: "engines"},
+		schema.GroupVersionResource{Group: "results.example.com", Version: "v1", Resource: "results"})
+	clientSet, err := NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engines, err := clientSet.ChaosEngines("litmus").List(metav1.ListOptions{LabelSelector: "team=payments"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(engines.Items) != 1 || engines.Items[0].Name != "engine-nginx" {
+		t.Errorf("unexpected engines %v", engines.Items)
+	}
+	result, err := clientSet.ChaosResults("litmus").Get("engine-nginx-pod-delete", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verdict := result.Verdict(); verdict != "pass" {
+		t.Errorf("expected a pass verdict, got %q", verdict)
+	}
+}
+
+func TestChaosResultWatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/apis/litmuschaos.io/v1alpha1/namespaces/litmus/chaosresults" || r.URL.Query().Get("watch") != "true" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, `{"type":"MODIFIED","object":{"kind":"ChaosResult","metadata":{"name":"engine-nginx-pod-delete"},"status":{"experimentStatus":{"verdict":"Fail","failStep":"Unable to get the application pods"}}}}`)
+		fmt.Fprintln(w, `{"type":"ERROR","object":{"kind":"Status","status":"Failure","reason":"Expired","code":410}}`)
+	}))
+	defer server.Close()
+
+	clientSet, err := NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := clientSet.ChaosResults("litmus").Watch(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// The status of newer operators is decoded along with the object
+	event := <-w.ResultChan()
+	result, ok := event.Object.(*exporterV1alpha1.ChaosResult)
+	if event.Type != watch.Modified || !ok || result.FailStep() != "Unable to get the application pods" {
+		t.Fatalf("unexpected event %s %#v", event.Type, event.Object)
+	}
+	if event := <-w.ResultChan(); event.Type != watch.Error || event.Object.(*metav1.Status).Code != 410 {
+		t.Errorf("expected an expired status, got %s %#v", event.Type, event.Object)
+	}
+}
@@ -3,6 +3,7 @@ package v1alpha1
 import (
 	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 )
@@ -11,12 +12,15 @@ type ChaosEngineInterface interface {
 	List(opts metav1.ListOptions) (*v1alpha1.ChaosEngineList, error)
 	Get(name string, options metav1.GetOptions) (*v1alpha1.ChaosEngine, error)
 	Create(*v1alpha1.ChaosEngine) (*v1alpha1.ChaosEngine, error)
+	Watch(opts metav1.ListOptions) (watch.Interface, error)
+	Delete(name string, options *metav1.DeleteOptions) error
 	// ...
 }
 
 type chaosEngineClient struct {
 	restClient rest.Interface
 	ns         string
+	resource   string
 }
 
 func (c *chaosEngineClient) List(opts metav1.ListOptions) (*v1alpha1.ChaosEngineList, error) {
@@ -24,7 +28,7 @@ func (c *chaosEngineClient) List(opts metav1.ListOptions) (*v1alpha1.ChaosEngine
 	err := c.restClient.
 		Get().
 		Namespace(c.ns).
-		Resource("chaosengines").
+		Resource(c.resource).
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Do().
 		Into(&result)
@@ -37,7 +41,7 @@ func (c *chaosEngineClient) Get(name string, opts metav1.GetOptions) (*v1alpha1.
 	err := c.restClient.
 		Get().
 		Namespace(c.ns).
-		Resource("chaosengines").
+		Resource(c.resource).
 		Name(name).
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Do().
@@ -51,10 +55,31 @@ func (c *chaosEngineClient) Create(chaosengine *v1alpha1.ChaosEngine) (*v1alpha1
 	err := c.restClient.
 		Post().
 		Namespace(c.ns).
-		Resource("chaosengines").
+		Resource(c.resource).
 		Body(chaosengine).
 		Do().
 		Into(&result)
 
 	return &result, err
 }
+
+func (c *chaosEngineClient) Delete(name string, options *metav1.DeleteOptions) error {
+	return c.restClient.
+		Delete().
+		Namespace(c.ns).
+		Resource(c.resource).
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+func (c *chaosEngineClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.restClient.
+		Get().
+		Namespace(c.ns).
+		Resource(c.resource).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
@@ -0,0 +1,58 @@
+package v1alpha1
+
+import (
+	"github.com/litmuschaos/chaos-exporter/pkg/apis/litmuschaos/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+type ChaosExporterConfigInterface interface {
+	List(opts metav1.ListOptions) (*v1alpha1.ChaosExporterConfigList, error)
+	Get(name string, options metav1.GetOptions) (*v1alpha1.ChaosExporterConfig, error)
+	Watch(opts metav1.ListOptions) (watch.Interface, error)
+	// ...
+}
+
+type chaosExporterConfigClient struct {
+	restClient rest.Interface
+	ns         string
+}
+
+func (c *chaosExporterConfigClient) List(opts metav1.ListOptions) (*v1alpha1.ChaosExporterConfigList, error) {
+	result := v1alpha1.ChaosExporterConfigList{}
+	err := c.restClient.
+		Get().
+		Namespace(c.ns).
+		Resource("chaosexporterconfigs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *chaosExporterConfigClient) Get(name string, opts metav1.GetOptions) (*v1alpha1.ChaosExporterConfig, error) {
+	result := v1alpha1.ChaosExporterConfig{}
+	err := c.restClient.
+		Get().
+		Namespace(c.ns).
+		Resource("chaosexporterconfigs").
+		Name(name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(&result)
+
+	return &result, err
+}
+
+func (c *chaosExporterConfigClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.restClient.
+		Get().
+		Namespace(c.ns).
+		Resource("chaosexporterconfigs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
@@ -1,50 +1,65 @@
 package v1alpha1
 
 import (
+	"encoding/json"
+	"io"
+
+	exporterV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/apis/litmuschaos/v1alpha1"
 	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	//"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 )
 
+// ChaosResultInterface returns chaosresults including the status reported by newer operators,
+// see exporterV1alpha1.ChaosResult
 type ChaosResultInterface interface {
-	List(opts metav1.ListOptions) (*v1alpha1.ChaosResultList, error)
-	Get(name string, options metav1.GetOptions) (*v1alpha1.ChaosResult, error)
+	List(opts metav1.ListOptions) (*exporterV1alpha1.ChaosResultList, error)
+	Get(name string, options metav1.GetOptions) (*exporterV1alpha1.ChaosResult, error)
 	Create(*v1alpha1.ChaosResult) (*v1alpha1.ChaosResult, error)
-	// Watch(opts metav1.ListOptions) (watch.Interface, error)
+	Watch(opts metav1.ListOptions) (watch.Interface, error)
+	Delete(name string, options *metav1.DeleteOptions) error
 	// ...
 }
 
 type chaosResultClient struct {
 	restClient rest.Interface
 	ns         string
+	resource   string
 }
 
-func (c *chaosResultClient) List(opts metav1.ListOptions) (*v1alpha1.ChaosResultList, error) {
-	result := v1alpha1.ChaosResultList{}
-	err := c.restClient.
+func (c *chaosResultClient) List(opts metav1.ListOptions) (*exporterV1alpha1.ChaosResultList, error) {
+	result := exporterV1alpha1.ChaosResultList{}
+	raw, err := c.restClient.
 		Get().
 		Namespace(c.ns).
-		Resource("chaosresults").
+		Resource(c.resource).
 		VersionedParams(&opts, scheme.ParameterCodec).
-		Do().
-		Into(&result)
+		DoRaw()
+	if err != nil {
+		return &result, err
+	}
 
+	err = json.Unmarshal(raw, &result)
 	return &result, err
 }
 
-func (c *chaosResultClient) Get(name string, opts metav1.GetOptions) (*v1alpha1.ChaosResult, error) {
-	result := v1alpha1.ChaosResult{}
-	err := c.restClient.
+func (c *chaosResultClient) Get(name string, opts metav1.GetOptions) (*exporterV1alpha1.ChaosResult, error) {
+	result := exporterV1alpha1.ChaosResult{}
+	raw, err := c.restClient.
 		Get().
 		Namespace(c.ns).
-		Resource("chaosresults").
+		Resource(c.resource).
 		Name(name).
 		VersionedParams(&opts, scheme.ParameterCodec).
-		Do().
-		Into(&result)
+		DoRaw()
+	if err != nil {
+		return &result, err
+	}
 
+	err = json.Unmarshal(raw, &result)
 	return &result, err
 }
 
@@ -53,7 +68,7 @@ func (c *chaosResultClient) Create(chaosresult *v1alpha1.ChaosResult) (*v1alpha1
 	err := c.restClient.
 		Post().
 		Namespace(c.ns).
-		Resource("chaosresults").
+		Resource(c.resource).
 		Body(chaosresult).
 		Do().
 		Into(&result)
@@ -61,14 +76,56 @@ func (c *chaosResultClient) Create(chaosresult *v1alpha1.ChaosResult) (*v1alpha1
 	return &result, err
 }
 
-/*
-func (c *projectClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
-	opts.Watch = true
+func (c *chaosResultClient) Delete(name string, options *metav1.DeleteOptions) error {
 	return c.restClient.
+		Delete().
+		Namespace(c.ns).
+		Resource(c.resource).
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Watch watches the chaosresults, decoding the objects of the events as exporterV1alpha1.ChaosResult rather
+// than as the operator type, so that they carry the status
+func (c *chaosResultClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	stream, err := c.restClient.
 		Get().
 		Namespace(c.ns).
-		Resource("projects").
+		Resource(c.resource).
 		VersionedParams(&opts, scheme.ParameterCodec).
-		Watch()
+		Stream()
+	if err != nil {
+		return nil, err
+	}
+	return watch.NewStreamWatcher(&chaosResultDecoder{stream: stream, decoder: json.NewDecoder(stream)}), nil
+}
+
+// chaosResultDecoder decodes the watch events of chaosresults as plain JSON
+type chaosResultDecoder struct {
+	stream  io.ReadCloser
+	decoder *json.Decoder
+}
+
+// Decode returns the next event, its object a *exporterV1alpha1.ChaosResult or, for errors, a *metav1.Status
+func (d *chaosResultDecoder) Decode() (watch.EventType, runtime.Object, error) {
+	var event metav1.WatchEvent
+	if err := d.decoder.Decode(&event); err != nil {
+		return "", nil, err
+	}
+	var object runtime.Object = &exporterV1alpha1.ChaosResult{}
+	if watch.EventType(event.Type) == watch.Error {
+		object = &metav1.Status{}
+	}
+	if err := json.Unmarshal(event.Object.Raw, object); err != nil {
+		return "", nil, err
+	}
+	return watch.EventType(event.Type), object, nil
+}
+
+// Close closes the stream of the events
+func (d *chaosResultDecoder) Close() {
+	d.stream.Close()
 }
-*/
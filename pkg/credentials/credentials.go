@@ -0,0 +1,73 @@
+// Package credentials resolves the secrets (API keys, tokens, passwords) used by the
+// exporter's sinks without requiring them to be stored as long-lived Kubernetes Secrets.
+//
+// A credential is referenced by a string of the form:
+//
+//	file:/mnt/secrets-store/webhook-token    file mounted by the CSI secrets store driver
+//	vault:secret/data/chaos-exporter#token    key of a secret held in HashiCorp Vault
+//	env:WEBHOOK_TOKEN                         ENV of the exporter container
+//
+// Files & ENVs are re-read on every Get, Vault secrets once their lease (5m without one) has
+// expired, so rotated secrets are picked up without restarting the exporter.
+package credentials
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Provider returns the current value of a credential
+type Provider interface {
+	Get() (string, error)
+}
+
+// New returns the Provider for a credential reference
+func New(ref string) (Provider, error) {
+	kind := strings.SplitN(ref, ":", 2)
+	if len(kind) != 2 || kind[1] == "" {
+		return nil, fmt.Errorf("invalid credential reference %q, expected <file|vault|env>:<path>", ref)
+	}
+	switch kind[0] {
+	case "file":
+		return &FileProvider{Path: kind[1]}, nil
+	case "env":
+		return &EnvProvider{Name: kind[1]}, nil
+	case "vault":
+		path := strings.SplitN(kind[1], "#", 2)
+		if len(path) != 2 || path[1] == "" {
+			return nil, fmt.Errorf("invalid vault reference %q, expected vault:<secret-path>#<key>", ref)
+		}
+		return NewVaultProvider(DefaultVaultClient(), path[0], path[1]), nil
+	}
+	return nil, fmt.Errorf("unsupported credential source %q", kind[0])
+}
+
+// FileProvider reads the credential from a file, as mounted by the secrets store CSI driver
+type FileProvider struct {
+	Path string
+}
+
+// Get returns the trimmed contents of the file
+func (f *FileProvider) Get() (string, error) {
+	data, err := ioutil.ReadFile(f.Path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// EnvProvider reads the credential from an ENV
+type EnvProvider struct {
+	Name string
+}
+
+// Get returns the value of the ENV
+func (e *EnvProvider) Get() (string, error) {
+	value, ok := os.LookupEnv(e.Name)
+	if !ok {
+		return "", fmt.Errorf("ENV %s is not set", e.Name)
+	}
+	return value, nil
+}
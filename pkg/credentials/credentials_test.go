@@ -0,0 +1,99 @@
+package credentials
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestNew(t *testing.T) {
+	for ref, valid := range map[string]bool{
+		"file:/mnt/secrets/token":       true,
+		"env:TOKEN":                     true,
+		"vault:secret/data/chaos#token": true,
+		"vault:secret/data/chaos":       false,
+		"file:":                         false,
+		"s3:bucket/token":               false,
+		"token":                         false,
+	} {
+		if _, err := New(ref); (err == nil) != valid {
+			t.Errorf("New(%q) returned error %v, expected valid: %v", ref, err, valid)
+		}
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "token")
+	provider := &FileProvider{Path: path}
+	for _, value := range []string{"first", "rotated"} {
+		if err := ioutil.WriteFile(path, []byte(value+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := provider.Get(); err != nil || got != value {
+			t.Errorf("expected %q, got %q (err: %v)", value, got, err)
+		}
+	}
+}
+
+func TestVaultProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "static" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"errors":["permission denied"]}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"data":{"token":"s3cr3t"},"metadata":{"version":1}}}`)
+	}))
+	defer server.Close()
+
+	provider := NewVaultProvider(&VaultClient{Address: server.URL, Token: "static"}, "secret/data/chaos", "token")
+	if got, err := provider.Get(); err != nil || got != "s3cr3t" {
+		t.Errorf("expected s3cr3t, got %q (err: %v)", got, err)
+	}
+
+	denied := NewVaultProvider(&VaultClient{Address: server.URL, Token: "other"}, "secret/data/chaos", "token")
+	if _, err := denied.Get(); err == nil {
+		t.Error("expected an error for a rejected vault token")
+	}
+}
+
+func TestVaultProviderTTL(t *testing.T) {
+	reads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reads++
+		fmt.Fprint(w, `{"data":{"token":"s3cr3t"}}`)
+	}))
+	defer server.Close()
+
+	fakeClock := clock.NewFakeClock(time.Now())
+	provider := NewVaultProvider(&VaultClient{Address: server.URL, Token: "static", Clock: fakeClock}, "secret/chaos", "token")
+	for i := 0; i < 2; i++ {
+		if _, err := provider.Get(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if reads != 1 {
+		t.Errorf("expected the secret to be served from cache, got %d reads", reads)
+	}
+
+	fakeClock.Step(defaultSecretTTL)
+	if _, err := provider.Get(); err != nil {
+		t.Fatal(err)
+	}
+	if reads != 2 {
+		t.Errorf("expected the expired secret to be read again, got %d reads", reads)
+	}
+}
@@ -0,0 +1,238 @@
+package credentials
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// Path of the projected serviceaccount token used for the vault kubernetes auth method
+const serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// How long a secret read from vault is served from cache when vault does not provide a lease
+const defaultSecretTTL = 5 * time.Minute
+
+// VaultClient holds an authenticated session against a vault server. The token obtained
+// through the kubernetes auth method is renewed once half its TTL has elapsed, and the
+// client logs in again when renewal is no longer possible
+type VaultClient struct {
+	Address  string
+	AuthPath string
+	Role     string
+	// Token is a static vault token, used instead of the kubernetes auth method when set
+	Token string
+	// Clock drives the token & secret TTLs, defaults to the real clock
+	Clock clock.Clock
+
+	httpClient *http.Client
+	mu         sync.Mutex
+	token      string
+	renewAt    time.Time
+	expiry     time.Time
+	renewable  bool
+}
+
+var (
+	defaultClient     *VaultClient
+	defaultClientOnce sync.Once
+)
+
+// DefaultVaultClient returns a client configured from the standard VAULT_ADDR, VAULT_TOKEN,
+// VAULT_ROLE & VAULT_AUTH_PATH ENVs, shared by all vault credentials
+func DefaultVaultClient() *VaultClient {
+	defaultClientOnce.Do(func() {
+		authPath := os.Getenv("VAULT_AUTH_PATH")
+		if authPath == "" {
+			authPath = "kubernetes"
+		}
+		defaultClient = &VaultClient{
+			Address:  strings.TrimSuffix(os.Getenv("VAULT_ADDR"), "/"),
+			AuthPath: authPath,
+			Role:     os.Getenv("VAULT_ROLE"),
+			Token:    os.Getenv("VAULT_TOKEN"),
+		}
+	})
+	return defaultClient
+}
+
+// vaultResponse is the subset of the vault API response used by the exporter
+type vaultResponse struct {
+	Auth struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+	Data          map[string]interface{} `json:"data"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Errors        []string               `json:"errors"`
+}
+
+func (c *VaultClient) do(method, path, token string, body interface{}) (*vaultResponse, error) {
+	if c.Address == "" {
+		return nil, fmt.Errorf("vault address is not configured, set VAULT_ADDR")
+	}
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequest(method, c.Address+"/v1/"+strings.TrimPrefix(path, "/"), &payload)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &vaultResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil && resp.StatusCode < 300 {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("vault %s %s returned %d: %s", method, path, resp.StatusCode, strings.Join(result.Errors, ", "))
+	}
+	return result, nil
+}
+
+// login authenticates with the kubernetes auth method using the pod's serviceaccount token
+func (c *VaultClient) login() error {
+	jwt, err := ioutil.ReadFile(serviceAccountTokenPath)
+	if err != nil {
+		return fmt.Errorf("unable to read serviceaccount token for vault login: %v", err)
+	}
+	resp, err := c.do("POST", "auth/"+c.AuthPath+"/login", "", map[string]string{
+		"role": c.Role,
+		"jwt":  strings.TrimSpace(string(jwt)),
+	})
+	if err != nil {
+		return err
+	}
+	c.setToken(resp)
+	return nil
+}
+
+// renew extends the lease of the current token
+func (c *VaultClient) renew() error {
+	resp, err := c.do("POST", "auth/token/renew-self", c.token, nil)
+	if err != nil {
+		return err
+	}
+	c.setToken(resp)
+	return nil
+}
+
+func (c *VaultClient) setToken(resp *vaultResponse) {
+	c.token = resp.Auth.ClientToken
+	c.renewable = resp.Auth.Renewable
+	lease := time.Duration(resp.Auth.LeaseDuration) * time.Second
+	c.renewAt = c.now().Add(lease / 2)
+	c.expiry = c.now().Add(lease)
+}
+
+// now returns the current time of the client clock
+func (c *VaultClient) now() time.Time {
+	if c.Clock == nil {
+		return time.Now()
+	}
+	return c.Clock.Now()
+}
+
+// currentToken returns a valid token, renewing or re-acquiring it when past half its TTL
+func (c *VaultClient) currentToken() (string, error) {
+	if c.Token != "" {
+		return c.Token, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && c.now().Before(c.expiry) {
+		if c.now().Before(c.renewAt) {
+			return c.token, nil
+		}
+		if c.renewable && c.renew() == nil {
+			return c.token, nil
+		}
+	}
+	if err := c.login(); err != nil {
+		return "", err
+	}
+	return c.token, nil
+}
+
+// Read returns the data of the secret at path. The data of KV version 2 engines is unwrapped
+func (c *VaultClient) Read(path string) (map[string]interface{}, time.Duration, error) {
+	token, err := c.currentToken()
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := c.do("GET", path, token, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	data := resp.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = nested
+		}
+	}
+	return data, time.Duration(resp.LeaseDuration) * time.Second, nil
+}
+
+// VaultProvider returns a key of a vault secret, re-reading the secret when its lease expires
+type VaultProvider struct {
+	client *VaultClient
+	path   string
+	key    string
+
+	mu      sync.Mutex
+	value   string
+	expires time.Time
+}
+
+// NewVaultProvider returns a Provider for key of the secret at path
+func NewVaultProvider(client *VaultClient, path string, key string) *VaultProvider {
+	return &VaultProvider{client: client, path: path, key: key}
+}
+
+// Get returns the cached value of the secret key, refreshing it from vault once expired
+func (v *VaultProvider) Get() (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.value != "" && v.client.now().Before(v.expires) {
+		return v.value, nil
+	}
+	data, lease, err := v.client.Read(v.path)
+	if err != nil {
+		return "", err
+	}
+	value, ok := data[v.key].(string)
+	if !ok {
+		return "", fmt.Errorf("key %s not found in vault secret %s", v.key, v.path)
+	}
+	if lease <= 0 {
+		lease = defaultSecretTTL
+	}
+	v.value, v.expires = value, v.client.now().Add(lease)
+	return v.value, nil
+}
@@ -0,0 +1,135 @@
+// Package history keeps the daily pass & fail counts of the experiments observed by the exporter, to serve a
+// resilience heatmap (experiments × days) without querying Prometheus
+package history
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// Layout of the days in a Matrix, in UTC
+const dayLayout = "2006-01-02"
+
+// Cell holds the verdicts of an experiment on a day
+type Cell struct {
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+}
+
+// Row holds the verdicts of an experiment, one cell per day of the Matrix
+type Row struct {
+	Namespace  string `json:"namespace"`
+	Experiment string `json:"experiment"`
+	Cells      []Cell `json:"cells"`
+}
+
+// Matrix holds the verdicts of the experiments over consecutive days, ending with the current day
+type Matrix struct {
+	Days        []string `json:"days"`
+	Experiments []Row    `json:"experiments"`
+}
+
+type experiment struct {
+	namespace string
+	name      string
+}
+
+// Heatmap accumulates the verdicts of the experiments per day, for a bounded number of days
+type Heatmap struct {
+	mu        sync.Mutex
+	retention int
+	days      map[experiment]map[string]*Cell
+	clock     clock.Clock
+}
+
+// NewHeatmap returns an empty Heatmap retaining the verdicts of the given number of days
+func NewHeatmap(retention int) *Heatmap {
+	return &Heatmap{retention: retention, days: make(map[experiment]map[string]*Cell), clock: clock.RealClock{}}
+}
+
+// SetClock replaces the source of the current time, for e.g. to bucket verdicts by apiserver day
+func (h *Heatmap) SetClock(c clock.Clock) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clock = c
+}
+
+// Record counts a verdict of the experiment in the current day, dropping the days past the retention
+func (h *Heatmap) Record(namespace string, name string, passed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.clock.Now().UTC()
+	key := experiment{namespace: namespace, name: name}
+	if h.days[key] == nil {
+		h.days[key] = make(map[string]*Cell)
+	}
+	day := now.Format(dayLayout)
+	cell, ok := h.days[key][day]
+	if !ok {
+		cell = &Cell{}
+		h.days[key][day] = cell
+	}
+	if passed {
+		cell.Passed++
+	} else {
+		cell.Failed++
+	}
+	h.expire(now)
+}
+
+// Matrix returns the verdicts of the last given number of days, capped at the retention. Experiments
+// without verdicts in the range are omitted, the others are ordered by namespace & name
+func (h *Heatmap) Matrix(days int) Matrix {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if days <= 0 || days > h.retention {
+		days = h.retention
+	}
+	now := h.clock.Now().UTC()
+	matrix := Matrix{Days: make([]string, days), Experiments: []Row{}}
+	for i := range matrix.Days {
+		matrix.Days[i] = now.AddDate(0, 0, i-days+1).Format(dayLayout)
+	}
+
+	for key, counts := range h.days {
+		row := Row{Namespace: key.namespace, Experiment: key.name, Cells: make([]Cell, days)}
+		found := false
+		for i, day := range matrix.Days {
+			if cell, ok := counts[day]; ok {
+				row.Cells[i] = *cell
+				found = true
+			}
+		}
+		if found {
+			matrix.Experiments = append(matrix.Experiments, row)
+		}
+	}
+	sort.Slice(matrix.Experiments, func(i, j int) bool {
+		if matrix.Experiments[i].Namespace != matrix.Experiments[j].Namespace {
+			return matrix.Experiments[i].Namespace < matrix.Experiments[j].Namespace
+		}
+		return matrix.Experiments[i].Experiment < matrix.Experiments[j].Experiment
+	})
+	return matrix
+}
+
+// expire drops the days older than the retention, and the experiments left without any
+func (h *Heatmap) expire(now time.Time) {
+	oldest := now.AddDate(0, 0, -h.retention+1).Format(dayLayout)
+	for key, counts := range h.days {
+		for day := range counts {
+			// The layout sorts lexically in date order
+			if day < oldest {
+				delete(counts, day)
+			}
+		}
+		if len(counts) == 0 {
+			delete(h.days, key)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestHeatmap(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	heatmap := NewHeatmap(3)
+	heatmap.SetClock(fakeClock)
+
+	heatmap.Record("litmus", "pod-delete", true)
+	heatmap.Record("litmus", "pod-delete", false)
+	fakeClock.Step(24 * time.Hour)
+	heatmap.Record("litmus", "pod-delete", true)
+	heatmap.Record("litmus", "container-kill", false)
+
+	matrix := heatmap.Matrix(0)
+	if len(matrix.Days) != 3 || matrix.Days[0] != "2026-02-28" || matrix.Days[2] != "2026-03-02" {
+		t.Fatalf("unexpected days %v", matrix.Days)
+	}
+	if len(matrix.Experiments) != 2 || matrix.Experiments[0].Experiment != "container-kill" {
+		t.Fatalf("unexpected experiments %v", matrix.Experiments)
+	}
+	cells := matrix.Experiments[1].Cells
+	if cells[0] != (Cell{}) || cells[1] != (Cell{Passed: 1, Failed: 1}) || cells[2] != (Cell{Passed: 1}) {
+		t.Errorf("unexpected pod-delete cells %v", cells)
+	}
+
+	// The days past the retention are dropped on the next verdict
+	fakeClock.Step(3 * 24 * time.Hour)
+	heatmap.Record("litmus", "container-kill", true)
+	matrix = heatmap.Matrix(1)
+	if len(matrix.Days) != 1 || len(matrix.Experiments) != 1 || matrix.Experiments[0].Cells[0] != (Cell{Passed: 1}) {
+		t.Errorf("unexpected matrix %v", matrix)
+	}
+	if _, ok := heatmap.days[experiment{namespace: "litmus", name: "pod-delete"}]; ok {
+		t.Error("expected the expired pod-delete verdicts to be dropped")
+	}
+}
@@ -0,0 +1,132 @@
+// Package informers keeps in-memory caches of chaos resources up to date from a list & watch of the apiserver,
+// so that lookups are served from memory rather than by repeated requests
+package informers
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	exporterV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/apis/litmuschaos/v1alpha1"
+	"github.com/litmuschaos/chaos-exporter/pkg/cache"
+	clientV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/clientset/v1alpha1"
+	listers "github.com/litmuschaos/chaos-exporter/pkg/listers/v1alpha1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// Interval after which a failed list or watch is retried
+var retryInterval = 5 * time.Second
+
+// ChaosResultsGetter is the subset of the clientset used by the informer
+type ChaosResultsGetter interface {
+	ChaosResults(namespace string) clientV1alpha1.ChaosResultInterface
+}
+
+// ChaosResultInformer caches the chaosresults of a namespace, or of the cluster if the namespace is empty.
+// The cache is kept up to date by a watch, and relisted every resync period as a safety net against missed events
+type ChaosResultInformer struct {
+	client    ChaosResultsGetter
+	namespace string
+	resync    time.Duration
+	store     *cache.Store
+	synced    int32
+}
+
+// NewChaosResultInformer returns an informer of the chaosresults of namespace, relisted every resync period
+// (0 only lists on start & when the watch is lost)
+func NewChaosResultInformer(client ChaosResultsGetter, namespace string, resync time.Duration) *ChaosResultInformer {
+	return &ChaosResultInformer{client: client, namespace: namespace, resync: resync, store: cache.NewStore()}
+}
+
+// Lister returns a lister reading from the cache
+func (i *ChaosResultInformer) Lister() listers.ChaosResultLister {
+	return listers.NewChaosResultLister(i.store)
+}
+
+// HasSynced checks whether the cache has been filled by an initial list
+func (i *ChaosResultInformer) HasSynced() bool {
+	return atomic.LoadInt32(&i.synced) == 1
+}
+
+// Run keeps the cache up to date until stop is closed
+func (i *ChaosResultInformer) Run(stop <-chan struct{}) {
+	for {
+		err := i.listAndWatch(stop)
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		if err != nil {
+			log.Warnf("chaosresult informer of namespace %q: %v, retrying in %s", i.namespace, err, retryInterval)
+			select {
+			case <-stop:
+				return
+			case <-time.After(retryInterval):
+			}
+		}
+	}
+}
+
+// listAndWatch fills the cache & applies the watched changes to it, until the watch is lost, the resync period
+// has elapsed or stop is closed
+func (i *ChaosResultInformer) listAndWatch(stop <-chan struct{}) error {
+	list, err := i.client.ChaosResults(i.namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	objs := make([]interface{}, 0, len(list.Items))
+	for index := range list.Items {
+		objs = append(objs, &list.Items[index])
+	}
+	if err := i.store.Replace(objs); err != nil {
+		return err
+	}
+	atomic.StoreInt32(&i.synced, 1)
+
+	w, err := i.client.ChaosResults(i.namespace).Watch(metav1.ListOptions{ResourceVersion: list.ResourceVersion})
+	if err != nil {
+		return err
+	}
+	defer w.Stop()
+
+	var resync <-chan time.Time
+	if i.resync > 0 {
+		timer := time.NewTimer(i.resync)
+		defer timer.Stop()
+		resync = timer.C
+	}
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-resync:
+			return nil
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return nil
+			}
+			if err := i.apply(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// apply applies a watch event to the cache, its object decoded by the clientset as the exporter type
+func (i *ChaosResultInformer) apply(event watch.Event) error {
+	if event.Type == watch.Error {
+		return k8serrors.FromObject(event.Object)
+	}
+	result, ok := event.Object.(*exporterV1alpha1.ChaosResult)
+	if !ok {
+		return fmt.Errorf("unexpected object %T in the chaosresult watch", event.Object)
+	}
+	if event.Type == watch.Deleted {
+		return i.store.Delete(result)
+	}
+	return i.store.Add(result)
+}
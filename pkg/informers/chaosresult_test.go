@@ -0,0 +1,101 @@
+package informers
+
+import (
+	"testing"
+	"time"
+
+	exporterV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/apis/litmuschaos/v1alpha1"
+	clientV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/clientset/v1alpha1"
+	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// fakeChaosResults serves a fixed set of chaosresults & a fake watch
+type fakeChaosResults struct {
+	results map[string]*exporterV1alpha1.ChaosResult
+	watcher *watch.FakeWatcher
+}
+
+func (f *fakeChaosResults) ChaosResults(namespace string) clientV1alpha1.ChaosResultInterface {
+	return f
+}
+
+func (f *fakeChaosResults) List(opts metav1.ListOptions) (*exporterV1alpha1.ChaosResultList, error) {
+	list := &exporterV1alpha1.ChaosResultList{}
+	for _, result := range f.results {
+		list.Items = append(list.Items, *result)
+	}
+	return list, nil
+}
+
+func (f *fakeChaosResults) Get(name string, opts metav1.GetOptions) (*exporterV1alpha1.ChaosResult, error) {
+	if result, ok := f.results[name]; ok {
+		return result, nil
+	}
+	return nil, k8serrors.NewNotFound(v1alpha1.SchemeGroupVersion.WithResource("chaosresults").GroupResource(), name)
+}
+
+func (f *fakeChaosResults) Create(result *v1alpha1.ChaosResult) (*v1alpha1.ChaosResult, error) {
+	return result, nil
+}
+
+func (f *fakeChaosResults) Delete(name string, options *metav1.DeleteOptions) error {
+	return nil
+}
+
+func (f *fakeChaosResults) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	return f.watcher, nil
+}
+
+func newResult(name string, verdict string) *exporterV1alpha1.ChaosResult {
+	result := &exporterV1alpha1.ChaosResult{ObjectMeta: metav1.ObjectMeta{Namespace: "litmus", Name: name}}
+	result.Spec.ExperimentStatus.Verdict = verdict
+	return result
+}
+
+func TestChaosResultInformer(t *testing.T) {
+	client := &fakeChaosResults{
+		results: map[string]*exporterV1alpha1.ChaosResult{"engine-nginx-pod-delete": newResult("engine-nginx-pod-delete", "running")},
+		watcher: watch.NewFake(),
+	}
+	informer := NewChaosResultInformer(client, "litmus", 0)
+	stop := make(chan struct{})
+	defer close(stop)
+	go informer.Run(stop)
+
+	for !informer.HasSynced() {
+		time.Sleep(time.Millisecond)
+	}
+	lister := informer.Lister().ChaosResults("litmus")
+	if result, err := lister.Get("engine-nginx-pod-delete"); err != nil || result.Spec.ExperimentStatus.Verdict != "running" {
+		t.Fatalf("expected the listed chaosresult, got %v (err: %v)", result, err)
+	}
+
+	// The watched chaosresults are cached as decoded, without being read again
+	client.watcher.Modify(newResult("engine-nginx-pod-delete", "pass"))
+	client.watcher.Add(newResult("engine-redis-pod-delete", "fail"))
+	for {
+		if _, err := lister.Get("engine-redis-pod-delete"); err == nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if result, err := lister.Get("engine-nginx-pod-delete"); err != nil || result.Spec.ExperimentStatus.Verdict != "pass" {
+		t.Errorf("expected the modified chaosresult, got %v (err: %v)", result, err)
+	}
+
+	// The events are applied in order, the last one implies the previous ones were applied
+	client.watcher.Delete(newResult("engine-nginx-pod-delete", "pass"))
+	client.watcher.Add(newResult("engine-httpd-pod-delete", "pass"))
+	for {
+		if _, err := lister.Get("engine-httpd-pod-delete"); err == nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := lister.Get("engine-nginx-pod-delete"); !k8serrors.IsNotFound(err) {
+		t.Errorf("expected the deleted chaosresult to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+// Package leaderelection elects a single active exporter among replicas using a coordination.k8s.io Lease,
+// so that only the leader queries the apiserver for chaos metrics
+package leaderelection
+
+import (
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// LeaseClient is the subset of the Lease client used for the election
+type LeaseClient interface {
+	Get(name string, options metav1.GetOptions) (*coordinationv1.Lease, error)
+	Create(lease *coordinationv1.Lease) (*coordinationv1.Lease, error)
+	Update(lease *coordinationv1.Lease) (*coordinationv1.Lease, error)
+}
+
+// LeaderElector holds the settings of a Lease based election
+type LeaderElector struct {
+	Client   LeaseClient
+	Name     string
+	Identity string
+	// Duration after its last renewal that a lease held by another replica may be taken over
+	LeaseDuration time.Duration
+	// Interval at which the leader renews, and standbys try to acquire, the lease
+	RetryPeriod time.Duration
+	// Clock drives the renewals & lease expiry, defaults to the real clock
+	Clock clock.Clock
+
+	// Holds the time of the last successful renewal by this replica
+	renewed time.Time
+}
+
+// Run blocks until the lease is acquired and then calls onStartedLeading. The lease is renewed
+// for as long as possible, onStoppedLeading is called once it can no longer be renewed
+func (le *LeaderElector) Run(onStartedLeading func(), onStoppedLeading func()) {
+	if le.Clock == nil {
+		le.Clock = clock.RealClock{}
+	}
+
+	for !le.tryAcquireOrRenew() {
+		le.Clock.Sleep(le.RetryPeriod)
+	}
+	log.Infof("acquired lease %s as %s", le.Name, le.Identity)
+	go onStartedLeading()
+
+	for {
+		le.Clock.Sleep(le.RetryPeriod)
+		if le.tryAcquireOrRenew() {
+			continue
+		}
+		// Give up once the lease may have been taken over by another replica
+		if le.Clock.Since(le.renewed) >= le.LeaseDuration {
+			log.Warnf("failed to renew lease %s as %s", le.Name, le.Identity)
+			onStoppedLeading()
+			return
+		}
+	}
+}
+
+// tryAcquireOrRenew creates the lease, renews it when held by this replica, or takes it over
+// when it expired. It returns whether this replica holds the lease
+func (le *LeaderElector) tryAcquireOrRenew() bool {
+	now := metav1.NewMicroTime(le.Clock.Now())
+	duration := int32(le.LeaseDuration / time.Second)
+
+	lease, err := le.Client.Get(le.Name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: le.Name},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &le.Identity,
+				LeaseDurationSeconds: &duration,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		if _, err := le.Client.Create(lease); err != nil {
+			log.Debugf("unable to create lease %s: %v", le.Name, err)
+			return false
+		}
+		le.renewed = now.Time
+		return true
+	}
+	if err != nil {
+		log.Debugf("unable to get lease %s: %v", le.Name, err)
+		return false
+	}
+
+	holder := ""
+	if lease.Spec.HolderIdentity != nil {
+		holder = *lease.Spec.HolderIdentity
+	}
+	if holder != le.Identity && !expired(lease, now.Time) {
+		return false
+	}
+
+	if holder != le.Identity {
+		transitions := int32(1)
+		if lease.Spec.LeaseTransitions != nil {
+			transitions = *lease.Spec.LeaseTransitions + 1
+		}
+		lease.Spec.HolderIdentity = &le.Identity
+		lease.Spec.AcquireTime = &now
+		lease.Spec.LeaseTransitions = &transitions
+	}
+	lease.Spec.LeaseDurationSeconds = &duration
+	lease.Spec.RenewTime = &now
+
+	// The update is rejected if another replica modified the lease since it was read
+	if _, err := le.Client.Update(lease); err != nil {
+		log.Debugf("unable to update lease %s: %v", le.Name, err)
+		return false
+	}
+	le.renewed = now.Time
+	return true
+}
+
+// expired checks whether the holder of a lease failed to renew it within its duration
+func expired(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	return now.After(lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second))
+}
@@ -0,0 +1,63 @@
+package leaderelection
+
+import (
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// fakeLeases holds a single lease in memory
+type fakeLeases struct {
+	lease *coordinationv1.Lease
+}
+
+func (f *fakeLeases) Get(name string, options metav1.GetOptions) (*coordinationv1.Lease, error) {
+	if f.lease == nil {
+		return nil, k8serrors.NewNotFound(schema.GroupResource{Group: "coordination.k8s.io", Resource: "leases"}, name)
+	}
+	return f.lease.DeepCopy(), nil
+}
+
+func (f *fakeLeases) Create(lease *coordinationv1.Lease) (*coordinationv1.Lease, error) {
+	f.lease = lease.DeepCopy()
+	return lease, nil
+}
+
+func (f *fakeLeases) Update(lease *coordinationv1.Lease) (*coordinationv1.Lease, error) {
+	f.lease = lease.DeepCopy()
+	return lease, nil
+}
+
+func TestTryAcquireOrRenew(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	leases := &fakeLeases{}
+
+	first := &LeaderElector{Client: leases, Name: "chaos-exporter", Identity: "first", LeaseDuration: 15 * time.Second, Clock: fakeClock}
+	second := &LeaderElector{Client: leases, Name: "chaos-exporter", Identity: "second", LeaseDuration: 15 * time.Second, Clock: fakeClock}
+
+	if !first.tryAcquireOrRenew() {
+		t.Fatal("expected the first replica to create the lease")
+	}
+	if second.tryAcquireOrRenew() {
+		t.Fatal("expected the second replica to be denied a lease held by the first")
+	}
+	if !first.tryAcquireOrRenew() {
+		t.Fatal("expected the first replica to renew its lease")
+	}
+
+	fakeClock.Step(20 * time.Second)
+	if !second.tryAcquireOrRenew() {
+		t.Fatal("expected the second replica to take over the expired lease")
+	}
+	if got := *leases.lease.Spec.HolderIdentity; got != "second" {
+		t.Errorf("expected the lease to be held by second, got %s", got)
+	}
+	if got := *leases.lease.Spec.LeaseTransitions; got != 1 {
+		t.Errorf("expected a single lease transition, got %d", got)
+	}
+}
@@ -0,0 +1,69 @@
+// Package v1alpha1 holds the listers of the litmuschaos v1alpha1 resources, reading from an informer cache
+// rather than the apiserver
+package v1alpha1
+
+import (
+	"github.com/litmuschaos/chaos-exporter/pkg/cache"
+	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ChaosEngineLister lists chaosengines from the cache
+type ChaosEngineLister interface {
+	// List lists all the chaosengines matching selector
+	List(selector labels.Selector) ([]*v1alpha1.ChaosEngine, error)
+	// ChaosEngines returns the lister of the chaosengines of a namespace
+	ChaosEngines(namespace string) ChaosEngineNamespaceLister
+}
+
+// ChaosEngineNamespaceLister lists the chaosengines of a namespace from the cache
+type ChaosEngineNamespaceLister interface {
+	List(selector labels.Selector) ([]*v1alpha1.ChaosEngine, error)
+	Get(name string) (*v1alpha1.ChaosEngine, error)
+}
+
+type chaosEngineLister struct {
+	store *cache.Store
+}
+
+// NewChaosEngineLister returns a lister of the chaosengines held by store
+func NewChaosEngineLister(store *cache.Store) ChaosEngineLister {
+	return &chaosEngineLister{store: store}
+}
+
+func (l *chaosEngineLister) List(selector labels.Selector) ([]*v1alpha1.ChaosEngine, error) {
+	return l.ChaosEngines("").List(selector)
+}
+
+func (l *chaosEngineLister) ChaosEngines(namespace string) ChaosEngineNamespaceLister {
+	return &chaosEngineNamespaceLister{store: l.store, namespace: namespace}
+}
+
+type chaosEngineNamespaceLister struct {
+	store     *cache.Store
+	namespace string
+}
+
+// List lists the chaosengines of the namespace, or of all namespaces if it is empty
+func (l *chaosEngineNamespaceLister) List(selector labels.Selector) ([]*v1alpha1.ChaosEngine, error) {
+	var engines []*v1alpha1.ChaosEngine
+	for _, obj := range l.store.List() {
+		engine := obj.(*v1alpha1.ChaosEngine)
+		if l.namespace != "" && engine.Namespace != l.namespace {
+			continue
+		}
+		if selector.Matches(labels.Set(engine.Labels)) {
+			engines = append(engines, engine)
+		}
+	}
+	return engines, nil
+}
+
+func (l *chaosEngineNamespaceLister) Get(name string) (*v1alpha1.ChaosEngine, error) {
+	obj, ok := l.store.GetByKey(l.namespace + "/" + name)
+	if !ok {
+		return nil, k8serrors.NewNotFound(v1alpha1.SchemeGroupVersion.WithResource("chaosengines").GroupResource(), name)
+	}
+	return obj.(*v1alpha1.ChaosEngine), nil
+}
@@ -0,0 +1,68 @@
+package v1alpha1
+
+import (
+	exporterV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/apis/litmuschaos/v1alpha1"
+	"github.com/litmuschaos/chaos-exporter/pkg/cache"
+	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ChaosResultLister lists chaosresults from the cache
+type ChaosResultLister interface {
+	// List lists all the chaosresults matching selector
+	List(selector labels.Selector) ([]*exporterV1alpha1.ChaosResult, error)
+	// ChaosResults returns the lister of the chaosresults of a namespace
+	ChaosResults(namespace string) ChaosResultNamespaceLister
+}
+
+// ChaosResultNamespaceLister lists the chaosresults of a namespace from the cache
+type ChaosResultNamespaceLister interface {
+	List(selector labels.Selector) ([]*exporterV1alpha1.ChaosResult, error)
+	Get(name string) (*exporterV1alpha1.ChaosResult, error)
+}
+
+type chaosResultLister struct {
+	store *cache.Store
+}
+
+// NewChaosResultLister returns a lister of the chaosresults held by store
+func NewChaosResultLister(store *cache.Store) ChaosResultLister {
+	return &chaosResultLister{store: store}
+}
+
+func (l *chaosResultLister) List(selector labels.Selector) ([]*exporterV1alpha1.ChaosResult, error) {
+	return l.ChaosResults("").List(selector)
+}
+
+func (l *chaosResultLister) ChaosResults(namespace string) ChaosResultNamespaceLister {
+	return &chaosResultNamespaceLister{store: l.store, namespace: namespace}
+}
+
+type chaosResultNamespaceLister struct {
+	store     *cache.Store
+	namespace string
+}
+
+// List lists the chaosresults of the namespace, or of all namespaces if it is empty
+func (l *chaosResultNamespaceLister) List(selector labels.Selector) ([]*exporterV1alpha1.ChaosResult, error) {
+	var results []*exporterV1alpha1.ChaosResult
+	for _, obj := range l.store.List() {
+		result := obj.(*exporterV1alpha1.ChaosResult)
+		if l.namespace != "" && result.Namespace != l.namespace {
+			continue
+		}
+		if selector.Matches(labels.Set(result.Labels)) {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+func (l *chaosResultNamespaceLister) Get(name string) (*exporterV1alpha1.ChaosResult, error) {
+	obj, ok := l.store.GetByKey(l.namespace + "/" + name)
+	if !ok {
+		return nil, k8serrors.NewNotFound(v1alpha1.SchemeGroupVersion.WithResource("chaosresults").GroupResource(), name)
+	}
+	return obj.(*exporterV1alpha1.ChaosResult), nil
+}
@@ -0,0 +1,59 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	exporterV1alpha1 "github.com/litmuschaos/chaos-exporter/pkg/apis/litmuschaos/v1alpha1"
+	"github.com/litmuschaos/chaos-exporter/pkg/cache"
+	"github.com/litmuschaos/chaos-operator/pkg/apis/litmuschaos/v1alpha1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestChaosEngineLister(t *testing.T) {
+	store := cache.NewStore()
+	for _, engine := range []*v1alpha1.ChaosEngine{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "engine-nginx", Labels: map[string]string{"team": "payments"}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "engine-redis"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "orders", Name: "engine-nginx", Labels: map[string]string{"team": "orders"}}},
+	} {
+		if err := store.Add(engine); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lister := NewChaosEngineLister(store)
+
+	if engines, _ := lister.List(labels.Everything()); len(engines) != 3 {
+		t.Errorf("expected 3 chaosengines, got %d", len(engines))
+	}
+	if engines, _ := lister.ChaosEngines("payments").List(labels.Everything()); len(engines) != 2 {
+		t.Errorf("expected 2 chaosengines in payments, got %d", len(engines))
+	}
+	selector, _ := labels.Parse("team=orders")
+	if engines, _ := lister.List(selector); len(engines) != 1 || engines[0].Namespace != "orders" {
+		t.Errorf("expected the chaosengine of the orders team, got %v", engines)
+	}
+	if _, err := lister.ChaosEngines("orders").Get("engine-redis"); !k8serrors.IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestChaosResultLister(t *testing.T) {
+	store := cache.NewStore()
+	result := &exporterV1alpha1.ChaosResult{ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "engine-nginx-pod-delete"}}
+	if err := store.Replace([]interface{}{result}); err != nil {
+		t.Fatal(err)
+	}
+	lister := NewChaosResultLister(store)
+
+	if got, err := lister.ChaosResults("payments").Get("engine-nginx-pod-delete"); err != nil || got != result {
+		t.Errorf("expected the stored chaosresult, got %v (err: %v)", got, err)
+	}
+	if err := store.Delete(result); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lister.ChaosResults("payments").Get("engine-nginx-pod-delete"); !k8serrors.IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package sinks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AWSCredentials are the credentials the AWS requests are signed with
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Time the credentials expire at, zero for static credentials
+	Expiration time.Time
+}
+
+// AWSCredentialsProvider returns the current AWS credentials
+type AWSCredentialsProvider interface {
+	Credentials(ctx context.Context) (AWSCredentials, error)
+}
+
+// EnvAWSCredentials reads the credentials from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY & AWS_SESSION_TOKEN
+type EnvAWSCredentials struct{}
+
+// Credentials returns the credentials of the ENVs
+func (EnvAWSCredentials) Credentials(context.Context) (AWSCredentials, error) {
+	credentials := AWSCredentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+		return AWSCredentials{}, fmt.Errorf("AWS_ACCESS_KEY_ID & AWS_SECRET_ACCESS_KEY are not set")
+	}
+	return credentials, nil
+}
+
+// WebIdentityAWSCredentials assumes a role with the web identity token of the serviceaccount, as set up by IAM
+// roles for service accounts (IRSA) on EKS. The credentials are renewed 5 minutes before they expire
+type WebIdentityAWSCredentials struct {
+	RoleARN   string
+	TokenFile string
+	// URL of the STS endpoint, e.g. https://sts.eu-west-1.amazonaws.com
+	STSURL string
+	Client *http.Client
+
+	mu     sync.Mutex
+	cached AWSCredentials
+}
+
+// NewAWSCredentials returns the web identity credentials if AWS_ROLE_ARN & AWS_WEB_IDENTITY_TOKEN_FILE are set
+// (IRSA), the static credentials of the ENVs otherwise
+func NewAWSCredentials(region string, client *http.Client) AWSCredentialsProvider {
+	roleARN, tokenFile := os.Getenv("AWS_ROLE_ARN"), os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	if roleARN != "" && tokenFile != "" {
+		return &WebIdentityAWSCredentials{RoleARN: roleARN, TokenFile: tokenFile, STSURL: "https://sts." + region + ".amazonaws.com", Client: client}
+	}
+	return EnvAWSCredentials{}
+}
+
+// Credentials returns the cached credentials of the role, assuming it again if they are about to expire
+func (w *WebIdentityAWSCredentials) Credentials(ctx context.Context) (AWSCredentials, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cached.AccessKeyID != "" && time.Until(w.cached.Expiration) > 5*time.Minute {
+		return w.cached, nil
+	}
+
+	token, err := ioutil.ReadFile(w.TokenFile)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	query := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {w.RoleARN},
+		"RoleSessionName":  {"chaos-exporter"},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	request, err := http.NewRequest(http.MethodPost, w.STSURL, strings.NewReader(query.Encode()))
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return AWSCredentials{}, fmt.Errorf("unable to assume role %s: %s", w.RoleARN, response.Status)
+	}
+	var result struct {
+		Credentials struct {
+			AccessKeyID     string    `xml:"AccessKeyId"`
+			SecretAccessKey string    `xml:"SecretAccessKey"`
+			SessionToken    string    `xml:"SessionToken"`
+			Expiration      time.Time `xml:"Expiration"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	if err := xml.NewDecoder(response.Body).Decode(&result); err != nil {
+		return AWSCredentials{}, err
+	}
+	w.cached = AWSCredentials(result.Credentials)
+	return w.cached, nil
+}
+
+// signAWSRequest signs request, whose body is payload, with the AWS Signature Version 4 of service in region
+func signAWSRequest(request *http.Request, payload []byte, credentials AWSCredentials, region string, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	request.Header.Set("X-Amz-Date", amzDate)
+	if credentials.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name := range request.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(request.Header.Get(name))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := request.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		request.Method, path, request.URL.RawQuery, canonicalHeaders.String(), signedHeaders, sha256Hex(payload),
+	}, "\n")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), date)
+	for _, part := range []string{region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,247 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Resource of the Azure Monitor custom metrics API the tokens are requested for
+const azureMonitorResource = "https://monitoring.azure.com/"
+
+// AzureManagedIdentity requests tokens of the managed identity of the node from the instance metadata service
+// (IMDS), the user-assigned identity of ClientID if set, the system-assigned one otherwise
+type AzureManagedIdentity struct {
+	ClientID string
+	// URL of the token endpoint, http://169.254.169.254/metadata/identity/oauth2/token unless set
+	URL    string
+	Client *http.Client
+}
+
+// Token returns a token of the managed identity for Azure Monitor
+func (m *AzureManagedIdentity) Token() (*oauth2.Token, error) {
+	address := m.URL
+	if address == "" {
+		address = "http://169.254.169.254/metadata/identity/oauth2/token"
+	}
+	query := url.Values{"api-version": {"2018-02-01"}, "resource": {azureMonitorResource}}
+	if m.ClientID != "" {
+		query.Set("client_id", m.ClientID)
+	}
+	request, err := http.NewRequest(http.MethodGet, address+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Metadata", "true")
+	var result struct {
+		AccessToken string      `json:"access_token"`
+		ExpiresOn   json.Number `json:"expires_on"`
+	}
+	if err := azureTokenRequest(m.Client, request, &result); err != nil {
+		return nil, err
+	}
+	expiresOn, err := result.ExpiresOn.Int64()
+	if err != nil {
+		return nil, fmt.Errorf("invalid expiry %q", result.ExpiresOn)
+	}
+	return &oauth2.Token{AccessToken: result.AccessToken, TokenType: "Bearer", Expiry: time.Unix(expiresOn, 0)}, nil
+}
+
+// AzureWorkloadIdentity exchanges the federated token of the serviceaccount for a token of the application of
+// ClientID, as set up by Microsoft Entra Workload ID on AKS
+type AzureWorkloadIdentity struct {
+	// URL of the Entra ID authority, e.g. https://login.microsoftonline.com/
+	AuthorityHost string
+	TenantID      string
+	ClientID      string
+	// File of the federated token, read on every exchange as it is rotated
+	TokenFile string
+	Client    *http.Client
+}
+
+// Token returns a token of the application for Azure Monitor
+func (w *AzureWorkloadIdentity) Token() (*oauth2.Token, error) {
+	assertion, err := ioutil.ReadFile(w.TokenFile)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_id":             {w.ClientID},
+		"scope":                 {azureMonitorResource + ".default"},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {strings.TrimSpace(string(assertion))},
+	}
+	address := strings.TrimSuffix(w.AuthorityHost, "/") + "/" + url.PathEscape(w.TenantID) + "/oauth2/v2.0/token"
+	request, err := http.NewRequest(http.MethodPost, address, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := azureTokenRequest(w.Client, request, &result); err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{AccessToken: result.AccessToken, TokenType: "Bearer", Expiry: time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)}, nil
+}
+
+// azureTokenRequest sends a token request, decoding the response into result
+func azureTokenRequest(client *http.Client, request *http.Request, result interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		message, _ := ioutil.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("unable to get a token, unexpected status %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+// NewAzureTokenSource returns the tokens of the workload identity if AZURE_FEDERATED_TOKEN_FILE, AZURE_TENANT_ID
+// & AZURE_CLIENT_ID are set (Workload ID), of the managed identity otherwise (of AZURE_CLIENT_ID if set). The
+// tokens are cached until they are about to expire
+func NewAzureTokenSource(client *http.Client) oauth2.TokenSource {
+	tokenFile, tenantID, clientID := os.Getenv("AZURE_FEDERATED_TOKEN_FILE"), os.Getenv("AZURE_TENANT_ID"), os.Getenv("AZURE_CLIENT_ID")
+	if tokenFile != "" && tenantID != "" && clientID != "" {
+		authority := os.Getenv("AZURE_AUTHORITY_HOST")
+		if authority == "" {
+			authority = "https://login.microsoftonline.com/"
+		}
+		return oauth2.ReuseTokenSource(nil, &AzureWorkloadIdentity{AuthorityHost: authority, TenantID: tenantID, ClientID: clientID, TokenFile: tokenFile, Client: client})
+	}
+	return oauth2.ReuseTokenSource(nil, &AzureManagedIdentity{ClientID: clientID, Client: client})
+}
+
+// AzureMetric is a value of an Azure Monitor custom metric
+type AzureMetric struct {
+	Name       string
+	Dimensions []Tag
+	Value      float64
+}
+
+// AzureMonitor publishes custom metrics of an Azure resource, e.g. an AKS cluster, to Azure Monitor
+type AzureMonitor struct {
+	// Region of the resource, e.g. westeurope
+	Region string
+	// ID of the resource, e.g. /subscriptions/<id>/resourceGroups/<group>/providers/Microsoft.ContainerService/managedClusters/<name>
+	ResourceID string
+	// Namespace of the custom metrics
+	Namespace string
+	// URL of the API, https://<region>.monitoring.azure.com unless set
+	URL string
+	// Client authenticating the requests, e.g. with the tokens of NewAzureTokenSource
+	Client *http.Client
+}
+
+type azureMonitorRequest struct {
+	Time string `json:"time"`
+	Data struct {
+		BaseData struct {
+			Metric    string               `json:"metric"`
+			Namespace string               `json:"namespace"`
+			DimNames  []string             `json:"dimNames,omitempty"`
+			Series    []azureMonitorSeries `json:"series"`
+		} `json:"baseData"`
+	} `json:"data"`
+}
+
+type azureMonitorSeries struct {
+	DimValues []string `json:"dimValues,omitempty"`
+	Min       float64  `json:"min"`
+	Max       float64  `json:"max"`
+	Sum       float64  `json:"sum"`
+	Count     int      `json:"count"`
+}
+
+// Publish publishes metrics, observed at now, a request per metric name. A metric is dimensioned by the
+// dimensions of all its values, those a value lacks or leaves empty are set to none
+func (a *AzureMonitor) Publish(ctx context.Context, metrics []AzureMetric, now time.Time) error {
+	byName := make(map[string][]AzureMetric)
+	var names []string
+	for _, metric := range metrics {
+		if _, ok := byName[metric.Name]; !ok {
+			names = append(names, metric.Name)
+		}
+		byName[metric.Name] = append(byName[metric.Name], metric)
+	}
+	for _, name := range names {
+		if err := a.publish(ctx, name, byName[name], now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *AzureMonitor) publish(ctx context.Context, name string, metrics []AzureMetric, now time.Time) error {
+	request := azureMonitorRequest{Time: now.UTC().Format(time.RFC3339)}
+	request.Data.BaseData.Metric = name
+	request.Data.BaseData.Namespace = a.Namespace
+	dimensions := make(map[string]string)
+	for _, metric := range metrics {
+		for _, dimension := range metric.Dimensions {
+			dimensions[dimension.Name] = ""
+		}
+	}
+	request.Data.BaseData.DimNames = sortedKeys(dimensions)
+	for _, metric := range metrics {
+		values := make(map[string]string, len(metric.Dimensions))
+		for _, dimension := range metric.Dimensions {
+			values[dimension.Name] = dimension.Value
+		}
+		series := azureMonitorSeries{Min: metric.Value, Max: metric.Value, Sum: metric.Value, Count: 1}
+		for _, dimension := range request.Data.BaseData.DimNames {
+			value := values[dimension]
+			if value == "" {
+				value = "none"
+			}
+			series.DimValues = append(series.DimValues, value)
+		}
+		request.Data.BaseData.Series = append(request.Data.BaseData.Series, series)
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	base := a.URL
+	if base == "" {
+		base = "https://" + a.Region + ".monitoring.azure.com"
+	}
+	httpRequest, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(base, "/")+a.ResourceID+"/metrics", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(httpRequest.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestAzureMonitor(t *testing.T) {
+	var paths, authorizations []string
+	var requests []azureMonitorRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request azureMonitorRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		paths, authorizations, requests = append(paths, r.URL.Path), append(authorizations, r.Header.Get("Authorization")), append(requests, request)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &oauth2.Transport{Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "secret"}), Base: http.DefaultTransport}}
+	resourceID := "/subscriptions/0000/resourceGroups/chaos/providers/Microsoft.ContainerService/managedClusters/aks"
+	monitor := &AzureMonitor{Region: "westeurope", ResourceID: resourceID, Namespace: "LitmusChaos", URL: server.URL, Client: client}
+	metrics := []AzureMetric{
+		{Name: "c_engine_failed_experiments", Dimensions: []Tag{{Name: "engine_name", Value: "engine-nginx"}, {Name: "chaos_namespace", Value: "litmus"}}, Value: 1},
+		{Name: "c_engine_failed_experiments", Dimensions: []Tag{{Name: "engine_name", Value: "engine-redis"}}, Value: 0},
+		{Name: "c_engine_experiment_count", Value: 2},
+	}
+	if err := monitor.Publish(context.Background(), metrics, time.Unix(60, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 || paths[0] != resourceID+"/metrics" || authorizations[0] != "Bearer secret" {
+		t.Fatalf("unexpected requests %v, %v", paths, authorizations)
+	}
+	failed := requests[0].Data.BaseData
+	if failed.Metric != "c_engine_failed_experiments" || failed.Namespace != "LitmusChaos" || requests[0].Time != "1970-01-01T00:01:00Z" {
+		t.Errorf("unexpected request %+v", requests[0])
+	}
+	if fmt.Sprint(failed.DimNames) != "[chaos_namespace engine_name]" || fmt.Sprint(failed.Series[1].DimValues) != "[none engine-redis]" || failed.Series[0].Max != 1 || failed.Series[0].Count != 1 {
+		t.Errorf("unexpected series %+v of %v", failed.Series, failed.DimNames)
+	}
+	if count := requests[1].Data.BaseData; count.DimNames != nil || count.Series[0].Sum != 2 {
+		t.Errorf("unexpected dimensionless series %+v", count)
+	}
+}
+
+func TestAzureManagedIdentity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" || r.URL.Query().Get("resource") != "https://monitoring.azure.com/" || r.URL.Query().Get("client_id") != "identity" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"access_token":"token","expires_on":"3600","token_type":"Bearer"}`)
+	}))
+	defer server.Close()
+
+	token, err := (&AzureManagedIdentity{ClientID: "identity", URL: server.URL, Client: server.Client()}).Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "token" || !token.Expiry.Equal(time.Unix(3600, 0)) {
+		t.Errorf("unexpected token %+v", token)
+	}
+}
+
+func TestAzureWorkloadIdentity(t *testing.T) {
+	dir, err := ioutil.TempDir("", "azure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("federated\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if r.FormValue("client_assertion") != "federated" || r.FormValue("client_id") != "app" || r.FormValue("scope") != "https://monitoring.azure.com/.default" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"access_token":"token","expires_in":3600,"token_type":"Bearer"}`)
+	}))
+	defer server.Close()
+
+	identity := &AzureWorkloadIdentity{AuthorityHost: server.URL + "/", TenantID: "tenant", ClientID: "app", TokenFile: tokenFile, Client: server.Client()}
+	token, err := identity.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/tenant/oauth2/v2.0/token" || token.AccessToken != "token" || time.Until(token.Expiry) < 59*time.Minute {
+		t.Errorf("unexpected token %+v from %s", token, path)
+	}
+}
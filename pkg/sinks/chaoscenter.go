@@ -0,0 +1,105 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/credentials"
+)
+
+// ChaosCenter reports runs to the GraphQL API of a Litmus ChaosCenter, as the execution plane of a chaos
+// infrastructure does, so that they show in the Portal
+type ChaosCenter struct {
+	// URL of the GraphQL endpoint, e.g. http://litmusportal-server-service.litmus:9002/query
+	URL string
+	// ID & access key of the chaos infrastructure the runs are reported for
+	InfraID   string
+	AccessKey credentials.Provider
+	// Version of the reporting infrastructure
+	Version string
+	Client  *http.Client
+}
+
+// ChaosCenterRun is a run of an experiment reported to the ChaosCenter
+type ChaosCenterRun struct {
+	ExperimentID   string
+	ExperimentName string
+	RunID          string
+	Completed      bool
+	// Execution data of the run, JSON encoded in the request
+	ExecutionData interface{}
+}
+
+// chaosCenterRunMutation reports a run, see the ExperimentRunRequest of the ChaosCenter GraphQL schema
+const chaosCenterRunMutation = `mutation chaosExperimentRun($request: ExperimentRunRequest!) {
+  chaosExperimentRun(request: $request)
+}`
+
+// Report sends run to the ChaosCenter, failing on the errors of the GraphQL response
+func (c *ChaosCenter) Report(ctx context.Context, run ChaosCenterRun) error {
+	accessKey, err := c.AccessKey.Get()
+	if err != nil {
+		return fmt.Errorf("unable to read the access key: %v", err)
+	}
+	executionData, err := json.Marshal(run.ExecutionData)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"query": chaosCenterRunMutation,
+		"variables": map[string]interface{}{"request": map[string]interface{}{
+			"experimentID":    run.ExperimentID,
+			"experimentRunID": run.RunID,
+			"experimentName":  run.ExperimentName,
+			"executionData":   string(executionData),
+			"infraID":         map[string]string{"infraID": c.InfraID, "accessKey": accessKey, "version": c.Version},
+			"revisionID":      "",
+			"completed":       run.Completed,
+			"updatedBy":       "chaos-exporter",
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest(http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	// GraphQL reports the failures of a request in its errors, along with a 200
+	var result struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return fmt.Errorf("invalid response: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		messages := make([]string, 0, len(result.Errors))
+		for _, e := range result.Errors {
+			messages = append(messages, e.Message)
+		}
+		return fmt.Errorf("rejected: %s", strings.Join(messages, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChaosCenter(t *testing.T) {
+	var request struct {
+		Query     string `json:"query"`
+		Variables struct {
+			Request map[string]interface{} `json:"request"`
+		} `json:"variables"`
+	}
+	rejected := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&request)
+		if rejected {
+			fmt.Fprint(w, `{"errors":[{"message":"invalid access key"}],"data":null}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"chaosExperimentRun":"experiment run received"}}`)
+	}))
+	defer server.Close()
+
+	center := &ChaosCenter{URL: server.URL, InfraID: "infra-1", AccessKey: staticToken("secret"), Version: "1.2.0", Client: server.Client()}
+	run := ChaosCenterRun{ExperimentID: "litmus/engine-nginx", ExperimentName: "litmus/engine-nginx", RunID: "litmus-engine-nginx-60", Completed: true, ExecutionData: map[string]string{"phase": "Succeeded"}}
+	if err := center.Report(context.Background(), run); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(request.Query, "chaosExperimentRun(request: $request)") {
+		t.Errorf("unexpected query %s", request.Query)
+	}
+	infra := request.Variables.Request["infraID"].(map[string]interface{})
+	if infra["infraID"] != "infra-1" || infra["accessKey"] != "secret" || infra["version"] != "1.2.0" {
+		t.Errorf("unexpected infrastructure %v", infra)
+	}
+	if request.Variables.Request["executionData"] != `{"phase":"Succeeded"}` || request.Variables.Request["completed"] != true {
+		t.Errorf("unexpected request %v", request.Variables.Request)
+	}
+
+	rejected = true
+	if err := center.Report(context.Background(), run); err == nil || !strings.Contains(err.Error(), "invalid access key") {
+		t.Errorf("expected the GraphQL error, got %v", err)
+	}
+}
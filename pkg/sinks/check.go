@@ -0,0 +1,75 @@
+// Package sinks holds the helpers shared by the exporter's external sinks (webhooks, brokers, remote-write
+// endpoints), starting with the reachability check run for each of them at startup
+package sinks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Endpoint is the address of an external sink, either a URL or a host:port pair (e.g. a Kafka broker)
+type Endpoint struct {
+	// Name of the sink, e.g. webhook or kafka
+	Sink    string
+	Address string
+}
+
+// Check resolves the host of the endpoint and opens (then closes) a TCP connection to it, returning the
+// first error met. It does not send any payload, so it is safe to run against production sinks. The host of
+// udp:// endpoints (e.g. StatsD) is only resolved, UDP having no handshake to check the port with
+func Check(ctx context.Context, endpoint Endpoint, timeout time.Duration) error {
+	hostPort, err := hostPort(endpoint.Address)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	host, port, _ := net.SplitHostPort(hostPort)
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return fmt.Errorf("unable to resolve %s: %v", host, err)
+	}
+	if strings.HasPrefix(endpoint.Address, "udp://") {
+		return nil
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(addrs[0], port))
+	if err != nil {
+		return fmt.Errorf("unable to connect to %s: %v", hostPort, err)
+	}
+	return conn.Close()
+}
+
+// hostPort returns the host:port of an address, defaulting the port of URLs from their scheme
+func hostPort(address string) (string, error) {
+	if !strings.Contains(address, "://") {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return "", fmt.Errorf("invalid endpoint %q, expected a URL or host:port: %v", address, err)
+		}
+		return address, nil
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", err
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("invalid endpoint %q, missing host", address)
+	}
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		default:
+			return "", fmt.Errorf("invalid endpoint %q, missing port", address)
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port), nil
+}
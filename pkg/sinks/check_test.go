@@ -0,0 +1,46 @@
+package sinks
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	if err := Check(context.Background(), Endpoint{Sink: "webhook", Address: server.URL}, time.Second); err != nil {
+		t.Errorf("expected %s to be reachable: %v", server.URL, err)
+	}
+
+	// Grab a free port, then close the listener so that nothing is listening on it
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := listener.Addr().String()
+	listener.Close()
+	if err := Check(context.Background(), Endpoint{Sink: "kafka", Address: closed}, time.Second); err == nil {
+		t.Errorf("expected %s to be unreachable", closed)
+	}
+
+	if err := Check(context.Background(), Endpoint{Sink: "kafka", Address: "broker-without-port"}, time.Second); err == nil {
+		t.Error("expected an address without port to be rejected")
+	}
+}
+
+func TestHostPort(t *testing.T) {
+	tests := map[string]string{
+		"https://hooks.example.com/services/T000": "hooks.example.com:443",
+		"http://pushgateway:9091":                 "pushgateway:9091",
+		"kafka-0.kafka:9092":                      "kafka-0.kafka:9092",
+	}
+	for address, expected := range tests {
+		if got, err := hostPort(address); err != nil || got != expected {
+			t.Errorf("hostPort(%q) = %q, %v, expected %q", address, got, err, expected)
+		}
+	}
+}
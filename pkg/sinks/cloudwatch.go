@@ -0,0 +1,106 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Maximum number of datums of a PutMetricData request
+const cloudWatchBatchSize = 500
+
+// CloudWatchDatum is a value of a CloudWatch metric
+type CloudWatchDatum struct {
+	MetricName string
+	// Dimensions of the metric, in order
+	Dimensions []Tag
+	Value      float64
+	// CloudWatch unit, e.g. Count, Seconds or None
+	Unit string
+}
+
+// CloudWatch publishes metrics to AWS CloudWatch with PutMetricData
+type CloudWatch struct {
+	// Namespace the metrics are published to, e.g. LitmusChaos
+	Namespace   string
+	Region      string
+	Credentials AWSCredentialsProvider
+	// URL of the CloudWatch endpoint, https://monitoring.<region>.amazonaws.com unless set
+	URL    string
+	Client *http.Client
+}
+
+// PutMetricData publishes datums, observed at now, in batches
+func (c *CloudWatch) PutMetricData(ctx context.Context, datums []CloudWatchDatum, now time.Time) error {
+	for start := 0; start < len(datums); start += cloudWatchBatchSize {
+		end := start + cloudWatchBatchSize
+		if end > len(datums) {
+			end = len(datums)
+		}
+		if err := c.put(ctx, datums[start:end], now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *CloudWatch) put(ctx context.Context, datums []CloudWatchDatum, now time.Time) error {
+	form := url.Values{
+		"Action":    {"PutMetricData"},
+		"Version":   {"2010-08-01"},
+		"Namespace": {c.Namespace},
+	}
+	timestamp := now.UTC().Format(time.RFC3339)
+	for i, datum := range datums {
+		prefix := "MetricData.member." + strconv.Itoa(i+1) + "."
+		form.Set(prefix+"MetricName", datum.MetricName)
+		form.Set(prefix+"Value", strconv.FormatFloat(datum.Value, 'f', -1, 64))
+		form.Set(prefix+"Timestamp", timestamp)
+		if datum.Unit != "" {
+			form.Set(prefix+"Unit", datum.Unit)
+		}
+		for j, dimension := range datum.Dimensions {
+			dimensionPrefix := prefix + "Dimensions.member." + strconv.Itoa(j+1) + "."
+			form.Set(dimensionPrefix+"Name", dimension.Name)
+			form.Set(dimensionPrefix+"Value", dimension.Value)
+		}
+	}
+	payload := []byte(form.Encode())
+
+	endpoint := c.URL
+	if endpoint == "" {
+		endpoint = "https://monitoring." + c.Region + ".amazonaws.com"
+	}
+	request, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	credentials, err := c.Credentials.Credentials(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get the AWS credentials: %v", err)
+	}
+	signAWSRequest(request, payload, credentials, c.Region, "monitoring", now)
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
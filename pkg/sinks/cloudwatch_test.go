@@ -0,0 +1,68 @@
+package sinks
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// staticAWSCredentials returns the same credentials on every call
+type staticAWSCredentials AWSCredentials
+
+func (s staticAWSCredentials) Credentials(context.Context) (AWSCredentials, error) {
+	return AWSCredentials(s), nil
+}
+
+func TestSignAWSRequest(t *testing.T) {
+	// get-vanilla of the AWS Signature Version 4 test suite
+	request, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	credentials := AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signAWSRequest(request, nil, credentials, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if authorization := request.Header.Get("Authorization"); authorization != expected {
+		t.Errorf("expected %s, got %s", expected, authorization)
+	}
+}
+
+func TestCloudWatch(t *testing.T) {
+	var form url.Values
+	var authorization, token string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		form, _ = url.ParseQuery(string(body))
+		authorization, token = r.Header.Get("Authorization"), r.Header.Get("X-Amz-Security-Token")
+	}))
+	defer server.Close()
+
+	cloudWatch := &CloudWatch{
+		Namespace:   "LitmusChaos",
+		Region:      "eu-west-1",
+		Credentials: staticAWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session"},
+		URL:         server.URL,
+		Client:      server.Client(),
+	}
+	datums := []CloudWatchDatum{{
+		MetricName: "c_engine_failed_experiments",
+		Dimensions: []Tag{{Name: "engine_name", Value: "engine-nginx"}},
+		Value:      1,
+		Unit:       "Count",
+	}}
+	if err := cloudWatch.PutMetricData(context.Background(), datums, time.Unix(0, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if form.Get("Action") != "PutMetricData" || form.Get("Namespace") != "LitmusChaos" {
+		t.Errorf("unexpected request %v", form)
+	}
+	if form.Get("MetricData.member.1.MetricName") != "c_engine_failed_experiments" || form.Get("MetricData.member.1.Dimensions.member.1.Value") != "engine-nginx" {
+		t.Errorf("unexpected metric data %v", form)
+	}
+	if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKID/19700101/eu-west-1/monitoring/aws4_request") || token != "session" {
+		t.Errorf("unexpected signature %q, token %q", authorization, token)
+	}
+}
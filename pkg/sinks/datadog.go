@@ -0,0 +1,90 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/credentials"
+)
+
+// Datadog submits metrics & events to the Datadog API
+type Datadog struct {
+	// Base URL of the API of the Datadog site, e.g. https://api.datadoghq.eu
+	URL    string
+	APIKey credentials.Provider
+	Client *http.Client
+}
+
+// DatadogSeries is a metric submitted to Datadog, see /api/v1/series
+type DatadogSeries struct {
+	Metric string `json:"metric"`
+	// Pairs of a unix timestamp (seconds) & a value
+	Points [][2]float64 `json:"points"`
+	Type   string       `json:"type"`
+	Tags   []string     `json:"tags,omitempty"`
+}
+
+// DatadogEvent is an event posted to the Datadog event stream, see /api/v1/events
+type DatadogEvent struct {
+	Title string   `json:"title"`
+	Text  string   `json:"text"`
+	Tags  []string `json:"tags,omitempty"`
+	// error, warning, info or success
+	AlertType string `json:"alert_type"`
+	// Groups the events of the same subject, e.g. an experiment of an engine
+	AggregationKey string `json:"aggregation_key,omitempty"`
+	SourceType     string `json:"source_type_name,omitempty"`
+	DateHappened   int64  `json:"date_happened,omitempty"`
+}
+
+// SubmitSeries submits the points of series
+func (d *Datadog) SubmitSeries(ctx context.Context, series []DatadogSeries) error {
+	return d.post(ctx, "/api/v1/series", map[string][]DatadogSeries{"series": series})
+}
+
+// PostEvent posts event to the event stream
+func (d *Datadog) PostEvent(ctx context.Context, event DatadogEvent) error {
+	return d.post(ctx, "/api/v1/events", event)
+}
+
+func (d *Datadog) post(ctx context.Context, path string, payload interface{}) error {
+	apiKey, err := d.APIKey.Get()
+	if err != nil {
+		return fmt.Errorf("unable to read the API key: %v", err)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(d.URL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("DD-API-KEY", apiKey)
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// DatadogTag formats a name:value tag
+func DatadogTag(name string, value string) string {
+	return name + ":" + value
+}
@@ -0,0 +1,121 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Maximum number of time series of a timeSeries.create request
+const cloudMonitoringBatchSize = 200
+
+// CloudMonitoringResource is the monitored resource the time series are written for, e.g. a k8s_cluster
+type CloudMonitoringResource struct {
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels"`
+}
+
+// CloudMonitoringSeries is a point of a custom metric
+type CloudMonitoringSeries struct {
+	// Type of the metric, e.g. custom.googleapis.com/litmuschaos/c_engine_failed_experiments
+	MetricType string
+	Labels     map[string]string
+	Value      float64
+}
+
+// CloudMonitoring writes custom metrics to Google Cloud Monitoring
+type CloudMonitoring struct {
+	Project  string
+	Resource CloudMonitoringResource
+	// URL of the API, https://monitoring.googleapis.com unless set
+	URL string
+	// Client authenticating the requests, e.g. with the application default credentials
+	Client *http.Client
+}
+
+type cloudMonitoringRequest struct {
+	TimeSeries []cloudMonitoringTimeSeries `json:"timeSeries"`
+}
+
+type cloudMonitoringTimeSeries struct {
+	Metric struct {
+		Type   string            `json:"type"`
+		Labels map[string]string `json:"labels,omitempty"`
+	} `json:"metric"`
+	Resource   CloudMonitoringResource `json:"resource"`
+	MetricKind string                  `json:"metricKind"`
+	ValueType  string                  `json:"valueType"`
+	Points     []cloudMonitoringPoint  `json:"points"`
+}
+
+type cloudMonitoringPoint struct {
+	Interval struct {
+		EndTime string `json:"endTime"`
+	} `json:"interval"`
+	Value struct {
+		DoubleValue float64 `json:"doubleValue"`
+	} `json:"value"`
+}
+
+// WriteTimeSeries writes series as gauge points observed at now, in batches. The metric descriptors of the
+// custom metrics are created by Cloud Monitoring on their first write
+func (c *CloudMonitoring) WriteTimeSeries(ctx context.Context, series []CloudMonitoringSeries, now time.Time) error {
+	for start := 0; start < len(series); start += cloudMonitoringBatchSize {
+		end := start + cloudMonitoringBatchSize
+		if end > len(series) {
+			end = len(series)
+		}
+		if err := c.write(ctx, series[start:end], now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *CloudMonitoring) write(ctx context.Context, series []CloudMonitoringSeries, now time.Time) error {
+	request := cloudMonitoringRequest{}
+	for _, s := range series {
+		timeSeries := cloudMonitoringTimeSeries{Resource: c.Resource, MetricKind: "GAUGE", ValueType: "DOUBLE"}
+		timeSeries.Metric.Type = s.MetricType
+		timeSeries.Metric.Labels = s.Labels
+		point := cloudMonitoringPoint{}
+		point.Interval.EndTime = now.UTC().Format(time.RFC3339Nano)
+		point.Value.DoubleValue = s.Value
+		timeSeries.Points = []cloudMonitoringPoint{point}
+		request.TimeSeries = append(request.TimeSeries, timeSeries)
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	base := c.URL
+	if base == "" {
+		base = "https://monitoring.googleapis.com"
+	}
+	httpRequest, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(base, "/")+"/v3/projects/"+c.Project+"/timeSeries", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(httpRequest.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
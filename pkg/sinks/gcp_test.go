@@ -0,0 +1,50 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCloudMonitoring(t *testing.T) {
+	var path string
+	var received cloudMonitoringRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	cloudMonitoring := &CloudMonitoring{
+		Project:  "chaos-project",
+		Resource: CloudMonitoringResource{Type: "k8s_cluster", Labels: map[string]string{"project_id": "chaos-project", "location": "europe-west1", "cluster_name": "prod"}},
+		URL:      server.URL,
+		Client:   server.Client(),
+	}
+	series := make([]CloudMonitoringSeries, cloudMonitoringBatchSize+1)
+	for i := range series {
+		series[i] = CloudMonitoringSeries{MetricType: "custom.googleapis.com/litmuschaos/c_exp_pod_delete", Labels: map[string]string{"engine_name": "engine-nginx"}, Value: 3}
+	}
+	if err := cloudMonitoring.WriteTimeSeries(context.Background(), series, time.Unix(60, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/v3/projects/chaos-project/timeSeries" {
+		t.Errorf("unexpected path %s", path)
+	}
+	// The last batch holds the series left over
+	if len(received.TimeSeries) != 1 {
+		t.Fatalf("expected a last batch of 1 series, got %d", len(received.TimeSeries))
+	}
+	written := received.TimeSeries[0]
+	if written.Resource.Type != "k8s_cluster" || written.Metric.Labels["engine_name"] != "engine-nginx" || written.Points[0].Value.DoubleValue != 3 {
+		t.Errorf("unexpected time series %+v", written)
+	}
+	if written.Points[0].Interval.EndTime != "1970-01-01T00:01:00Z" {
+		t.Errorf("unexpected end time %s", written.Points[0].Interval.EndTime)
+	}
+}
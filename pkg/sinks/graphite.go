@@ -0,0 +1,112 @@
+package sinks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Graphite sends metric families to a carbon daemon in the plaintext protocol, over a connection per flush
+type Graphite struct {
+	// TCP host:port address of carbon, e.g. carbon.monitoring:2003
+	Address string
+	// Prefix of the metric paths, e.g. litmuschaos
+	Prefix  string
+	Timeout time.Duration
+}
+
+// Send sends the samples of families, observed at now
+func (g *Graphite) Send(ctx context.Context, families []*dto.MetricFamily, now time.Time) error {
+	lines := GraphiteLines(g.Prefix, families, now)
+	if len(lines) == 0 {
+		return nil
+	}
+	dialer := net.Dialer{Timeout: g.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", g.Address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if g.Timeout > 0 {
+		conn.SetWriteDeadline(time.Now().Add(g.Timeout))
+	}
+	if _, err := conn.Write([]byte(strings.Join(lines, "\n") + "\n")); err != nil {
+		return fmt.Errorf("unable to send to %s: %v", g.Address, err)
+	}
+	return nil
+}
+
+// GraphiteLines formats the samples of families in the plaintext protocol, with a timestamp in seconds. The path
+// of a sample is the prefix, the name of its family & the values of its labels in the order of their names, e.g.
+// litmuschaos.c_engine_experiment_count.litmus.engine-nginx; histograms & summaries are sent as their _sum,
+// _count & _bucket.<le> or quantile.<quantile> components. Samples not a number are left out
+func GraphiteLines(prefix string, families []*dto.MetricFamily, now time.Time) []string {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	var lines []string
+	add := func(path []string, value float64) {
+		if value != value {
+			return
+		}
+		if prefix != "" {
+			path = append([]string{prefix}, path...)
+		}
+		lines = append(lines, strings.Join(path, ".")+" "+strconv.FormatFloat(value, 'f', -1, 64)+" "+timestamp)
+	}
+	for _, family := range families {
+		name := graphiteEscape(family.GetName())
+		for _, metric := range family.Metric {
+			labels := append([]*dto.LabelPair(nil), metric.Label...)
+			sort.Slice(labels, func(i, j int) bool { return labels[i].GetName() < labels[j].GetName() })
+			values := make([]string, 0, len(labels))
+			for _, label := range labels {
+				values = append(values, graphiteEscape(label.GetValue()))
+			}
+			path := func(components ...string) []string {
+				return append(components, values...)
+			}
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				add(path(name), metric.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add(path(name), metric.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add(path(name), metric.GetUntyped().GetValue())
+			case dto.MetricType_HISTOGRAM:
+				add(path(name+"_sum"), metric.GetHistogram().GetSampleSum())
+				add(path(name+"_count"), float64(metric.GetHistogram().GetSampleCount()))
+				for _, bucket := range metric.GetHistogram().Bucket {
+					add(append(path(name+"_bucket"), graphiteBound(bucket.GetUpperBound())), float64(bucket.GetCumulativeCount()))
+				}
+			case dto.MetricType_SUMMARY:
+				add(path(name+"_sum"), metric.GetSummary().GetSampleSum())
+				add(path(name+"_count"), float64(metric.GetSummary().GetSampleCount()))
+				for _, quantile := range metric.GetSummary().Quantile {
+					add(append(path(name), graphiteBound(quantile.GetQuantile())), quantile.GetValue())
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// Replace the characters delimiting the paths & the fields of the plaintext protocol
+var graphiteReplacer = strings.NewReplacer(".", "_", " ", "_", "\t", "_", "\n", "_", "/", "_")
+
+// graphiteEscape returns s as a component of a metric path, empty values as none
+func graphiteEscape(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return graphiteReplacer.Replace(s)
+}
+
+// graphiteBound returns a bucket bound or quantile as a component of a metric path, e.g. 0_5 or inf
+func graphiteBound(bound float64) string {
+	return graphiteEscape(strings.TrimPrefix(strings.ToLower(strconv.FormatFloat(bound, 'f', -1, 64)), "+"))
+}
@@ -0,0 +1,79 @@
+package sinks
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestGraphite(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var lines []string
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		received <- lines
+	}()
+
+	families := []*dto.MetricFamily{
+		{
+			Name: proto.String("c_engine_experiment_count"),
+			Type: dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{{
+				Label: []*dto.LabelPair{
+					{Name: proto.String("engine_name"), Value: proto.String("engine.nginx")},
+					{Name: proto.String("chaos_namespace"), Value: proto.String("litmus")},
+					{Name: proto.String("app_uid"), Value: proto.String("")},
+				},
+				Gauge: &dto.Gauge{Value: proto.Float64(2)},
+			}},
+		},
+		{
+			Name: proto.String("litmuschaos_scrape_duration_seconds"),
+			Type: dto.MetricType_HISTOGRAM.Enum(),
+			Metric: []*dto.Metric{{
+				Histogram: &dto.Histogram{
+					SampleCount: proto.Uint64(3),
+					SampleSum:   proto.Float64(1.5),
+					Bucket:      []*dto.Bucket{{UpperBound: proto.Float64(0.5), CumulativeCount: proto.Uint64(2)}},
+				},
+			}},
+		},
+	}
+	graphite := &Graphite{Address: listener.Addr().String(), Prefix: "litmuschaos", Timeout: time.Second}
+	if err := graphite.Send(context.Background(), families, time.Unix(60, 0)); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"litmuschaos.c_engine_experiment_count.none.litmus.engine_nginx 2 60",
+		"litmuschaos.litmuschaos_scrape_duration_seconds_sum 1.5 60",
+		"litmuschaos.litmuschaos_scrape_duration_seconds_count 3 60",
+		"litmuschaos.litmuschaos_scrape_duration_seconds_bucket.0_5 2 60",
+	}
+	select {
+	case lines := <-received:
+		if !reflect.DeepEqual(lines, expected) {
+			t.Errorf("expected %q, got %q", expected, lines)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no lines received")
+	}
+}
@@ -0,0 +1,160 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/credentials"
+)
+
+// Incident is an alert opened in an incident management service
+type Incident struct {
+	// Deduplicates the incident, it is closed by the same key
+	Key     string
+	Summary string
+	// Source of the incident, e.g. the chaosengine
+	Source  string
+	Details map[string]string
+}
+
+// IncidentManager opens & closes incidents in an incident management service, e.g. PagerDuty or Opsgenie
+type IncidentManager interface {
+	Open(ctx context.Context, incident Incident) error
+	Close(ctx context.Context, key string) error
+}
+
+// PagerDuty triggers & resolves alerts with the Events API v2
+type PagerDuty struct {
+	// URL of the Events API, https://events.pagerduty.com unless set
+	URL string
+	// Integration key of the service the alerts are triggered on
+	RoutingKey credentials.Provider
+	Client     *http.Client
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Component     string            `json:"component,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// Open triggers a critical alert
+func (p *PagerDuty) Open(ctx context.Context, incident Incident) error {
+	return p.enqueue(ctx, pagerDutyEvent{EventAction: "trigger", DedupKey: incident.Key, Payload: &pagerDutyPayload{
+		Summary:       incident.Summary,
+		Source:        incident.Source,
+		Severity:      "critical",
+		Component:     "litmuschaos",
+		CustomDetails: incident.Details,
+	}})
+}
+
+// Close resolves the alert of key
+func (p *PagerDuty) Close(ctx context.Context, key string) error {
+	return p.enqueue(ctx, pagerDutyEvent{EventAction: "resolve", DedupKey: key})
+}
+
+func (p *PagerDuty) enqueue(ctx context.Context, event pagerDutyEvent) error {
+	routingKey, err := p.RoutingKey.Get()
+	if err != nil {
+		return fmt.Errorf("unable to read the routing key: %v", err)
+	}
+	event.RoutingKey = routingKey
+	base := p.URL
+	if base == "" {
+		base = "https://events.pagerduty.com"
+	}
+	return postIncidentJSON(ctx, p.Client, strings.TrimSuffix(base, "/")+"/v2/enqueue", nil, event)
+}
+
+// Opsgenie creates & closes alerts with the Alert API
+type Opsgenie struct {
+	// URL of the API, https://api.opsgenie.com unless set (https://api.eu.opsgenie.com for the EU instance)
+	URL    string
+	APIKey credentials.Provider
+	Client *http.Client
+}
+
+type opsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description,omitempty"`
+	Source      string            `json:"source,omitempty"`
+	Priority    string            `json:"priority"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+// Open creates a P2 alert, aliased by the key of the incident
+func (o *Opsgenie) Open(ctx context.Context, incident Incident) error {
+	return o.post(ctx, "/v2/alerts", opsgenieAlert{
+		Message:  incident.Summary,
+		Alias:    incident.Key,
+		Source:   incident.Source,
+		Priority: "P2",
+		Tags:     []string{"litmuschaos"},
+		Details:  incident.Details,
+	})
+}
+
+// Close closes the alert aliased key
+func (o *Opsgenie) Close(ctx context.Context, key string) error {
+	return o.post(ctx, "/v2/alerts/"+url.PathEscape(key)+"/close?identifierType=alias", map[string]string{"source": "chaos-exporter"})
+}
+
+func (o *Opsgenie) post(ctx context.Context, path string, payload interface{}) error {
+	apiKey, err := o.APIKey.Get()
+	if err != nil {
+		return fmt.Errorf("unable to read the API key: %v", err)
+	}
+	base := o.URL
+	if base == "" {
+		base = "https://api.opsgenie.com"
+	}
+	return postIncidentJSON(ctx, o.Client, strings.TrimSuffix(base, "/")+path, map[string]string{"Authorization": "GenieKey " + apiKey}, payload)
+}
+
+// postIncidentJSON posts payload as JSON to address with headers, failing on a non 2xx response
+func postIncidentJSON(ctx context.Context, client *http.Client, address string, headers map[string]string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest(http.MethodPost, address, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
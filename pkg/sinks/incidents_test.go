@@ -0,0 +1,57 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIncidentManagers(t *testing.T) {
+	var requests []string
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, r.URL.RequestURI()+" "+r.Header.Get("Authorization"))
+		bodies = append(bodies, body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	incident := Incident{Key: "litmuschaos/litmus/engine-nginx", Summary: "Chaos experiments failed", Source: "litmus/engine-nginx"}
+	managers := []IncidentManager{
+		&PagerDuty{URL: server.URL, RoutingKey: staticToken("routing"), Client: server.Client()},
+		&Opsgenie{URL: server.URL, APIKey: staticToken("secret"), Client: server.Client()},
+	}
+	for _, manager := range managers {
+		if err := manager.Open(context.Background(), incident); err != nil {
+			t.Fatal(err)
+		}
+		if err := manager.Close(context.Background(), incident.Key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{
+		"/v2/enqueue ",
+		"/v2/enqueue ",
+		"/v2/alerts GenieKey secret",
+		"/v2/alerts/litmuschaos%2Flitmus%2Fengine-nginx/close?identifierType=alias GenieKey secret",
+	}
+	for i, request := range expected {
+		if requests[i] != request {
+			t.Errorf("expected request %s, got %s", request, requests[i])
+		}
+	}
+	if bodies[0]["event_action"] != "trigger" || bodies[0]["dedup_key"] != incident.Key || bodies[0]["routing_key"] != "routing" {
+		t.Errorf("unexpected trigger %v", bodies[0])
+	}
+	if bodies[1]["event_action"] != "resolve" {
+		t.Errorf("unexpected resolve %v", bodies[1])
+	}
+	if bodies[2]["alias"] != incident.Key || bodies[2]["message"] != incident.Summary {
+		t.Errorf("unexpected alert %v", bodies[2])
+	}
+}
@@ -0,0 +1,107 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/credentials"
+)
+
+// InfluxPoint is a point of the InfluxDB line protocol
+type InfluxPoint struct {
+	Measurement string
+	// Tags of the point, sorted by name for the best write performance
+	Tags   []Tag
+	Fields map[string]float64
+}
+
+// InfluxDB writes points to a bucket of InfluxDB v2
+type InfluxDB struct {
+	// URL of the InfluxDB server, e.g. http://influxdb.monitoring:8086
+	URL    string
+	Org    string
+	Bucket string
+	Token  credentials.Provider
+	Client *http.Client
+}
+
+// Write writes points, observed at now, to the bucket
+func (i *InfluxDB) Write(ctx context.Context, points []InfluxPoint, now time.Time) error {
+	var body bytes.Buffer
+	for _, point := range points {
+		body.WriteString(InfluxLine(point, now))
+		body.WriteByte('\n')
+	}
+	query := url.Values{"org": {i.Org}, "bucket": {i.Bucket}, "precision": {"s"}}
+	request, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(i.URL, "/")+"/api/v2/write?"+query.Encode(), &body)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if i.Token != nil {
+		token, err := i.Token.Get()
+		if err != nil {
+			return fmt.Errorf("unable to read the token: %v", err)
+		}
+		request.Header.Set("Authorization", "Token "+token)
+	}
+	client := i.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// Escape the special characters of the measurements, and of the tag keys, tag values & field keys
+var (
+	influxMeasurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "\n", " ")
+	influxKeyEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `, "\n", " ")
+)
+
+// InfluxLine formats point in the line protocol, with a timestamp in seconds. Tags with an empty value are
+// left out, as the line protocol has no empty tag values
+func InfluxLine(point InfluxPoint, now time.Time) string {
+	var line strings.Builder
+	line.WriteString(influxMeasurementEscaper.Replace(point.Measurement))
+	for _, tag := range point.Tags {
+		if tag.Value == "" {
+			continue
+		}
+		line.WriteString("," + influxKeyEscaper.Replace(tag.Name) + "=" + influxKeyEscaper.Replace(tag.Value))
+	}
+	for i, name := range sortedFieldNames(point.Fields) {
+		separator := ","
+		if i == 0 {
+			separator = " "
+		}
+		line.WriteString(separator + influxKeyEscaper.Replace(name) + "=" + strconv.FormatFloat(point.Fields[name], 'f', -1, 64))
+	}
+	line.WriteString(" " + strconv.FormatInt(now.Unix(), 10))
+	return line.String()
+}
+
+// sortedFieldNames returns the names of fields in order
+func sortedFieldNames(fields map[string]float64) []string {
+	names := make(map[string]string, len(fields))
+	for name := range fields {
+		names[name] = ""
+	}
+	return sortedKeys(names)
+}
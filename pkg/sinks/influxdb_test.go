@@ -0,0 +1,52 @@
+package sinks
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/credentials"
+)
+
+func TestInfluxLine(t *testing.T) {
+	point := InfluxPoint{
+		Measurement: "c_exp_pod_delete",
+		Tags:        []Tag{{Name: "app_uid", Value: ""}, {Name: "engine_name", Value: "engine nginx"}, {Name: "openebs_version", Value: "1.0"}},
+		Fields:      map[string]float64{"value": 3},
+	}
+	expected := `c_exp_pod_delete,engine_name=engine\ nginx,openebs_version=1.0 value=3 60`
+	if line := InfluxLine(point, time.Unix(60, 0)); line != expected {
+		t.Errorf("expected %s, got %s", expected, line)
+	}
+}
+
+func TestInfluxDB(t *testing.T) {
+	var query, authorization, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		query, authorization, body = r.URL.RawQuery, r.Header.Get("Authorization"), string(data)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	influx := &InfluxDB{URL: server.URL, Org: "sre", Bucket: "chaos", Token: staticToken("secret"), Client: server.Client()}
+	points := []InfluxPoint{{Measurement: "c_engine_experiment_count", Fields: map[string]float64{"value": 2}}}
+	if err := influx.Write(context.Background(), points, time.Unix(60, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if query != "bucket=chaos&org=sre&precision=s" || authorization != "Token secret" || body != "c_engine_experiment_count value=2 60\n" {
+		t.Errorf("unexpected write %s, %s, %q", query, authorization, body)
+	}
+}
+
+// staticToken is a credential of a fixed value
+type staticToken string
+
+func (s staticToken) Get() (string, error) {
+	return string(s), nil
+}
+
+var _ credentials.Provider = staticToken("")
@@ -0,0 +1,88 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// KafkaRecord is a record produced to a Kafka topic
+type KafkaRecord struct {
+	// Key of the record, records of the same key are produced to the same partition
+	Key   string      `json:"key,omitempty"`
+	Value interface{} `json:"value"`
+}
+
+// KafkaRESTProxy produces records to a Kafka topic through the REST Proxy (v2 API), encoded as JSON or, with a
+// value schema, as Avro registered in the Schema Registry the proxy is configured with
+type KafkaRESTProxy struct {
+	// URL of the REST Proxy, e.g. http://kafka-rest.kafka:8082. Credentials in the URL are sent as basic auth
+	URL   string
+	Topic string
+	// Avro schema of the values, the records are encoded as JSON if empty
+	ValueSchema string
+	Client      *http.Client
+}
+
+type kafkaProduceRequest struct {
+	KeySchema   string        `json:"key_schema,omitempty"`
+	ValueSchema string        `json:"value_schema,omitempty"`
+	Records     []KafkaRecord `json:"records"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// Produce produces records to the topic, failing if any of them is not written
+func (k *KafkaRESTProxy) Produce(ctx context.Context, records []KafkaRecord) error {
+	request := kafkaProduceRequest{Records: records}
+	contentType := "application/vnd.kafka.json.v2+json"
+	if k.ValueSchema != "" {
+		contentType = "application/vnd.kafka.avro.v2+json"
+		request.KeySchema = `"string"`
+		request.ValueSchema = k.ValueSchema
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	httpRequest, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(k.URL, "/")+"/topics/"+url.PathEscape(k.Topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", contentType)
+	httpRequest.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	client := k.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(httpRequest.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	var produced kafkaProduceResponse
+	if err := json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(&produced); err != nil {
+		return fmt.Errorf("invalid response: %v", err)
+	}
+	for _, offset := range produced.Offsets {
+		if offset.ErrorCode != nil || offset.Error != "" {
+			return fmt.Errorf("unable to produce to %s: %s", k.Topic, offset.Error)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestKafkaRESTProxy(t *testing.T) {
+	var path, contentType string
+	var request kafkaProduceRequest
+	response := `{"offsets":[{"partition":0,"offset":1}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	proxy := &KafkaRESTProxy{URL: server.URL, Topic: "litmuschaos-verdicts", Client: server.Client()}
+	records := []KafkaRecord{{Key: "litmus/engine-nginx/pod-delete", Value: map[string]string{"to": "fail"}}}
+	if err := proxy.Produce(context.Background(), records); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/topics/litmuschaos-verdicts" || contentType != "application/vnd.kafka.json.v2+json" || request.ValueSchema != "" {
+		t.Errorf("unexpected JSON request to %s as %s: %+v", path, contentType, request)
+	}
+	if len(request.Records) != 1 || request.Records[0].Key != "litmus/engine-nginx/pod-delete" {
+		t.Errorf("unexpected records %+v", request.Records)
+	}
+
+	proxy.ValueSchema = `{"type":"record","name":"VerdictEvent","fields":[{"name":"to","type":"string"}]}`
+	if err := proxy.Produce(context.Background(), records); err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "application/vnd.kafka.avro.v2+json" || request.KeySchema != `"string"` || request.ValueSchema != proxy.ValueSchema {
+		t.Errorf("unexpected Avro request as %s: %+v", contentType, request)
+	}
+
+	response = `{"offsets":[{"partition":null,"offset":null,"error_code":40403,"error":"Schema not found"}]}`
+	if err := proxy.Produce(context.Background(), records); err == nil {
+		t.Error("expected an error when a record is not produced")
+	}
+}
@@ -0,0 +1,103 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/litmuschaos/chaos-exporter/pkg/credentials"
+)
+
+// Endpoints of the Metric API, by the region of the account
+var newRelicEndpoints = map[string]string{
+	"us": "https://metric-api.newrelic.com/metric/v1",
+	"eu": "https://metric-api.eu.newrelic.com/metric/v1",
+}
+
+// NewRelicMetric is a gauge of the New Relic Metric API
+type NewRelicMetric struct {
+	Name       string
+	Value      float64
+	Attributes map[string]string
+}
+
+// NewRelic sends dimensional metrics to the New Relic Metric API
+type NewRelic struct {
+	// Region of the account, us or eu
+	Region string
+	// URL of the Metric API, that of the region unless set
+	URL        string
+	LicenseKey credentials.Provider
+	// Attributes of every metric, e.g. those linking them to the entity of the cluster
+	CommonAttributes map[string]string
+	Client           *http.Client
+}
+
+// NewRelicEndpoint returns the Metric API endpoint of region, empty if the region is unknown
+func NewRelicEndpoint(region string) string {
+	return newRelicEndpoints[region]
+}
+
+type newRelicPayload struct {
+	Common struct {
+		Timestamp  int64             `json:"timestamp"`
+		Attributes map[string]string `json:"attributes,omitempty"`
+	} `json:"common"`
+	Metrics []newRelicMetric `json:"metrics"`
+}
+
+type newRelicMetric struct {
+	Name       string            `json:"name"`
+	Type       string            `json:"type"`
+	Value      float64           `json:"value"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Send sends metrics as gauges observed at now
+func (n *NewRelic) Send(ctx context.Context, metrics []NewRelicMetric, now time.Time) error {
+	licenseKey, err := n.LicenseKey.Get()
+	if err != nil {
+		return fmt.Errorf("unable to read the license key: %v", err)
+	}
+	payload := newRelicPayload{}
+	payload.Common.Timestamp = now.UnixNano() / int64(time.Millisecond)
+	payload.Common.Attributes = n.CommonAttributes
+	for _, metric := range metrics {
+		payload.Metrics = append(payload.Metrics, newRelicMetric{Name: metric.Name, Type: "gauge", Value: metric.Value, Attributes: metric.Attributes})
+	}
+	body, err := json.Marshal([]newRelicPayload{payload})
+	if err != nil {
+		return err
+	}
+
+	address := n.URL
+	if address == "" {
+		address = NewRelicEndpoint(n.Region)
+	}
+	request, err := http.NewRequest(http.MethodPost, address, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Api-Key", licenseKey)
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewRelic(t *testing.T) {
+	var apiKey string
+	var payloads []newRelicPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey = r.Header.Get("Api-Key")
+		json.NewDecoder(r.Body).Decode(&payloads)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	newRelic := &NewRelic{URL: server.URL, LicenseKey: staticToken("secret"), CommonAttributes: map[string]string{"k8s.cluster.name": "prod"}, Client: server.Client()}
+	metrics := []NewRelicMetric{{Name: "c_engine_failed_experiments", Value: 1, Attributes: map[string]string{"engine_name": "engine-nginx"}}}
+	if err := newRelic.Send(context.Background(), metrics, time.Unix(60, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if apiKey != "secret" || len(payloads) != 1 || payloads[0].Common.Timestamp != 60000 || payloads[0].Common.Attributes["k8s.cluster.name"] != "prod" {
+		t.Fatalf("unexpected request with the key %q: %+v", apiKey, payloads)
+	}
+	if metric := payloads[0].Metrics[0]; metric.Type != "gauge" || metric.Value != 1 || metric.Attributes["engine_name"] != "engine-nginx" {
+		t.Errorf("unexpected metric %+v", metric)
+	}
+	if NewRelicEndpoint("eu") != "https://metric-api.eu.newrelic.com/metric/v1" || NewRelicEndpoint("apac") != "" {
+		t.Error("unexpected endpoints of the regions")
+	}
+}
@@ -0,0 +1,340 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// OTLPExporter exports metric families to an OpenTelemetry Collector over OTLP/HTTP, protobuf or JSON encoded
+type OTLPExporter struct {
+	// URL the metrics are posted to, e.g. http://otel-collector:4318/v1/metrics
+	Endpoint string
+	Headers  map[string]string
+	// Attributes of the resource the metrics are reported for, e.g. service.name
+	Resource map[string]string
+	// Start of the cumulative sums & histograms, i.e. the start of the exporter
+	Start time.Time
+	// Protobuf encodes the requests in the binary protobuf encoding (http/protobuf), in JSON (http/json) otherwise
+	Protobuf bool
+	Client   *http.Client
+}
+
+// OTLP/JSON messages, see opentelemetry-proto. 64 bit integers are encoded as strings
+type (
+	otlpRequest struct {
+		ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+	}
+	otlpResourceMetrics struct {
+		Resource     otlpResource       `json:"resource"`
+		ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeMetrics struct {
+		Scope   otlpScope    `json:"scope"`
+		Metrics []otlpMetric `json:"metrics"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpMetric struct {
+		Name        string         `json:"name"`
+		Description string         `json:"description,omitempty"`
+		Gauge       *otlpGauge     `json:"gauge,omitempty"`
+		Sum         *otlpSum       `json:"sum,omitempty"`
+		Histogram   *otlpHistogram `json:"histogram,omitempty"`
+	}
+	otlpGauge struct {
+		DataPoints []otlpNumberDataPoint `json:"dataPoints"`
+	}
+	otlpSum struct {
+		DataPoints             []otlpNumberDataPoint `json:"dataPoints"`
+		AggregationTemporality int                   `json:"aggregationTemporality"`
+		IsMonotonic            bool                  `json:"isMonotonic"`
+	}
+	otlpHistogram struct {
+		DataPoints             []otlpHistogramDataPoint `json:"dataPoints"`
+		AggregationTemporality int                      `json:"aggregationTemporality"`
+	}
+	otlpNumberDataPoint struct {
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano,omitempty"`
+		TimeUnixNano      string          `json:"timeUnixNano"`
+		AsDouble          float64         `json:"asDouble"`
+	}
+	otlpHistogramDataPoint struct {
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		TimeUnixNano      string          `json:"timeUnixNano"`
+		Count             string          `json:"count"`
+		Sum               float64         `json:"sum"`
+		BucketCounts      []string        `json:"bucketCounts"`
+		ExplicitBounds    []float64       `json:"explicitBounds"`
+	}
+	otlpAttribute struct {
+		Key   string        `json:"key"`
+		Value otlpAttrValue `json:"value"`
+	}
+	otlpAttrValue struct {
+		StringValue string `json:"stringValue"`
+	}
+)
+
+// AGGREGATION_TEMPORALITY_CUMULATIVE, the temporality of the Prometheus counters & histograms
+const otlpCumulative = 2
+
+// Export posts families, observed at now, to the collector. Summaries are not exported, the exporter has none
+func (e *OTLPExporter) Export(ctx context.Context, families []*dto.MetricFamily, now time.Time) error {
+	var body []byte
+	contentType := "application/x-protobuf"
+	if e.Protobuf {
+		body = otlpProtobuf(e.request(families, now))
+	} else {
+		var err error
+		if body, err = json.Marshal(e.request(families, now)); err != nil {
+			return err
+		}
+		contentType = "application/json"
+	}
+	request, err := http.NewRequest(http.MethodPost, e.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", contentType)
+	for name, value := range e.Headers {
+		request.Header.Set(name, value)
+	}
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// request converts families to an OTLP export request
+func (e *OTLPExporter) request(families []*dto.MetricFamily, now time.Time) otlpRequest {
+	timestamp := strconv.FormatInt(now.UnixNano(), 10)
+	start := strconv.FormatInt(e.Start.UnixNano(), 10)
+
+	var metrics []otlpMetric
+	for _, family := range families {
+		metric := otlpMetric{Name: family.GetName(), Description: family.GetHelp()}
+		switch family.GetType() {
+		case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+			metric.Gauge = &otlpGauge{}
+			for _, m := range family.Metric {
+				value := m.GetGauge().GetValue()
+				if family.GetType() == dto.MetricType_UNTYPED {
+					value = m.GetUntyped().GetValue()
+				}
+				metric.Gauge.DataPoints = append(metric.Gauge.DataPoints, otlpNumberDataPoint{
+					Attributes: otlpLabels(m.Label), TimeUnixNano: timestamp, AsDouble: value,
+				})
+			}
+		case dto.MetricType_COUNTER:
+			metric.Sum = &otlpSum{AggregationTemporality: otlpCumulative, IsMonotonic: true}
+			for _, m := range family.Metric {
+				metric.Sum.DataPoints = append(metric.Sum.DataPoints, otlpNumberDataPoint{
+					Attributes: otlpLabels(m.Label), StartTimeUnixNano: start, TimeUnixNano: timestamp, AsDouble: m.GetCounter().GetValue(),
+				})
+			}
+		case dto.MetricType_HISTOGRAM:
+			metric.Histogram = &otlpHistogram{AggregationTemporality: otlpCumulative}
+			for _, m := range family.Metric {
+				metric.Histogram.DataPoints = append(metric.Histogram.DataPoints, otlpHistogramPoint(m, start, timestamp))
+			}
+		default:
+			continue
+		}
+		metrics = append(metrics, metric)
+	}
+
+	var resource []otlpAttribute
+	for _, key := range sortedKeys(e.Resource) {
+		resource = append(resource, otlpAttribute{Key: key, Value: otlpAttrValue{StringValue: e.Resource[key]}})
+	}
+	return otlpRequest{ResourceMetrics: []otlpResourceMetrics{{
+		Resource:     otlpResource{Attributes: resource},
+		ScopeMetrics: []otlpScopeMetrics{{Scope: otlpScope{Name: "chaos-exporter"}, Metrics: metrics}},
+	}}}
+}
+
+// otlpHistogramPoint converts the cumulative buckets of a Prometheus histogram to the per bucket counts of OTLP,
+// the last one counting the observations above the highest bound
+func otlpHistogramPoint(m *dto.Metric, start string, timestamp string) otlpHistogramDataPoint {
+	histogram := m.GetHistogram()
+	point := otlpHistogramDataPoint{
+		Attributes:        otlpLabels(m.Label),
+		StartTimeUnixNano: start,
+		TimeUnixNano:      timestamp,
+		Count:             strconv.FormatUint(histogram.GetSampleCount(), 10),
+		Sum:               histogram.GetSampleSum(),
+	}
+	var previous uint64
+	for _, bucket := range histogram.Bucket {
+		point.ExplicitBounds = append(point.ExplicitBounds, bucket.GetUpperBound())
+		point.BucketCounts = append(point.BucketCounts, strconv.FormatUint(bucket.GetCumulativeCount()-previous, 10))
+		previous = bucket.GetCumulativeCount()
+	}
+	point.BucketCounts = append(point.BucketCounts, strconv.FormatUint(histogram.GetSampleCount()-previous, 10))
+	return point
+}
+
+// otlpLabels converts the labels of a series to attributes
+func otlpLabels(labels []*dto.LabelPair) []otlpAttribute {
+	attributes := make([]otlpAttribute, 0, len(labels))
+	for _, label := range labels {
+		attributes = append(attributes, otlpAttribute{Key: label.GetName(), Value: otlpAttrValue{StringValue: label.GetValue()}})
+	}
+	return attributes
+}
+
+// otlpProtobuf returns the protobuf encoded ExportMetricsServiceRequest of request, see opentelemetry-proto. The
+// fields of a oneof, the values of the data points & attributes, are encoded even if zero to keep their presence
+func otlpProtobuf(request otlpRequest) []byte {
+	body := proto.NewBuffer(nil)
+	for _, resourceMetrics := range request.ResourceMetrics {
+		message := proto.NewBuffer(nil)
+		resource := proto.NewBuffer(nil)
+		otlpProtoAttributes(resource, 1, resourceMetrics.Resource.Attributes)
+		protoMessage(message, 1, resource)
+		for _, scopeMetrics := range resourceMetrics.ScopeMetrics {
+			scoped := proto.NewBuffer(nil)
+			scope := proto.NewBuffer(nil)
+			protoString(scope, 1, scopeMetrics.Scope.Name)
+			protoMessage(scoped, 1, scope)
+			for _, metric := range scopeMetrics.Metrics {
+				protoMessage(scoped, 2, otlpProtoMetric(metric))
+			}
+			protoMessage(message, 2, scoped)
+		}
+		protoMessage(body, 1, message)
+	}
+	return body.Bytes()
+}
+
+// otlpProtoMetric returns the protobuf encoded Metric of metric
+func otlpProtoMetric(metric otlpMetric) *proto.Buffer {
+	message := proto.NewBuffer(nil)
+	protoString(message, 1, metric.Name)
+	protoString(message, 2, metric.Description)
+	switch {
+	case metric.Gauge != nil:
+		gauge := proto.NewBuffer(nil)
+		for _, point := range metric.Gauge.DataPoints {
+			protoMessage(gauge, 1, otlpProtoNumberPoint(point))
+		}
+		protoMessage(message, 5, gauge)
+	case metric.Sum != nil:
+		sum := proto.NewBuffer(nil)
+		for _, point := range metric.Sum.DataPoints {
+			protoMessage(sum, 1, otlpProtoNumberPoint(point))
+		}
+		sum.EncodeVarint(2<<3 | proto.WireVarint)
+		sum.EncodeVarint(uint64(metric.Sum.AggregationTemporality))
+		if metric.Sum.IsMonotonic {
+			sum.EncodeVarint(3<<3 | proto.WireVarint)
+			sum.EncodeVarint(1)
+		}
+		protoMessage(message, 7, sum)
+	case metric.Histogram != nil:
+		histogram := proto.NewBuffer(nil)
+		for _, point := range metric.Histogram.DataPoints {
+			protoMessage(histogram, 1, otlpProtoHistogramPoint(point))
+		}
+		histogram.EncodeVarint(2<<3 | proto.WireVarint)
+		histogram.EncodeVarint(uint64(metric.Histogram.AggregationTemporality))
+		protoMessage(message, 9, histogram)
+	}
+	return message
+}
+
+// otlpProtoNumberPoint returns the protobuf encoded NumberDataPoint of point
+func otlpProtoNumberPoint(point otlpNumberDataPoint) *proto.Buffer {
+	message := proto.NewBuffer(nil)
+	otlpProtoTimes(message, point.StartTimeUnixNano, point.TimeUnixNano)
+	protoFixed64(message, 4, math.Float64bits(point.AsDouble))
+	otlpProtoAttributes(message, 7, point.Attributes)
+	return message
+}
+
+// otlpProtoHistogramPoint returns the protobuf encoded HistogramDataPoint of point, its bucket counts & bounds
+// packed
+func otlpProtoHistogramPoint(point otlpHistogramDataPoint) *proto.Buffer {
+	message := proto.NewBuffer(nil)
+	otlpProtoTimes(message, point.StartTimeUnixNano, point.TimeUnixNano)
+	protoFixed64(message, 4, otlpUint(point.Count))
+	protoFixed64(message, 5, math.Float64bits(point.Sum))
+	counts := proto.NewBuffer(nil)
+	for _, count := range point.BucketCounts {
+		counts.EncodeFixed64(otlpUint(count))
+	}
+	protoMessage(message, 6, counts)
+	bounds := proto.NewBuffer(nil)
+	for _, bound := range point.ExplicitBounds {
+		bounds.EncodeFixed64(math.Float64bits(bound))
+	}
+	protoMessage(message, 7, bounds)
+	otlpProtoAttributes(message, 9, point.Attributes)
+	return message
+}
+
+// otlpProtoTimes appends the start_time_unix_nano, unless unset, & time_unix_nano fields of a data point
+func otlpProtoTimes(message *proto.Buffer, start string, timestamp string) {
+	if start != "" {
+		protoFixed64(message, 2, otlpUint(start))
+	}
+	protoFixed64(message, 3, otlpUint(timestamp))
+}
+
+// otlpProtoAttributes appends attributes as the KeyValue fields of number, their values being string AnyValues
+func otlpProtoAttributes(message *proto.Buffer, number uint64, attributes []otlpAttribute) {
+	for _, attribute := range attributes {
+		value := proto.NewBuffer(nil)
+		value.EncodeVarint(1<<3 | proto.WireBytes)
+		value.EncodeStringBytes(attribute.Value.StringValue)
+		pair := proto.NewBuffer(nil)
+		protoString(pair, 1, attribute.Key)
+		protoMessage(pair, 2, value)
+		protoMessage(message, number, pair)
+	}
+}
+
+// otlpUint parses a 64 bit integer of the JSON encoding, held in a string
+func otlpUint(value string) uint64 {
+	parsed, _ := strconv.ParseUint(value, 10, 64)
+	return parsed
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
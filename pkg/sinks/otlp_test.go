@@ -0,0 +1,153 @@
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	var received otlpRequest
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	exporter := &OTLPExporter{
+		Endpoint: server.URL + "/v1/metrics",
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+		Resource: map[string]string{"service.name": "chaos-exporter"},
+		Start:    time.Unix(100, 0),
+		Client:   server.Client(),
+	}
+	families := []*dto.MetricFamily{{
+		Name: proto.String("c_engine_experiment_count"),
+		Type: dto.MetricType_GAUGE.Enum(),
+		Metric: []*dto.Metric{{
+			Label: []*dto.LabelPair{{Name: proto.String("engine_name"), Value: proto.String("engine-nginx")}},
+			Gauge: &dto.Gauge{Value: proto.Float64(2)},
+		}},
+	}, {
+		Name: proto.String("litmuschaos_exporter_http_request_duration_seconds"),
+		Type: dto.MetricType_HISTOGRAM.Enum(),
+		Metric: []*dto.Metric{{Histogram: &dto.Histogram{
+			SampleCount: proto.Uint64(5),
+			SampleSum:   proto.Float64(1.5),
+			Bucket: []*dto.Bucket{
+				{UpperBound: proto.Float64(0.1), CumulativeCount: proto.Uint64(2)},
+				{UpperBound: proto.Float64(1), CumulativeCount: proto.Uint64(4)},
+			},
+		}}},
+	}}
+	if err := exporter.Export(context.Background(), families, time.Unix(200, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if authorization != "Bearer token" {
+		t.Errorf("expected the headers to be sent, got %q", authorization)
+	}
+
+	metrics := received.ResourceMetrics[0].ScopeMetrics[0].Metrics
+	if len(metrics) != 2 {
+		t.Fatalf("expected 2 metrics, got %d", len(metrics))
+	}
+	gauge := metrics[0].Gauge.DataPoints[0]
+	if gauge.AsDouble != 2 || gauge.TimeUnixNano != "200000000000" || gauge.Attributes[0].Value.StringValue != "engine-nginx" {
+		t.Errorf("unexpected gauge data point %+v", gauge)
+	}
+	histogram := metrics[1].Histogram.DataPoints[0]
+	if !reflect.DeepEqual(histogram.BucketCounts, []string{"2", "2", "1"}) || histogram.StartTimeUnixNano != "100000000000" {
+		t.Errorf("unexpected histogram data point %+v", histogram)
+	}
+}
+
+func TestOTLPExporterProtobuf(t *testing.T) {
+	var contentType string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	exporter := &OTLPExporter{Endpoint: server.URL, Start: time.Unix(100, 0), Protobuf: true, Client: server.Client()}
+	families := []*dto.MetricFamily{{
+		Name: proto.String("litmuschaos_exporter_collections_total"),
+		Type: dto.MetricType_COUNTER.Enum(),
+		Metric: []*dto.Metric{{
+			Label:   []*dto.LabelPair{{Name: proto.String("result"), Value: proto.String("success")}},
+			Counter: &dto.Counter{Value: proto.Float64(3)},
+		}},
+	}, {
+		Name: proto.String("litmuschaos_exporter_http_request_duration_seconds"),
+		Type: dto.MetricType_HISTOGRAM.Enum(),
+		Metric: []*dto.Metric{{Histogram: &dto.Histogram{
+			SampleCount: proto.Uint64(5),
+			SampleSum:   proto.Float64(1.5),
+			Bucket:      []*dto.Bucket{{UpperBound: proto.Float64(0.1), CumulativeCount: proto.Uint64(2)}},
+		}}},
+	}}
+	if err := exporter.Export(context.Background(), families, time.Unix(200, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "application/x-protobuf" {
+		t.Errorf("expected a protobuf request, got %q", contentType)
+	}
+
+	fixed64 := func(field []byte) uint64 {
+		value, _ := proto.NewBuffer(field).DecodeVarint()
+		return value
+	}
+	resourceMetrics := protoFields(t, protoFields(t, body)[1][0])
+	scopeMetrics := protoFields(t, resourceMetrics[2][0])
+	if scope := protoFields(t, scopeMetrics[1][0]); string(scope[1][0]) != "chaos-exporter" {
+		t.Errorf("unexpected scope %q", scope[1][0])
+	}
+	if len(scopeMetrics[2]) != 2 {
+		t.Fatalf("expected 2 metrics, got %d", len(scopeMetrics[2]))
+	}
+
+	counter := protoFields(t, scopeMetrics[2][0])
+	if string(counter[1][0]) != "litmuschaos_exporter_collections_total" || len(counter[7]) != 1 {
+		t.Fatalf("expected the counter as a sum, got %v", counter)
+	}
+	sum := protoFields(t, counter[7][0])
+	if fixed64(sum[2][0]) != 2 || fixed64(sum[3][0]) != 1 {
+		t.Errorf("expected a cumulative monotonic sum, got %v", sum)
+	}
+	point := protoFields(t, sum[1][0])
+	if math.Float64frombits(fixed64(point[4][0])) != 3 || fixed64(point[2][0]) != 100e9 || fixed64(point[3][0]) != 200e9 {
+		t.Errorf("unexpected sum data point %v", point)
+	}
+	attribute := protoFields(t, point[7][0])
+	if value := protoFields(t, attribute[2][0]); string(attribute[1][0]) != "result" || string(value[1][0]) != "success" {
+		t.Errorf("unexpected attribute %v", attribute)
+	}
+
+	histogram := protoFields(t, protoFields(t, scopeMetrics[2][1])[9][0])
+	point = protoFields(t, histogram[1][0])
+	counts := proto.NewBuffer(point[6][0])
+	var buckets []uint64
+	for {
+		count, err := counts.DecodeFixed64()
+		if err != nil {
+			break
+		}
+		buckets = append(buckets, count)
+	}
+	if fixed64(point[4][0]) != 5 || !reflect.DeepEqual(buckets, []uint64{2, 3}) || len(point[7][0]) != 8 {
+		t.Errorf("unexpected histogram data point, count %d & buckets %v", fixed64(point[4][0]), buckets)
+	}
+}
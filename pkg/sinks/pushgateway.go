@@ -0,0 +1,88 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// Pushgateway pushes metric families to the groups of a job of a Prometheus Pushgateway, for clusters
+// Prometheus cannot scrape
+type Pushgateway struct {
+	// Base URL of the Pushgateway, e.g. http://pushgateway.monitoring:9091
+	URL    string
+	Job    string
+	Client *http.Client
+}
+
+// Push replaces the metrics of the group identified by grouping with families
+func (p *Pushgateway) Push(ctx context.Context, grouping map[string]string, families []*dto.MetricFamily) error {
+	var body bytes.Buffer
+	encoder := expfmt.NewEncoder(&body, expfmt.FmtText)
+	for _, family := range families {
+		if err := encoder.Encode(family); err != nil {
+			return err
+		}
+	}
+	return p.do(ctx, http.MethodPut, grouping, &body)
+}
+
+// Delete deletes the metrics of the group identified by grouping, e.g. once its engine is deleted
+func (p *Pushgateway) Delete(ctx context.Context, grouping map[string]string) error {
+	return p.do(ctx, http.MethodDelete, grouping, nil)
+}
+
+func (p *Pushgateway) do(ctx context.Context, method string, grouping map[string]string, body io.Reader) error {
+	request, err := http.NewRequest(method, p.GroupURL(grouping), body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", string(expfmt.FmtText))
+	}
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// GroupURL returns the URL of a group of the job, its labels sorted by name. Values that cannot be carried as a
+// path segment (empty or holding a /) are base64 encoded
+func (p *Pushgateway) GroupURL(grouping map[string]string) string {
+	path := strings.TrimSuffix(p.URL, "/") + "/metrics/" + pathSegment("job", p.Job)
+	for _, name := range sortedKeys(grouping) {
+		path += "/" + pathSegment(name, grouping[name])
+	}
+	return path
+}
+
+// pathSegment returns the name/value segment of a grouping label
+func pathSegment(name string, value string) string {
+	if value == "" {
+		// The Pushgateway reads a lone = as the empty value
+		return name + "@base64/="
+	}
+	if strings.Contains(value, "/") {
+		return name + "@base64/" + base64.RawURLEncoding.EncodeToString([]byte(value))
+	}
+	return name + "/" + url.PathEscape(value)
+}
@@ -0,0 +1,46 @@
+package sinks
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestPushgateway(t *testing.T) {
+	var method, path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.EscapedPath(), string(data)
+	}))
+	defer server.Close()
+
+	pushgateway := &Pushgateway{URL: server.URL + "/", Job: "chaos-exporter", Client: server.Client()}
+	families := []*dto.MetricFamily{{
+		Name:   proto.String("c_engine_experiment_count"),
+		Type:   dto.MetricType_GAUGE.Enum(),
+		Metric: []*dto.Metric{{Gauge: &dto.Gauge{Value: proto.Float64(2)}}},
+	}}
+	grouping := map[string]string{"engine_name": "engine-nginx", "chaos_namespace": "litmus"}
+	if err := pushgateway.Push(context.Background(), grouping, families); err != nil {
+		t.Fatal(err)
+	}
+	if method != http.MethodPut || path != "/metrics/job/chaos-exporter/chaos_namespace/litmus/engine_name/engine-nginx" {
+		t.Errorf("unexpected request %s %s", method, path)
+	}
+	if !strings.Contains(body, "c_engine_experiment_count 2") {
+		t.Errorf("unexpected body %q", body)
+	}
+
+	if err := pushgateway.Delete(context.Background(), map[string]string{"chaos_namespace": "", "engine_name": "a/b"}); err != nil {
+		t.Fatal(err)
+	}
+	if method != http.MethodDelete || path != "/metrics/job/chaos-exporter/chaos_namespace@base64/=/engine_name@base64/YS9i" {
+		t.Errorf("unexpected request %s %s", method, path)
+	}
+}
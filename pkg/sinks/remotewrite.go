@@ -0,0 +1,210 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/litmuschaos/chaos-exporter/pkg/credentials"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// RemoteWrite writes metric families to a Prometheus remote_write endpoint, e.g. Cortex, Thanos Receive or
+// Mimir, as a snappy compressed WriteRequest
+type RemoteWrite struct {
+	// URL of the endpoint, e.g. http://thanos-receive:19291/api/v1/receive
+	URL string
+	// Bearer token sent on every request, if set
+	BearerToken credentials.Provider
+	// Basic auth credentials sent on every request, if the username is set
+	Username string
+	Password credentials.Provider
+	Client   *http.Client
+}
+
+// remoteWriteSeries is a TimeSeries of a WriteRequest holding a single sample
+type remoteWriteSeries struct {
+	labels []*dto.LabelPair
+	value  float64
+}
+
+// Write writes the samples of families, observed at now unless they carry a timestamp
+func (r *RemoteWrite) Write(ctx context.Context, families []*dto.MetricFamily, now time.Time) error {
+	request, err := http.NewRequest(http.MethodPost, r.URL, bytes.NewReader(snappyEncode(RemoteWriteRequest(families, now))))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-protobuf")
+	request.Header.Set("Content-Encoding", "snappy")
+	request.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	request.Header.Set("User-Agent", "chaos-exporter")
+	if r.BearerToken != nil {
+		token, err := r.BearerToken.Get()
+		if err != nil {
+			return fmt.Errorf("unable to read the bearer token: %v", err)
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	if r.Username != "" {
+		password := ""
+		if r.Password != nil {
+			if password, err = r.Password.Get(); err != nil {
+				return fmt.Errorf("unable to read the password: %v", err)
+			}
+		}
+		request.SetBasicAuth(r.Username, password)
+	}
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// Types of the MetricMetadata of a WriteRequest, by the type of the families
+var remoteWriteMetadataTypes = map[dto.MetricType]uint64{
+	dto.MetricType_COUNTER:   1,
+	dto.MetricType_GAUGE:     2,
+	dto.MetricType_HISTOGRAM: 3,
+	dto.MetricType_SUMMARY:   5,
+}
+
+// RemoteWriteRequest returns the protobuf encoded WriteRequest of families, see prometheus/prompb: a TimeSeries per
+// sample, histograms & summaries expanded into their _bucket/quantile, _sum & _count series, and the MetricMetadata
+// of every family
+func RemoteWriteRequest(families []*dto.MetricFamily, now time.Time) []byte {
+	request := proto.NewBuffer(nil)
+	for _, family := range families {
+		for _, metric := range family.Metric {
+			timestamp := now.UnixNano() / int64(time.Millisecond)
+			if metric.TimestampMs != nil {
+				timestamp = metric.GetTimestampMs()
+			}
+			for _, series := range remoteWriteSamples(family, metric) {
+				message := proto.NewBuffer(nil)
+				for _, label := range series.labels {
+					pair := proto.NewBuffer(nil)
+					protoString(pair, 1, label.GetName())
+					protoString(pair, 2, label.GetValue())
+					protoMessage(message, 1, pair)
+				}
+				sample := proto.NewBuffer(nil)
+				protoFixed64(sample, 1, math.Float64bits(series.value))
+				sample.EncodeVarint(2<<3 | proto.WireVarint)
+				sample.EncodeVarint(uint64(timestamp))
+				protoMessage(message, 2, sample)
+				protoMessage(request, 1, message)
+			}
+		}
+	}
+	for _, family := range families {
+		metadata := proto.NewBuffer(nil)
+		metadata.EncodeVarint(1<<3 | proto.WireVarint)
+		metadata.EncodeVarint(remoteWriteMetadataTypes[family.GetType()])
+		protoString(metadata, 2, family.GetName())
+		protoString(metadata, 4, family.GetHelp())
+		protoMessage(request, 3, metadata)
+	}
+	return request.Bytes()
+}
+
+// remoteWriteSamples returns the series of metric, labelled by __name__ & its labels sorted by name as
+// remote_write requires
+func remoteWriteSamples(family *dto.MetricFamily, metric *dto.Metric) []remoteWriteSeries {
+	name := family.GetName()
+	series := func(suffix string, value float64, extra ...*dto.LabelPair) remoteWriteSeries {
+		labels := []*dto.LabelPair{{Name: proto.String("__name__"), Value: proto.String(name + suffix)}}
+		labels = append(append(labels, metric.Label...), extra...)
+		sort.Slice(labels, func(i, j int) bool { return labels[i].GetName() < labels[j].GetName() })
+		return remoteWriteSeries{labels: labels, value: value}
+	}
+	bound := func(name string, value float64) *dto.LabelPair {
+		return &dto.LabelPair{Name: proto.String(name), Value: proto.String(strconv.FormatFloat(value, 'g', -1, 64))}
+	}
+	switch family.GetType() {
+	case dto.MetricType_COUNTER:
+		return []remoteWriteSeries{series("", metric.GetCounter().GetValue())}
+	case dto.MetricType_GAUGE:
+		return []remoteWriteSeries{series("", metric.GetGauge().GetValue())}
+	case dto.MetricType_HISTOGRAM:
+		histogram := metric.GetHistogram()
+		var samples []remoteWriteSeries
+		for _, bucket := range histogram.Bucket {
+			samples = append(samples, series("_bucket", float64(bucket.GetCumulativeCount()), bound("le", bucket.GetUpperBound())))
+		}
+		samples = append(samples, series("_bucket", float64(histogram.GetSampleCount()), bound("le", math.Inf(1))))
+		return append(samples, series("_sum", histogram.GetSampleSum()), series("_count", float64(histogram.GetSampleCount())))
+	case dto.MetricType_SUMMARY:
+		summary := metric.GetSummary()
+		var samples []remoteWriteSeries
+		for _, quantile := range summary.Quantile {
+			samples = append(samples, series("", quantile.GetValue(), bound("quantile", quantile.GetQuantile())))
+		}
+		return append(samples, series("_sum", summary.GetSampleSum()), series("_count", float64(summary.GetSampleCount())))
+	}
+	return []remoteWriteSeries{series("", metric.GetUntyped().GetValue())}
+}
+
+// protoString appends the string field of number to buffer, left out if empty as proto3 does
+func protoString(buffer *proto.Buffer, number uint64, value string) {
+	if value == "" {
+		return
+	}
+	buffer.EncodeVarint(number<<3 | proto.WireBytes)
+	buffer.EncodeStringBytes(value)
+}
+
+// protoFixed64 appends the 64 bit field of number to buffer, e.g. a double or a fixed64
+func protoFixed64(buffer *proto.Buffer, number uint64, value uint64) {
+	buffer.EncodeVarint(number<<3 | proto.WireFixed64)
+	buffer.EncodeFixed64(value)
+}
+
+// protoMessage appends the embedded message field of number to buffer
+func protoMessage(buffer *proto.Buffer, number uint64, message *proto.Buffer) {
+	buffer.EncodeVarint(number<<3 | proto.WireBytes)
+	buffer.EncodeRawBytes(message.Bytes())
+}
+
+// snappyEncode encodes data in the snappy block format remote_write requires. The data is held in literals, the
+// valid encoding of a block without any copy: the requests are small, the compression is not worth a dependency
+func snappyEncode(data []byte) []byte {
+	encoded := proto.NewBuffer(nil)
+	encoded.EncodeVarint(uint64(len(data)))
+	out := encoded.Bytes()
+	for len(data) > 0 {
+		chunk := data
+		if len(chunk) > 1<<16 {
+			chunk = chunk[:1<<16]
+		}
+		length := len(chunk) - 1
+		if length < 60 {
+			out = append(out, byte(length<<2))
+		} else if length < 1<<8 {
+			out = append(out, 60<<2, byte(length))
+		} else {
+			out = append(out, 61<<2, byte(length), byte(length>>8))
+		}
+		out = append(out, chunk...)
+		data = data[len(chunk):]
+	}
+	return out
+}
@@ -0,0 +1,160 @@
+package sinks
+
+import (
+	"context"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// snappyDecode decodes a snappy block made of literals, as snappyEncode encodes them
+func snappyDecode(t *testing.T, data []byte) []byte {
+	length, err := proto.NewBuffer(data).DecodeVarint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = data[proto.SizeVarint(length):]
+	var decoded []byte
+	for len(data) > 0 {
+		tag, size := int(data[0]>>2), 0
+		switch tag {
+		case 60:
+			tag, size = int(data[1]), 2
+		case 61:
+			tag, size = int(data[1])|int(data[2])<<8, 3
+		default:
+			size = 1
+		}
+		decoded = append(decoded, data[size:size+tag+1]...)
+		data = data[size+tag+1:]
+	}
+	if uint64(len(decoded)) != length {
+		t.Fatalf("expected %d decoded bytes, got %d", length, len(decoded))
+	}
+	return decoded
+}
+
+// protoFields decodes the length delimited & fixed64 fields of a message, by number
+func protoFields(t *testing.T, message []byte) map[uint64][][]byte {
+	fields := make(map[uint64][][]byte)
+	buffer := proto.NewBuffer(message)
+	for {
+		key, err := buffer.DecodeVarint()
+		if err != nil {
+			return fields
+		}
+		var value []byte
+		switch key & 7 {
+		case proto.WireBytes:
+			value, err = buffer.DecodeRawBytes(true)
+		case proto.WireFixed64:
+			var bits uint64
+			bits, err = buffer.DecodeFixed64()
+			value = proto.EncodeVarint(bits)
+		default:
+			var number uint64
+			number, err = buffer.DecodeVarint()
+			value = proto.EncodeVarint(number)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		fields[key>>3] = append(fields[key>>3], value)
+	}
+}
+
+func TestSnappyEncode(t *testing.T) {
+	for _, size := range []int{0, 1, 60, 300, 70000} {
+		data := []byte(strings.Repeat("a", size))
+		if decoded := snappyDecode(t, snappyEncode(data)); string(decoded) != string(data) {
+			t.Errorf("expected %d bytes decoded, got %d", size, len(decoded))
+		}
+	}
+}
+
+func TestRemoteWrite(t *testing.T) {
+	var headers http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	families := []*dto.MetricFamily{{
+		Name: proto.String("c_engine_experiment_count"),
+		Help: proto.String("Total number of experiments executed by the chaos engine"),
+		Type: dto.MetricType_GAUGE.Enum(),
+		Metric: []*dto.Metric{{
+			Label: []*dto.LabelPair{{Name: proto.String("engine_name"), Value: proto.String("engine-nginx")}, {Name: proto.String("chaos_namespace"), Value: proto.String("litmus")}},
+			Gauge: &dto.Gauge{Value: proto.Float64(2)},
+		}},
+	}}
+	sink := &RemoteWrite{URL: server.URL, Username: "chaos", Password: staticToken("secret"), Client: server.Client()}
+	if err := sink.Write(context.Background(), families, time.Unix(60, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if headers.Get("Content-Encoding") != "snappy" || headers.Get("X-Prometheus-Remote-Write-Version") != "0.1.0" {
+		t.Errorf("unexpected headers %v", headers)
+	}
+	if username, password, ok := (&http.Request{Header: headers}).BasicAuth(); !ok || username != "chaos" || password != "secret" {
+		t.Errorf("expected the basic auth credentials, got %v", headers.Get("Authorization"))
+	}
+
+	request := protoFields(t, snappyDecode(t, body))
+	if len(request[1]) != 1 || len(request[3]) != 1 {
+		t.Fatalf("expected a series & its metadata, got %d & %d", len(request[1]), len(request[3]))
+	}
+	series := protoFields(t, request[1][0])
+	var labels []string
+	for _, label := range series[1] {
+		pair := protoFields(t, label)
+		labels = append(labels, string(pair[1][0])+"="+string(pair[2][0]))
+	}
+	if expected := "__name__=c_engine_experiment_count,chaos_namespace=litmus,engine_name=engine-nginx"; strings.Join(labels, ",") != expected {
+		t.Errorf("expected the labels %s, got %s", expected, strings.Join(labels, ","))
+	}
+	sample := protoFields(t, series[2][0])
+	value, _ := proto.NewBuffer(sample[1][0]).DecodeVarint()
+	timestamp, _ := proto.NewBuffer(sample[2][0]).DecodeVarint()
+	if math.Float64frombits(value) != 2 || timestamp != 60000 {
+		t.Errorf("expected the sample 2 at 60000, got %v at %d", math.Float64frombits(value), timestamp)
+	}
+}
+
+func TestRemoteWriteHistogram(t *testing.T) {
+	family := &dto.MetricFamily{
+		Name: proto.String("litmuschaos_exporter_collection_duration_seconds"),
+		Type: dto.MetricType_HISTOGRAM.Enum(),
+		Metric: []*dto.Metric{{Histogram: &dto.Histogram{
+			SampleCount: proto.Uint64(3),
+			SampleSum:   proto.Float64(1.5),
+			Bucket:      []*dto.Bucket{{UpperBound: proto.Float64(0.5), CumulativeCount: proto.Uint64(2)}},
+		}}},
+	}
+	var names []string
+	for _, series := range remoteWriteSamples(family, family.Metric[0]) {
+		name := ""
+		for _, label := range series.labels {
+			name += label.GetName() + "=" + label.GetValue() + " "
+		}
+		names = append(names, strings.TrimSpace(name))
+	}
+	expected := []string{
+		"__name__=litmuschaos_exporter_collection_duration_seconds_bucket le=0.5",
+		"__name__=litmuschaos_exporter_collection_duration_seconds_bucket le=+Inf",
+		"__name__=litmuschaos_exporter_collection_duration_seconds_sum",
+		"__name__=litmuschaos_exporter_collection_duration_seconds_count",
+	}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected the series %v, got %v", expected, names)
+	}
+}
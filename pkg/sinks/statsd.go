@@ -0,0 +1,94 @@
+package sinks
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Tag is a dimension of a StatsD metric, sent as a DogStatsD tag or appended to the name in plain StatsD
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// StatsD sends counters & gauges to a StatsD (or DogStatsD) daemon, one datagram per metric
+type StatsD struct {
+	// Prefix of the metric names, e.g. litmuschaos
+	Prefix string
+	// Send the tags in the DogStatsD format (name:value|c|#tag:value), rather than in the name
+	DogStatsD bool
+	conn      io.Writer
+}
+
+// NewStatsD returns the client of the StatsD daemon listening on the UDP host:port address
+func NewStatsD(address string, prefix string, dogStatsD bool) (*StatsD, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	return &StatsD{Prefix: prefix, DogStatsD: dogStatsD, conn: conn}, nil
+}
+
+// Count adds value to a counter
+func (s *StatsD) Count(name string, value float64, tags ...Tag) error {
+	return s.send(name, value, "c", tags)
+}
+
+// Gauge sets a gauge to value
+func (s *StatsD) Gauge(name string, value float64, tags ...Tag) error {
+	return s.send(name, value, "g", tags)
+}
+
+func (s *StatsD) send(name string, value float64, kind string, tags []Tag) error {
+	_, err := io.WriteString(s.conn, s.line(name, value, kind, tags))
+	return err
+}
+
+// line formats a metric in the StatsD line protocol
+func (s *StatsD) line(name string, value float64, kind string, tags []Tag) string {
+	parts := []string{}
+	if s.Prefix != "" {
+		parts = append(parts, s.Prefix)
+	}
+	parts = append(parts, statsdEscape(name))
+	if !s.DogStatsD {
+		for _, tag := range tags {
+			parts = append(parts, statsdEscape(tag.Value))
+		}
+	}
+	line := fmt.Sprintf("%s:%s|%s", strings.Join(parts, "."), strconv.FormatFloat(value, 'f', -1, 64), kind)
+	if s.DogStatsD && len(tags) > 0 {
+		pairs := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			pairs = append(pairs, statsdEscape(tag.Name)+":"+dogStatsDEscape(tag.Value))
+		}
+		line += "|#" + strings.Join(pairs, ",")
+	}
+	return line
+}
+
+// Replace the characters delimiting the fields of the line protocol, and for names the . separating their
+// components
+var (
+	statsdReplacer    = strings.NewReplacer(":", "_", "|", "_", "@", "_", "#", "_", ",", "_", ".", "_", "\n", "_", " ", "_")
+	dogStatsDReplacer = strings.NewReplacer(":", "_", "|", "_", "@", "_", "#", "_", ",", "_", "\n", "_", " ", "_")
+)
+
+// statsdEscape returns s as a component of a metric name, empty values as none
+func statsdEscape(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return statsdReplacer.Replace(s)
+}
+
+// dogStatsDEscape returns s as the value of a DogStatsD tag, empty values as none
+func dogStatsDEscape(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return dogStatsDReplacer.Replace(s)
+}
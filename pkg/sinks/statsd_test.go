@@ -0,0 +1,44 @@
+package sinks
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestStatsD(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	tags := []Tag{{Name: "engine_name", Value: "engine-nginx"}, {Name: "kubernetes_version", Value: "1.13"}, {Name: "to", Value: ""}}
+	tests := map[bool]string{
+		false: "litmuschaos.verdict_transitions.engine-nginx.1_13.none:1|c",
+		true:  "litmuschaos.verdict_transitions:1|c|#engine_name:engine-nginx,kubernetes_version:1.13,to:none",
+	}
+	for dogStatsD, expected := range tests {
+		client, err := NewStatsD(listener.LocalAddr().String(), "litmuschaos", dogStatsD)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.Count("verdict_transitions", 1, tags...); err != nil {
+			t.Fatal(err)
+		}
+		buffer := make([]byte, 512)
+		listener.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := listener.ReadFrom(buffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line := string(buffer[:n]); line != expected {
+			t.Errorf("expected %q, got %q", expected, line)
+		}
+	}
+
+	client := &StatsD{Prefix: "chaos"}
+	if line := client.line("c_engine_passed_experiments", 2.5, "g", nil); line != "chaos.c_engine_passed_experiments:2.5|g" {
+		t.Errorf("unexpected gauge line %q", line)
+	}
+}
@@ -0,0 +1,170 @@
+// Package sla derives a per-application resilience SLA from the chaos windows, probe outcomes and
+// verdicts observed by the exporter: the share of the time under chaos the application stayed available
+package sla
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// EngineState is the state of a chaosengine as observed by a collection pass
+type EngineState struct {
+	// Set while any experiment of the engine is running
+	UnderChaos bool
+	// Set while none of the steady-state probes of the engine are failing
+	Available bool
+	// Number of experiments of the engine with a pass & fail verdict
+	Passed int
+	Failed int
+}
+
+// Report holds the resilience SLA of an application
+type Report struct {
+	AppNamespace     string  `json:"appNamespace"`
+	AppLabel         string  `json:"appLabel"`
+	ChaosSeconds     float64 `json:"chaosSeconds"`
+	AvailableSeconds float64 `json:"availableSeconds"`
+	// Percentage of the chaos time during which the application stayed available
+	SLAPercent        float64 `json:"slaPercent"`
+	PassedExperiments int     `json:"passedExperiments"`
+	FailedExperiments int     `json:"failedExperiments"`
+	// Keys of the chaosengines targeting the application, as namespace/name, ordered
+	Engines []string `json:"engines"`
+}
+
+// application holds the chaos windows accumulated for an application
+type application struct {
+	namespace        string
+	label            string
+	engines          map[string]EngineState
+	lastUpdate       time.Time
+	chaosSeconds     float64
+	availableSeconds float64
+}
+
+// Tracker accumulates the chaos windows of the applications targeted by the monitored chaosengines
+type Tracker struct {
+	mu    sync.Mutex
+	apps  map[string]*application
+	clock clock.Clock
+}
+
+// NewTracker returns an empty Tracker
+func NewTracker() *Tracker {
+	return &Tracker{apps: make(map[string]*application), clock: clock.RealClock{}}
+}
+
+// SetClock replaces the source of the current time, for e.g. to account chaos windows in apiserver time
+func (t *Tracker) SetClock(c clock.Clock) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.clock = c
+}
+
+// Observe records the state of a chaosengine targeting the application identified by its namespace & label.
+// The time since the previous observation is attributed to the previously observed state of the application.
+// An engine re-targeted at another application stops accounting for the one it targeted before: the reports of
+// the applications left without engines are returned along with the report of the application, and dropped
+func (t *Tracker) Observe(appNamespace string, appLabel string, engine string, state EngineState) (Report, []Report) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := appNamespace + "/" + appLabel
+	dropped := t.remove(engine, key)
+	app, ok := t.apps[key]
+	if !ok {
+		app = &application{namespace: appNamespace, label: appLabel, engines: make(map[string]EngineState), lastUpdate: t.clock.Now()}
+		t.apps[key] = app
+	}
+	app.advance(t.clock.Now())
+	app.engines[engine] = state
+	return app.report(), dropped
+}
+
+// Forget stops tracking a chaosengine, for e.g. once deleted, so that its last state no longer accounts for the
+// applications it targeted. The time accumulated so far is kept for the applications still targeted by other
+// engines, the reports of those left without engines are returned, and dropped
+func (t *Tracker) Forget(engine string) []Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.remove(engine, "")
+}
+
+// remove removes engine from the applications, but the one of keep, dropping those left without engines
+func (t *Tracker) remove(engine string, keep string) []Report {
+	var dropped []Report
+	for key, app := range t.apps {
+		if _, ok := app.engines[engine]; !ok || key == keep {
+			continue
+		}
+		app.advance(t.clock.Now())
+		delete(app.engines, engine)
+		if len(app.engines) == 0 {
+			dropped = append(dropped, app.report())
+			delete(t.apps, key)
+		}
+	}
+	return dropped
+}
+
+// Reports returns the SLA of every tracked application, ordered by namespace & label
+func (t *Tracker) Reports() []Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	reports := make([]Report, 0, len(t.apps))
+	for _, app := range t.apps {
+		app.advance(t.clock.Now())
+		reports = append(reports, app.report())
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].AppNamespace != reports[j].AppNamespace {
+			return reports[i].AppNamespace < reports[j].AppNamespace
+		}
+		return reports[i].AppLabel < reports[j].AppLabel
+	})
+	return reports
+}
+
+// advance accumulates the time elapsed since the last update in the current state of the application.
+// The application is under chaos if any of its engines is, and available only if all of them are
+func (app *application) advance(now time.Time) {
+	elapsed := now.Sub(app.lastUpdate).Seconds()
+	app.lastUpdate = now
+
+	underChaos, available := false, true
+	for _, state := range app.engines {
+		underChaos = underChaos || state.UnderChaos
+		available = available && state.Available
+	}
+	if underChaos && elapsed > 0 {
+		app.chaosSeconds += elapsed
+		if available {
+			app.availableSeconds += elapsed
+		}
+	}
+}
+
+func (app *application) report() Report {
+	report := Report{
+		AppNamespace:     app.namespace,
+		AppLabel:         app.label,
+		ChaosSeconds:     app.chaosSeconds,
+		AvailableSeconds: app.availableSeconds,
+		SLAPercent:       100,
+	}
+	if app.chaosSeconds > 0 {
+		report.SLAPercent = 100 * app.availableSeconds / app.chaosSeconds
+	}
+	for engine, state := range app.engines {
+		report.PassedExperiments += state.Passed
+		report.FailedExperiments += state.Failed
+		report.Engines = append(report.Engines, engine)
+	}
+	sort.Strings(report.Engines)
+	return report
+}
@@ -0,0 +1,92 @@
+package sla
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestTracker(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	tracker := NewTracker()
+	tracker.SetClock(fakeClock)
+
+	// 60s of chaos with the app available, followed by 30s of chaos with a failing probe
+	tracker.Observe("default", "app=nginx", "engine-nginx", EngineState{UnderChaos: true, Available: true})
+	fakeClock.Step(60 * time.Second)
+	tracker.Observe("default", "app=nginx", "engine-nginx", EngineState{UnderChaos: true, Available: false})
+	fakeClock.Step(30 * time.Second)
+	tracker.Observe("default", "app=nginx", "engine-nginx", EngineState{Available: true, Failed: 1})
+	// Time outside of chaos windows does not count
+	fakeClock.Step(600 * time.Second)
+
+	reports := tracker.Reports()
+	if len(reports) != 1 {
+		t.Fatalf("expected a single application, got %d", len(reports))
+	}
+	report := reports[0]
+	if report.ChaosSeconds != 90 || report.AvailableSeconds != 60 {
+		t.Errorf("expected 90s of chaos & 60s available, got %v & %v", report.ChaosSeconds, report.AvailableSeconds)
+	}
+	if report.SLAPercent < 66.6 || report.SLAPercent > 66.7 {
+		t.Errorf("expected an SLA of 66.67%%, got %v", report.SLAPercent)
+	}
+	if report.FailedExperiments != 1 {
+		t.Errorf("expected a single failed experiment, got %d", report.FailedExperiments)
+	}
+}
+
+func TestTrackerForget(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	tracker := NewTracker()
+	tracker.SetClock(fakeClock)
+
+	tracker.Observe("default", "app=nginx", "engine-nginx", EngineState{UnderChaos: true, Available: true})
+	tracker.Observe("default", "app=nginx", "engine-nginx-cpu", EngineState{Available: true})
+	fakeClock.Step(60 * time.Second)
+	// The engine is deleted while under chaos, its chaos window ends with it
+	if dropped := tracker.Forget("engine-nginx"); len(dropped) != 0 {
+		t.Errorf("expected the application to be kept, got %v dropped", dropped)
+	}
+	fakeClock.Step(60 * time.Second)
+
+	if report := tracker.Reports()[0]; report.ChaosSeconds != 60 || report.AvailableSeconds != 60 {
+		t.Errorf("expected 60s of chaos & 60s available, got %v & %v", report.ChaosSeconds, report.AvailableSeconds)
+	}
+	if engines := tracker.Reports()[0].Engines; len(engines) != 1 || engines[0] != "engine-nginx-cpu" {
+		t.Errorf("expected the remaining engine, got %v", engines)
+	}
+
+	// The application is dropped along with its last engine
+	dropped := tracker.Forget("engine-nginx-cpu")
+	if len(dropped) != 1 || dropped[0].AppNamespace != "default" || dropped[0].AppLabel != "app=nginx" {
+		t.Errorf("expected app=nginx to be dropped, got %v", dropped)
+	}
+	if reports := tracker.Reports(); len(reports) != 0 {
+		t.Errorf("expected no application, got %v", reports)
+	}
+}
+
+func TestTrackerRetarget(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	tracker := NewTracker()
+	tracker.SetClock(fakeClock)
+
+	tracker.Observe("default", "app=nginx", "engine", EngineState{UnderChaos: true, Available: true})
+	fakeClock.Step(60 * time.Second)
+	// The applabel of the engine is edited, its state no longer accounts for app=nginx
+	report, dropped := tracker.Observe("default", "app=httpd", "engine", EngineState{UnderChaos: true, Available: false})
+	if len(dropped) != 1 || dropped[0].AppLabel != "app=nginx" || dropped[0].ChaosSeconds != 60 {
+		t.Errorf("expected app=nginx to be dropped after 60s of chaos, got %v", dropped)
+	}
+	fakeClock.Step(30 * time.Second)
+
+	reports := tracker.Reports()
+	if len(reports) != 1 || reports[0].AppLabel != "app=httpd" {
+		t.Fatalf("expected app=httpd alone, got %v", reports)
+	}
+	if report.ChaosSeconds != 0 || reports[0].ChaosSeconds != 30 || reports[0].AvailableSeconds != 0 {
+		t.Errorf("expected the chaos of app=httpd to start with the engine, got %v", reports[0])
+	}
+}
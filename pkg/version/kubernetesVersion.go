@@ -12,13 +12,11 @@ func GetKubernetesVersion(cfg *rest.Config) (string, error) {
 	// function to get Kubernetes Version
 	clientSet, err := discovery.NewDiscoveryClientForConfig(cfg)
 	if err != nil {
-		fmt.Println("Unable to create the required ClientSet")
-		return "N/A", err
+		return "N/A", fmt.Errorf("unable to create the required ClientSet: %v", err)
 	}
 	version, err := clientSet.ServerVersion()
 	if err != nil {
-		fmt.Println("ClientSet is unable to communicate with the kubernetes cluster")
-		return "N/A", err
+		return "N/A", fmt.Errorf("ClientSet is unable to communicate with the kubernetes cluster: %v", err)
 	}
 	return version.GitVersion, nil
 
@@ -0,0 +1,64 @@
+package version
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/rest"
+)
+
+// Provider caches the Kubernetes & OpenEBS versions, so that they are not looked up on every collection
+// while still reflecting upgrades once the refresh interval has elapsed
+type Provider struct {
+	cfg              *rest.Config
+	openebsNamespace string
+	interval         time.Duration
+	clock            clock.Clock
+
+	mu                sync.Mutex
+	kubernetesVersion string
+	openebsVersion    string
+	refreshed         time.Time
+}
+
+// NewProvider returns a Provider refreshing the versions every interval, 0 looks them up only once
+func NewProvider(cfg *rest.Config, openebsNamespace string, interval time.Duration) *Provider {
+	return &Provider{cfg: cfg, openebsNamespace: openebsNamespace, interval: interval, clock: clock.RealClock{}}
+}
+
+// SetConfig replaces the client config used for the lookups, for e.g. once its credentials have been rotated
+func (p *Provider) SetConfig(cfg *rest.Config) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cfg = cfg
+}
+
+// Versions returns the cached Kubernetes & OpenEBS versions, looking them up again if they are older
+// than the refresh interval. A failed lookup retains the previously found version
+func (p *Provider) Versions() (kubernetesVersion string, openebsVersion string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.refreshed.IsZero() && (p.interval <= 0 || p.clock.Since(p.refreshed) < p.interval) {
+		return p.kubernetesVersion, p.openebsVersion
+	}
+	p.refreshed = p.clock.Now()
+
+	kubernetesVersion, err := GetKubernetesVersion(p.cfg)
+	if err != nil {
+		log.Info("Unable to get Kubernetes Version : ", err)
+	}
+	if err == nil || p.kubernetesVersion == "" {
+		p.kubernetesVersion = kubernetesVersion
+	}
+	openebsVersion, err = GetOpenebsVersion(p.cfg, p.openebsNamespace)
+	if err != nil {
+		log.Info("Unable to get OpenEBS Version : ", err)
+	}
+	if err == nil || p.openebsVersion == "" {
+		p.openebsVersion = openebsVersion
+	}
+	return p.kubernetesVersion, p.openebsVersion
+}
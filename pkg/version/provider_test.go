@@ -0,0 +1,58 @@
+package version
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/rest"
+)
+
+func TestProviderVersions(t *testing.T) {
+	var lookups, failing int32
+	gitVersion := atomic.Value{}
+	gitVersion.Store("v1.13.0")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/version" {
+			atomic.AddInt32(&lookups, 1)
+			if atomic.LoadInt32(&failing) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprintf(w, `{"gitVersion":%q}`, gitVersion.Load())
+			return
+		}
+		fmt.Fprint(w, `{"apiVersion":"v1","kind":"PodList","metadata":{},"items":[`+
+			`{"metadata":{"name":"maya-apiserver","labels":{"openebs.io/version":"1.0.0"}}}]}`)
+	}))
+	defer server.Close()
+
+	fakeClock := clock.NewFakeClock(time.Now())
+	provider := NewProvider(&rest.Config{Host: server.URL}, "openebs", time.Minute)
+	provider.clock = fakeClock
+	if kubernetesVersion, openebsVersion := provider.Versions(); kubernetesVersion != "v1.13.0" || openebsVersion != "1.0.0" {
+		t.Errorf("unexpected versions %s & %s", kubernetesVersion, openebsVersion)
+	}
+
+	// Served from cache until the refresh interval has elapsed
+	gitVersion.Store("v1.14.0")
+	if kubernetesVersion, _ := provider.Versions(); kubernetesVersion != "v1.13.0" || atomic.LoadInt32(&lookups) != 1 {
+		t.Errorf("expected the cached version, got %s after %d lookups", kubernetesVersion, atomic.LoadInt32(&lookups))
+	}
+	fakeClock.Step(time.Minute)
+	if kubernetesVersion, _ := provider.Versions(); kubernetesVersion != "v1.14.0" {
+		t.Errorf("expected the upgraded version, got %s", kubernetesVersion)
+	}
+
+	// A failed lookup keeps the version found last
+	atomic.StoreInt32(&failing, 1)
+	fakeClock.Step(time.Minute)
+	if kubernetesVersion, _ := provider.Versions(); kubernetesVersion != "v1.14.0" || atomic.LoadInt32(&lookups) != 3 {
+		t.Errorf("expected the previous version, got %s after %d lookups", kubernetesVersion, atomic.LoadInt32(&lookups))
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -109,7 +110,7 @@ var _ = Describe("BDD on chaos-exporter", func() {
 			}
 
 			By("Checking experiments metrics")
-			expTotal, passTotal, failTotal, expMap, err := chaosmetrics.GetLitmusChaosMetrics(config, chaosengine, appNS)
+			expTotal, passTotal, failTotal, expMap, err := chaosmetrics.GetLitmusChaosMetrics(context.TODO(), config, chaosengine, appNS)
 			if err != nil {
 				Fail(err.Error()) // Unable to get metrics:
 			}